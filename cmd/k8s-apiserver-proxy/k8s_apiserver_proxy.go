@@ -14,6 +14,11 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 		endpointsConfigFile        string
 		refreshEndpointsInterval   time.Duration
 		refreshEndpointsKubeconfig string
+		nodeName                   string
+		healthCheckInterval        time.Duration
+		healthCheckTimeout         time.Duration
+		drainTimeout               time.Duration
+		metricsAddress             string
 	}
 
 	cmd := &cobra.Command{
@@ -36,6 +41,11 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				EndpointsConfigFile: opts.endpointsConfigFile,
 				KubeconfigFile:      opts.refreshEndpointsKubeconfig,
 				RefreshCh:           refreshCh,
+				NodeName:            opts.nodeName,
+				HealthCheckInterval: opts.healthCheckInterval,
+				HealthCheckTimeout:  opts.healthCheckTimeout,
+				DrainTimeout:        opts.drainTimeout,
+				MetricsAddress:      opts.metricsAddress,
 			}
 
 			if err := p.Run(cmd.Context()); err != nil {
@@ -55,6 +65,11 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	cmd.Flags().StringVar(&opts.endpointsConfigFile, "endpoints", "/etc/kubernetes/k8s-apiserver-proxy.json", "configuration file with known kube-apiserver endpoints")
 	cmd.Flags().StringVar(&opts.refreshEndpointsKubeconfig, "kubeconfig", "/etc/kubernetes/kubelet.conf", "kubeconfig file to use for updating list of known kube-apiserver endpoints")
 	cmd.Flags().DurationVar(&opts.refreshEndpointsInterval, "refresh-interval", 30*time.Second, "interval between checking for new kube-apiserver endpoints. set to 0 to disable")
+	cmd.Flags().StringVar(&opts.nodeName, "node-name", "", "name of the local node. if set, kube-apiserver endpoints in the same topology zone are preferred")
+	cmd.Flags().DurationVar(&opts.healthCheckInterval, "health-check-interval", 5*time.Second, "interval between health checks of the kube-apiserver endpoints")
+	cmd.Flags().DurationVar(&opts.healthCheckTimeout, "health-check-timeout", 2*time.Second, "timeout of health checks of the kube-apiserver endpoints")
+	cmd.Flags().DurationVar(&opts.drainTimeout, "drain-timeout", 30*time.Second, "time within which connections to removed kube-apiserver endpoints are closed, and to wait for connections to end on shutdown")
+	cmd.Flags().StringVar(&opts.metricsAddress, "metrics-address", "", "listen address for the Prometheus metrics endpoint, e.g. \"127.0.0.1:4219\"")
 
 	return cmd
 }
@@ -87,7 +87,9 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 		newDisableCmd(env),
 		newRefreshCertsCmd(env),
 		newCertsStatusCmd(env),
+		newRotateCACmd(env),
 		newSetCmd(env),
+		newApplyCmd(env),
 		newGetCmd(env),
 		newInspectCmd(env),
 	)
@@ -105,6 +107,8 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 		newXCAPICmd(env),
 		newListImagesCmd(env),
 		newXCleanupCmd(env),
+		newXAuthTokensCmd(env),
+		newXEtcdCmd(env),
 	)
 
 	cmd.DisableAutoGenTag = true
@@ -0,0 +1,256 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/client/k8sd"
+	"github.com/canonical/k8sd/pkg/utils"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// ClusterConfigDocument is the declarative cluster configuration that is used by "k8s apply".
+type ClusterConfigDocument struct {
+	apiv2.UserFacingClusterConfig `yaml:",inline"`
+
+	// Addons is the configuration of the operator-defined Helm add-ons.
+	Addons map[string]k8sdapi.HelmAddonConfig `json:"addons,omitempty" yaml:"addons,omitempty"`
+}
+
+type ApplyResult struct {
+	DryRunResult `yaml:",inline"`
+
+	// Applied is true if the changes were applied.
+	Applied bool `json:"applied" yaml:"applied"`
+}
+
+func (r ApplyResult) String() string {
+	if len(r.Changes) == 0 {
+		return "Cluster configuration is up to date."
+	}
+	if r.Applied {
+		return fmt.Sprintf("%s\n\nConfiguration applied.", r.DryRunResult)
+	}
+	return fmt.Sprintf("%s\n\nThe cluster configuration has drifted. Run without --dry-run to apply the changes.", r.DryRunResult)
+}
+
+func newApplyCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		file         string
+		outputFormat string
+		timeout      time.Duration
+		dryRun       bool
+	}
+	cmd := &cobra.Command{
+		Use:   "apply -f <file>",
+		Short: "Converge the cluster configuration to a YAML document",
+		Long: `Converge the cluster configuration to a YAML document with the same structure as the output of 'k8s get', plus an optional 'addons' section.
+
+Fields that are not set in the document keep their current value, except that:
+  - features that are not enabled in the document are disabled,
+  - annotations that are not in the document are removed,
+  - add-ons that are not in the document are disabled.
+
+The changes are shown before they are applied in a single update. With --dry-run, the changes are only shown
+and the command exits with a non-zero code if the cluster configuration has drifted from the document.`,
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.file == "" {
+				cmd.PrintErrln("Error: The --file flag is required.")
+				env.Exit(1)
+				return
+			}
+
+			document, err := getClusterConfigDocument(env, opts.file)
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to read the cluster configuration document %q.\n\nThe error was: %v\n", opts.file, err)
+				env.Exit(1)
+				return
+			}
+
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			if _, initialized, err := client.NodeStatus(cmd.Context()); err != nil {
+				cmd.PrintErrf("Error: Failed to check the current node status.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			} else if !initialized {
+				cmd.PrintErrln("Error: The node is not part of a Kubernetes cluster. You can bootstrap a new cluster with:\n\n  sudo k8s bootstrap")
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			current, err := client.GetClusterConfigWithAddons(ctx)
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to get the current cluster configuration.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			request := applyRequest(current, document)
+			request.ExpectedResourceVersion = current.ResourceVersion
+
+			request.DryRun = true
+			changes, err := client.SetClusterConfigWithAddons(ctx, request)
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to compare the cluster configuration.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			result := ApplyResult{DryRunResult: DryRunResult(changes)}
+			if len(result.Changes) == 0 || opts.dryRun {
+				outputFormatter.Print(result)
+				if len(result.Changes) > 0 {
+					env.Exit(1)
+				}
+				return
+			}
+
+			request.DryRun = false
+			if _, err := client.SetClusterConfigWithAddons(ctx, request); err != nil {
+				if errors.Is(err, k8sd.ErrClusterConfigConflict) {
+					cmd.PrintErrln("Error: The cluster configuration was changed while applying the document. Run the command again to apply it on top of the latest changes.")
+				} else {
+					cmd.PrintErrf("Error: Failed to apply the cluster configuration.\n\nThe error was: %v\n", err)
+				}
+				env.Exit(1)
+				return
+			}
+
+			result.Applied = true
+			outputFormatter.Print(result)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.file, "file", "f", "", "path to the YAML file containing the cluster configuration. Use '-' to read from stdin.")
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "show the changes without applying them and exit with a non-zero code if there are any")
+
+	return cmd
+}
+
+func getClusterConfigDocument(env cmdutil.ExecutionEnvironment, filePath string) (ClusterConfigDocument, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if filePath == "-" {
+		if b, err = io.ReadAll(env.Stdin); err != nil {
+			return ClusterConfigDocument{}, fmt.Errorf("failed to read from stdin: %w", err)
+		}
+	} else if b, err = os.ReadFile(filePath); err != nil {
+		return ClusterConfigDocument{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var document ClusterConfigDocument
+	if err := yaml.UnmarshalStrict(b, &document); err != nil {
+		return ClusterConfigDocument{}, fmt.Errorf("failed to parse YAML document: %w", err)
+	}
+	for name, addon := range document.Addons {
+		if addon.Values, err = utils.NormalizeYAMLMap(addon.Values); err != nil {
+			return ClusterConfigDocument{}, fmt.Errorf("invalid values of add-on %q: %w", name, err)
+		}
+		document.Addons[name] = addon
+	}
+	return document, nil
+}
+
+// applyRequest returns the SetClusterConfig request that converges the current cluster configuration to a document.
+// Features that are not enabled in the document are disabled, annotations that are not in the document are removed
+// ("-") and add-ons that are not in the document are disabled. Optional settings that are not in the document are
+// sent as their empty or zero value, so that they are cleared. Settings with a non-empty default (e.g.
+// dns.cluster-domain or load-balancer.l2-mode) keep their current value if they are not in the document.
+func applyRequest(current k8sdapi.GetClusterConfigResponse, document ClusterConfigDocument) k8sdapi.SetClusterConfigRequest {
+	config := document.UserFacingClusterConfig
+
+	setIfMissing(&config.CloudProvider, "")
+	setIfMissing(&config.Ingress.DefaultTLSSecret, "")
+	setIfMissing(&config.Ingress.EnableProxyProtocol, false)
+	setIfMissing(&config.LoadBalancer.CIDRs, []string{})
+	setIfMissing(&config.LoadBalancer.L2Interfaces, []string{})
+	setIfMissing(&config.LoadBalancer.BGPMode, false)
+	setIfMissing(&config.LoadBalancer.BGPLocalASN, 0)
+	setIfMissing(&config.LoadBalancer.BGPPeerAddress, "")
+	setIfMissing(&config.LoadBalancer.BGPPeerASN, 0)
+
+	for _, feature := range []struct {
+		desired **bool
+		current *bool
+	}{
+		{desired: &config.Network.Enabled, current: current.Config.Network.Enabled},
+		{desired: &config.DNS.Enabled, current: current.Config.DNS.Enabled},
+		{desired: &config.Gateway.Enabled, current: current.Config.Gateway.Enabled},
+		{desired: &config.Ingress.Enabled, current: current.Config.Ingress.Enabled},
+		{desired: &config.LoadBalancer.Enabled, current: current.Config.LoadBalancer.Enabled},
+		{desired: &config.LocalStorage.Enabled, current: current.Config.LocalStorage.Enabled},
+		{desired: &config.MetricsServer.Enabled, current: current.Config.MetricsServer.Enabled},
+	} {
+		if *feature.desired == nil && feature.current != nil && *feature.current {
+			*feature.desired = utils.Pointer(false)
+		}
+	}
+
+	config.Annotations = maps.Clone(config.Annotations)
+	for key := range current.Config.Annotations {
+		if _, ok := config.Annotations[key]; !ok {
+			if config.Annotations == nil {
+				config.Annotations = map[string]string{}
+			}
+			config.Annotations[key] = "-"
+		}
+	}
+
+	addons := maps.Clone(document.Addons)
+	for name, addon := range addons {
+		if addon.Values == nil {
+			// values are replaced as a whole, an empty map clears them
+			addon.Values = map[string]any{}
+			addons[name] = addon
+		}
+	}
+	for name, addon := range current.Addons {
+		if _, ok := addons[name]; !ok && addon.GetEnabled() {
+			if addons == nil {
+				addons = map[string]k8sdapi.HelmAddonConfig{}
+			}
+			addons[name] = k8sdapi.HelmAddonConfig{Enabled: utils.Pointer(false)}
+		}
+	}
+
+	return k8sdapi.SetClusterConfigRequest{
+		SetClusterConfigRequest: apiv2.SetClusterConfigRequest{Config: config},
+		Addons:                  addons,
+	}
+}
+
+// setIfMissing sets a field of the document to a value if the field is not set.
+func setIfMissing[T any](field **T, value T) {
+	if *field == nil {
+		*field = &value
+	}
+}
@@ -0,0 +1,151 @@
+package k8s_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	"github.com/canonical/k8sd/cmd/k8s"
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	k8sdmock "github.com/canonical/k8sd/pkg/client/k8sd/mock"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	"github.com/canonical/k8sd/pkg/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestK8sApplyCmd(t *testing.T) {
+	document := `
+network:
+  enabled: true
+load-balancer:
+  enabled: true
+  cidrs: [10.0.0.0/24]
+annotations:
+  k8sd/v1alpha/example: "true"
+addons:
+  demo:
+    enabled: true
+    values:
+      image:
+        tag: v1
+  plain:
+    enabled: true
+`
+	current := k8sdapi.GetClusterConfigResponse{
+		GetClusterConfigResponse: apiv2.GetClusterConfigResponse{
+			Config: apiv2.UserFacingClusterConfig{
+				Network:     apiv2.NetworkConfig{Enabled: utils.Pointer(true)},
+				Ingress:     apiv2.IngressConfig{Enabled: utils.Pointer(true)},
+				Annotations: map[string]string{"k8sd/v1alpha/old": "true"},
+			},
+		},
+		Addons:          map[string]k8sdapi.HelmAddonConfig{"legacy": {Enabled: utils.Pointer(true)}},
+		ResourceVersion: 7,
+	}
+	expectedCall := k8sdapi.SetClusterConfigRequest{
+		SetClusterConfigRequest: apiv2.SetClusterConfigRequest{
+			Config: apiv2.UserFacingClusterConfig{
+				Network: apiv2.NetworkConfig{Enabled: utils.Pointer(true)},
+				Ingress: apiv2.IngressConfig{
+					Enabled:             utils.Pointer(false),
+					DefaultTLSSecret:    utils.Pointer(""),
+					EnableProxyProtocol: utils.Pointer(false),
+				},
+				// optional settings that are not in the document are cleared
+				LoadBalancer: apiv2.LoadBalancerConfig{
+					Enabled:        utils.Pointer(true),
+					CIDRs:          utils.Pointer([]string{"10.0.0.0/24"}),
+					L2Interfaces:   utils.Pointer([]string{}),
+					BGPMode:        utils.Pointer(false),
+					BGPLocalASN:    utils.Pointer(0),
+					BGPPeerAddress: utils.Pointer(""),
+					BGPPeerASN:     utils.Pointer(0),
+				},
+				CloudProvider: utils.Pointer(""),
+				Annotations:   map[string]string{"k8sd/v1alpha/example": "true", "k8sd/v1alpha/old": "-"},
+			},
+		},
+		Addons: map[string]k8sdapi.HelmAddonConfig{
+			"demo":   {Enabled: utils.Pointer(true), Values: map[string]any{"image": map[string]any{"tag": "v1"}}},
+			"plain":  {Enabled: utils.Pointer(true), Values: map[string]any{}},
+			"legacy": {Enabled: utils.Pointer(false)},
+		},
+		ExpectedResourceVersion: 7,
+	}
+	drift := k8sdapi.SetClusterConfigResponse{
+		Changes: []k8sdapi.FieldChange{{Key: "ingress.enabled", Old: "true", New: "false"}},
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		response       k8sdapi.SetClusterConfigResponse
+		expectedDryRun bool
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Apply",
+			response:       drift,
+			expectedStdout: "Configuration applied.",
+		},
+		{
+			name:           "DryRun",
+			args:           []string{"--dry-run"},
+			response:       drift,
+			expectedDryRun: true,
+			expectedCode:   1,
+			expectedStdout: "ingress.enabled: true -> false",
+		},
+		{
+			name:           "UpToDate",
+			expectedDryRun: true,
+			expectedStdout: "up to date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			file := filepath.Join(t.TempDir(), "cluster.yaml")
+			g.Expect(os.WriteFile(file, []byte(document), 0o600)).To(Succeed())
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			mockClient := &k8sdmock.Mock{
+				NodeStatusInitialized:              true,
+				GetClusterConfigWithAddonsResponse: current,
+				SetClusterConfigWithAddonsResponse: tt.response,
+			}
+			var returnCode int
+			env := cmdutil.ExecutionEnvironment{
+				Stdout: stdout,
+				Stderr: stderr,
+				Getuid: func() int { return 0 },
+				Snap: &snapmock.Snap{
+					Mock: snapmock.Mock{
+						K8sdClient: mockClient,
+					},
+				},
+				Exit: func(rc int) { returnCode = rc },
+			}
+			cmd := k8s.NewRootCmd(env)
+
+			cmd.SetArgs(append([]string{"apply", "-f", file}, tt.args...))
+			cmd.Execute()
+
+			g.Expect(stdout.String()).To(ContainSubstring(tt.expectedStdout))
+			g.Expect(stderr.String()).To(ContainSubstring(tt.expectedStderr))
+			g.Expect(returnCode).To(Equal(tt.expectedCode))
+
+			expected := expectedCall
+			expected.DryRun = tt.expectedDryRun
+			g.Expect(mockClient.SetClusterConfigWithAddonsCalledWith).To(Equal(expected))
+		})
+	}
+}
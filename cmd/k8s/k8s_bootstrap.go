@@ -15,6 +15,7 @@ import (
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/canonical/k8sd/pkg/client/snapd"
 	"github.com/canonical/k8sd/pkg/config"
 	"github.com/canonical/k8sd/pkg/k8sd/features"
@@ -44,6 +45,8 @@ func newBootstrapCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 		outputFormat      string
 		timeout           time.Duration
 		containerdBaseDir string
+		bundleFile        string
+		bundlePassphrase  string
 	}
 	cmd := &cobra.Command{
 		Use:    "bootstrap",
@@ -73,6 +76,32 @@ func newBootstrapCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				return
 			}
 
+			if opts.bundleFile != "" && opts.interactive {
+				cmd.PrintErrln("Error: --interactive and --bundle flags cannot be set at the same time.")
+				env.Exit(1)
+				return
+			}
+			if (opts.bundleFile == "") != (opts.bundlePassphrase == "") {
+				cmd.PrintErrln("Error: --bundle and --bundle-passphrase-file flags must be set together.")
+				env.Exit(1)
+				return
+			}
+
+			var bundle []byte
+			var bundlePassphrase string
+			if opts.bundleFile != "" {
+				if bundle, err = os.ReadFile(opts.bundleFile); err != nil {
+					cmd.PrintErrf("Error: Failed to read the cluster bundle from %q.\n\nThe error was: %v\n", opts.bundleFile, err)
+					env.Exit(1)
+					return
+				}
+				if bundlePassphrase, err = cmdutil.ReadPassphrase(env, opts.bundlePassphrase); err != nil {
+					cmd.PrintErrf("Error: Failed to read the cluster bundle passphrase.\n\nThe error was: %v\n", err)
+					env.Exit(1)
+					return
+				}
+			}
+
 			if opts.timeout < minTimeout {
 				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
 				opts.timeout = minTimeout
@@ -173,11 +202,15 @@ func newBootstrapCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 
 			cmd.PrintErrln("Bootstrapping the cluster. This may take a few seconds, please wait.")
 
-			response, err := client.BootstrapCluster(cmd.Context(), apiv2.BootstrapClusterRequest{
-				Name:    opts.name,
-				Address: address,
-				Config:  bootstrapConfig,
-				Timeout: opts.timeout,
+			response, err := client.BootstrapCluster(cmd.Context(), k8sdapi.BootstrapClusterRequest{
+				BootstrapClusterRequest: apiv2.BootstrapClusterRequest{
+					Name:    opts.name,
+					Address: address,
+					Config:  bootstrapConfig,
+					Timeout: opts.timeout,
+				},
+				Bundle:           bundle,
+				BundlePassphrase: bundlePassphrase,
 			})
 			if err != nil {
 				cmd.PrintErrf("Error: Failed to bootstrap the cluster.\n\nThe error was: %v\n", err)
@@ -196,6 +229,8 @@ func newBootstrapCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
 	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
 	cmd.Flags().StringVar(&opts.containerdBaseDir, "containerd-base-dir", "", "set a dedicated absolute base directory for containerd")
+	cmd.Flags().StringVar(&opts.bundleFile, "bundle", "", "path to a cluster bundle created with 'k8sd cluster-export'. The cluster configuration, certificate authorities and tokens are imported from the bundle.")
+	cmd.Flags().StringVar(&opts.bundlePassphrase, "bundle-passphrase-file", "", "path to a file containing the passphrase of the cluster bundle. Use '-' to read from stdin.")
 
 	return cmd
 }
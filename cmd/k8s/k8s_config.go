@@ -6,22 +6,34 @@ import (
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/spf13/cobra"
 )
 
 func newKubeConfigCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	var opts struct {
 		server  string
+		user    string
+		groups  []string
+		ttl     time.Duration
 		timeout time.Duration
 	}
 	cmd := &cobra.Command{
 		Use:    "config",
 		Hidden: true,
 		Short:  "Generate an admin kubeconfig for cluster access",
-		Long:   "Generate an admin kubeconfig file that can be used to access the Kubernetes cluster.",
+		Long: `Generate an admin kubeconfig file that can be used to access the Kubernetes cluster.
+If --user or --ttl are set, the kubeconfig uses a dedicated client certificate for the requested identity,
+which expires after the TTL (default 24h) and can be revoked with "k8s config revoke". --group requires --user.`,
 		Args:   cobra.NoArgs,
 		PreRun: chainPreRunHooks(hookRequireRoot(env)),
 		Run: func(cmd *cobra.Command, args []string) {
+			if len(opts.groups) > 0 && opts.user == "" {
+				cmd.PrintErrln("Error: The --group flag requires the --user flag.")
+				env.Exit(1)
+				return
+			}
+
 			if opts.timeout < minTimeout {
 				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
 				opts.timeout = minTimeout
@@ -47,17 +59,74 @@ func newKubeConfigCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
 			cobra.OnFinalize(cancel)
 
-			response, err := client.KubeConfig(ctx, apiv2.KubeConfigRequest{Server: opts.server})
+			response, err := client.KubeConfig(ctx, k8sdapi.KubeConfigRequest{
+				KubeConfigRequest: apiv2.KubeConfigRequest{Server: opts.server},
+				User:              opts.user,
+				Groups:            opts.groups,
+				TTL:               opts.ttl,
+			})
 			if err != nil {
 				cmd.PrintErrf("Error: Failed to generate an admin kubeconfig for %q.\n\nThe error was: %v\n", opts.server, err)
 				env.Exit(1)
 				return
 			}
 
+			if response.Serial != "" {
+				cmd.PrintErrf("The client certificate %s of the kubeconfig expires at %s.\n", response.Serial, response.ExpiresAt.Format(time.RFC3339))
+			}
 			cmd.Println(response.KubeConfig)
 		},
 	}
 	cmd.Flags().StringVar(&opts.server, "server", "", "custom cluster server address")
+	cmd.Flags().StringVar(&opts.user, "user", "", "the Kubernetes user of the kubeconfig (default kubernetes-admin in group system:masters)")
+	cmd.Flags().StringSliceVar(&opts.groups, "group", nil, "the Kubernetes groups of the user")
+	cmd.Flags().DurationVar(&opts.ttl, "ttl", 0, "the time until the client certificate of the kubeconfig expires")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	cmd.AddCommand(
+		newConfigHistoryCmd(env),
+		newConfigRollbackCmd(env),
+		newConfigRevokeCmd(env),
+	)
+	return cmd
+}
+
+func newConfigRevokeCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		timeout time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "revoke <serial>",
+		Short:  "Revoke the client certificate of a kubeconfig",
+		Long:   "Revoke the client certificate of a kubeconfig generated with --user, --group or --ttl, by the serial number reported when it was generated.",
+		Args:   cmdutil.ExactArgs(env, 1),
+		PreRun: chainPreRunHooks(hookRequireRoot(env)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			if err := client.RevokeKubeConfig(ctx, k8sdapi.RevokeKubeConfigRequest{Serial: args[0]}); err != nil {
+				cmd.PrintErrf("Error: Failed to revoke the kubeconfig with serial %q.\n\nThe error was: %v\n", args[0], err)
+				env.Exit(1)
+				return
+			}
+
+			cmd.Printf("Revoked the kubeconfig with serial %s.\n", args[0])
+		},
+	}
 	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
 	return cmd
 }
@@ -0,0 +1,172 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+// ClusterConfigHistory is the list of cluster configuration revisions.
+type ClusterConfigHistory k8sdapi.ClusterConfigHistoryResponse
+
+// TICS -COV_GO_SUPPRESSED_ERROR
+// we are just formatting the output of the config history, it is ok to ignore failures from fmt.Fprintf()
+
+func (h ClusterConfigHistory) String() string {
+	if len(h.Revisions) == 0 {
+		return "no revisions recorded"
+	}
+
+	result := strings.Builder{}
+	w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "REVISION\tCREATED\tREQUESTED BY\tREASON\tCHANGES")
+	for _, revision := range h.Revisions {
+		reason := revision.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		changes := []string{"-"}
+		if len(revision.Changes) > 0 {
+			changes = make([]string, 0, len(revision.Changes))
+			for _, change := range revision.Changes {
+				changes = append(changes, formatFieldChange(change))
+			}
+		}
+		fmt.Fprintf(w, "\n%d\t%s\t%s\t%s\t%s",
+			revision.Revision,
+			revision.CreatedAt.Format(time.RFC3339),
+			revision.RequestedBy,
+			reason,
+			changes[0],
+		)
+		for _, change := range changes[1:] {
+			fmt.Fprintf(w, "\n\t\t\t\t%s", change)
+		}
+	}
+	w.Flush()
+
+	return result.String()
+}
+
+// TICS +COV_GO_SUPPRESSED_ERROR
+
+type ClusterConfigRollbackResult struct {
+	Revision int64 `json:"revision" yaml:"revision"`
+}
+
+func (r ClusterConfigRollbackResult) String() string {
+	return fmt.Sprintf("Configuration rolled back to revision %d.", r.Revision)
+}
+
+func newConfigHistoryCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		outputFormat string
+		limit        int
+		timeout      time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "history",
+		Short:  "Show the revisions of the cluster configuration",
+		Long:   "Show the revisions of the cluster configuration, along with who requested each change and the changed configuration fields.",
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			response, err := client.ClusterConfigHistory(ctx, k8sdapi.ClusterConfigHistoryRequest{Limit: opts.limit})
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to retrieve the cluster configuration history.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			outputFormatter.Print(ClusterConfigHistory(response))
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().IntVar(&opts.limit, "limit", 20, "maximum number of revisions to show, 0 shows all retained revisions")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+func newConfigRollbackCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		outputFormat string
+		timeout      time.Duration
+		dryRun       bool
+	}
+	cmd := &cobra.Command{
+		Use:    "rollback <revision>",
+		Short:  "Roll back the cluster configuration to a previous revision",
+		Long:   "Roll back the cluster configuration to a previous revision. The rollback is validated and applied like any other configuration change and is recorded as a new revision. The datastore configuration is not rolled back.",
+		Args:   cmdutil.ExactArgs(env, 1),
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			revision, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || revision <= 0 {
+				cmd.PrintErrf("Error: Invalid revision %q, must be a positive number.\n", args[0])
+				env.Exit(1)
+				return
+			}
+
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			response, err := client.RollbackClusterConfig(ctx, k8sdapi.ClusterConfigRollbackRequest{
+				Revision: revision,
+				DryRun:   opts.dryRun,
+			})
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to roll back the cluster configuration to revision %d.\n\nThe error was: %v\n", revision, err)
+				env.Exit(1)
+				return
+			}
+
+			if opts.dryRun {
+				outputFormatter.Print(DryRunResult(response))
+				return
+			}
+			outputFormatter.Print(ClusterConfigRollbackResult{Revision: revision})
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "show the configuration and Helm values changes without rolling back")
+
+	return cmd
+}
@@ -0,0 +1,107 @@
+package k8s_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/cmd/k8s"
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	k8sdmock "github.com/canonical/k8sd/pkg/client/k8sd/mock"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+)
+
+func TestClusterConfigHistoryFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(k8s.ClusterConfigHistory{}.String()).To(Equal("no revisions recorded"))
+
+	history := k8s.ClusterConfigHistory{
+		Revisions: []k8sdapi.ClusterConfigRevision{
+			{
+				Revision:    3,
+				CreatedAt:   time.Date(2026, 10, 10, 10, 0, 0, 0, time.UTC),
+				RequestedBy: "alice (local)",
+				Changes: []k8sdapi.FieldChange{
+					{Key: "ingress.enabled", Old: "true", New: "false"},
+					{Key: "load-balancer.enabled", New: "true"},
+				},
+			},
+			{
+				Revision:    1,
+				CreatedAt:   time.Date(2026, 10, 9, 10, 0, 0, 0, time.UTC),
+				RequestedBy: "k8sd",
+				Reason:      "rollback to revision 0",
+			},
+		},
+	}
+	g.Expect(history.String()).To(Equal(`REVISION  CREATED               REQUESTED BY   REASON                  CHANGES
+3         2026-10-10T10:00:00Z  alice (local)  -                       ingress.enabled: true -> false
+                                                                       load-balancer.enabled: <unset> -> true
+1         2026-10-09T10:00:00Z  k8sd           rollback to revision 0  -`))
+}
+
+func TestK8sConfigRollbackCmd(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedCall   k8sdapi.ClusterConfigRollbackRequest
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Rollback",
+			args:           []string{"3"},
+			expectedCall:   k8sdapi.ClusterConfigRollbackRequest{Revision: 3},
+			expectedStdout: "rolled back to revision 3",
+		},
+		{
+			name:           "DryRun",
+			args:           []string{"3", "--dry-run"},
+			expectedCall:   k8sdapi.ClusterConfigRollbackRequest{Revision: 3, DryRun: true},
+			expectedStdout: "no changes",
+		},
+		{
+			name:           "InvalidRevision",
+			args:           []string{"latest"},
+			expectedCode:   1,
+			expectedStderr: "Invalid revision",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			mockClient := &k8sdmock.Mock{}
+			var returnCode int
+			env := cmdutil.ExecutionEnvironment{
+				Stdout: stdout,
+				Stderr: stderr,
+				Snap: &snapmock.Snap{
+					Mock: snapmock.Mock{
+						K8sdClient: mockClient,
+					},
+				},
+				Exit: func(rc int) { returnCode = rc },
+			}
+			cmd := k8s.NewRootCmd(env)
+
+			cmd.SetArgs(append([]string{"config", "rollback"}, tt.args...))
+			cmd.Execute()
+
+			g.Expect(stdout.String()).To(ContainSubstring(tt.expectedStdout))
+			g.Expect(stderr.String()).To(ContainSubstring(tt.expectedStderr))
+			g.Expect(returnCode).To(Equal(tt.expectedCode))
+
+			if tt.expectedCode == 0 {
+				g.Expect(mockClient.RollbackClusterConfigCalledWith).To(Equal(tt.expectedCall))
+			}
+		})
+	}
+}
@@ -2,13 +2,18 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/client/k8sd"
 	"github.com/canonical/k8sd/pkg/k8sd/features"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/utils"
 	"github.com/spf13/cobra"
 )
@@ -25,11 +30,12 @@ func newDisableCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	var opts struct {
 		outputFormat string
 		timeout      time.Duration
+		cascade      bool
 	}
 	cmd := &cobra.Command{
 		Use:    fmt.Sprintf("disable [%s] ...", strings.Join(featureList, "|")),
 		Short:  "Disable one or more core cluster features",
-		Long:   fmt.Sprintf("Disable one or more core cluster features.\n\nAvailable features: %s", strings.Join(featureList, ", ")),
+		Long:   fmt.Sprintf("Disable one or more core cluster features.\n\nAvailable features: %s\n\nFeatures that are required by other enabled features (e.g. network is required by ingress and gateway) can only be disabled together with them, or with --cascade.", strings.Join(featureList, ", ")),
 		Args:   cmdutil.MinimumNArgs(env, 1),
 		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
 		Run: func(cmd *cobra.Command, args []string) {
@@ -40,7 +46,19 @@ func newDisableCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				opts.timeout = minTimeout
 			}
 
-			for _, feature := range args {
+			// with --cascade, also disable the features that require the disabled ones
+			disabled := slices.Clone(args)
+			if opts.cascade {
+				for _, feature := range args {
+					for _, dependent := range features.RequiredBy(types.FeatureName(feature)) {
+						if !slices.Contains(disabled, string(dependent)) {
+							disabled = append(disabled, string(dependent))
+						}
+					}
+				}
+			}
+
+			for _, feature := range disabled {
 				switch feature {
 				case string(features.Network):
 					config.Network = apiv2.NetworkConfig{
@@ -84,7 +102,7 @@ func newDisableCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				return
 			}
 
-			cmd.PrintErrf("Disabling %s from the cluster. This may take a few seconds, please wait.\n", strings.Join(args, ", "))
+			cmd.PrintErrf("Disabling %s from the cluster. This may take a few seconds, please wait.\n", strings.Join(disabled, ", "))
 			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
 			cobra.OnFinalize(cancel)
 
@@ -98,18 +116,25 @@ func newDisableCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				return
 			}
 
-			if err := client.SetClusterConfig(ctx, apiv2.SetClusterConfigRequest{Config: config}); err != nil {
-				cmd.PrintErrf("Error: Failed to disable %s from the cluster.\n\nThe error was: %v\n", strings.Join(args, ", "), err)
+			if _, err := setClusterConfig(ctx, client, k8sdapi.SetClusterConfigRequest{
+				SetClusterConfigRequest: apiv2.SetClusterConfigRequest{Config: config},
+			}); err != nil {
+				cmd.PrintErrf("Error: Failed to disable %s from the cluster.\n\nThe error was: %v\n", strings.Join(disabled, ", "), err)
+				printClusterConfigConflictHint(cmd, err)
+				if !opts.cascade && !errors.Is(err, k8sd.ErrClusterConfigConflict) {
+					cmd.PrintErrln("\nTo also disable the features that depend on them, use --cascade.")
+				}
 				env.Exit(1)
 				return
 			}
 
-			outputFormatter.Print(DisableResult{Features: args})
+			outputFormatter.Print(DisableResult{Features: disabled})
 		},
 	}
 
 	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
 	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+	cmd.Flags().BoolVar(&opts.cascade, "cascade", false, "also disable the features that require the disabled features")
 
 	return cmd
 }
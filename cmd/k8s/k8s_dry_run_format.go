@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+)
+
+// DryRunResult is the result of a cluster configuration change that was requested with --dry-run.
+type DryRunResult k8sdapi.SetClusterConfigResponse
+
+// TICS -COV_GO_SUPPRESSED_ERROR
+// we are just formatting the output of a dry-run, it is ok to ignore failures from result.WriteString()
+
+func (r DryRunResult) String() string {
+	if len(r.Changes) == 0 && len(r.Features) == 0 {
+		return "no changes"
+	}
+
+	result := strings.Builder{}
+	result.WriteString("configuration changes:")
+	if len(r.Changes) == 0 {
+		result.WriteString(" none")
+	}
+	for _, change := range r.Changes {
+		result.WriteString(fmt.Sprintf("\n  %s", formatFieldChange(change)))
+	}
+
+	for _, feature := range r.Features {
+		result.WriteString(fmt.Sprintf("\n\n%s:", feature.Name))
+		if feature.Error != "" {
+			result.WriteString(fmt.Sprintf("\n  error: %s", feature.Error))
+		}
+		for _, chart := range feature.Charts {
+			result.WriteString(fmt.Sprintf("\n  %s chart %s/%s", chart.Action, chart.Namespace, chart.Name))
+			for _, change := range chart.Changes {
+				result.WriteString(fmt.Sprintf("\n    %s", formatFieldChange(change)))
+			}
+		}
+	}
+
+	return result.String()
+}
+
+func formatFieldChange(change k8sdapi.FieldChange) string {
+	old, new := change.Old, change.New
+	if old == "" {
+		old = "<unset>"
+	}
+	if new == "" {
+		new = "<unset>"
+	}
+	return fmt.Sprintf("%s: %s -> %s", change.Key, old, new)
+}
+
+// TICS +COV_GO_SUPPRESSED_ERROR
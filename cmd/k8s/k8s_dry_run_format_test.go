@@ -0,0 +1,72 @@
+package k8s_test
+
+import (
+	"testing"
+
+	"github.com/canonical/k8sd/cmd/k8s"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	. "github.com/onsi/gomega"
+)
+
+func TestDryRunResultFormat(t *testing.T) {
+	testCases := []struct {
+		name           string
+		result         k8s.DryRunResult
+		expectedOutput string
+	}{
+		{
+			name:           "NoChanges",
+			expectedOutput: "no changes",
+		},
+		{
+			name: "Changes",
+			result: k8s.DryRunResult{
+				Changes: []k8sdapi.FieldChange{
+					{Key: "load-balancer.cidrs", New: `["10.0.0.0/24"]`},
+					{Key: "load-balancer.enabled", Old: "false", New: "true"},
+				},
+				Features: []k8sdapi.FeatureDiff{
+					{
+						Name: "load-balancer",
+						Charts: []k8sdapi.ChartDiff{
+							{Name: "metallb", Namespace: "metallb-system", Action: "install", Changes: []k8sdapi.FieldChange{{Key: "speaker.frr.enabled", New: "false"}}},
+							{Name: "metallb-loadbalancer", Namespace: "metallb-system", Action: "upgrade", Changes: []k8sdapi.FieldChange{{Key: "ipPool.cidrs", Old: "[]", New: `[{"cidr":"10.0.0.0/24"}]`}}},
+						},
+					},
+					{Name: "gateway", Error: "failed to render new configuration: boom"},
+				},
+			},
+			expectedOutput: `configuration changes:
+  load-balancer.cidrs: <unset> -> ["10.0.0.0/24"]
+  load-balancer.enabled: false -> true
+
+load-balancer:
+  install chart metallb-system/metallb
+    speaker.frr.enabled: <unset> -> false
+  upgrade chart metallb-system/metallb-loadbalancer
+    ipPool.cidrs: [] -> [{"cidr":"10.0.0.0/24"}]
+
+gateway:
+  error: failed to render new configuration: boom`,
+		},
+		{
+			name: "ChartsOnly",
+			result: k8s.DryRunResult{
+				Features: []k8sdapi.FeatureDiff{
+					{Name: "network", Charts: []k8sdapi.ChartDiff{{Name: "ck-network", Namespace: "kube-system", Action: "delete"}}},
+				},
+			},
+			expectedOutput: `configuration changes: none
+
+network:
+  delete chart kube-system/ck-network`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tc.result.String()).To(Equal(tc.expectedOutput))
+		})
+	}
+}
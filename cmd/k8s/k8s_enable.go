@@ -8,6 +8,7 @@ import (
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/canonical/k8sd/pkg/k8sd/features"
 	"github.com/canonical/k8sd/pkg/utils"
 	"github.com/spf13/cobra"
@@ -25,6 +26,7 @@ func newEnableCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	var opts struct {
 		outputFormat string
 		timeout      time.Duration
+		dryRun       bool
 	}
 	cmd := &cobra.Command{
 		Use:    fmt.Sprintf("enable [%s] ...", strings.Join(featureList, "|")),
@@ -84,7 +86,9 @@ func newEnableCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				return
 			}
 
-			cmd.PrintErrf("Enabling %s on the cluster. This may take a few seconds, please wait.\n", strings.Join(args, ", "))
+			if !opts.dryRun {
+				cmd.PrintErrf("Enabling %s on the cluster. This may take a few seconds, please wait.\n", strings.Join(args, ", "))
+			}
 			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
 			cobra.OnFinalize(cancel)
 
@@ -98,18 +102,29 @@ func newEnableCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				return
 			}
 
-			if err := client.SetClusterConfig(ctx, apiv2.SetClusterConfigRequest{Config: config}); err != nil {
+			response, err := setClusterConfig(ctx, client, k8sdapi.SetClusterConfigRequest{
+				SetClusterConfigRequest: apiv2.SetClusterConfigRequest{Config: config},
+				DryRun:                  opts.dryRun,
+			})
+			if err != nil {
 				cmd.PrintErrf("Error: Failed to enable %s on the cluster.\n\nThe error was: %v\n", strings.Join(args, ", "), err)
+				printClusterConfigConflictHint(cmd, err)
 				env.Exit(1)
 				return
 			}
 
+			if opts.dryRun {
+				outputFormatter.Print(DryRunResult(response))
+				return
+			}
+
 			outputFormatter.Print(EnableResult{Features: args})
 		},
 	}
 
 	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
 	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "show the configuration and Helm values changes without enabling the features")
 
 	return cmd
 }
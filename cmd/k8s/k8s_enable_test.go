@@ -2,11 +2,14 @@ package k8s_test
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	"github.com/canonical/k8sd/cmd/k8s"
 	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/client/k8sd"
 	k8sdmock "github.com/canonical/k8sd/pkg/client/k8sd/mock"
 	"github.com/canonical/k8sd/pkg/k8sd/features"
 	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
@@ -18,7 +21,8 @@ func TestK8sEnableCmd(t *testing.T) {
 	tests := []struct {
 		name           string
 		funcs          []string
-		expectedCall   apiv2.SetClusterConfigRequest
+		setErr         error
+		expectedCall   k8sdapi.SetClusterConfigRequest
 		expectedCode   int
 		expectedStdout string
 		expectedStderr string
@@ -32,9 +36,11 @@ func TestK8sEnableCmd(t *testing.T) {
 		{
 			name:  "one",
 			funcs: []string{string(features.Gateway)},
-			expectedCall: apiv2.SetClusterConfigRequest{
-				Config: apiv2.UserFacingClusterConfig{
-					Gateway: apiv2.GatewayConfig{Enabled: utils.Pointer(true)},
+			expectedCall: k8sdapi.SetClusterConfigRequest{
+				SetClusterConfigRequest: apiv2.SetClusterConfigRequest{
+					Config: apiv2.UserFacingClusterConfig{
+						Gateway: apiv2.GatewayConfig{Enabled: utils.Pointer(true)},
+					},
 				},
 			},
 			expectedStdout: "enabled",
@@ -42,14 +48,36 @@ func TestK8sEnableCmd(t *testing.T) {
 		{
 			name:  "multiple",
 			funcs: []string{string(features.LoadBalancer), string(features.Gateway)},
-			expectedCall: apiv2.SetClusterConfigRequest{
-				Config: apiv2.UserFacingClusterConfig{
-					Gateway:      apiv2.GatewayConfig{Enabled: utils.Pointer(true)},
-					LoadBalancer: apiv2.LoadBalancerConfig{Enabled: utils.Pointer(true)},
+			expectedCall: k8sdapi.SetClusterConfigRequest{
+				SetClusterConfigRequest: apiv2.SetClusterConfigRequest{
+					Config: apiv2.UserFacingClusterConfig{
+						Gateway:      apiv2.GatewayConfig{Enabled: utils.Pointer(true)},
+						LoadBalancer: apiv2.LoadBalancerConfig{Enabled: utils.Pointer(true)},
+					},
 				},
 			},
 			expectedStdout: "enabled",
 		},
+		{
+			name:  "dry-run",
+			funcs: []string{"--dry-run", string(features.Gateway)},
+			expectedCall: k8sdapi.SetClusterConfigRequest{
+				SetClusterConfigRequest: apiv2.SetClusterConfigRequest{
+					Config: apiv2.UserFacingClusterConfig{
+						Gateway: apiv2.GatewayConfig{Enabled: utils.Pointer(true)},
+					},
+				},
+				DryRun: true,
+			},
+			expectedStdout: "no changes",
+		},
+		{
+			name:           "conflict",
+			funcs:          []string{string(features.Gateway)},
+			setErr:         fmt.Errorf("%w: resource version is 4, but 3 was expected", k8sd.ErrClusterConfigConflict),
+			expectedCode:   1,
+			expectedStderr: "Run the command again",
+		},
 		{
 			name:           "unknown",
 			funcs:          []string{"unknownFunc"},
@@ -65,7 +93,9 @@ func TestK8sEnableCmd(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
 			mockClient := &k8sdmock.Mock{
-				NodeStatusInitialized: true,
+				NodeStatusInitialized:              true,
+				GetClusterConfigWithAddonsResponse: k8sdapi.GetClusterConfigResponse{ResourceVersion: 3},
+				SetClusterConfigWithAddonsErr:      tt.setErr,
 			}
 			var returnCode int
 			env := cmdutil.ExecutionEnvironment{
@@ -89,7 +119,10 @@ func TestK8sEnableCmd(t *testing.T) {
 			g.Expect(returnCode).To(Equal(tt.expectedCode))
 
 			if tt.expectedCode == 0 {
-				g.Expect(mockClient.SetClusterConfigCalledWith).To(Equal(tt.expectedCall))
+				// the change is based on the current revision of the cluster configuration
+				expected := tt.expectedCall
+				expected.ExpectedResourceVersion = 3
+				g.Expect(mockClient.SetClusterConfigWithAddonsCalledWith).To(Equal(expected))
 			}
 		})
 	}
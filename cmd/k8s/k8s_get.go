@@ -50,7 +50,7 @@ func newGetCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				return
 			}
 
-			response, err := client.GetClusterConfig(ctx)
+			response, err := client.GetClusterConfigWithAddons(ctx)
 			if err != nil {
 				cmd.PrintErrf("Error: Failed to get the current cluster configuration.\n\nThe error was: %v\n", err)
 				env.Exit(1)
@@ -132,7 +132,15 @@ func newGetCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				output = config.LoadBalancer.GetBGPPeerPort()
 			case fmt.Sprintf("%s.bgp-peer-asn", features.LoadBalancer):
 				output = config.LoadBalancer.GetBGPPeerASN()
+			case "addons":
+				output = response.Addons
 			default:
+				if name, ok := strings.CutPrefix(key, "addons."); ok {
+					if addon, ok := response.Addons[name]; ok {
+						output = addon
+						break
+					}
+				}
 				cmd.PrintErrf("Error: Unknown config key %q.\n", key)
 				env.Exit(1)
 				return
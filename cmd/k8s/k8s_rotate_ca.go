@@ -0,0 +1,146 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+// CARotationStatus is the status of the cluster certificate authority rotation.
+type CARotationStatus k8sdapi.CARotationStatusResponse
+
+func (s CARotationStatus) String() string {
+	switch s.Phase {
+	case "":
+		return "No CA rotation in progress."
+	case "trust":
+		return fmt.Sprintf(`CA rotation started at %s, phase "trust".
+All nodes trust both the old and the new certificate authorities. Once the new trust bundle is in use on all nodes and
+by all clients, run "k8s rotate-ca reissue" to re-issue the certificates, or "k8s rotate-ca abort" to cancel the rotation.`,
+			s.StartedAt.Format(time.RFC3339))
+	case "reissue":
+		return fmt.Sprintf(`CA rotation started at %s, phase "reissue" since %s.
+Certificates are signed by the new certificate authorities and node certificates are re-issued automatically, or with
+"k8s refresh-certs". Once all nodes and clients use certificates of the new certificate authorities, run
+"k8s rotate-ca finish" to remove the old ones. Finishing fails while any node still uses certificates of the old ones.`,
+			s.StartedAt.Format(time.RFC3339), s.UpdatedAt.Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("CA rotation in unknown phase %q.", s.Phase)
+	}
+}
+
+func newRotateCACmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-ca",
+		Short: "Rotate the cluster certificate authorities",
+		Long: `Rotate the kubernetes, client and front-proxy certificate authorities of the cluster without downtime.
+The rotation is performed in steps:
+  start    generate new certificate authorities, and trust both the old and the new ones on all nodes
+  reissue  sign certificates with the new certificate authorities and re-issue the node certificates
+  finish   stop trusting the old certificate authorities
+A rotation can be aborted before the reissue step.`,
+	}
+
+	cmd.AddCommand(
+		newRotateCAStatusCmd(env),
+		newRotateCAActionCmd(env, k8sdapi.CARotationActionStart, "Start a CA rotation by distributing new certificate authorities"),
+		newRotateCAActionCmd(env, k8sdapi.CARotationActionReissue, "Sign certificates with the new certificate authorities"),
+		newRotateCAActionCmd(env, k8sdapi.CARotationActionFinish, "Remove the old certificate authorities from the trust bundle"),
+		newRotateCAActionCmd(env, k8sdapi.CARotationActionAbort, "Abort the CA rotation and restore the old certificate authorities"),
+	)
+	return cmd
+}
+
+func newRotateCAStatusCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		outputFormat string
+		timeout      time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "status",
+		Short:  "Show the status of the CA rotation",
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			response, err := client.CARotationStatus(ctx)
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to retrieve the CA rotation status.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			outputFormatter.Print(CARotationStatus(response))
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+func newRotateCAActionCmd(env cmdutil.ExecutionEnvironment, action k8sdapi.CARotationAction, short string) *cobra.Command {
+	var opts struct {
+		outputFormat string
+		timeout      time.Duration
+		force        bool
+	}
+	cmd := &cobra.Command{
+		Use:    string(action),
+		Short:  short,
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			response, err := client.RotateCA(ctx, k8sdapi.RotateCARequest{Action: action, Force: opts.force})
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to %s the CA rotation.\n\nThe error was: %v\n", action, err)
+				env.Exit(1)
+				return
+			}
+
+			outputFormatter.Print(CARotationStatus(response))
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+	if action == k8sdapi.CARotationActionFinish {
+		cmd.Flags().BoolVar(&opts.force, "force", false, "finish without checking that all nodes use certificates of the new certificate authorities")
+	}
+
+	return cmd
+}
@@ -0,0 +1,110 @@
+package k8s_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/cmd/k8s"
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	k8sdmock "github.com/canonical/k8sd/pkg/client/k8sd/mock"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+)
+
+func TestCARotationStatusFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(k8s.CARotationStatus{}.String()).To(Equal("No CA rotation in progress."))
+	g.Expect(k8s.CARotationStatus{
+		Phase:     "trust",
+		StartedAt: time.Date(2026, 10, 10, 10, 0, 0, 0, time.UTC),
+	}.String()).To(And(
+		HavePrefix(`CA rotation started at 2026-10-10T10:00:00Z, phase "trust".`),
+		ContainSubstring("k8s rotate-ca reissue"),
+	))
+	g.Expect(k8s.CARotationStatus{
+		Phase:     "reissue",
+		StartedAt: time.Date(2026, 10, 10, 10, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 10, 11, 10, 0, 0, 0, time.UTC),
+	}.String()).To(And(
+		HavePrefix(`CA rotation started at 2026-10-10T10:00:00Z, phase "reissue" since 2026-10-11T10:00:00Z.`),
+		ContainSubstring("k8s rotate-ca finish"),
+	))
+}
+
+func TestK8sRotateCACmd(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		response       k8sdapi.CARotationStatusResponse
+		err            error
+		expectedCall   k8sdapi.RotateCARequest
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Start",
+			args:           []string{"start"},
+			response:       k8sdapi.CARotationStatusResponse{Phase: "trust"},
+			expectedCall:   k8sdapi.RotateCARequest{Action: k8sdapi.CARotationActionStart},
+			expectedStdout: `phase "trust"`,
+		},
+		{
+			name:           "Finish",
+			args:           []string{"finish"},
+			expectedCall:   k8sdapi.RotateCARequest{Action: k8sdapi.CARotationActionFinish},
+			expectedStdout: "No CA rotation in progress.",
+		},
+		{
+			name:           "FinishForce",
+			args:           []string{"finish", "--force"},
+			expectedCall:   k8sdapi.RotateCARequest{Action: k8sdapi.CARotationActionFinish, Force: true},
+			expectedStdout: "No CA rotation in progress.",
+		},
+		{
+			name:           "Error",
+			args:           []string{"reissue"},
+			err:            fmt.Errorf("no CA rotation in progress"),
+			expectedCall:   k8sdapi.RotateCARequest{Action: k8sdapi.CARotationActionReissue},
+			expectedCode:   1,
+			expectedStderr: "Failed to reissue the CA rotation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			mockClient := &k8sdmock.Mock{
+				RotateCAResponse: tt.response,
+				RotateCAErr:      tt.err,
+			}
+			var returnCode int
+			env := cmdutil.ExecutionEnvironment{
+				Stdout: stdout,
+				Stderr: stderr,
+				Snap: &snapmock.Snap{
+					Mock: snapmock.Mock{
+						K8sdClient: mockClient,
+					},
+				},
+				Exit: func(rc int) { returnCode = rc },
+			}
+			cmd := k8s.NewRootCmd(env)
+
+			cmd.SetArgs(append([]string{"rotate-ca"}, tt.args...))
+			cmd.Execute()
+
+			g.Expect(stdout.String()).To(ContainSubstring(tt.expectedStdout))
+			g.Expect(stderr.String()).To(ContainSubstring(tt.expectedStderr))
+			g.Expect(returnCode).To(Equal(tt.expectedCode))
+			g.Expect(mockClient.RotateCACalledWith).To(Equal(tt.expectedCall))
+		})
+	}
+}
@@ -2,12 +2,16 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/client/k8sd"
 	"github.com/canonical/k8sd/pkg/k8sd/features"
 	"github.com/canonical/k8sd/pkg/utils"
 	"github.com/mitchellh/mapstructure"
@@ -15,7 +19,8 @@ import (
 )
 
 type SetResult struct {
-	ClusterConfig apiv2.UserFacingClusterConfig `json:"cluster-config" yaml:"cluster-config"`
+	ClusterConfig apiv2.UserFacingClusterConfig      `json:"cluster-config" yaml:"cluster-config"`
+	Addons        map[string]k8sdapi.HelmAddonConfig `json:"addons,omitempty" yaml:"addons,omitempty"`
 }
 
 func (s SetResult) String() string {
@@ -26,15 +31,17 @@ func newSetCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	var opts struct {
 		outputFormat string
 		timeout      time.Duration
+		dryRun       bool
 	}
 	cmd := &cobra.Command{
 		Use:    "set <feature.key=value> ...",
 		Short:  "Set cluster configuration",
-		Long:   fmt.Sprintf("Set cluster configuration for one or more features.\n\nAvailable features: %s\n\nUse 'k8s get' to explore configuration options.", strings.Join(featureList, ", ")),
+		Long:   fmt.Sprintf("Set cluster configuration for one or more features.\n\nAvailable features: %s\n\nOperator-defined Helm add-ons are configured with 'addons.<name>.<key>=value', where key is one of: %s.\n\nUse 'k8s get' to explore configuration options.", strings.Join(featureList, ", "), strings.Join(addonSetKeys, ", ")),
 		Args:   cmdutil.MinimumNArgs(env, 1),
 		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
 		Run: func(cmd *cobra.Command, args []string) {
 			config := apiv2.UserFacingClusterConfig{}
+			addons := map[string]k8sdapi.HelmAddonConfig{}
 
 			if opts.timeout < minTimeout {
 				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
@@ -42,7 +49,13 @@ func newSetCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 			}
 
 			for _, arg := range args {
-				if err := updateConfigMapstructure(&config, arg); err != nil {
+				var err error
+				if strings.HasPrefix(arg, "addons.") {
+					err = updateAddonsConfig(addons, arg)
+				} else {
+					err = updateConfigMapstructure(&config, arg)
+				}
+				if err != nil {
 					cmd.PrintErrf("Error: Invalid option %q.\n\nThe error was: %v\n", arg, err)
 					env.Exit(1)
 				}
@@ -58,22 +71,59 @@ func newSetCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
 			cobra.OnFinalize(cancel)
 
-			if err := client.SetClusterConfig(ctx, apiv2.SetClusterConfigRequest{Config: config}); err != nil {
+			if len(addons) == 0 {
+				addons = nil
+			}
+
+			request := k8sdapi.SetClusterConfigRequest{
+				SetClusterConfigRequest: apiv2.SetClusterConfigRequest{Config: config},
+				Addons:                  addons,
+				DryRun:                  opts.dryRun,
+			}
+			response, err := setClusterConfig(ctx, client, request)
+			if err != nil {
 				cmd.PrintErrf("Error: Failed to apply requested cluster configuration changes.\n\nThe error was: %v\n", err)
+				printClusterConfigConflictHint(cmd, err)
 				env.Exit(1)
 				return
 			}
 
-			outputFormatter.Print(SetResult{ClusterConfig: config})
+			if opts.dryRun {
+				outputFormatter.Print(DryRunResult(response))
+				return
+			}
+
+			outputFormatter.Print(SetResult{ClusterConfig: config, Addons: addons})
 		},
 	}
 
 	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
 	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "show the configuration and Helm values changes without applying them")
 
 	return cmd
 }
 
+// setClusterConfig applies a cluster configuration change on top of the current revision of the cluster configuration.
+// The change is rejected with an error wrapping k8sd.ErrClusterConfigConflict if the cluster configuration is
+// modified concurrently, e.g. by another node.
+func setClusterConfig(ctx context.Context, client k8sd.Client, request k8sdapi.SetClusterConfigRequest) (k8sdapi.SetClusterConfigResponse, error) {
+	current, err := client.GetClusterConfigWithAddons(ctx)
+	if err != nil {
+		return k8sdapi.SetClusterConfigResponse{}, fmt.Errorf("failed to get the current cluster configuration: %w", err)
+	}
+	request.ExpectedResourceVersion = current.ResourceVersion
+	return client.SetClusterConfigWithAddons(ctx, request)
+}
+
+// printClusterConfigConflictHint tells the user how to proceed if a change was rejected by setClusterConfig
+// because of a concurrent change of the cluster configuration.
+func printClusterConfigConflictHint(cmd *cobra.Command, err error) {
+	if errors.Is(err, k8sd.ErrClusterConfigConflict) {
+		cmd.PrintErrln("\nThe cluster configuration was changed by another request. Run the command again to apply the change on top of the latest configuration.")
+	}
+}
+
 var knownSetKeys = map[string]struct{}{
 	"annotations":    {},
 	"cloud-provider": {},
@@ -137,6 +187,60 @@ func updateConfigMapstructure(config *apiv2.UserFacingClusterConfig, arg string)
 	return nil
 }
 
+// addonSetKeys are the configuration keys of an operator-defined Helm add-on.
+var addonSetKeys = []string{"enabled", "chart-path", "namespace", "values", "remove"}
+
+// updateAddonsConfig parses an "addons.<name>.<key>=<value>" option into the add-ons configuration.
+// The value of "addons.<name>.values" is parsed as a YAML document.
+func updateAddonsConfig(addons map[string]k8sdapi.HelmAddonConfig, arg string) error {
+	parts := strings.SplitN(arg, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("option not in <key>=<value> format")
+	}
+	key := parts[0]
+	value := parts[1]
+
+	keyParts := strings.Split(key, ".")
+	if len(keyParts) != 3 || keyParts[1] == "" {
+		return fmt.Errorf("add-on option key %q not in addons.<name>.<key> format", key)
+	}
+	name, field := keyParts[1], keyParts[2]
+
+	addon := addons[name]
+	switch field {
+	case "enabled":
+		enabled := false
+		if value != "" {
+			var err error
+			if enabled, err = strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("invalid option %q: %w", arg, err)
+			}
+		}
+		addon.Enabled = utils.Pointer(enabled)
+	case "chart-path":
+		addon.ChartPath = utils.Pointer(value)
+	case "namespace":
+		addon.Namespace = utils.Pointer(value)
+	case "values":
+		values, err := utils.UnmarshalYAMLMap([]byte(value))
+		if err != nil {
+			return fmt.Errorf("invalid option %q: values must be a YAML map: %w", arg, err)
+		}
+		addon.Values = values
+	case "remove":
+		remove, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid option %q: %w", arg, err)
+		}
+		addon.Remove = utils.Pointer(remove)
+	default:
+		return fmt.Errorf("unknown option key %q", key)
+	}
+	addons[name] = addon
+
+	return nil
+}
+
 func toRecursiveMap(key, value string) map[string]any {
 	parts := strings.SplitN(key, ".", 2)
 	if len(parts) == 2 {
@@ -6,6 +6,7 @@ import (
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	metallbAnnotations "github.com/canonical/k8s-snap-api/v2/api/annotations/metallb"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/canonical/k8sd/pkg/utils"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/types"
@@ -238,3 +239,35 @@ func Test_updateConfigMapstructure_annotationsYAMLBlockLiteral(t *testing.T) {
 	g.Expect(peersYAML).To(ContainSubstring("peerAddress: 10.0.0.1"))
 	g.Expect(peersYAML).To(ContainSubstring("peerASN: 65001"))
 }
+
+func Test_updateAddonsConfig(t *testing.T) {
+	for _, tc := range []struct {
+		val       string
+		expectErr bool
+		expectVal k8sdapi.HelmAddonConfig
+	}{
+		{val: "addons.my-chart.enabled=true", expectVal: k8sdapi.HelmAddonConfig{Enabled: utils.Pointer(true)}},
+		{val: "addons.my-chart.enabled=", expectVal: k8sdapi.HelmAddonConfig{Enabled: utils.Pointer(false)}},
+		{val: "addons.my-chart.enabled=yes", expectErr: true},
+		{val: "addons.my-chart.chart-path=/opt/charts/my-chart", expectVal: k8sdapi.HelmAddonConfig{ChartPath: utils.Pointer("/opt/charts/my-chart")}},
+		{val: "addons.my-chart.namespace=my-namespace", expectVal: k8sdapi.HelmAddonConfig{Namespace: utils.Pointer("my-namespace")}},
+		{val: "addons.my-chart.values=replicas: 2", expectVal: k8sdapi.HelmAddonConfig{Values: map[string]any{"replicas": 2}}},
+		{val: "addons.my-chart.values=- a", expectErr: true},
+		{val: "addons.my-chart.unknown=value", expectErr: true},
+		{val: "addons.my-chart=value", expectErr: true},
+		{val: "addons.my-chart.enabled", expectErr: true},
+	} {
+		t.Run(tc.val, func(t *testing.T) {
+			g := NewWithT(t)
+
+			addons := map[string]k8sdapi.HelmAddonConfig{}
+			err := updateAddonsConfig(addons, tc.val)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(addons).To(HaveKeyWithValue("my-chart", tc.expectVal))
+			}
+		})
+	}
+}
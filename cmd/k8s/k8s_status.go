@@ -2,10 +2,13 @@ package k8s
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/features"
 	"github.com/spf13/cobra"
 )
 
@@ -14,11 +17,13 @@ func newStatusCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 		waitReady    bool
 		outputFormat string
 		timeout      time.Duration
+		history      string
+		historyLimit int
 	}
 	cmd := &cobra.Command{
 		Use:    "status",
 		Short:  "Retrieve the current status of the cluster",
-		Long:   "Retrieve the current status of the cluster, including node information and the deployment status of core features.",
+		Long:   "Retrieve the current status of the cluster, including node information and the deployment status of core features and add-ons.\nUse --history to show the recent reconcile attempts and health changes of a feature or add-on.",
 		Args:   cobra.NoArgs,
 		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
 		Run: func(cmd *cobra.Command, args []string) {
@@ -47,7 +52,25 @@ func newStatusCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				return
 			}
 
-			response, err := client.ClusterStatus(ctx, opts.waitReady)
+			if opts.history != "" {
+				feature := opts.history
+				// add-ons are recorded with their status name, e.g. "helm-addon/<name>"
+				if addon, ok := strings.CutPrefix(feature, "addon/"); ok {
+					feature = string(features.HelmAddonStatusName(addon))
+				}
+
+				response, err := client.FeatureStatusHistory(ctx, k8sdapi.FeatureStatusHistoryRequest{Feature: feature, Limit: opts.historyLimit})
+				if err != nil {
+					cmd.PrintErrf("Error: Failed to retrieve the status history of %q.\n\nThe error was: %v\n", opts.history, err)
+					env.Exit(1)
+					return
+				}
+
+				outputFormatter.Print(FeatureStatusHistory(response))
+				return
+			}
+
+			response, err := client.ClusterStatusWithAddons(ctx, opts.waitReady)
 			if err != nil {
 				cmd.PrintErrf("Error: Failed to retrieve the cluster status.\n\nThe error was: %v\n", err)
 				env.Exit(1)
@@ -58,12 +81,17 @@ func newStatusCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 			// silence the config, this should be retrieved with "k8s get".
 			status.Config = apiv2.UserFacingClusterConfig{}
 
-			outputFormatter.Print(ClusterStatus(status))
+			outputFormatter.Print(ClusterStatusWithAddons{
+				ClusterStatus: ClusterStatus(status),
+				Addons:        response.Addons,
+			})
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.waitReady, "wait-ready", false, "wait until at least one cluster node is ready")
 	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
 	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+	cmd.Flags().StringVar(&opts.history, "history", "", "show the status history of a feature (e.g. network) or add-on (e.g. addon/<name>)")
+	cmd.Flags().IntVar(&opts.historyLimit, "history-limit", 20, "the maximum number of history entries to show, 0 shows all retained entries")
 	return cmd
 }
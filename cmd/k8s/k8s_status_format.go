@@ -2,13 +2,25 @@ package k8s
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 )
 
 type ClusterStatus apiv2.ClusterStatus
 
+// ClusterStatusWithAddons is the cluster status along with the status of the operator-defined Helm add-ons.
+type ClusterStatusWithAddons struct {
+	ClusterStatus `yaml:",inline"`
+
+	Addons map[string]apiv2.FeatureStatus `json:"addons,omitempty" yaml:"addons,omitempty"`
+}
+
 // TICS -COV_GO_SUPPRESSED_ERROR
 // we are just formatting the output for the k8s status command, it is ok to ignore failures from result.WriteString()
 
@@ -74,4 +86,49 @@ func (c ClusterStatus) String() string {
 	return result.String()
 }
 
+func (c ClusterStatusWithAddons) String() string {
+	result := strings.Builder{}
+	result.WriteString(c.ClusterStatus.String())
+
+	for _, name := range slices.Sorted(maps.Keys(c.Addons)) {
+		result.WriteString(fmt.Sprintf("\n%-25s %s", fmt.Sprintf("addon %s:", name), c.Addons[name]))
+	}
+
+	return result.String()
+}
+
+// FeatureStatusHistory is the history of reconcile attempts and health changes of a feature.
+type FeatureStatusHistory k8sdapi.FeatureStatusHistoryResponse
+
+func (h FeatureStatusHistory) String() string {
+	if len(h.Entries) == 0 {
+		return "no history recorded"
+	}
+
+	result := strings.Builder{}
+	w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "STARTED\tOUTCOME\tCOUNT\tDURATION\tCONFIG\tMESSAGE")
+	for _, entry := range h.Entries {
+		configHash := entry.ConfigHash
+		if len(configHash) > 12 {
+			configHash = configHash[:12]
+		}
+		if configHash == "" {
+			configHash = "-"
+		}
+		count := max(entry.Count, 1)
+		fmt.Fprintf(w, "\n%s\t%s\t%d\t%s\t%s\t%s",
+			entry.StartedAt.Format(time.RFC3339),
+			entry.Outcome,
+			count,
+			entry.Duration.Round(time.Millisecond),
+			configHash,
+			entry.Status,
+		)
+	}
+	w.Flush()
+
+	return result.String()
+}
+
 // TICS +COV_GO_SUPPRESSED_ERROR
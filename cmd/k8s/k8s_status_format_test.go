@@ -2,9 +2,11 @@ package k8s_test
 
 import (
 	"testing"
+	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	"github.com/canonical/k8sd/cmd/k8s"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	. "github.com/onsi/gomega"
 )
 
@@ -92,3 +94,64 @@ gateway                   disabled`,
 		})
 	}
 }
+
+func TestClusterStatusWithAddonsFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	status := k8s.ClusterStatusWithAddons{
+		ClusterStatus: k8s.ClusterStatus(apiv2.ClusterStatus{
+			Ready:     true,
+			Members:   []apiv2.NodeStatus{{Name: "node1", DatastoreRole: apiv2.DatastoreRoleVoter, Address: "192.168.0.1", ClusterRole: apiv2.ClusterRoleControlPlane}},
+			Datastore: apiv2.Datastore{Type: "etcd"},
+			Network:   apiv2.FeatureStatus{Message: "enabled"},
+		}),
+		Addons: map[string]apiv2.FeatureStatus{
+			"observability": {Enabled: false, Message: "Failed to deploy add-on observability"},
+			"cert-manager":  {Enabled: true, Message: "enabled"},
+		},
+	}
+
+	g.Expect(status.String()).To(Equal(`cluster status:           ready
+control plane nodes:      192.168.0.1 (voter)
+high availability:        no
+datastore:                etcd
+network:                  enabled
+dns:                      disabled
+ingress:                  disabled
+load-balancer:            disabled
+local-storage:            disabled
+gateway                   disabled
+addon cert-manager:       enabled
+addon observability:      Failed to deploy add-on observability`))
+}
+
+func TestFeatureStatusHistoryFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	history := k8s.FeatureStatusHistory(k8sdapi.FeatureStatusHistoryResponse{
+		Entries: []k8sdapi.FeatureStatusHistoryEntry{
+			{
+				Outcome:   "unhealthy",
+				Status:    apiv2.FeatureStatus{Enabled: true, Message: "enabled, but unhealthy: pods not ready"},
+				Error:     "pods not ready",
+				StartedAt: t0,
+				Duration:  1234567 * time.Microsecond,
+				Count:     3,
+			},
+			{
+				Outcome:    "succeeded",
+				Status:     apiv2.FeatureStatus{Enabled: true},
+				ConfigHash: "0123456789abcdef",
+				StartedAt:  t0,
+				Duration:   2 * time.Second,
+			},
+		},
+	})
+
+	g.Expect(history.String()).To(Equal(`STARTED               OUTCOME    COUNT  DURATION  CONFIG        MESSAGE
+2026-01-02T03:04:05Z  unhealthy  3      1.235s    -             enabled, but unhealthy: pods not ready
+2026-01-02T03:04:05Z  succeeded  1      2s        0123456789ab  enabled`))
+
+	g.Expect(k8s.FeatureStatusHistory{}.String()).To(Equal("no history recorded"))
+}
@@ -0,0 +1,222 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+// KubernetesAuthTokens is the list of Kubernetes auth tokens of the cluster.
+type KubernetesAuthTokens k8sdapi.ListKubernetesAuthTokensResponse
+
+// TICS -COV_GO_SUPPRESSED_ERROR
+// we are just formatting the list of tokens, it is ok to ignore failures from fmt.Fprintf()
+
+func (l KubernetesAuthTokens) String() string {
+	if len(l.Tokens) == 0 {
+		return "no auth tokens"
+	}
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	result := strings.Builder{}
+	w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "ID\tUSERNAME\tGROUPS\tDESCRIPTION\tCREATED\tEXPIRES\tLAST USED")
+	for _, token := range l.Tokens {
+		groups, description := strings.Join(token.Groups, ","), token.Description
+		if groups == "" {
+			groups = "-"
+		}
+		if description == "" {
+			description = "-"
+		}
+		fmt.Fprintf(w, "\n%d\t%s\t%s\t%s\t%s\t%s\t%s",
+			token.ID,
+			token.Username,
+			groups,
+			description,
+			formatTime(token.CreatedAt),
+			formatTime(token.ExpiresAt),
+			formatTime(token.LastUsedAt),
+		)
+	}
+	w.Flush()
+
+	return result.String()
+}
+
+// TICS +COV_GO_SUPPRESSED_ERROR
+
+func newXAuthTokensCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "x-auth-tokens",
+		Short:  "Manage the tokens that authenticate with the Kubernetes API server",
+		Hidden: true,
+	}
+
+	cmd.AddCommand(
+		newXAuthTokensCreateCmd(env),
+		newXAuthTokensListCmd(env),
+		newXAuthTokensRevokeCmd(env),
+	)
+	return cmd
+}
+
+func newXAuthTokensCreateCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		groups      []string
+		description string
+		ttl         time.Duration
+		timeout     time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "create <username>",
+		Short:  "Create a token for a Kubernetes user",
+		Long:   "Create a new token for a Kubernetes user. Tokens with a TTL expire and are removed automatically, tokens without a TTL are valid until they are revoked.",
+		Args:   cmdutil.ExactArgs(env, 1),
+		PreRun: chainPreRunHooks(hookRequireRoot(env)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			// NOTE: Default to a description that records who created the token, as shown by "k8s x-auth-tokens list".
+			description := opts.description
+			if description == "" {
+				description = fmt.Sprintf("created with k8s x-auth-tokens by %s", cmdutil.Username(env))
+			}
+			response, err := client.GenerateKubernetesAuthToken(ctx, k8sdapi.GenerateKubernetesAuthTokenRequest{
+				GenerateKubernetesAuthTokenRequest: apiv2.GenerateKubernetesAuthTokenRequest{Username: args[0], Groups: opts.groups},
+				Description:                        description,
+				TTL:                                opts.ttl,
+			})
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create an auth token for %q.\n\nThe error was: %v\n", args[0], err)
+				env.Exit(1)
+				return
+			}
+
+			cmd.Println(response.Token)
+		},
+	}
+	cmd.Flags().StringSliceVar(&opts.groups, "group", nil, "the Kubernetes groups of the user")
+	cmd.Flags().StringVar(&opts.description, "description", "", "describe the purpose of the token")
+	cmd.Flags().DurationVar(&opts.ttl, "ttl", 0, "the time until the token expires, tokens without a TTL do not expire")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+func newXAuthTokensListCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		outputFormat string
+		timeout      time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "list",
+		Short:  "List the Kubernetes auth tokens",
+		Long:   "List the Kubernetes auth tokens of the cluster, along with their expiry and last use. The tokens themselves are not shown.",
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			response, err := client.ListKubernetesAuthTokens(ctx)
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to list the auth tokens.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			outputFormatter.Print(KubernetesAuthTokens(response))
+		},
+	}
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+func newXAuthTokensRevokeCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		timeout time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "revoke <id>",
+		Short:  "Revoke a Kubernetes auth token",
+		Long:   `Revoke a Kubernetes auth token, by the ID shown in "k8s x-auth-tokens list".`,
+		Args:   cmdutil.ExactArgs(env, 1),
+		PreRun: chainPreRunHooks(hookRequireRoot(env)),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || id <= 0 {
+				cmd.PrintErrf("Error: Invalid token ID %q, must be a positive number.\n", args[0])
+				env.Exit(1)
+				return
+			}
+
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			if err := client.RevokeKubernetesAuthToken(ctx, k8sdapi.RevokeKubernetesAuthTokenRequest{ID: id}); err != nil {
+				cmd.PrintErrf("Error: Failed to revoke the auth token %d.\n\nThe error was: %v\n", id, err)
+				env.Exit(1)
+				return
+			}
+
+			cmd.Printf("Revoked the auth token %d.\n", id)
+		},
+	}
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	return cmd
+}
@@ -0,0 +1,102 @@
+package k8s_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/cmd/k8s"
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	k8sdmock "github.com/canonical/k8sd/pkg/client/k8sd/mock"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+)
+
+func TestKubernetesAuthTokensFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(k8s.KubernetesAuthTokens{}.String()).To(Equal("no auth tokens"))
+
+	output := k8s.KubernetesAuthTokens{Tokens: []k8sdapi.KubernetesAuthToken{
+		{ID: 1, Username: "admin", Groups: []string{"system:masters"}},
+		{
+			ID:          2,
+			Username:    "ci",
+			Description: "nightly",
+			CreatedAt:   time.Date(2026, 10, 10, 10, 0, 0, 0, time.UTC),
+			ExpiresAt:   time.Date(2026, 10, 11, 10, 0, 0, 0, time.UTC),
+			LastUsedAt:  time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC),
+		},
+	}}.String()
+	g.Expect(output).To(HavePrefix("ID  USERNAME  GROUPS          DESCRIPTION  CREATED"))
+	g.Expect(output).To(ContainSubstring("1   admin     system:masters  -            -"))
+	g.Expect(output).To(ContainSubstring("2   ci        -               nightly      2026-10-10T10:00:00Z  2026-10-11T10:00:00Z  2026-10-10T12:00:00Z"))
+}
+
+func TestK8sXAuthTokensRevokeCmd(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		err            error
+		expectedCall   k8sdapi.RevokeKubernetesAuthTokenRequest
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Revoke",
+			args:           []string{"3"},
+			expectedCall:   k8sdapi.RevokeKubernetesAuthTokenRequest{ID: 3},
+			expectedStdout: "Revoked the auth token 3.",
+		},
+		{
+			name:           "InvalidID",
+			args:           []string{"token::abc"},
+			expectedCode:   1,
+			expectedStderr: "Invalid token ID",
+		},
+		{
+			name:           "Error",
+			args:           []string{"4"},
+			err:            fmt.Errorf("no auth token with id 4"),
+			expectedCall:   k8sdapi.RevokeKubernetesAuthTokenRequest{ID: 4},
+			expectedCode:   1,
+			expectedStderr: "Failed to revoke the auth token 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			mockClient := &k8sdmock.Mock{
+				RevokeKubernetesAuthTokenErr: tt.err,
+			}
+			var returnCode int
+			env := cmdutil.ExecutionEnvironment{
+				Stdout: stdout,
+				Stderr: stderr,
+				Getuid: func() int { return 0 },
+				Snap: &snapmock.Snap{
+					Mock: snapmock.Mock{
+						K8sdClient: mockClient,
+					},
+				},
+				Exit: func(rc int) { returnCode = rc },
+			}
+			cmd := k8s.NewRootCmd(env)
+
+			cmd.SetArgs(append([]string{"x-auth-tokens", "revoke"}, tt.args...))
+			cmd.Execute()
+
+			g.Expect(stdout.String()).To(ContainSubstring(tt.expectedStdout))
+			g.Expect(stderr.String()).To(ContainSubstring(tt.expectedStderr))
+			g.Expect(returnCode).To(Equal(tt.expectedCode))
+			g.Expect(mockClient.RevokeKubernetesAuthTokenCalledWith).To(Equal(tt.expectedCall))
+		})
+	}
+}
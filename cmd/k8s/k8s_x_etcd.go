@@ -0,0 +1,515 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/utils/control"
+	"github.com/spf13/cobra"
+)
+
+// EtcdMembers is the list of members of the managed etcd cluster.
+type EtcdMembers k8sdapi.ListEtcdMembersResponse
+
+// formatBytes formats a size in bytes using binary units, e.g. "12.5MiB".
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// TICS -COV_GO_SUPPRESSED_ERROR
+// we are just formatting the list of members, it is ok to ignore failures from fmt.Fprintf()
+
+func (l EtcdMembers) String() string {
+	if len(l.Members) == 0 {
+		return "no etcd members"
+	}
+
+	result := strings.Builder{}
+	w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "ID\tNAME\tROLE\tLEADER\tDB SIZE\tRAFT INDEX\tVERSION")
+	for _, member := range l.Members {
+		name, role, leader := member.Name, "voter", "no"
+		if name == "" {
+			name = "-"
+		}
+		if member.IsLearner {
+			role = "learner"
+		}
+		if member.IsLeader {
+			leader = "yes"
+		}
+		if member.Error != "" {
+			fmt.Fprintf(w, "\n%s\t%s\t%s\t-\t-\t-\t- (%s)", member.ID, name, role, member.Error)
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\t%s\t%s\t%s\t%s (%s in use)\t%d\t%s",
+			member.ID,
+			name,
+			role,
+			leader,
+			formatBytes(member.DBSize),
+			formatBytes(member.DBSizeInUse),
+			member.RaftIndex,
+			member.Version,
+		)
+	}
+	w.Flush()
+
+	return result.String()
+}
+
+// EtcdSnapshots is the list of stored snapshots of the managed etcd cluster.
+type EtcdSnapshots k8sdapi.ListEtcdSnapshotsResponse
+
+func (l EtcdSnapshots) String() string {
+	result := strings.Builder{}
+	if len(l.Snapshots) == 0 {
+		fmt.Fprintf(&result, "no etcd snapshots in %s", l.Location)
+	} else {
+		// NOTE: Snapshots in the local directories of the control plane nodes are listed with their node.
+		withNode := slices.ContainsFunc(l.Snapshots, func(snapshot k8sdapi.EtcdSnapshot) bool { return snapshot.Node != "" })
+
+		w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+		fmt.Fprint(w, "NAME\tSIZE\tCREATED")
+		if withNode {
+			fmt.Fprint(w, "\tNODE")
+		}
+		for _, snapshot := range l.Snapshots {
+			fmt.Fprintf(w, "\n%s\t%s\t%s", snapshot.Name, formatBytes(snapshot.Size), snapshot.CreatedAt.UTC().Format(time.RFC3339))
+			if withNode {
+				fmt.Fprintf(w, "\t%s", snapshot.Node)
+			}
+		}
+		w.Flush()
+	}
+
+	nodes := slices.Sorted(maps.Keys(l.Unavailable))
+	for _, node := range nodes {
+		fmt.Fprintf(&result, "\nwarning: the snapshots of node %s could not be listed: %s", node, l.Unavailable[node])
+	}
+
+	return result.String()
+}
+
+// EtcdRestoreStatus is the status of the last restore of the etcd cluster.
+type EtcdRestoreStatus k8sdapi.EtcdRestoreStatusResponse
+
+func (s EtcdRestoreStatus) String() string {
+	if s.State == "" {
+		return "No etcd restore was started on this node."
+	}
+
+	result := strings.Builder{}
+	fmt.Fprintf(&result, "Restore of %s started at %s: %s.", s.Snapshot, s.StartedAt.Format(time.RFC3339), s.State)
+	if s.Step != "" {
+		fmt.Fprintf(&result, "\nStep: %s", s.Step)
+	}
+	if s.Error != "" {
+		fmt.Fprintf(&result, "\nError: %s", s.Error)
+	}
+	for _, node := range s.Rejoined {
+		fmt.Fprintf(&result, "\nNode %s rejoined the etcd cluster.", node)
+	}
+	nodes := make([]string, 0, len(s.Failed))
+	for node := range s.Failed {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		fmt.Fprintf(&result, "\nNode %s did not rejoin the etcd cluster: %s", node, s.Failed[node])
+	}
+	return result.String()
+}
+
+// TICS +COV_GO_SUPPRESSED_ERROR
+
+func newXEtcdCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "x-etcd",
+		Short:  "Inspect and maintain the managed etcd cluster",
+		Hidden: true,
+	}
+
+	cmd.AddCommand(
+		newXEtcdMembersCmd(env),
+		newXEtcdMaintenanceCmd(env, k8sdapi.EtcdMaintenanceActionPromote, &cobra.Command{
+			Use:   "promote <member>",
+			Short: "Promote an etcd learner to a voting member",
+			Long:  "Promote an etcd learner to a voting member, by name or ID. The learner must have caught up with the leader.",
+			Args:  cmdutil.ExactArgs(env, 1),
+		}),
+		newXEtcdMaintenanceCmd(env, k8sdapi.EtcdMaintenanceActionMoveLeader, &cobra.Command{
+			Use:   "move-leader <member>",
+			Short: "Transfer the etcd leadership to another member",
+			Long:  "Transfer the leadership of the etcd cluster to a voting member, by name or ID.",
+			Args:  cmdutil.ExactArgs(env, 1),
+		}),
+		newXEtcdMaintenanceCmd(env, k8sdapi.EtcdMaintenanceActionDefragment, &cobra.Command{
+			Use:   "defrag [member]",
+			Short: "Defragment the etcd database",
+			Long:  "Defragment the database of an etcd member to release unused space, or of all members one after another if no member is given. A member does not serve requests while it is being defragmented.",
+			Args:  cmdutil.MaximumNArgs(env, 1),
+		}),
+		newXEtcdMaintenanceCmd(env, k8sdapi.EtcdMaintenanceActionCompact, &cobra.Command{
+			Use:   "compact",
+			Short: "Compact the etcd key-value history",
+			Long: fmt.Sprintf(`Compact the key-value history of the etcd cluster up to a revision, keeping the most recent %d revisions by default. Run "k8s x-etcd defrag" afterwards to release the space.
+The history is never compacted up to the current revision, since watches that are behind the compacted revision fail and clients have to list all resources again.`, k8sdapi.DefaultEtcdCompactRetain),
+			Args: cobra.NoArgs,
+		}),
+		newXEtcdMaintenanceCmd(env, k8sdapi.EtcdMaintenanceActionRejoin, &cobra.Command{
+			Use:   "rejoin <node>",
+			Short: "Rejoin the etcd member of a control plane node",
+			Long: `Reset the etcd member of a control plane node and add it to the etcd cluster again, as a learner that is promoted once it has caught up.
+Run this command for each remaining control plane node after "k8sd cluster-recover" forced a new etcd cluster, once k8s has been started on the node.`,
+			Args: cmdutil.ExactArgs(env, 1),
+		}),
+		newXEtcdSnapshotsCmd(env),
+		newXEtcdSnapshotCmd(env),
+		newXEtcdRestoreCmd(env),
+		newXEtcdRestoreStatusCmd(env),
+	)
+	return cmd
+}
+
+func newXEtcdSnapshotsCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		outputFormat string
+		timeout      time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "snapshots",
+		Short:  "List the etcd snapshots",
+		Long:   "List the stored snapshots of the managed etcd cluster, oldest first. Without an S3 bucket, every control plane node keeps the snapshots it took in its local directory, and the snapshots of all control plane nodes are listed along with their node.",
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			response, err := client.ListEtcdSnapshots(ctx, k8sdapi.ListEtcdSnapshotsRequest{})
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to list the etcd snapshots.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			outputFormatter.Print(EtcdSnapshots(response))
+		},
+	}
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+func newXEtcdSnapshotCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		timeout time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "snapshot",
+		Short:  "Take an etcd snapshot",
+		Long:   "Take a snapshot of the managed etcd cluster now. The snapshot is stored like the scheduled snapshots, and the oldest snapshots are pruned if a retention is configured.",
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			response, err := client.TakeEtcdSnapshot(ctx, k8sdapi.TakeEtcdSnapshotRequest{})
+			if err != nil || response.Snapshot == nil {
+				cmd.PrintErrf("Error: Failed to take an etcd snapshot.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			cmd.Printf("Took the etcd snapshot %s (%s).\n", response.Snapshot.Name, formatBytes(response.Snapshot.Size))
+			for _, name := range response.Pruned {
+				cmd.Printf("Pruned the etcd snapshot %s.\n", name)
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 10*time.Minute, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+func newXEtcdRestoreCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		timeout time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:   "restore <snapshot>",
+		Short: "Restore the etcd cluster from a snapshot",
+		Long: `Restore the managed etcd cluster from a stored snapshot, by name (see "k8s x-etcd snapshots").
+Without an S3 bucket, the snapshot must be restored on the control plane node that stores it.
+The snapshot is verified and restored into a staging directory first. The etcd members of the other control plane nodes are then reset, this node is restored as a single-member cluster, and the other control plane nodes rejoin it one after another.
+The restore runs in the background on this node, and this command follows it until it finishes. Use "k8s x-etcd restore-status" to check on it later.
+The previous etcd data directories are kept next to the new ones. All changes made after the snapshot was taken are lost.`,
+		Args:   cmdutil.ExactArgs(env, 1),
+		PreRun: chainPreRunHooks(hookRequireRoot(env)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			status, err := client.RestoreEtcd(ctx, k8sdapi.RestoreEtcdRequest{Snapshot: args[0]})
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to restore the etcd cluster from %s.\n\nThe error was: %v\n", args[0], err)
+				env.Exit(1)
+				return
+			}
+
+			step := ""
+			if err := control.WaitUntilReady(ctx, func() (bool, error) {
+				if status.Step != step {
+					step = status.Step
+					cmd.Printf("%s...\n", step)
+				}
+				if status.State != k8sdapi.EtcdRestoreStateRunning {
+					return true, nil
+				}
+				var err error
+				status, err = client.EtcdRestoreStatus(ctx)
+				return false, err
+			}); err != nil {
+				cmd.PrintErrf("Error: Failed to wait for the restore of the etcd cluster from %s. Use \"k8s x-etcd restore-status\" to check on it.\n\nThe error was: %v\n", args[0], err)
+				env.Exit(1)
+				return
+			}
+
+			if status.State == k8sdapi.EtcdRestoreStateFailed {
+				cmd.PrintErrf("Error: Failed to restore the etcd cluster from %s.\n\nThe error was: %v\n", args[0], status.Error)
+				env.Exit(1)
+				return
+			}
+
+			cmd.Printf("Restored the etcd cluster from %s.\n", args[0])
+			for _, node := range status.Rejoined {
+				cmd.Printf("Node %s rejoined the etcd cluster.\n", node)
+			}
+			if len(status.Failed) > 0 {
+				nodes := make([]string, 0, len(status.Failed))
+				for node := range status.Failed {
+					nodes = append(nodes, node)
+				}
+				sort.Strings(nodes)
+				for _, node := range nodes {
+					cmd.PrintErrf("Error: Node %s did not rejoin the etcd cluster: %s\n", node, status.Failed[node])
+				}
+				cmd.PrintErrln("\nRemove the nodes that did not rejoin the cluster and join them again.")
+				env.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 30*time.Minute, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+func newXEtcdRestoreStatusCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		outputFormat string
+		timeout      time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "restore-status",
+		Short:  "Show the status of the etcd restore",
+		Long:   "Show the status of the last restore of the etcd cluster that was started on this node.",
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			status, err := client.EtcdRestoreStatus(ctx)
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to retrieve the status of the etcd restore.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			outputFormatter.Print(EtcdRestoreStatus(status))
+		},
+	}
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+func newXEtcdMembersCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		outputFormat string
+		timeout      time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:    "members",
+		Short:  "List the etcd cluster members",
+		Long:   "List the members of the managed etcd cluster, along with their role, leader status, database size and raft index.",
+		Args:   cobra.NoArgs,
+		PreRun: chainPreRunHooks(hookRequireRoot(env), hookInitializeFormatter(env, &opts.outputFormat)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.timeout < minTimeout {
+				cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+				opts.timeout = minTimeout
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			cobra.OnFinalize(cancel)
+
+			response, err := client.ListEtcdMembers(ctx)
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to list the etcd members.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			outputFormatter.Print(EtcdMembers(response))
+		},
+	}
+	cmd.Flags().StringVar(&opts.outputFormat, "output-format", "plain", "set the output format to one of plain, json or yaml")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 90*time.Second, "the max time to wait for the command to execute")
+
+	return cmd
+}
+
+// newXEtcdMaintenanceCmd completes cmd to perform an etcd maintenance action.
+// The optional argument of cmd is the member to operate on.
+func newXEtcdMaintenanceCmd(env cmdutil.ExecutionEnvironment, action k8sdapi.EtcdMaintenanceAction, cmd *cobra.Command) *cobra.Command {
+	var opts struct {
+		revision int64
+		retain   int64
+		timeout  time.Duration
+	}
+	cmd.PreRun = chainPreRunHooks(hookRequireRoot(env))
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if opts.timeout < minTimeout {
+			cmd.PrintErrf("Timeout %v is less than minimum of %v. Using the minimum %v instead.\n", opts.timeout, minTimeout, minTimeout)
+			opts.timeout = minTimeout
+		}
+
+		client, err := env.Snap.K8sdClient("")
+		if err != nil {
+			cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+			env.Exit(1)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+		cobra.OnFinalize(cancel)
+
+		request := k8sdapi.EtcdMaintenanceRequest{Action: action, Revision: opts.revision, Retain: opts.retain}
+		if len(args) > 0 {
+			request.Member = args[0]
+		}
+		response, err := client.EtcdMaintenance(ctx, request)
+		if err != nil {
+			cmd.PrintErrf("Error: The etcd %s action failed.\n\nThe error was: %v\n", action, err)
+			env.Exit(1)
+			return
+		}
+
+		switch action {
+		case k8sdapi.EtcdMaintenanceActionPromote:
+			cmd.Printf("Promoted the etcd member %s.\n", request.Member)
+		case k8sdapi.EtcdMaintenanceActionMoveLeader:
+			cmd.Printf("Moved the etcd leadership to %s.\n", request.Member)
+		case k8sdapi.EtcdMaintenanceActionDefragment:
+			if request.Member == "" {
+				cmd.Println("Defragmented all etcd members.")
+			} else {
+				cmd.Printf("Defragmented the etcd member %s.\n", request.Member)
+			}
+		case k8sdapi.EtcdMaintenanceActionCompact:
+			cmd.Printf("Compacted the etcd history to revision %d.\n", response.Revision)
+		case k8sdapi.EtcdMaintenanceActionRejoin:
+			cmd.Printf("Node %s rejoined the etcd cluster.\n", request.Member)
+		}
+	}
+	if action == k8sdapi.EtcdMaintenanceActionCompact {
+		cmd.Flags().Int64Var(&opts.revision, "revision", 0, "the revision to compact the history to, which must be lower than the current revision")
+		cmd.Flags().Int64Var(&opts.retain, "retain", 0, fmt.Sprintf("the number of most recent revisions to keep (default %d)", k8sdapi.DefaultEtcdCompactRetain))
+		cmd.MarkFlagsMutuallyExclusive("revision", "retain")
+	}
+	defaultTimeout := 90 * time.Second
+	if action == k8sdapi.EtcdMaintenanceActionRejoin {
+		// NOTE: The member is only promoted once it has caught up with the leader.
+		defaultTimeout = 5 * time.Minute
+	}
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", defaultTimeout, "the max time to wait for the command to execute")
+
+	return cmd
+}
@@ -0,0 +1,282 @@
+package k8s_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/cmd/k8s"
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	k8sdmock "github.com/canonical/k8sd/pkg/client/k8sd/mock"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+)
+
+func TestEtcdMembersFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(k8s.EtcdMembers{}.String()).To(Equal("no etcd members"))
+
+	output := k8s.EtcdMembers{Members: []k8sdapi.EtcdMember{
+		{ID: "8e9e05c52164694d", Name: "cp1", IsLeader: true, DBSize: 25 * 1024 * 1024, DBSizeInUse: 10 * 1024 * 1024, RaftIndex: 1234, Version: "3.6.7"},
+		{ID: "91bc3c398fb3c146", Name: "cp2", IsLearner: true, DBSize: 512, DBSizeInUse: 512, RaftIndex: 1200, Version: "3.6.7"},
+		{ID: "fd422379fda50e48", IsLearner: true, Error: "member has not started yet"},
+	}}.String()
+	g.Expect(output).To(HavePrefix("ID                NAME  ROLE     LEADER  DB SIZE"))
+	g.Expect(output).To(ContainSubstring("8e9e05c52164694d  cp1   voter    yes     25.0MiB (10.0MiB in use)  1234        3.6.7"))
+	g.Expect(output).To(ContainSubstring("91bc3c398fb3c146  cp2   learner  no      512B (512B in use)        1200        3.6.7"))
+	g.Expect(output).To(ContainSubstring("fd422379fda50e48  -     learner  -       -                         -           - (member has not started yet)"))
+}
+
+func TestEtcdSnapshotsFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(k8s.EtcdSnapshots{Location: "s3://backups/"}.String()).To(Equal("no etcd snapshots in s3://backups/"))
+
+	createdAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	output := k8s.EtcdSnapshots{
+		Snapshots: []k8sdapi.EtcdSnapshot{
+			{Name: "etcd-snapshot-cp1-20261016T120000Z.db", Size: 2048, CreatedAt: createdAt, Node: "cp1"},
+			{Name: "etcd-snapshot-cp2-20261016T180000Z.db", Size: 4096, CreatedAt: createdAt.Add(6 * time.Hour), Node: "cp2"},
+		},
+		Unavailable: map[string]string{"cp3": "connection refused"},
+	}.String()
+	g.Expect(output).To(HavePrefix("NAME                                   SIZE    CREATED               NODE"))
+	g.Expect(output).To(ContainSubstring("etcd-snapshot-cp1-20261016T120000Z.db  2.0KiB  2026-10-16T12:00:00Z  cp1"))
+	g.Expect(output).To(ContainSubstring("etcd-snapshot-cp2-20261016T180000Z.db  4.0KiB  2026-10-16T18:00:00Z  cp2"))
+	g.Expect(output).To(HaveSuffix("warning: the snapshots of node cp3 could not be listed: connection refused"))
+}
+
+func TestK8sXEtcdMaintenanceCmd(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		response       k8sdapi.EtcdMaintenanceResponse
+		err            error
+		expectedCall   k8sdapi.EtcdMaintenanceRequest
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Promote",
+			args:           []string{"promote", "cp2"},
+			expectedCall:   k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionPromote, Member: "cp2"},
+			expectedStdout: "Promoted the etcd member cp2.",
+		},
+		{
+			name:           "PromoteNoMember",
+			args:           []string{"promote"},
+			expectedCode:   1,
+			expectedStderr: "accepts 1 arg(s), received 0",
+		},
+		{
+			name:           "MoveLeader",
+			args:           []string{"move-leader", "cp3"},
+			expectedCall:   k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionMoveLeader, Member: "cp3"},
+			expectedStdout: "Moved the etcd leadership to cp3.",
+		},
+		{
+			name:           "Rejoin",
+			args:           []string{"rejoin", "cp2"},
+			expectedCall:   k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionRejoin, Member: "cp2"},
+			expectedStdout: "Node cp2 rejoined the etcd cluster.",
+		},
+		{
+			name:           "DefragAll",
+			args:           []string{"defrag"},
+			expectedCall:   k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionDefragment},
+			expectedStdout: "Defragmented all etcd members.",
+		},
+		{
+			name:           "Compact",
+			args:           []string{"compact"},
+			response:       k8sdapi.EtcdMaintenanceResponse{Revision: 4321},
+			expectedCall:   k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionCompact},
+			expectedStdout: "Compacted the etcd history to revision 4321.",
+		},
+		{
+			name:           "CompactRevision",
+			args:           []string{"compact", "--revision", "4000"},
+			response:       k8sdapi.EtcdMaintenanceResponse{Revision: 4000},
+			expectedCall:   k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionCompact, Revision: 4000},
+			expectedStdout: "Compacted the etcd history to revision 4000.",
+		},
+		{
+			name:           "CompactRetain",
+			args:           []string{"compact", "--retain", "100"},
+			response:       k8sdapi.EtcdMaintenanceResponse{Revision: 4221},
+			expectedCall:   k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionCompact, Retain: 100},
+			expectedStdout: "Compacted the etcd history to revision 4221.",
+		},
+		{
+			name:           "Error",
+			args:           []string{"promote", "cp1"},
+			err:            fmt.Errorf("etcd member cp1 is not a learner"),
+			expectedCall:   k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionPromote, Member: "cp1"},
+			expectedCode:   1,
+			expectedStderr: "The etcd promote action failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			mockClient := &k8sdmock.Mock{
+				EtcdMaintenanceResponse: tt.response,
+				EtcdMaintenanceErr:      tt.err,
+			}
+			var returnCode int
+			env := cmdutil.ExecutionEnvironment{
+				Stdout: stdout,
+				Stderr: stderr,
+				Getuid: func() int { return 0 },
+				Snap: &snapmock.Snap{
+					Mock: snapmock.Mock{
+						K8sdClient: mockClient,
+					},
+				},
+				Exit: func(rc int) { returnCode = rc },
+			}
+			cmd := k8s.NewRootCmd(env)
+
+			cmd.SetArgs(append([]string{"x-etcd"}, tt.args...))
+			cmd.Execute()
+
+			g.Expect(stdout.String()).To(ContainSubstring(tt.expectedStdout))
+			g.Expect(stderr.String()).To(ContainSubstring(tt.expectedStderr))
+			g.Expect(returnCode).To(Equal(tt.expectedCode))
+			g.Expect(mockClient.EtcdMaintenanceCalledWith).To(Equal(tt.expectedCall))
+		})
+	}
+}
+
+func TestK8sXEtcdRestoreCmd(t *testing.T) {
+	running := k8sdapi.EtcdRestoreStatusResponse{Snapshot: "etcd-snapshot-cp1-20261016T120000Z.db", State: k8sdapi.EtcdRestoreStateRunning, Step: "Fetching the snapshot"}
+	tests := []struct {
+		name           string
+		status         k8sdapi.EtcdRestoreStatusResponse
+		err            error
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Restored",
+			status:         k8sdapi.EtcdRestoreStatusResponse{State: k8sdapi.EtcdRestoreStateSucceeded, Step: "Restored the etcd cluster", Rejoined: []string{"cp2", "cp3"}},
+			expectedStdout: "Fetching the snapshot...\nRestored the etcd cluster...\nRestored the etcd cluster from etcd-snapshot-cp1-20261016T120000Z.db.\nNode cp2 rejoined the etcd cluster.\nNode cp3 rejoined the etcd cluster.\n",
+		},
+		{
+			name:           "NodeFailed",
+			status:         k8sdapi.EtcdRestoreStatusResponse{State: k8sdapi.EtcdRestoreStateSucceeded, Rejoined: []string{"cp2"}, Failed: map[string]string{"cp3": "failed to promote etcd member: context deadline exceeded"}},
+			expectedCode:   1,
+			expectedStdout: "Node cp2 rejoined the etcd cluster.",
+			expectedStderr: "Error: Node cp3 did not rejoin the etcd cluster: failed to promote etcd member: context deadline exceeded",
+		},
+		{
+			name:           "RestoreFailed",
+			status:         k8sdapi.EtcdRestoreStatusResponse{State: k8sdapi.EtcdRestoreStateFailed, Step: "Verifying the snapshot", Error: "failed to verify snapshot: snapshot does not match its integrity hash"},
+			expectedCode:   1,
+			expectedStdout: "Verifying the snapshot...",
+			expectedStderr: "Failed to restore the etcd cluster from etcd-snapshot-cp1-20261016T120000Z.db.\n\nThe error was: failed to verify snapshot",
+		},
+		{
+			name:           "Error",
+			err:            fmt.Errorf("invalid snapshot name"),
+			expectedCode:   1,
+			expectedStderr: "Failed to restore the etcd cluster from etcd-snapshot-cp1-20261016T120000Z.db",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			mockClient := &k8sdmock.Mock{
+				RestoreEtcdResponse:       running,
+				RestoreEtcdErr:            tt.err,
+				EtcdRestoreStatusResponse: tt.status,
+			}
+			var returnCode int
+			env := cmdutil.ExecutionEnvironment{
+				Stdout: stdout,
+				Stderr: stderr,
+				Getuid: func() int { return 0 },
+				Snap: &snapmock.Snap{
+					Mock: snapmock.Mock{
+						K8sdClient: mockClient,
+					},
+				},
+				Exit: func(rc int) { returnCode = rc },
+			}
+			cmd := k8s.NewRootCmd(env)
+
+			cmd.SetArgs([]string{"x-etcd", "restore", "etcd-snapshot-cp1-20261016T120000Z.db"})
+			cmd.Execute()
+
+			g.Expect(stdout.String()).To(ContainSubstring(tt.expectedStdout))
+			g.Expect(stderr.String()).To(ContainSubstring(tt.expectedStderr))
+			g.Expect(returnCode).To(Equal(tt.expectedCode))
+			g.Expect(mockClient.RestoreEtcdCalledWith).To(Equal(k8sdapi.RestoreEtcdRequest{Snapshot: "etcd-snapshot-cp1-20261016T120000Z.db"}))
+		})
+	}
+}
+
+func TestK8sXEtcdRestoreStatusCmd(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         k8sdapi.EtcdRestoreStatusResponse
+		expectedStdout string
+	}{
+		{
+			name:           "None",
+			expectedStdout: "No etcd restore was started on this node.",
+		},
+		{
+			name: "Running",
+			status: k8sdapi.EtcdRestoreStatusResponse{
+				Snapshot:  "etcd-snapshot-cp1-20261016T120000Z.db",
+				State:     k8sdapi.EtcdRestoreStateRunning,
+				Step:      "Rejoining the etcd member of node cp3",
+				StartedAt: time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC),
+				Rejoined:  []string{"cp2"},
+			},
+			expectedStdout: "Restore of etcd-snapshot-cp1-20261016T120000Z.db started at 2026-10-16T12:30:00Z: running.\nStep: Rejoining the etcd member of node cp3\nNode cp2 rejoined the etcd cluster.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			stdout := &bytes.Buffer{}
+			mockClient := &k8sdmock.Mock{EtcdRestoreStatusResponse: tt.status}
+			var returnCode int
+			env := cmdutil.ExecutionEnvironment{
+				Stdout: stdout,
+				Stderr: &bytes.Buffer{},
+				Getuid: func() int { return 0 },
+				Snap: &snapmock.Snap{
+					Mock: snapmock.Mock{
+						K8sdClient: mockClient,
+					},
+				},
+				Exit: func(rc int) { returnCode = rc },
+			}
+			cmd := k8s.NewRootCmd(env)
+
+			cmd.SetArgs([]string{"x-etcd", "restore-status"})
+			cmd.Execute()
+
+			g.Expect(stdout.String()).To(ContainSubstring(tt.expectedStdout))
+			g.Expect(returnCode).To(Equal(0))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	cmdutil "github.com/canonical/k8sd/cmd/util"
@@ -11,60 +12,47 @@ import (
 )
 
 func newXWaitForCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
-	var opts struct {
-		timeout time.Duration
+	cmd := &cobra.Command{
+		Use:    "x-wait-for",
+		Short:  "Wait for specific cluster features to be ready",
+		Long:   "Wait for specific cluster features (such as DNS or Network) to be ready. This is an experimental command.",
+		Hidden: true,
 	}
-	waitForDNSCmd := &cobra.Command{
-		Use:   string(features.DNS),
-		Short: "Wait for DNS to be ready",
-		Args:  cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
-			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
-			defer cancel()
-			if err := control.WaitUntilReady(ctx, func() (bool, error) {
-				err := features.StatusChecks.CheckDNS(cmd.Context(), env.Snap)
-				if err != nil {
-					cmd.PrintErrf("DNS not ready yet: %v\n", err.Error())
-				}
-				return err == nil, nil
-			}); err != nil {
-				cmd.PrintErrf("Error: DNS did not become ready: %v\n", err)
-				env.Exit(1)
-			}
-		},
+
+	for _, feature := range features.Registered() {
+		if feature.CheckStatus == nil {
+			continue
+		}
+		cmd.AddCommand(newXWaitForFeatureCmd(env, feature))
 	}
-	waitForDNSCmd.Flags().DurationVar(&opts.timeout, "timeout", 5*time.Minute, "maximum time to wait")
 
-	waitForNetworkCmd := &cobra.Command{
-		Use:   string(features.Network),
-		Short: "Wait for Network to be ready",
+	return cmd
+}
+
+func newXWaitForFeatureCmd(env cmdutil.ExecutionEnvironment, feature features.Feature) *cobra.Command {
+	var opts struct {
+		timeout time.Duration
+	}
+	cmd := &cobra.Command{
+		Use:   string(feature.Name),
+		Short: fmt.Sprintf("Wait for %s to be ready", feature.Name),
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
 			defer cancel()
 			if err := control.WaitUntilReady(ctx, func() (bool, error) {
-				err := features.StatusChecks.CheckNetwork(cmd.Context(), env.Snap)
+				err := feature.CheckStatus(cmd.Context(), env.Snap)
 				if err != nil {
-					cmd.PrintErrf("network not ready yet: %v\n", err.Error())
+					cmd.PrintErrf("%s not ready yet: %v\n", feature.Name, err.Error())
 				}
 				return err == nil, nil
 			}); err != nil {
-				cmd.PrintErrf("Error: network did not become ready: %v\n", err)
+				cmd.PrintErrf("Error: %s did not become ready: %v\n", feature.Name, err)
 				env.Exit(1)
 			}
 		},
 	}
-	waitForNetworkCmd.Flags().DurationVar(&opts.timeout, "timeout", 5*time.Minute, "maximum time to wait")
-
-	cmd := &cobra.Command{
-		Use:    "x-wait-for",
-		Short:  "Wait for specific cluster features to be ready",
-		Long:   "Wait for specific cluster features (such as DNS or Network) to be ready. This is an experimental command.",
-		Hidden: true,
-	}
-
-	cmd.AddCommand(waitForDNSCmd)
-	cmd.AddCommand(waitForNetworkCmd)
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 5*time.Minute, "maximum time to wait")
 
 	return cmd
 }
@@ -15,6 +15,7 @@ var rootCmdOpts struct {
 	logLevel                            int
 	stateDir                            string
 	pprofAddress                        string
+	metricsAddress                      string
 	disableNodeConfigController         bool
 	nodeConfigControllerWatchDuration   time.Duration
 	disableNodeLabelController          bool
@@ -28,6 +29,15 @@ var rootCmdOpts struct {
 	featureControllerMaxRetryAttempts   int
 	disableServiceArgsController        bool
 	serviceArgsControllerCheckInterval  time.Duration
+	disableCertRotationController       bool
+	certRotationCheckInterval           time.Duration
+	certRotationThreshold               float64
+	certRotationValidity                time.Duration
+	disableCertExpiryController         bool
+	certExpiryCheckInterval             time.Duration
+	certExpiryWarningWindow             time.Duration
+	disableEtcdSnapshotController       bool
+	etcdSnapshotCheckInterval           time.Duration
 }
 
 func addCommands(root *cobra.Command, group *cobra.Group, commands ...*cobra.Command) {
@@ -53,24 +63,34 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 			})
 
 			app, err := app.New(app.Config{
-				Debug:                               rootCmdOpts.logDebug,
-				Verbose:                             rootCmdOpts.logVerbose,
-				StateDir:                            rootCmdOpts.stateDir,
-				Snap:                                env.Snap,
-				PprofAddress:                        rootCmdOpts.pprofAddress,
-				DisableNodeConfigController:         rootCmdOpts.disableNodeConfigController,
-				NodeConfigControllerWatchDuration:   rootCmdOpts.nodeConfigControllerWatchDuration,
-				DisableNodeLabelController:          rootCmdOpts.disableNodeLabelController,
-				DisableControlPlaneConfigController: rootCmdOpts.disableControlPlaneConfigController,
-				DisableUpdateNodeConfigController:   rootCmdOpts.disableUpdateNodeConfigController,
-				DisableFeatureController:            rootCmdOpts.disableFeatureController,
-				DisableDNSRebalancerController:      rootCmdOpts.disableDNSRebalancerController,
-				DisableCSRSigningController:         rootCmdOpts.disableCSRSigningController,
-				DisableUpgradeController:            rootCmdOpts.disableUpgradeController,
-				DrainConnectionsTimeout:             rootCmdOpts.drainConnectionsTimeout,
-				FeatureControllerMaxRetryAttempts:   rootCmdOpts.featureControllerMaxRetryAttempts,
-				DisableServiceArgsController:        rootCmdOpts.disableServiceArgsController,
-				ServiceArgsControllerCheckInterval:  rootCmdOpts.serviceArgsControllerCheckInterval,
+				Debug:                                rootCmdOpts.logDebug,
+				Verbose:                              rootCmdOpts.logVerbose,
+				StateDir:                             rootCmdOpts.stateDir,
+				Snap:                                 env.Snap,
+				PprofAddress:                         rootCmdOpts.pprofAddress,
+				MetricsAddress:                       rootCmdOpts.metricsAddress,
+				DisableNodeConfigController:          rootCmdOpts.disableNodeConfigController,
+				NodeConfigControllerWatchDuration:    rootCmdOpts.nodeConfigControllerWatchDuration,
+				DisableNodeLabelController:           rootCmdOpts.disableNodeLabelController,
+				DisableControlPlaneConfigController:  rootCmdOpts.disableControlPlaneConfigController,
+				DisableUpdateNodeConfigController:    rootCmdOpts.disableUpdateNodeConfigController,
+				DisableFeatureController:             rootCmdOpts.disableFeatureController,
+				DisableDNSRebalancerController:       rootCmdOpts.disableDNSRebalancerController,
+				DisableCSRSigningController:          rootCmdOpts.disableCSRSigningController,
+				DisableUpgradeController:             rootCmdOpts.disableUpgradeController,
+				DrainConnectionsTimeout:              rootCmdOpts.drainConnectionsTimeout,
+				FeatureControllerMaxRetryAttempts:    rootCmdOpts.featureControllerMaxRetryAttempts,
+				DisableServiceArgsController:         rootCmdOpts.disableServiceArgsController,
+				ServiceArgsControllerCheckInterval:   rootCmdOpts.serviceArgsControllerCheckInterval,
+				DisableCertificateRotationController: rootCmdOpts.disableCertRotationController,
+				CertificateRotationCheckInterval:     rootCmdOpts.certRotationCheckInterval,
+				CertificateRotationThreshold:         rootCmdOpts.certRotationThreshold,
+				CertificateRotationValidity:          rootCmdOpts.certRotationValidity,
+				DisableCertificateExpiryController:   rootCmdOpts.disableCertExpiryController,
+				CertificateExpiryCheckInterval:       rootCmdOpts.certExpiryCheckInterval,
+				CertificateExpiryWarningWindow:       rootCmdOpts.certExpiryWarningWindow,
+				DisableEtcdSnapshotController:        rootCmdOpts.disableEtcdSnapshotController,
+				EtcdSnapshotCheckInterval:            rootCmdOpts.etcdSnapshotCheckInterval,
 			})
 			if err != nil {
 				cmd.PrintErrf("Error: Failed to initialize k8sd: %v", err)
@@ -95,6 +115,7 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(&rootCmdOpts.logVerbose, "verbose", "v", true, "Deprecated: Show all information messages")
 	cmd.PersistentFlags().StringVar(&rootCmdOpts.stateDir, "state-dir", "", "Directory with the datastore")
 	cmd.PersistentFlags().StringVar(&rootCmdOpts.pprofAddress, "pprof-address", "", "Listen address for pprof endpoints, e.g. \"127.0.0.1:4217\"")
+	cmd.PersistentFlags().StringVar(&rootCmdOpts.metricsAddress, "metrics-address", "", "Listen address for the Prometheus metrics endpoint, e.g. \"127.0.0.1:4218\"")
 	cmd.PersistentFlags().BoolVar(&rootCmdOpts.disableNodeConfigController, "disable-node-config-controller", false, "Disable the Node Config Controller")
 	cmd.Flags().DurationVar(&rootCmdOpts.nodeConfigControllerWatchDuration, "node-config-controller-watch-duration", 5*time.Minute, "The duration that node config controller watches k8sd-config map before restarting and triggering a fresh GET/WATCH. Should be greater than 30 seconds.")
 	cmd.PersistentFlags().BoolVar(&rootCmdOpts.disableNodeLabelController, "disable-node-label-controller", false, "Disable the Node Label Controller")
@@ -111,6 +132,15 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	cmd.Flags().IntVar(&rootCmdOpts.featureControllerMaxRetryAttempts, "feature-controller-max-retry-attempts", 64, "Maximum number of retry attempts for the feature controller before giving up. Zero or negative values mean no limit.")
 	cmd.Flags().BoolVar(&rootCmdOpts.disableServiceArgsController, "disable-service-args-controller", false, "Disable the Service Args Controller")
 	cmd.Flags().DurationVar(&rootCmdOpts.serviceArgsControllerCheckInterval, "service-args-controller-check-interval", 2*time.Minute, "Interval at which the service args controller checks for changes. Should be greater than 30 seconds.")
+	cmd.Flags().BoolVar(&rootCmdOpts.disableCertRotationController, "disable-certificate-rotation-controller", false, "Disable the Certificate Rotation Controller")
+	cmd.Flags().DurationVar(&rootCmdOpts.certRotationCheckInterval, "certificate-rotation-check-interval", time.Hour, "Interval at which the certificate rotation controller checks the expiry of the node certificates. Should be greater than 30 seconds.")
+	cmd.Flags().Float64Var(&rootCmdOpts.certRotationThreshold, "certificate-rotation-threshold", 1.0/3, "Certificates are renewed automatically when less than this fraction of their lifetime is left.")
+	cmd.Flags().DurationVar(&rootCmdOpts.certRotationValidity, "certificate-rotation-validity", 365*24*time.Hour, "Validity of automatically renewed certificates.")
+	cmd.Flags().BoolVar(&rootCmdOpts.disableCertExpiryController, "disable-certificate-expiry-controller", false, "Disable the Certificate Expiry Controller")
+	cmd.Flags().DurationVar(&rootCmdOpts.certExpiryCheckInterval, "certificate-expiry-check-interval", time.Minute, "Interval at which the certificate expiry controller updates the certificate metrics. Should be greater than 30 seconds.")
+	cmd.Flags().DurationVar(&rootCmdOpts.certExpiryWarningWindow, "certificate-expiry-warning-window", 14*24*time.Hour, "Warning events are recorded on the node for certificates that expire within this duration.")
+	cmd.Flags().BoolVar(&rootCmdOpts.disableEtcdSnapshotController, "disable-etcd-snapshot-controller", false, "Disable the Etcd Snapshot Controller")
+	cmd.Flags().DurationVar(&rootCmdOpts.etcdSnapshotCheckInterval, "etcd-snapshot-check-interval", time.Minute, "Interval at which the etcd snapshot controller checks whether a scheduled etcd snapshot is due. Should be greater than 30 seconds.")
 
 	cmd.AddCommand(newSqlCmd(env))
 
@@ -118,6 +148,7 @@ func NewRootCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 		cmd,
 		&cobra.Group{ID: "cluster", Title: "K8sd clustering commands:"},
 		newClusterRecoverCmd(env),
+		newClusterExportCmd(env),
 	)
 
 	return cmd
@@ -0,0 +1,70 @@
+package k8sd
+
+import (
+	"os"
+
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+func newClusterExportCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
+	var opts struct {
+		outputFile     string
+		passphraseFile string
+	}
+	cmd := &cobra.Command{
+		Use:   "cluster-export",
+		Short: "Export the cluster configuration as a sealed bundle for disaster recovery",
+		Long: `Export the cluster configuration as a sealed bundle for disaster recovery.
+
+The bundle contains the complete cluster configuration, including the certificate
+authorities and their keys, the datastore settings, the feature configuration,
+the ClusterAPI token and the Kubernetes auth tokens. The bundle is encrypted and
+signed with the passphrase, and must be stored securely.
+
+Use 'k8s bootstrap --bundle <file> --bundle-passphrase-file <file>' on a fresh
+machine to rebuild an equivalent control plane from the bundle.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.outputFile == "" || opts.passphraseFile == "" {
+				cmd.PrintErrln("Error: --output and --passphrase-file flags must be set.")
+				env.Exit(1)
+				return
+			}
+
+			passphrase, err := cmdutil.ReadPassphrase(env, opts.passphraseFile)
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to read the passphrase.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			client, err := env.Snap.K8sdClient("")
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to create a k8sd client. Make sure that the k8sd service is running.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			response, err := client.ExportClusterBundle(cmd.Context(), k8sdapi.ExportClusterBundleRequest{Passphrase: passphrase})
+			if err != nil {
+				cmd.PrintErrf("Error: Failed to export the cluster bundle.\n\nThe error was: %v\n", err)
+				env.Exit(1)
+				return
+			}
+
+			if err := os.WriteFile(opts.outputFile, response.Bundle, 0o600); err != nil {
+				cmd.PrintErrf("Error: Failed to write the cluster bundle to %q.\n\nThe error was: %v\n", opts.outputFile, err)
+				env.Exit(1)
+				return
+			}
+			cmd.Printf("Cluster bundle saved to %s\n", opts.outputFile)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.outputFile, "output", "o", "", "path to write the cluster bundle to")
+	cmd.Flags().StringVar(&opts.passphraseFile, "passphrase-file", "", "path to a file containing the passphrase used to seal the bundle. Use '-' to read from stdin.")
+
+	return cmd
+}
@@ -28,6 +28,7 @@ const preRecoveryMessage = `You should only run this command if:
 
 Note that before applying any changes, a database backup is created at:
 * k8sd (microcluster): /var/snap/k8s/common/var/lib/k8sd/state/db_backup.<timestamp>.tar.gz
+* etcd (if used as the datastore): /var/snap/k8s/common/var/lib/etcd/data_backup.<timestamp>.tar.gz
 `
 
 const recoveryConfirmation = "Do you want to proceed? (yes/no): "
@@ -68,9 +69,13 @@ const daemonYamlRecoveryComment = `# Verify the name and address of the local no
 const yamlHelperCommentFooter = "# ------- everything below will be written -------\n"
 
 var clusterRecoverOpts struct {
-	NonInteractive bool
+	NonInteractive   bool
+	SkipEtcdRecovery bool
 }
 
+// stdinReader reads the answers to the interactive prompts.
+var stdinReader = bufio.NewReader(os.Stdin)
+
 func newClusterRecoverCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cluster-recover",
@@ -86,7 +91,7 @@ func newClusterRecoverCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				env.Exit(1)
 			}
 
-			k8sdTarballPath, err := recoverK8sd()
+			k8sdTarballPath, staleMembers, remainingMembers, err := recoverK8sd()
 			if err != nil {
 				cmd.PrintErrf("Failed to recover k8sd, error: %v\n", err)
 				env.Exit(1)
@@ -97,11 +102,29 @@ func newClusterRecoverCmd(env cmdutil.ExecutionEnvironment) *cobra.Command {
 				"on all remaining cluster members.\n",
 				k8sdTarballPath, k8sdTarballPath)
 			cmd.Printf("K8sd will load this file during startup.\n\n")
+
+			etcdMember, err := recoverEtcd(cmd, env.Snap)
+			if err != nil {
+				cmd.PrintErrf("Failed to recover etcd, error: %v\n", err)
+				env.Exit(1)
+			}
+
+			if err := removeStaleMembers(cmd, env.Snap, staleMembers); err != nil {
+				cmd.PrintErrf("Failed to remove the lost cluster members, error: %v\n", err)
+				env.Exit(1)
+			}
+
+			if err := rejoinEtcdMembers(cmd, env.Snap, etcdMember, remainingMembers); err != nil {
+				cmd.PrintErrf("Failed to rejoin the etcd members of the remaining cluster members, error: %v\n", err)
+				env.Exit(1)
+			}
 		},
 	}
 
 	cmd.Flags().BoolVar(&clusterRecoverOpts.NonInteractive, "non-interactive",
 		false, "disable interactive prompts, assume that the configs have been updated")
+	cmd.Flags().BoolVar(&clusterRecoverOpts.SkipEtcdRecovery, "skip-etcd-recovery",
+		false, "do not force a new etcd cluster from this member, even if the managed etcd datastore is used")
 
 	return cmd
 }
@@ -139,16 +162,11 @@ func recoveryCmdPrechecks(cmd *cobra.Command) error {
 		cmd.Print(nonInteractiveMessage)
 		cmd.Print("\n")
 	} else {
-		reader := bufio.NewReader(os.Stdin)
-		cmd.Print(recoveryConfirmation)
-
-		input, err := reader.ReadString('\n')
+		confirmed, err := readConfirmation(cmd, recoveryConfirmation)
 		if err != nil {
-			return fmt.Errorf("couldn't read user input, error: %w", err)
+			return err
 		}
-		input = strings.TrimSuffix(input, "\n")
-
-		if strings.ToLower(input) != "yes" {
+		if !confirmed {
 			return fmt.Errorf("cluster edit aborted; no changes made")
 		}
 
@@ -158,6 +176,19 @@ func recoveryCmdPrechecks(cmd *cobra.Command) error {
 	return nil
 }
 
+// readConfirmation prints prompt and returns true if the user answers "yes".
+func readConfirmation(cmd *cobra.Command, prompt string) (bool, error) {
+	cmd.Print(prompt)
+
+	input, err := stdinReader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("couldn't read user input, error: %w", err)
+	}
+	input = strings.TrimSuffix(input, "\n")
+
+	return strings.ToLower(input) == "yes", nil
+}
+
 // yamlEditorGuide is a convenience wrapper around shared.TextEditor
 // that passes the current file contents prepended by the guide contents,
 // which are meant to assist the user. Returns the user-edited file contents.
@@ -200,27 +231,28 @@ func yamlEditorGuide(
 	return newContent, err
 }
 
-// On success, returns the recovery tarball path.
-func recoverK8sd() (string, error) {
+// On success, returns the recovery tarball path, the names of the members that were given the "spare" role, which
+// are considered lost, and the names of the remaining members, including the local one.
+func recoverK8sd() (string, []string, []string, error) {
 	m, err := microcluster.App(
 		microcluster.Args{
 			StateDir: rootCmdOpts.stateDir,
 		},
 	)
 	if err != nil {
-		return "", fmt.Errorf("could not initialize microcluster app, error: %w", err)
+		return "", nil, nil, fmt.Errorf("could not initialize microcluster app, error: %w", err)
 	}
 
 	// The following method parses cluster.yaml and filters out the entries
 	// that are not included in the trust store.
 	members, err := m.GetDqliteClusterMembers()
 	if err != nil {
-		return "", fmt.Errorf("could not retrieve K8sd cluster members, error: %w", err)
+		return "", nil, nil, fmt.Errorf("could not retrieve K8sd cluster members, error: %w", err)
 	}
 
 	oldMembersYaml, err := yaml.Marshal(members)
 	if err != nil {
-		return "", fmt.Errorf("could not serialize cluster members, error: %w", err)
+		return "", nil, nil, fmt.Errorf("could not serialize cluster members, error: %w", err)
 	}
 
 	clusterYamlPath := path.Join(m.FileSystem.DatabaseDir(), "cluster.yaml")
@@ -244,7 +276,7 @@ func recoverK8sd() (string, error) {
 			false,
 		)
 		if err != nil {
-			return "", fmt.Errorf("interactive text editor failed, error: %w", err)
+			return "", nil, nil, fmt.Errorf("interactive text editor failed, error: %w", err)
 		}
 
 		infoYamlPath := path.Join(m.FileSystem.DatabaseDir(), "info.yaml")
@@ -263,7 +295,7 @@ func recoverK8sd() (string, error) {
 			true,
 		)
 		if err != nil {
-			return "", fmt.Errorf("interactive text editor failed, error: %w", err)
+			return "", nil, nil, fmt.Errorf("interactive text editor failed, error: %w", err)
 		}
 
 		daemonYamlPath := path.Join(m.FileSystem.StateDir(), "daemon.yaml")
@@ -282,13 +314,13 @@ func recoverK8sd() (string, error) {
 			true,
 		)
 		if err != nil {
-			return "", fmt.Errorf("interactive text editor failed, error: %w", err)
+			return "", nil, nil, fmt.Errorf("interactive text editor failed, error: %w", err)
 		}
 	}
 
 	newMembers := []mctypes.DqliteMember{}
 	if err = yaml.Unmarshal(clusterYamlContent, &newMembers); err != nil {
-		return "", fmt.Errorf("couldn't parse cluster.yaml, error: %w", err)
+		return "", nil, nil, fmt.Errorf("couldn't parse cluster.yaml, error: %w", err)
 	}
 
 	// As of 2.0.2, the following microcluster method will:
@@ -311,8 +343,17 @@ func recoverK8sd() (string, error) {
 	//   in the state dir.
 	tarballPath, err := m.RecoverFromQuorumLoss(newMembers)
 	if err != nil {
-		return "", fmt.Errorf("k8sd recovery failed, error: %w", err)
+		return "", nil, nil, fmt.Errorf("k8sd recovery failed, error: %w", err)
+	}
+
+	var staleMembers, remainingMembers []string
+	for _, member := range newMembers {
+		if member.Role == "spare" {
+			staleMembers = append(staleMembers, member.Name)
+		} else {
+			remainingMembers = append(remainingMembers, member.Name)
+		}
 	}
 
-	return tarballPath, nil
+	return tarballPath, staleMembers, remainingMembers, nil
 }
@@ -0,0 +1,347 @@
+package k8sd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/setup"
+	"github.com/canonical/k8sd/pkg/log"
+	"github.com/canonical/k8sd/pkg/snap"
+	snaputil "github.com/canonical/k8sd/pkg/snap/util"
+	"github.com/canonical/k8sd/pkg/utils"
+	"github.com/canonical/k8sd/pkg/utils/control"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/spf13/cobra"
+)
+
+const etcdRecoveryMessage = `This member uses the managed etcd datastore, which has also lost its quorum.
+
+A new single-member etcd cluster can be forced from the data directory of this member:
+ - The etcd arguments are rewritten so that this member forms the new cluster
+ - All other members are removed from the etcd cluster
+ - Changes that were not replicated to this member are lost
+
+The etcd members of the other remaining control plane nodes are reset and rejoin the new cluster afterwards, once
+k8s is started on them.
+`
+
+const etcdRecoveryConfirmation = "Do you want to force a new etcd cluster from this member? (yes/no): "
+
+const staleMembersMessage = `The following cluster members were given the "spare" role and are considered lost:
+%s
+They can now be removed from k8sd (microcluster), etcd and Kubernetes.
+Copy the recovery tarball to the remaining cluster members, then start k8s on this member (sudo snap start k8s).
+`
+
+const staleMembersConfirmation = "Do you want to remove the lost cluster members once k8s is started? (yes/no): "
+
+const rejoinEtcdMembersMessage = `The etcd cluster now consists of this member only. The etcd members of the following remaining cluster members
+have to rejoin it, once k8s is started on them:
+%s
+Their etcd data directories are moved aside, and they are added to the new etcd cluster one after another.
+Worker nodes do not run etcd and are skipped.
+`
+
+const rejoinEtcdMembersConfirmation = "Do you want the etcd members to rejoin once k8s is started on them? (yes/no): "
+
+// etcdReadyTimeout is the maximum time to wait for etcd to start with --force-new-cluster.
+const etcdReadyTimeout = 2 * time.Minute
+
+// k8sdReadyTimeout is the maximum time to wait for k8s to be started on the local member, and to remove the lost
+// cluster members.
+const k8sdReadyTimeout = 30 * time.Minute
+
+// recoverEtcd forces a new etcd cluster from the data directory of the local member, if the node uses the managed
+// etcd datastore. recoverEtcd returns the name of the local etcd member, or an empty string if etcd was not recovered.
+func recoverEtcd(cmd *cobra.Command, snap snap.Snap) (string, error) {
+	log := log.FromContext(cmd.Context())
+
+	dataDir, err := snaputil.GetServiceArgument(snap, "etcd", "--data-dir")
+	if err != nil || dataDir == "" {
+		log.V(1).Info("Etcd is not configured on this member, skipping etcd recovery", "error", err)
+		return "", nil
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "member")); err != nil {
+		log.V(1).Info("Etcd data directory not found, skipping etcd recovery", "dataDir", dataDir, "error", err)
+		return "", nil
+	}
+
+	if clusterRecoverOpts.SkipEtcdRecovery {
+		cmd.Printf("Skipping the etcd recovery.\n\n")
+		return "", nil
+	}
+	cmd.Print(etcdRecoveryMessage)
+	cmd.Print("\n")
+	if !clusterRecoverOpts.NonInteractive {
+		confirmed, err := readConfirmation(cmd, etcdRecoveryConfirmation)
+		if err != nil {
+			return "", err
+		}
+		if !confirmed {
+			cmd.Printf("Skipping the etcd recovery.\n\n")
+			return "", nil
+		}
+		cmd.Print("\n")
+	}
+
+	tarballPath := filepath.Join(filepath.Dir(dataDir), fmt.Sprintf("%s_backup.%d.tar.gz", filepath.Base(dataDir), time.Now().Unix()))
+	if err := utils.CreateTarball(tarballPath, filepath.Dir(dataDir), filepath.Base(dataDir), nil); err != nil {
+		return "", fmt.Errorf("failed to back up etcd data directory, error: %w", err)
+	}
+	cmd.Printf("Etcd data directory backed up to %s\n", tarballPath)
+
+	name, clientURL, err := setup.EtcdNewClusterFromLocalMember(snap)
+	if err != nil {
+		return "", fmt.Errorf("failed to rewrite etcd arguments, error: %w", err)
+	}
+
+	removed, err := forceNewEtcdCluster(cmd.Context(), snap, name, clientURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to force a new etcd cluster, error: %w", err)
+	}
+	if len(removed) > 0 {
+		log.Info("Removed etcd members", "members", removed)
+	}
+	cmd.Printf("Etcd cluster changes applied. The etcd cluster now consists of %s only.\n\n", name)
+
+	return name, nil
+}
+
+// forceNewEtcdCluster runs etcd with --force-new-cluster until it is ready, so that the local member forms a new
+// cluster from its data directory. Any other member left in the cluster is removed.
+// forceNewEtcdCluster returns the names of the removed members.
+func forceNewEtcdCluster(ctx context.Context, snap snap.Snap, name string, clientURL string) ([]string, error) {
+	args, err := utils.ParseArgumentFile(filepath.Join(snap.ServiceArgumentsDir(), "etcd"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read etcd arguments: %w", err)
+	}
+	argv := make([]string, 0, len(args)+1)
+	for key, value := range args {
+		argv = append(argv, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(argv)
+	argv = append(argv, "--force-new-cluster=true")
+
+	// NOTE: etcd runs in the foreground of this command, so that --force-new-cluster is not persisted in the
+	// arguments of the etcd service.
+	var output bytes.Buffer
+	etcd := exec.Command(filepath.Join(snap.K8sBinDir(), "etcd"), argv...)
+	etcd.Stdout = &output
+	etcd.Stderr = &output
+	if err := etcd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start etcd: %w", err)
+	}
+	var waitErr error
+	exited := make(chan struct{})
+	go func() {
+		waitErr = etcd.Wait()
+		close(exited)
+	}()
+	defer func() {
+		select {
+		case <-exited:
+		default:
+			_ = etcd.Process.Signal(syscall.SIGTERM)
+			<-exited
+		}
+	}()
+
+	client, err := snap.EtcdClient([]string{clientURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client: %w", err)
+	}
+	defer client.Close()
+
+	readyCtx, cancel := context.WithTimeout(ctx, etcdReadyTimeout)
+	defer cancel()
+	if err := control.WaitUntilReady(readyCtx, func() (bool, error) {
+		select {
+		case <-exited:
+			return false, fmt.Errorf("etcd exited: %v: %s", waitErr, strings.TrimSpace(output.String()))
+		default:
+		}
+		callCtx, cancel := context.WithTimeout(readyCtx, 5*time.Second)
+		defer cancel()
+		_, err := client.MemberList(callCtx)
+		return err == nil, nil
+	}); err != nil {
+		return nil, fmt.Errorf("etcd did not become ready: %w", err)
+	}
+
+	removed, err := client.RemoveOtherMembers(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := etcd.Process.Signal(syscall.SIGTERM); err != nil {
+		return nil, fmt.Errorf("failed to stop etcd: %w", err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(30 * time.Second):
+		_ = etcd.Process.Kill()
+		<-exited
+	}
+	if waitErr != nil {
+		log.FromContext(ctx).V(1).Info("Etcd exited", "error", waitErr)
+	}
+
+	return removed, nil
+}
+
+// removeStaleMembers removes the lost cluster members from k8sd (microcluster), etcd and Kubernetes, once k8sd has
+// been started on the local member. In non-interactive mode, the commands to remove them are printed instead.
+func removeStaleMembers(cmd *cobra.Command, snap snap.Snap, staleMembers []string) error {
+	if len(staleMembers) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(staleMembers))
+	for _, member := range staleMembers {
+		lines = append(lines, fmt.Sprintf(" - %s", member))
+	}
+	cmd.Printf(staleMembersMessage, strings.Join(lines, "\n"))
+	cmd.Print("\n")
+
+	confirmed := false
+	if !clusterRecoverOpts.NonInteractive {
+		var err error
+		if confirmed, err = readConfirmation(cmd, staleMembersConfirmation); err != nil {
+			return err
+		}
+		cmd.Print("\n")
+	}
+	if !confirmed {
+		cmd.Printf("Once k8s is started, remove the lost cluster members with:\n")
+		for _, member := range staleMembers {
+			cmd.Printf("  sudo k8s remove-node %s --force\n", member)
+		}
+		return nil
+	}
+
+	client, err := snap.K8sdClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+
+	cmd.Printf("Waiting for k8s to be started on this member...\n")
+	ctx, cancel := context.WithTimeout(cmd.Context(), k8sdReadyTimeout)
+	defer cancel()
+	if err := control.WaitUntilReady(ctx, func() (bool, error) {
+		_, initialized, err := client.NodeStatus(ctx)
+		return err == nil && initialized, nil
+	}); err != nil {
+		return fmt.Errorf("k8sd did not become ready: %w", err)
+	}
+
+	for _, member := range staleMembers {
+		if err := client.RemoveNode(ctx, apiv2.RemoveNodeRequest{Name: member, Force: true, Timeout: 5 * time.Minute}); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", member, err)
+		}
+		cmd.Printf("Removed %s from the cluster.\n", member)
+	}
+
+	return nil
+}
+
+// rejoinEtcdMembers makes the etcd members of the remaining control plane nodes rejoin the new etcd cluster formed by
+// the local member, once k8s has been started on them. In non-interactive mode, the commands to rejoin them are
+// printed instead.
+func rejoinEtcdMembers(cmd *cobra.Command, snap snap.Snap, localMember string, remainingMembers []string) error {
+	if localMember == "" {
+		return nil
+	}
+	var members []string
+	for _, member := range remainingMembers {
+		if member != localMember {
+			members = append(members, member)
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(members))
+	for _, member := range members {
+		lines = append(lines, fmt.Sprintf(" - %s", member))
+	}
+	cmd.Printf(rejoinEtcdMembersMessage, strings.Join(lines, "\n"))
+	cmd.Print("\n")
+
+	confirmed := false
+	if !clusterRecoverOpts.NonInteractive {
+		var err error
+		if confirmed, err = readConfirmation(cmd, rejoinEtcdMembersConfirmation); err != nil {
+			return err
+		}
+		cmd.Print("\n")
+	}
+	if !confirmed {
+		cmd.Printf("Once k8s is started on them, rejoin the etcd members of the remaining control plane nodes with:\n")
+		for _, member := range members {
+			cmd.Printf("  sudo k8s x-etcd rejoin %s\n", member)
+		}
+		return nil
+	}
+
+	client, err := snap.K8sdClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+
+	cmd.Printf("Waiting for k8s to be started on this member...\n")
+	ctx, cancel := context.WithTimeout(cmd.Context(), k8sdReadyTimeout)
+	defer cancel()
+	if err := control.WaitUntilReady(ctx, func() (bool, error) {
+		_, initialized, err := client.NodeStatus(ctx)
+		return err == nil && initialized, nil
+	}); err != nil {
+		return fmt.Errorf("k8sd did not become ready: %w", err)
+	}
+
+	var failed []string
+	for _, member := range members {
+		cmd.Printf("Waiting for k8s to be started on %s...\n", member)
+		err := control.WaitUntilReady(ctx, func() (bool, error) {
+			_, err := client.EtcdMaintenance(ctx, k8sdapi.EtcdMaintenanceRequest{Action: k8sdapi.EtcdMaintenanceActionRejoin, Member: member})
+			// Error 503 means the node is not started yet
+			var statusErr api.StatusError
+			if errors.As(err, &statusErr) && statusErr.Status() == http.StatusServiceUnavailable {
+				return false, nil
+			}
+			return true, err
+		})
+		var statusErr api.StatusError
+		switch {
+		case err == nil:
+			cmd.Printf("The etcd member of %s rejoined the cluster.\n", member)
+		case errors.As(err, &statusErr) && statusErr.Status() == http.StatusBadRequest:
+			// NOTE: Worker nodes and nodes that already rejoined are rejected with a bad request.
+			cmd.Printf("Skipping %s: %v\n", member, err)
+		default:
+			cmd.PrintErrf("Failed to rejoin the etcd member of %s, error: %v\n", member, err)
+			failed = append(failed, member)
+		}
+	}
+
+	if len(failed) > 0 {
+		cmd.Printf("Rejoin the remaining etcd members once their nodes are started with:\n")
+		for _, member := range failed {
+			cmd.Printf("  sudo k8s x-etcd rejoin %s\n", member)
+		}
+		return fmt.Errorf("%d etcd member(s) did not rejoin the cluster", len(failed))
+	}
+	return nil
+}
@@ -100,3 +100,20 @@ func ExistsInEnviron(environ []string, key string) bool {
 	}
 	return false
 }
+
+// Username returns the name of the user that runs the command, for auditing purposes.
+// For commands that run with sudo, this is the user that invoked sudo.
+func Username(env ExecutionEnvironment) string {
+	for _, key := range []string{"SUDO_USER", "USER"} {
+		for _, envKV := range env.Environ {
+			parts := strings.SplitN(envKV, "=", 2)
+			if len(parts) == 2 && parts[0] == key && parts[1] != "" {
+				return parts[1]
+			}
+		}
+	}
+	if env.Getuid != nil {
+		return fmt.Sprintf("uid=%d", env.Getuid())
+	}
+	return ""
+}
@@ -84,3 +84,35 @@ func TestExistsInEnviron(t *testing.T) {
 		})
 	}
 }
+
+func TestUsername(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		env      cmdutil.ExecutionEnvironment
+		expected string
+	}{
+		{
+			name:     "Sudo",
+			env:      cmdutil.ExecutionEnvironment{Environ: []string{"USER=root", "SUDO_USER=alice"}},
+			expected: "alice",
+		},
+		{
+			name:     "User",
+			env:      cmdutil.ExecutionEnvironment{Environ: []string{"USER=bob", "SUDO_USER="}},
+			expected: "bob",
+		},
+		{
+			name:     "Uid",
+			env:      cmdutil.ExecutionEnvironment{Getuid: func() int { return 1000 }},
+			expected: "uid=1000",
+		},
+		{
+			name: "Unknown",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(cmdutil.Username(tc.env)).To(Equal(tc.expected))
+		})
+	}
+}
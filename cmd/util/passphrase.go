@@ -0,0 +1,30 @@
+package cmdutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadPassphrase reads a passphrase from a file, or from stdin if path is "-".
+// Trailing newlines are removed from the passphrase.
+func ReadPassphrase(env ExecutionEnvironment, path string) (string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if path == "-" {
+		if b, err = io.ReadAll(env.Stdin); err != nil {
+			return "", fmt.Errorf("failed to read passphrase from stdin: %w", err)
+		}
+	} else if b, err = os.ReadFile(path); err != nil {
+		return "", fmt.Errorf("failed to read passphrase file: %w", err)
+	}
+
+	passphrase := strings.TrimRight(string(b), "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase is empty")
+	}
+	return passphrase, nil
+}
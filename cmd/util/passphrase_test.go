@@ -0,0 +1,48 @@
+package cmdutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cmdutil "github.com/canonical/k8sd/cmd/util"
+	. "github.com/onsi/gomega"
+)
+
+func TestReadPassphrase(t *testing.T) {
+	t.Run("File", func(t *testing.T) {
+		g := NewWithT(t)
+
+		path := filepath.Join(t.TempDir(), "passphrase")
+		g.Expect(os.WriteFile(path, []byte("secret passphrase\n"), 0o600)).To(Succeed())
+
+		passphrase, err := cmdutil.ReadPassphrase(cmdutil.ExecutionEnvironment{}, path)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(passphrase).To(Equal("secret passphrase"))
+	})
+
+	t.Run("Stdin", func(t *testing.T) {
+		g := NewWithT(t)
+
+		env := cmdutil.ExecutionEnvironment{Stdin: strings.NewReader("secret\r\n")}
+		passphrase, err := cmdutil.ReadPassphrase(env, "-")
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(passphrase).To(Equal("secret"))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		g := NewWithT(t)
+
+		env := cmdutil.ExecutionEnvironment{Stdin: strings.NewReader("\n")}
+		_, err := cmdutil.ReadPassphrase(env, "-")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := cmdutil.ReadPassphrase(cmdutil.ExecutionEnvironment{}, filepath.Join(t.TempDir(), "missing"))
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,6 @@
+// Package api defines the request and response messages of the k8sd RPCs that extend the
+// public API in github.com/canonical/k8s-snap-api.
+//
+// Messages in this package embed their upstream counterpart, so that clients unaware of
+// the extra fields keep working.
+package api
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"time"
+)
+
+// CARotationRPC is the path for the CARotationStatus (GET) and RotateCA (POST) RPCs.
+const CARotationRPC = "k8sd/cluster/ca-rotation"
+
+// CARotationAction is a step of a cluster certificate authority rotation.
+type CARotationAction string
+
+const (
+	// CARotationActionStart generates new certificate authorities and distributes a trust bundle with both the old and
+	// the new certificate authorities to all nodes and kubeconfigs. Certificates are still signed by the old ones.
+	CARotationActionStart CARotationAction = "start"
+	// CARotationActionReissue switches to signing certificates with the new certificate authorities.
+	// Node certificates are re-issued automatically by the certificate rotation controller of each node.
+	CARotationActionReissue CARotationAction = "reissue"
+	// CARotationActionFinish removes the old certificate authorities from the trust bundle.
+	// Finish fails if the certificates of any node are not issued by the new certificate authorities yet.
+	CARotationActionFinish CARotationAction = "finish"
+	// CARotationActionAbort restores the old certificate authorities. Abort is only possible before the reissue phase.
+	CARotationActionAbort CARotationAction = "abort"
+)
+
+// RotateCARequest is the request message for the RotateCA RPC.
+// The response message is CARotationStatusResponse.
+type RotateCARequest struct {
+	// Action is the rotation step to perform.
+	Action CARotationAction `json:"action"`
+	// Force finishes the rotation without checking that all nodes use certificates of the new certificate authorities.
+	Force bool `json:"force,omitempty"`
+}
+
+// CARotationStatusResponse is the response message for the CARotationStatus and RotateCA RPCs.
+type CARotationStatusResponse struct {
+	// Phase is the current phase of the rotation ("trust" or "reissue"). Phase is empty if no rotation is in progress.
+	Phase string `json:"phase,omitempty" yaml:"phase,omitempty"`
+	// StartedAt is the time the rotation was started.
+	StartedAt time.Time `json:"started-at,omitempty" yaml:"started-at,omitempty"`
+	// UpdatedAt is the time the rotation entered the current phase.
+	UpdatedAt time.Time `json:"updated-at,omitempty" yaml:"updated-at,omitempty"`
+}
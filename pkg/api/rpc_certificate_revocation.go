@@ -0,0 +1,14 @@
+package api
+
+// CertificateRevocationListRPC is the path for the CertificateRevocationList (GET) RPC.
+const CertificateRevocationListRPC = "k8sd/certificates/crl"
+
+// KubernetesAuthorizationWebhookRPC is the path of the authorization webhook for kube-apiserver, which denies requests
+// with revoked client certificates.
+const KubernetesAuthorizationWebhookRPC = "kubernetes/auth/authorize"
+
+// CertificateRevocationListResponse is the response message for the CertificateRevocationList RPC.
+type CertificateRevocationListResponse struct {
+	// CRL is the PEM encoded certificate revocation list of the client certificate authority.
+	CRL string `json:"crl"`
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+)
+
+// ExportClusterBundleRPC is the path for the ExportClusterBundle RPC.
+const ExportClusterBundleRPC = "k8sd/cluster/export"
+
+// ExportClusterBundleRequest is the request message for the ExportClusterBundle RPC.
+type ExportClusterBundleRequest struct {
+	// Passphrase is used to encrypt and sign the bundle. The same passphrase is required to import the bundle.
+	Passphrase string `json:"passphrase"`
+}
+
+// ExportClusterBundleResponse is the response message for the ExportClusterBundle RPC.
+type ExportClusterBundleResponse struct {
+	// Bundle is the sealed cluster bundle.
+	// The bundle contains the cluster configuration, the certificate authorities and the authentication tokens of the cluster.
+	Bundle []byte `json:"bundle"`
+}
+
+// BootstrapClusterRequest is the request message for the BootstrapCluster RPC.
+type BootstrapClusterRequest struct {
+	apiv2.BootstrapClusterRequest `yaml:",inline"`
+
+	// Bundle is an optional sealed cluster bundle, as returned by ExportClusterBundle.
+	// If set, the cluster configuration, certificate authorities and authentication tokens are imported from the bundle.
+	// Node-specific settings (e.g. extra SANs and extra service arguments) are still taken from the bootstrap config.
+	Bundle []byte `json:"bundle,omitempty" yaml:"bundle,omitempty"`
+
+	// BundlePassphrase is the passphrase that was used to export the Bundle.
+	BundlePassphrase string `json:"bundle-passphrase,omitempty" yaml:"bundle-passphrase,omitempty"`
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+)
+
+// GetClusterConfigResponse is the response message for the GetClusterConfig RPC.
+type GetClusterConfigResponse struct {
+	apiv2.GetClusterConfigResponse `yaml:",inline"`
+
+	// Addons is the configuration of the operator-defined Helm add-ons.
+	Addons map[string]HelmAddonConfig `json:"addons,omitempty" yaml:"addons,omitempty"`
+
+	// ResourceVersion identifies the current revision of the cluster configuration.
+	// It can be passed as the ExpectedResourceVersion of a SetClusterConfig request to detect concurrent changes.
+	ResourceVersion int64 `json:"resource-version,omitempty" yaml:"resource-version,omitempty"`
+}
+
+// SetClusterConfigRequest is the request message for the SetClusterConfig RPC.
+type SetClusterConfigRequest struct {
+	apiv2.SetClusterConfigRequest `yaml:",inline"`
+
+	// Addons is the configuration of the operator-defined Helm add-ons.
+	// Add-ons that are not included are left unchanged.
+	Addons map[string]HelmAddonConfig `json:"addons,omitempty" yaml:"addons,omitempty"`
+
+	// DryRun validates the request and returns the resulting changes without applying them.
+	DryRun bool `json:"dry-run,omitempty" yaml:"dry-run,omitempty"`
+
+	// ExpectedResourceVersion is the resource version the change is based on, as returned by GetClusterConfig.
+	// If set, the request is rejected with a conflict error when the cluster configuration was changed in the meantime.
+	// Zero means that the change is applied regardless of concurrent changes.
+	ExpectedResourceVersion int64 `json:"expected-resource-version,omitempty" yaml:"expected-resource-version,omitempty"`
+}
+
+// SetClusterConfigResponse is the response message for the SetClusterConfig RPC.
+type SetClusterConfigResponse struct {
+	apiv2.SetClusterConfigResponse `yaml:",inline"`
+
+	// ResourceVersion is the resource version of the cluster configuration after the change.
+	// ResourceVersion is not set for dry-run requests.
+	ResourceVersion int64 `json:"resource-version,omitempty" yaml:"resource-version,omitempty"`
+
+	// Changes is the list of changed cluster configuration fields. Changes is only set for dry-run requests.
+	Changes []FieldChange `json:"changes,omitempty" yaml:"changes,omitempty"`
+	// Features is the list of features that would be reconciled. Features is only set for dry-run requests.
+	Features []FeatureDiff `json:"features,omitempty" yaml:"features,omitempty"`
+}
+
+// FieldChange is the change of a single configuration field or Helm value.
+type FieldChange struct {
+	// Key is the dotted path of the field, e.g. "load-balancer.cidrs".
+	Key string `json:"key" yaml:"key"`
+	// Old is the JSON encoded old value. Old is empty if the field is added.
+	Old string `json:"old,omitempty" yaml:"old,omitempty"`
+	// New is the JSON encoded new value. New is empty if the field is removed.
+	New string `json:"new,omitempty" yaml:"new,omitempty"`
+}
+
+// FeatureDiff describes the Helm charts that a feature would apply after a configuration change.
+type FeatureDiff struct {
+	// Name is the name of the feature.
+	Name string `json:"name" yaml:"name"`
+	// Charts is the list of Helm charts that would change.
+	Charts []ChartDiff `json:"charts,omitempty" yaml:"charts,omitempty"`
+	// Error is set if the changes of the feature could not be rendered.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ChartDiff describes the change of a Helm release.
+type ChartDiff struct {
+	// Name is the name of the Helm release.
+	Name string `json:"name" yaml:"name"`
+	// Namespace is the namespace of the Helm release.
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Action is one of "install", "upgrade" or "delete".
+	Action string `json:"action" yaml:"action"`
+	// Changes is the list of changed Helm values.
+	Changes []FieldChange `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
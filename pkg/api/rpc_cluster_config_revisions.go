@@ -0,0 +1,47 @@
+package api
+
+import (
+	"time"
+)
+
+// ClusterConfigHistoryRPC is the path for the ClusterConfigHistory RPC.
+const ClusterConfigHistoryRPC = "k8sd/cluster/config/history"
+
+// ClusterConfigRollbackRPC is the path for the ClusterConfigRollback RPC.
+const ClusterConfigRollbackRPC = "k8sd/cluster/config/rollback"
+
+// ClusterConfigHistoryRequest is the request message for the ClusterConfigHistory RPC.
+type ClusterConfigHistoryRequest struct {
+	// Limit is the maximum number of revisions to return. Zero means all retained revisions.
+	Limit int `json:"limit,omitempty"`
+}
+
+// ClusterConfigHistoryResponse is the response message for the ClusterConfigHistory RPC.
+type ClusterConfigHistoryResponse struct {
+	// Revisions is the list of cluster configuration revisions, newest first.
+	Revisions []ClusterConfigRevision `json:"revisions" yaml:"revisions"`
+}
+
+// ClusterConfigRevision describes an accepted revision of the cluster configuration.
+type ClusterConfigRevision struct {
+	// Revision is the revision number.
+	Revision int64 `json:"revision" yaml:"revision"`
+	// CreatedAt is the time the revision was stored.
+	CreatedAt time.Time `json:"created-at" yaml:"created-at"`
+	// RequestedBy identifies who requested the change.
+	RequestedBy string `json:"requested-by" yaml:"requested-by"`
+	// Reason is an optional description of the change.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// Changes is the list of changed configuration fields compared to the previous revision.
+	// Changes is empty for the oldest retained revision.
+	Changes []FieldChange `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
+
+// ClusterConfigRollbackRequest is the request message for the ClusterConfigRollback RPC.
+// The response message is SetClusterConfigResponse.
+type ClusterConfigRollbackRequest struct {
+	// Revision is the revision to roll back to.
+	Revision int64 `json:"revision"`
+	// DryRun validates the rollback and returns the resulting changes without applying them.
+	DryRun bool `json:"dry-run,omitempty"`
+}
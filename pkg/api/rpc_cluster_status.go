@@ -0,0 +1,13 @@
+package api
+
+import (
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+)
+
+// ClusterStatusResponse is the response message for the ClusterStatus RPC.
+type ClusterStatusResponse struct {
+	apiv2.ClusterStatusResponse `yaml:",inline"`
+
+	// Addons is the status of the operator-defined Helm add-ons.
+	Addons map[string]apiv2.FeatureStatus `json:"addons,omitempty" yaml:"addons,omitempty"`
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"time"
+)
+
+// EtcdMembersRPC is the path for the ListEtcdMembers (GET) and EtcdMaintenance (POST) RPCs.
+const EtcdMembersRPC = "k8sd/etcd/members"
+
+// EtcdMaintenanceAction is a maintenance operation on the managed etcd cluster.
+type EtcdMaintenanceAction string
+
+const (
+	// EtcdMaintenanceActionPromote promotes a learner to a voting member.
+	EtcdMaintenanceActionPromote EtcdMaintenanceAction = "promote"
+	// EtcdMaintenanceActionMoveLeader transfers the leadership to a voting member.
+	EtcdMaintenanceActionMoveLeader EtcdMaintenanceAction = "move-leader"
+	// EtcdMaintenanceActionDefragment defragments the database of a member, or of all members if no member is set.
+	EtcdMaintenanceActionDefragment EtcdMaintenanceAction = "defragment"
+	// EtcdMaintenanceActionCompact compacts the key-value history up to a revision, see EtcdMaintenanceRequest.
+	EtcdMaintenanceActionCompact EtcdMaintenanceAction = "compact"
+	// EtcdMaintenanceActionRejoin resets the etcd member of a control plane node and adds it to the cluster again.
+	// It is used on the remaining control plane nodes after "k8sd cluster-recover" forced a new etcd cluster.
+	EtcdMaintenanceActionRejoin EtcdMaintenanceAction = "rejoin"
+)
+
+// DefaultEtcdCompactRetain is the number of revisions that the compact action keeps, if no revision is requested.
+const DefaultEtcdCompactRetain = 10000
+
+// EtcdMember describes a member of the managed etcd cluster.
+type EtcdMember struct {
+	// ID is the member ID, in hex.
+	ID string `json:"id" yaml:"id"`
+	// Name is the member name, which is the name of the node. Name is empty for members that have not started yet.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// PeerURLs are the URLs the member uses to communicate with other members.
+	PeerURLs []string `json:"peer-urls,omitempty" yaml:"peer-urls,omitempty"`
+	// ClientURLs are the URLs the member serves clients on.
+	ClientURLs []string `json:"client-urls,omitempty" yaml:"client-urls,omitempty"`
+	// IsLearner is true for non-voting members.
+	IsLearner bool `json:"is-learner" yaml:"is-learner"`
+	// IsLeader is true for the current leader of the cluster.
+	IsLeader bool `json:"is-leader" yaml:"is-leader"`
+	// DBSize is the size of the backend database of the member, in bytes.
+	DBSize int64 `json:"db-size,omitempty" yaml:"db-size,omitempty"`
+	// DBSizeInUse is the size of the backend database that is in use, in bytes. The rest can be released by defragmenting.
+	DBSizeInUse int64 `json:"db-size-in-use,omitempty" yaml:"db-size-in-use,omitempty"`
+	// RaftIndex is the current raft index of the member.
+	RaftIndex uint64 `json:"raft-index,omitempty" yaml:"raft-index,omitempty"`
+	// RaftTerm is the current raft term of the member.
+	RaftTerm uint64 `json:"raft-term,omitempty" yaml:"raft-term,omitempty"`
+	// Version is the etcd version of the member.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Error is set if the status of the member could not be retrieved.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ListEtcdMembersResponse is the response message for the ListEtcdMembers RPC.
+type ListEtcdMembersResponse struct {
+	// Members are the members of the managed etcd cluster, ordered by name.
+	Members []EtcdMember `json:"members" yaml:"members"`
+}
+
+// EtcdMaintenanceRequest is the request message for the EtcdMaintenance RPC.
+type EtcdMaintenanceRequest struct {
+	// Action is the maintenance operation to perform.
+	Action EtcdMaintenanceAction `json:"action"`
+	// Member is the name or ID (in hex) of the member to operate on. For the rejoin action, Member is the node name.
+	Member string `json:"member,omitempty"`
+	// Revision is the revision to compact the key-value history to, for the compact action.
+	// Revision must be lower than the current revision.
+	Revision int64 `json:"revision,omitempty"`
+	// Retain is the number of most recent revisions that the compact action keeps, if no Revision is set.
+	// Retain defaults to DefaultEtcdCompactRetain.
+	Retain int64 `json:"retain,omitempty"`
+}
+
+// EtcdMaintenanceResponse is the response message for the EtcdMaintenance RPC.
+type EtcdMaintenanceResponse struct {
+	// Revision is the revision the key-value history was compacted to, for the compact action.
+	Revision int64 `json:"revision,omitempty" yaml:"revision,omitempty"`
+}
+
+// EtcdSnapshotsRPC is the path for the ListEtcdSnapshots (GET), TakeEtcdSnapshot (POST) and DeleteEtcdSnapshot (DELETE)
+// RPCs.
+const EtcdSnapshotsRPC = "k8sd/etcd/snapshots"
+
+// EtcdSnapshot describes a stored snapshot of the managed etcd cluster.
+type EtcdSnapshot struct {
+	// Name is the name of the snapshot, e.g. "etcd-snapshot-cp1-20261016T120000Z.db".
+	Name string `json:"name" yaml:"name"`
+	// Size is the size of the snapshot, in bytes.
+	Size int64 `json:"size" yaml:"size"`
+	// CreatedAt is the time the snapshot was taken.
+	CreatedAt time.Time `json:"created-at" yaml:"created-at"`
+	// Node is the control plane node that stores the snapshot in its local directory.
+	// Node is empty for snapshots stored in an S3 bucket.
+	Node string `json:"node,omitempty" yaml:"node,omitempty"`
+}
+
+// ListEtcdSnapshotsRequest is the request message for the ListEtcdSnapshots RPC.
+type ListEtcdSnapshotsRequest struct {
+	// Local only lists the snapshots in the local directory of the node. Without an S3 bucket, every control plane
+	// node stores the snapshots it took, and Local is set by the node that lists the snapshots of all of them.
+	Local bool `json:"local,omitempty"`
+}
+
+// ListEtcdSnapshotsResponse is the response message for the ListEtcdSnapshots RPC.
+type ListEtcdSnapshotsResponse struct {
+	// Location is where the snapshots are stored, either a local directory or an S3 URL ("s3://<bucket>/<prefix>").
+	Location string `json:"location" yaml:"location"`
+	// Snapshots are the stored snapshots, oldest first.
+	Snapshots []EtcdSnapshot `json:"snapshots" yaml:"snapshots"`
+	// Unavailable are the control plane nodes whose local snapshots could not be listed, with the error.
+	Unavailable map[string]string `json:"unavailable,omitempty" yaml:"unavailable,omitempty"`
+}
+
+// DeleteEtcdSnapshotRequest is the request message for the DeleteEtcdSnapshot RPC.
+// The snapshot is deleted from the store of the node. DeleteEtcdSnapshot is used by the node that applies the
+// retention of the snapshots to the local directories of the other control plane nodes.
+type DeleteEtcdSnapshotRequest struct {
+	// Name is the name of the snapshot.
+	Name string `json:"name"`
+}
+
+// TakeEtcdSnapshotRequest is the request message for the TakeEtcdSnapshot RPC.
+type TakeEtcdSnapshotRequest struct {
+	// Scheduled is set by the snapshot controller. Scheduled snapshots are only taken if they are configured, the node
+	// runs the etcd leader and the last snapshot is older than the configured interval.
+	Scheduled bool `json:"scheduled,omitempty"`
+}
+
+// TakeEtcdSnapshotResponse is the response message for the TakeEtcdSnapshot RPC.
+type TakeEtcdSnapshotResponse struct {
+	// Snapshot is the snapshot that was taken. Snapshot is nil if a scheduled snapshot was not due.
+	Snapshot *EtcdSnapshot `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+	// Pruned are the names of the snapshots that were deleted by the retention policy.
+	Pruned []string `json:"pruned,omitempty" yaml:"pruned,omitempty"`
+}
+
+// EtcdRestoreRPC is the path for the EtcdRestoreStatus (GET) and RestoreEtcd (POST) RPCs.
+const EtcdRestoreRPC = "k8sd/etcd/restore"
+
+// RestoreEtcdRequest is the request message for the RestoreEtcd RPC.
+type RestoreEtcdRequest struct {
+	// Snapshot is the name of a stored snapshot, as listed by the ListEtcdSnapshots RPC.
+	Snapshot string `json:"snapshot"`
+}
+
+// EtcdRestoreState is the state of a restore of the managed etcd cluster.
+type EtcdRestoreState string
+
+const (
+	// EtcdRestoreStateRunning means that the restore is in progress.
+	EtcdRestoreStateRunning EtcdRestoreState = "running"
+	// EtcdRestoreStateSucceeded means that the cluster was restored. Some control plane nodes may still have failed
+	// to rejoin it.
+	EtcdRestoreStateSucceeded EtcdRestoreState = "succeeded"
+	// EtcdRestoreStateFailed means that the restore was aborted.
+	EtcdRestoreStateFailed EtcdRestoreState = "failed"
+)
+
+// EtcdRestoreStatusResponse is the response message for the EtcdRestoreStatus and RestoreEtcd RPCs.
+// Restores run in the background on the node they were requested on. Only the status of the last restore since k8sd
+// started is kept.
+type EtcdRestoreStatusResponse struct {
+	// Snapshot is the name of the snapshot that is restored. Snapshot is empty if no restore was started.
+	Snapshot string `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+	// State is the state of the restore.
+	State EtcdRestoreState `json:"state,omitempty" yaml:"state,omitempty"`
+	// Step describes the current step of a running restore, or the last step of a finished one.
+	Step string `json:"step,omitempty" yaml:"step,omitempty"`
+	// Error is the reason a failed restore was aborted.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// StartedAt is the time the restore was started.
+	StartedAt time.Time `json:"started-at,omitempty" yaml:"started-at,omitempty"`
+	// Rejoined are the names of the control plane nodes that rejoined the restored etcd cluster.
+	Rejoined []string `json:"rejoined,omitempty" yaml:"rejoined,omitempty"`
+	// Failed are the control plane nodes that could not rejoin the restored etcd cluster, with the error.
+	Failed map[string]string `json:"failed,omitempty" yaml:"failed,omitempty"`
+}
+
+// EtcdLocalMemberRPC is the path for the EtcdLocalMember (POST) and EtcdMemberOperation (GET) RPCs.
+// They are used between control plane nodes while restoring the etcd cluster from a snapshot, or while an etcd member
+// rejoins the cluster.
+const EtcdLocalMemberRPC = "k8sd/etcd/local-member"
+
+// EtcdLocalMemberAction is an operation on the local etcd member of a node.
+type EtcdLocalMemberAction string
+
+const (
+	// EtcdLocalMemberActionReset stops the local etcd member and moves its data directory aside.
+	EtcdLocalMemberActionReset EtcdLocalMemberAction = "reset"
+	// EtcdLocalMemberActionRejoin starts the local etcd member with an empty data directory, to join an existing
+	// cluster. The member must have been added to the cluster before.
+	EtcdLocalMemberActionRejoin EtcdLocalMemberAction = "rejoin"
+)
+
+// EtcdLocalMemberRequest is the request message for the EtcdLocalMember RPC.
+type EtcdLocalMemberRequest struct {
+	// Action is the operation to perform.
+	Action EtcdLocalMemberAction `json:"action"`
+	// InitialCluster are the peer URLs of the started members of the cluster to rejoin, by name.
+	InitialCluster map[string]string `json:"initial-cluster,omitempty"`
+	// Coordinator is the name of the node that restores the cluster or rejoins the member, and OperationID identifies
+	// the restore or rejoin on that node. The node checks with the coordinator that the operation is in progress
+	// before it touches its etcd member, see EtcdMemberOperationRequest.
+	Coordinator string `json:"coordinator"`
+	OperationID string `json:"operation-id"`
+}
+
+// EtcdMemberOperationRequest is the request message for the EtcdMemberOperation RPC.
+type EtcdMemberOperationRequest struct {
+	// OperationID identifies the restore or rejoin on the coordinating node.
+	OperationID string `json:"operation-id"`
+	// Node is the name of the node whose etcd member is reset or rejoined.
+	Node string `json:"node"`
+}
+
+// EtcdMemberOperationResponse is the response message for the EtcdMemberOperation RPC.
+type EtcdMemberOperationResponse struct {
+	// InProgress is true if the operation is in progress and includes the etcd member of the node.
+	InProgress bool `json:"in-progress"`
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+)
+
+// FeatureStatusHistoryRPC is the path for the FeatureStatusHistory RPC.
+const FeatureStatusHistoryRPC = "k8sd/features/history"
+
+// FeatureStatusHistoryRequest is the request message for the FeatureStatusHistory RPC.
+type FeatureStatusHistoryRequest struct {
+	// Feature is the name of the feature, e.g. "network" or "helm-addon/<name>".
+	Feature string `json:"feature"`
+	// Limit is the maximum number of entries to return. Zero means all retained entries.
+	Limit int `json:"limit,omitempty"`
+}
+
+// FeatureStatusHistoryResponse is the response message for the FeatureStatusHistory RPC.
+type FeatureStatusHistoryResponse struct {
+	// Entries is the history of the feature, newest first.
+	Entries []FeatureStatusHistoryEntry `json:"entries" yaml:"entries"`
+}
+
+// FeatureStatusHistoryEntry records a reconcile attempt or a health change of a feature.
+type FeatureStatusHistoryEntry struct {
+	// Outcome is the outcome of the attempt, one of "succeeded", "failed", "waiting", "unhealthy" or "recovered".
+	Outcome string `json:"outcome" yaml:"outcome"`
+	// Status is the feature status that was reported.
+	Status apiv2.FeatureStatus `json:"status" yaml:"status"`
+	// Error is the error of a failed attempt, if any.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// ConfigHash is the hash of the cluster configuration that was applied.
+	ConfigHash string `json:"config-hash,omitempty" yaml:"config-hash,omitempty"`
+	// StartedAt is the time the attempt started.
+	StartedAt time.Time `json:"started-at" yaml:"started-at"`
+	// Duration is the duration of the attempt.
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	// Count is the number of consecutive attempts with the same outcome and status.
+	Count int `json:"count,omitempty" yaml:"count,omitempty"`
+	// LastSeenAt is the time the latest repeated attempt started, if the entry was repeated.
+	LastSeenAt time.Time `json:"last-seen-at,omitzero" yaml:"last-seen-at,omitempty"`
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+)
+
+// KubeConfigRequest is the request message for the KubeConfig RPC.
+type KubeConfigRequest struct {
+	apiv2.KubeConfigRequest `yaml:",inline"`
+
+	// User is the Kubernetes username of the kubeconfig. If User or TTL are set, k8sd issues a dedicated client
+	// certificate for the kubeconfig. Otherwise, the kubeconfig uses the admin client certificate of the cluster.
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// Groups are the Kubernetes groups of the User. Groups cannot be set without a User.
+	Groups []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+	// TTL is the duration until the client certificate of the kubeconfig expires (time-to-live).
+	TTL time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+// KubeConfigResponse is the response message for the KubeConfig RPC.
+type KubeConfigResponse struct {
+	apiv2.KubeConfigResponse `yaml:",inline"`
+
+	// Serial is the serial number (in hex) of the dedicated client certificate of the kubeconfig.
+	// Serial is empty for admin kubeconfigs.
+	Serial string `json:"serial,omitempty" yaml:"serial,omitempty"`
+	// ExpiresAt is the expiry of the dedicated client certificate of the kubeconfig.
+	ExpiresAt time.Time `json:"expires-at,omitempty" yaml:"expires-at,omitempty"`
+}
+
+// RevokeKubeConfigRPC is the path for the RevokeKubeConfig RPC.
+const RevokeKubeConfigRPC = "k8sd/kubeconfig/revoke"
+
+// RevokeKubeConfigRequest is the request message for the RevokeKubeConfig RPC.
+type RevokeKubeConfigRequest struct {
+	// Serial is the serial number (in hex) of the client certificate to revoke, as returned by the KubeConfig RPC.
+	Serial string `json:"serial"`
+}
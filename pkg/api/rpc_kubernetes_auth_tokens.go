@@ -0,0 +1,55 @@
+package api
+
+import (
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+)
+
+// KubernetesAuthTokensRPC is the path for the GenerateKubernetesAuthToken (POST), ListKubernetesAuthTokens (GET) and
+// RevokeKubernetesAuthToken (DELETE) RPCs.
+const KubernetesAuthTokensRPC = apiv2.GenerateKubernetesAuthTokenRPC
+
+// GenerateKubernetesAuthTokenRequest is the request message for the GenerateKubernetesAuthToken RPC.
+// The response message is apiv2.GenerateKubernetesAuthTokenResponse.
+// Every request creates a new token, which is only returned once.
+type GenerateKubernetesAuthTokenRequest struct {
+	apiv2.GenerateKubernetesAuthTokenRequest `yaml:",inline"`
+
+	// Description describes the purpose of the token.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// TTL is the duration until the token expires (time-to-live). Tokens without a TTL are valid until they are revoked.
+	TTL time.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+// RevokeKubernetesAuthTokenRequest is the request message for the RevokeKubernetesAuthToken RPC.
+type RevokeKubernetesAuthTokenRequest struct {
+	apiv2.RevokeKubernetesAuthTokenRequest `yaml:",inline"`
+
+	// ID is the ID of the token to revoke, as returned by ListKubernetesAuthTokens. ID is used if Token is empty.
+	ID int64 `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// KubernetesAuthToken describes a Kubernetes auth token, without the token itself.
+type KubernetesAuthToken struct {
+	// ID identifies the token, e.g. to revoke it.
+	ID int64 `json:"id" yaml:"id"`
+	// Username is the Kubernetes username of the token.
+	Username string `json:"username" yaml:"username"`
+	// Groups are the Kubernetes groups of the token.
+	Groups []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+	// Description describes the purpose of the token.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// CreatedAt is the time the token was created. CreatedAt is zero for tokens created by older versions.
+	CreatedAt time.Time `json:"created-at,omitzero" yaml:"created-at,omitempty"`
+	// ExpiresAt is the expiry of the token. Tokens without an expiry are valid until they are revoked.
+	ExpiresAt time.Time `json:"expires-at,omitzero" yaml:"expires-at,omitempty"`
+	// LastUsedAt is the last time the token was used, with a resolution of one minute.
+	LastUsedAt time.Time `json:"last-used-at,omitzero" yaml:"last-used-at,omitempty"`
+}
+
+// ListKubernetesAuthTokensResponse is the response message for the ListKubernetesAuthTokens RPC.
+type ListKubernetesAuthTokensResponse struct {
+	// Tokens are the Kubernetes auth tokens of the cluster, ordered by username.
+	Tokens []KubernetesAuthToken `json:"tokens" yaml:"tokens"`
+}
@@ -0,0 +1,24 @@
+package api
+
+// HelmAddonConfig is the configuration of an operator-defined Helm chart that is managed by k8sd.
+type HelmAddonConfig struct {
+	// Enabled controls whether the chart is installed on the cluster.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// ChartPath is the path to the chart on the control plane nodes.
+	// Relative paths are resolved against the manifests directory of the snap.
+	ChartPath *string `json:"chart-path,omitempty" yaml:"chart-path,omitempty"`
+	// Namespace is the namespace to install the chart into.
+	Namespace *string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Values are the Helm values used to configure the chart.
+	// Values are replaced as a whole when set in a SetClusterConfig request. An empty map clears the values.
+	Values map[string]any `json:"values" yaml:"values,omitempty"`
+	// Remove deletes the add-on from the cluster configuration when set in a SetClusterConfig request.
+	// The add-on must be disabled before it can be removed. The add-on is deleted once its Helm release is
+	// uninstalled, and its name cannot be reused until then.
+	Remove *bool `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+func (c HelmAddonConfig) GetEnabled() bool     { return getField(c.Enabled) }
+func (c HelmAddonConfig) GetChartPath() string { return getField(c.ChartPath) }
+func (c HelmAddonConfig) GetNamespace() string { return getField(c.Namespace) }
+func (c HelmAddonConfig) GetRemove() bool      { return getField(c.Remove) }
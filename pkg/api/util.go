@@ -0,0 +1,9 @@
+package api
+
+func getField[T any](val *T) T {
+	if val != nil {
+		return *val
+	}
+	var zero T
+	return zero
+}
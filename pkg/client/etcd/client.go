@@ -2,14 +2,22 @@ package etcd
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 
 	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
 type Client struct {
 	*clientv3.Client
+
+	// tlsConfig is used to create clients for the endpoints of individual members.
+	tlsConfig *tls.Config
 }
 
 func NewClient(pkiDir string, endpoints []string) (*Client, error) {
@@ -31,7 +39,8 @@ func NewClient(pkiDir string, endpoints []string) (*Client, error) {
 	}
 
 	return &Client{
-		client,
+		Client:    client,
+		tlsConfig: tlsConfig,
 	}, nil
 }
 
@@ -60,3 +69,262 @@ func (c *Client) RemoveNodeByName(ctx context.Context, name string) error {
 
 	return nil
 }
+
+// RemoveOtherMembers removes all members except the member with the given name from the etcd cluster.
+// RemoveOtherMembers returns the names of the removed members, or their IDs (in hex) if they have not started.
+func (c *Client) RemoveOtherMembers(ctx context.Context, name string) ([]string, error) {
+	resp, err := c.MemberList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list etcd members: %w", err)
+	}
+
+	var removed []string
+	for _, m := range resp.Members {
+		if m.Name == name {
+			continue
+		}
+		if _, err := c.MemberRemove(ctx, m.ID); err != nil {
+			return removed, fmt.Errorf("failed to remove etcd member %x: %w", m.ID, err)
+		}
+		if m.Name != "" {
+			removed = append(removed, m.Name)
+		} else {
+			removed = append(removed, strconv.FormatUint(m.ID, 16))
+		}
+	}
+	return removed, nil
+}
+
+// MemberStatus is the status of an etcd cluster member.
+type MemberStatus struct {
+	Member *etcdserverpb.Member
+	// Status is the status reported by the member. Status is nil if Err is set.
+	Status *clientv3.StatusResponse
+	// Err is the error when retrieving the status of the member, e.g. because it is not started.
+	Err error
+}
+
+// MemberStatuses returns the members of the etcd cluster, along with the status reported by each member.
+func (c *Client) MemberStatuses(ctx context.Context) ([]MemberStatus, error) {
+	resp, err := c.MemberList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list etcd members: %w", err)
+	}
+
+	statuses := make([]MemberStatus, 0, len(resp.Members))
+	for _, m := range resp.Members {
+		status := MemberStatus{Member: m}
+		if len(m.ClientURLs) == 0 {
+			status.Err = fmt.Errorf("member has not started yet")
+		} else if status.Status, status.Err = c.Status(ctx, m.ClientURLs[0]); status.Err != nil {
+			status.Err = fmt.Errorf("failed to get status: %w", status.Err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// FindMember returns the etcd member with the given name or ID (in hex).
+func (c *Client) FindMember(ctx context.Context, nameOrID string) (*etcdserverpb.Member, error) {
+	resp, err := c.MemberList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list etcd members: %w", err)
+	}
+	id, _ := strconv.ParseUint(nameOrID, 16, 64)
+	for _, m := range resp.Members {
+		if m.Name == nameOrID || (id != 0 && m.ID == id) {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("no etcd member found with name or ID: %s", nameOrID)
+}
+
+// PromoteMember promotes a learner to a voting member. The learner must have caught up with the leader.
+func (c *Client) PromoteMember(ctx context.Context, nameOrID string) error {
+	member, err := c.FindMember(ctx, nameOrID)
+	if err != nil {
+		return err
+	}
+	if !member.IsLearner {
+		return fmt.Errorf("etcd member %s is not a learner", nameOrID)
+	}
+	if _, err := c.MemberPromote(ctx, member.ID); err != nil {
+		return fmt.Errorf("failed to promote etcd member %s: %w", nameOrID, err)
+	}
+	return nil
+}
+
+// leader returns the current leader of the etcd cluster.
+func (c *Client) leader(ctx context.Context) (*etcdserverpb.Member, error) {
+	statuses, err := c.MemberStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.Err == nil && status.Status.Leader == status.Member.ID {
+			return status.Member, nil
+		}
+	}
+	return nil, fmt.Errorf("the etcd cluster has no leader")
+}
+
+// memberClient returns a client that is only connected to the given member. The caller must close the client.
+func (c *Client) memberClient(member *etcdserverpb.Member) (*clientv3.Client, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints: member.ClientURLs,
+		TLS:       c.tlsConfig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client for member %s: %w", member.Name, err)
+	}
+	return client, nil
+}
+
+// MoveLeader transfers the leadership of the etcd cluster to a voting member.
+// The request is sent to the current leader, as etcd only accepts leadership transfers from the leader.
+func (c *Client) MoveLeader(ctx context.Context, nameOrID string) error {
+	target, err := c.FindMember(ctx, nameOrID)
+	if err != nil {
+		return err
+	}
+	if target.IsLearner {
+		return fmt.Errorf("etcd member %s is a learner and cannot become the leader", nameOrID)
+	}
+
+	leader, err := c.leader(ctx)
+	if err != nil {
+		return err
+	}
+	if leader.ID == target.ID {
+		return nil
+	}
+
+	leaderClient, err := c.memberClient(leader)
+	if err != nil {
+		return err
+	}
+	defer leaderClient.Close()
+
+	if _, err := leaderClient.MoveLeader(ctx, target.ID); err != nil {
+		return fmt.Errorf("failed to move etcd leadership from %s to %s: %w", leader.Name, target.Name, err)
+	}
+	return nil
+}
+
+// Defragment defragments the backend database of an etcd member, to release the space of compacted revisions.
+// If nameOrID is empty, all started members are defragmented one after another.
+// NOTE: A member does not serve requests while it is being defragmented.
+func (c *Client) Defragment(ctx context.Context, nameOrID string) error {
+	var members []*etcdserverpb.Member
+	if nameOrID != "" {
+		member, err := c.FindMember(ctx, nameOrID)
+		if err != nil {
+			return err
+		}
+		members = append(members, member)
+	} else {
+		resp, err := c.MemberList(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list etcd members: %w", err)
+		}
+		members = resp.Members
+	}
+
+	for _, m := range members {
+		if len(m.ClientURLs) == 0 {
+			if nameOrID != "" {
+				return fmt.Errorf("etcd member %s has not started yet", nameOrID)
+			}
+			continue
+		}
+		if _, err := c.Client.Defragment(ctx, m.ClientURLs[0]); err != nil {
+			return fmt.Errorf("failed to defragment etcd member %s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// CompactRevisions compacts the key-value history of the etcd cluster up to the given revision, or up to the current
+// revision minus retain if revision is zero. CompactRevisions never compacts up to the current revision, since
+// watches of clients (e.g. the watch cache of kube-apiserver) that are behind the compacted revision fail and have to
+// list all resources again. CompactRevisions returns the revision the history was compacted to.
+func (c *Client) CompactRevisions(ctx context.Context, revision int64, retain int64) (int64, error) {
+	endpoints := c.Endpoints()
+	if len(endpoints) == 0 {
+		return 0, fmt.Errorf("etcd client has no endpoints")
+	}
+	resp, err := c.Status(ctx, endpoints[0])
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current etcd revision: %w", err)
+	}
+	revision, err = compactRevision(resp.Header.Revision, revision, retain)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.Compact(ctx, revision, clientv3.WithCompactPhysical()); err != nil {
+		return 0, fmt.Errorf("failed to compact etcd revisions: %w", err)
+	}
+	return revision, nil
+}
+
+// compactRevision returns the revision to compact the key-value history to, see CompactRevisions.
+func compactRevision(current int64, revision int64, retain int64) (int64, error) {
+	switch {
+	case revision < 0 || retain < 0:
+		return 0, fmt.Errorf("revision and retain cannot be negative")
+	case revision > 0 && retain > 0:
+		return 0, fmt.Errorf("revision and retain cannot be set at the same time")
+	case revision >= current:
+		return 0, fmt.Errorf("cannot compact up to revision %d, the current revision is %d", revision, current)
+	case revision > 0:
+		return revision, nil
+	case retain == 0:
+		return 0, fmt.Errorf("either revision or retain must be set")
+	case current-retain <= 1:
+		return 0, fmt.Errorf("the key-value history has fewer than %d revisions", retain)
+	}
+	return current - retain, nil
+}
+
+// SaveSnapshot saves a snapshot of the backend database of the etcd leader to file.
+// The snapshot is written to a temporary file first, so that file is only created for complete snapshots.
+// SaveSnapshot returns the name of the leader the snapshot was taken from.
+func (c *Client) SaveSnapshot(ctx context.Context, file string) (string, error) {
+	leader, err := c.leader(ctx)
+	if err != nil {
+		return "", err
+	}
+	leaderClient, err := c.memberClient(leader)
+	if err != nil {
+		return "", err
+	}
+	defer leaderClient.Close()
+
+	r, err := leaderClient.Snapshot(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to request snapshot from %s: %w", leader.Name, err)
+	}
+	defer r.Close()
+
+	partFile := file + ".part"
+	f, err := os.OpenFile(partFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", partFile, err)
+	}
+	defer os.Remove(partFile)
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to receive snapshot from %s: %w", leader.Name, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to sync %s: %w", partFile, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to close %s: %w", partFile, err)
+	}
+	if err := os.Rename(partFile, file); err != nil {
+		return "", fmt.Errorf("failed to rename %s: %w", partFile, err)
+	}
+	return leader.Name, nil
+}
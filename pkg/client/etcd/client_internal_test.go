@@ -0,0 +1,38 @@
+package etcd
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCompactRevision(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		revision  int64
+		retain    int64
+		expected  int64
+		expectErr bool
+	}{
+		{name: "Revision", revision: 400, expected: 400},
+		{name: "Retain", retain: 100, expected: 900},
+		{name: "CurrentRevision", revision: 1000, expectErr: true},
+		{name: "FutureRevision", revision: 1001, expectErr: true},
+		{name: "RetainAll", retain: 1000, expectErr: true},
+		{name: "NoRetain", expectErr: true},
+		{name: "Both", revision: 400, retain: 100, expectErr: true},
+		{name: "Negative", retain: -1, expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			revision, err := compactRevision(1000, tc.revision, tc.retain)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(revision).To(Equal(tc.expected))
+		})
+	}
+}
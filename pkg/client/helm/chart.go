@@ -9,6 +9,7 @@ type InstallableChart struct {
 	Namespace string
 
 	// ManifestPath is the path to the chart's manifest, typically relative to "$SNAP/k8s/manifests".
+	// Absolute paths are used as-is, e.g. for operator-defined add-ons.
 	// TODO(neoaggelos): this should be a *chart.Chart, and we should use the "embed" package to load it when building k8sd.
 	ManifestPath string
 }
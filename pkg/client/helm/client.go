@@ -47,6 +47,15 @@ func NewClient(manifestsBaseDir string,
 	}
 }
 
+// chartPath returns the path to the chart on disk.
+// Relative manifest paths are resolved against the manifests base directory.
+func (h *client) chartPath(c InstallableChart) string {
+	if filepath.IsAbs(c.ManifestPath) {
+		return c.ManifestPath
+	}
+	return filepath.Join(h.manifestsBaseDir, c.ManifestPath)
+}
+
 func (h *client) newActionConfiguration(ctx context.Context, namespace string) (*action.Configuration, error) {
 	actionConfig := new(action.Configuration)
 
@@ -120,7 +129,7 @@ func (h *client) Apply(ctx context.Context, c InstallableChart, desired State, v
 		install.Namespace = c.Namespace
 		install.CreateNamespace = true
 
-		chart, err := loader.Load(filepath.Join(h.manifestsBaseDir, c.ManifestPath))
+		chart, err := loader.Load(h.chartPath(c))
 		if err != nil {
 			return false, fmt.Errorf("failed to load manifest for %s: %w", c.Name, err)
 		}
@@ -130,7 +139,7 @@ func (h *client) Apply(ctx context.Context, c InstallableChart, desired State, v
 		}
 		return true, nil
 	case isInstalled && desired != StateDeleted:
-		chart, err := loader.Load(filepath.Join(h.manifestsBaseDir, c.ManifestPath))
+		chart, err := loader.Load(h.chartPath(c))
 		if err != nil {
 			return false, fmt.Errorf("failed to load manifest for %s: %w", c.Name, err)
 		}
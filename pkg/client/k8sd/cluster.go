@@ -6,10 +6,11 @@ import (
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
 
-func (c *k8sd) BootstrapCluster(ctx context.Context, request apiv2.BootstrapClusterRequest) (apiv2.BootstrapClusterResponse, error) {
+func (c *k8sd) BootstrapCluster(ctx context.Context, request k8sdapi.BootstrapClusterRequest) (apiv2.BootstrapClusterResponse, error) {
 	if err := c.app.Ready(ctx); err != nil {
 		return apiv2.BootstrapClusterResponse{}, fmt.Errorf("k8sd is not ready: %w", err)
 	}
@@ -72,3 +73,7 @@ func (c *k8sd) GetClusterMember(ctx context.Context, name string) (mctypes.Clust
 func (c *k8sd) RemoveClusterMember(ctx context.Context, name string, addr string, force bool) error {
 	return c.app.RemoveClusterMember(ctx, name, addr, force)
 }
+
+func (c *k8sd) ExportClusterBundle(ctx context.Context, request k8sdapi.ExportClusterBundleRequest) (k8sdapi.ExportClusterBundleResponse, error) {
+	return query(ctx, c, "POST", k8sdapi.ExportClusterBundleRPC, request, &k8sdapi.ExportClusterBundleResponse{})
+}
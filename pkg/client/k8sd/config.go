@@ -2,15 +2,55 @@ package k8sd
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/lxd/shared/api"
 )
 
+// ErrClusterConfigConflict is returned by SetClusterConfig if the cluster configuration was changed since the
+// expected resource version. Callers should retrieve the cluster configuration again and retry the change.
+var ErrClusterConfigConflict = errors.New("the cluster configuration was modified concurrently")
+
 func (c *k8sd) SetClusterConfig(ctx context.Context, request apiv2.SetClusterConfigRequest) error {
-	_, err := query(ctx, c, "PUT", apiv2.SetClusterConfigRPC, request, &apiv2.SetClusterConfigResponse{})
+	_, err := c.SetClusterConfigWithAddons(ctx, k8sdapi.SetClusterConfigRequest{SetClusterConfigRequest: request})
+	return err
+}
+
+func (c *k8sd) SetClusterConfigWithAddons(ctx context.Context, request k8sdapi.SetClusterConfigRequest) (k8sdapi.SetClusterConfigResponse, error) {
+	response, err := query(ctx, c, "PUT", apiv2.SetClusterConfigRPC, request, &k8sdapi.SetClusterConfigResponse{})
+	if err != nil {
+		return k8sdapi.SetClusterConfigResponse{}, setClusterConfigError(err)
+	}
+	return response, nil
+}
+
+// setClusterConfigError wraps the errors of SetClusterConfig requests with ErrClusterConfigConflict if the expected
+// resource version of the request is stale.
+func setClusterConfigError(err error) error {
+	// Error 409 means the expected resource version is stale
+	var statusErr api.StatusError
+	if errors.As(err, &statusErr) && statusErr.Status() == http.StatusConflict {
+		return fmt.Errorf("%w: %w", ErrClusterConfigConflict, err)
+	}
 	return err
 }
 
+func (c *k8sd) ClusterConfigHistory(ctx context.Context, request k8sdapi.ClusterConfigHistoryRequest) (k8sdapi.ClusterConfigHistoryResponse, error) {
+	return query(ctx, c, "GET", k8sdapi.ClusterConfigHistoryRPC, request, &k8sdapi.ClusterConfigHistoryResponse{})
+}
+
+func (c *k8sd) RollbackClusterConfig(ctx context.Context, request k8sdapi.ClusterConfigRollbackRequest) (k8sdapi.SetClusterConfigResponse, error) {
+	return query(ctx, c, "POST", k8sdapi.ClusterConfigRollbackRPC, request, &k8sdapi.SetClusterConfigResponse{})
+}
+
 func (c *k8sd) GetClusterConfig(ctx context.Context) (apiv2.GetClusterConfigResponse, error) {
 	return query(ctx, c, "GET", apiv2.GetClusterConfigRPC, nil, &apiv2.GetClusterConfigResponse{})
 }
+
+func (c *k8sd) GetClusterConfigWithAddons(ctx context.Context) (k8sdapi.GetClusterConfigResponse, error) {
+	return query(ctx, c, "GET", apiv2.GetClusterConfigRPC, nil, &k8sdapi.GetClusterConfigResponse{})
+}
@@ -0,0 +1,36 @@
+package k8sd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/canonical/lxd/shared/api"
+	. "github.com/onsi/gomega"
+)
+
+func TestSetClusterConfigError(t *testing.T) {
+	t.Run("Conflict", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// errors are wrapped by query
+		err := fmt.Errorf("failed after potential retry: %w", api.StatusErrorf(http.StatusConflict, "resource version is 8, but 7 was expected"))
+
+		err = setClusterConfigError(err)
+		g.Expect(err).To(MatchError(ErrClusterConfigConflict))
+		g.Expect(err).To(MatchError(ContainSubstring("resource version is 8, but 7 was expected")))
+	})
+
+	t.Run("OtherError", func(t *testing.T) {
+		g := NewWithT(t)
+
+		for _, err := range []error{
+			api.StatusErrorf(http.StatusBadRequest, "invalid configuration"),
+			errors.New("connection refused"),
+		} {
+			g.Expect(setClusterConfigError(err)).To(Equal(err))
+			g.Expect(errors.Is(setClusterConfigError(err), ErrClusterConfigConflict)).To(BeFalse())
+		}
+	})
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
 
 // ClusterClient implements methods for managing the cluster members.
 type ClusterClient interface {
 	// BootstrapCluster initializes a new cluster using the provided configuration.
-	BootstrapCluster(context.Context, apiv2.BootstrapClusterRequest) (apiv2.BootstrapClusterResponse, error)
+	// If the request contains a cluster bundle, the cluster configuration is imported from the bundle.
+	BootstrapCluster(context.Context, k8sdapi.BootstrapClusterRequest) (apiv2.BootstrapClusterResponse, error)
 	// GetJoinToken generates a token for nodes to join the cluster.
 	GetJoinToken(context.Context, apiv2.GetJoinTokenRequest) (apiv2.GetJoinTokenResponse, error)
 	// JoinCluster joins an existing cluster.
@@ -23,6 +25,8 @@ type ClusterClient interface {
 	GetClusterMember(ctx context.Context, name string) (mctypes.ClusterMember, error)
 	// RemoveClusterMember removes a cluster member by name.
 	RemoveClusterMember(ctx context.Context, name string, addr string, force bool) error
+	// ExportClusterBundle exports the cluster configuration, certificate authorities and tokens as a sealed bundle.
+	ExportClusterBundle(context.Context, k8sdapi.ExportClusterBundleRequest) (k8sdapi.ExportClusterBundleResponse, error)
 }
 
 // StatusClient implements methods for retrieving the current status of the cluster.
@@ -32,6 +36,11 @@ type StatusClient interface {
 	NodeStatus(ctx context.Context) (apiv2.NodeStatusResponse, bool, error)
 	// ClusterStatus retrieves the current status of the Kubernetes cluster.
 	ClusterStatus(ctx context.Context, waitReady bool) (apiv2.ClusterStatusResponse, error)
+	// ClusterStatusWithAddons retrieves the current status of the Kubernetes cluster, including the status of the
+	// operator-defined Helm add-ons.
+	ClusterStatusWithAddons(ctx context.Context, waitReady bool) (k8sdapi.ClusterStatusResponse, error)
+	// FeatureStatusHistory retrieves the history of reconcile attempts and health changes of a feature.
+	FeatureStatusHistory(context.Context, k8sdapi.FeatureStatusHistoryRequest) (k8sdapi.FeatureStatusHistoryResponse, error)
 }
 
 // ConfigClient implements methods to retrieve and manage the cluster configuration.
@@ -40,6 +49,17 @@ type ConfigClient interface {
 	GetClusterConfig(context.Context) (apiv2.GetClusterConfigResponse, error)
 	// SetClusterConfig updates the k8sd cluster configuration.
 	SetClusterConfig(context.Context, apiv2.SetClusterConfigRequest) error
+	// GetClusterConfigWithAddons retrieves the k8sd cluster configuration, including the operator-defined Helm
+	// add-ons and the resource version of the configuration.
+	GetClusterConfigWithAddons(context.Context) (k8sdapi.GetClusterConfigResponse, error)
+	// SetClusterConfigWithAddons updates the k8sd cluster configuration, including the operator-defined Helm add-ons.
+	// SetClusterConfigWithAddons returns an error wrapping ErrClusterConfigConflict if an ExpectedResourceVersion is
+	// set and the cluster configuration was changed since.
+	SetClusterConfigWithAddons(context.Context, k8sdapi.SetClusterConfigRequest) (k8sdapi.SetClusterConfigResponse, error)
+	// ClusterConfigHistory retrieves the revisions of the k8sd cluster configuration.
+	ClusterConfigHistory(context.Context, k8sdapi.ClusterConfigHistoryRequest) (k8sdapi.ClusterConfigHistoryResponse, error)
+	// RollbackClusterConfig restores the k8sd cluster configuration of a previous revision.
+	RollbackClusterConfig(context.Context, k8sdapi.ClusterConfigRollbackRequest) (k8sdapi.SetClusterConfigResponse, error)
 }
 
 // ClusterMaintenanceClient implements methods to manage the cluster.
@@ -52,12 +72,44 @@ type ClusterMaintenanceClient interface {
 	RefreshCertificatesUpdate(context.Context, apiv2.RefreshCertificatesUpdateRequest) (apiv2.RefreshCertificatesUpdateResponse, error)
 	// CertificatesStatus shows the status of the node's certificates.
 	CertificatesStatus(context.Context, apiv2.CertificatesStatusRequest) (apiv2.CertificatesStatusResponse, error)
+	// CARotationStatus retrieves the status of the cluster certificate authority rotation.
+	CARotationStatus(context.Context) (k8sdapi.CARotationStatusResponse, error)
+	// RotateCA performs a step of the cluster certificate authority rotation.
+	RotateCA(context.Context, k8sdapi.RotateCARequest) (k8sdapi.CARotationStatusResponse, error)
+	// ListEtcdMembers lists the members of the managed etcd cluster, along with their status.
+	ListEtcdMembers(context.Context) (k8sdapi.ListEtcdMembersResponse, error)
+	// EtcdMaintenance performs a maintenance operation on the managed etcd cluster.
+	EtcdMaintenance(context.Context, k8sdapi.EtcdMaintenanceRequest) (k8sdapi.EtcdMaintenanceResponse, error)
+	// ListEtcdSnapshots lists the stored snapshots of the managed etcd cluster.
+	ListEtcdSnapshots(context.Context, k8sdapi.ListEtcdSnapshotsRequest) (k8sdapi.ListEtcdSnapshotsResponse, error)
+	// TakeEtcdSnapshot takes a snapshot of the managed etcd cluster and stores it.
+	TakeEtcdSnapshot(context.Context, k8sdapi.TakeEtcdSnapshotRequest) (k8sdapi.TakeEtcdSnapshotResponse, error)
+	// DeleteEtcdSnapshot deletes a stored snapshot of the managed etcd cluster from the store of the node.
+	DeleteEtcdSnapshot(context.Context, k8sdapi.DeleteEtcdSnapshotRequest) error
+	// RestoreEtcd starts to rebuild the managed etcd cluster from a stored snapshot and to rejoin the other control
+	// plane nodes. The restore runs in the background, use EtcdRestoreStatus to follow it.
+	RestoreEtcd(context.Context, k8sdapi.RestoreEtcdRequest) (k8sdapi.EtcdRestoreStatusResponse, error)
+	// EtcdRestoreStatus retrieves the status of the last restore of the managed etcd cluster started on the node.
+	EtcdRestoreStatus(context.Context) (k8sdapi.EtcdRestoreStatusResponse, error)
+	// EtcdLocalMember resets or rejoins the local etcd member of the node. It is used while restoring a snapshot or
+	// rejoining a member.
+	EtcdLocalMember(context.Context, k8sdapi.EtcdLocalMemberRequest) error
+	// EtcdMemberOperation checks that a restore or rejoin started on the node is in progress.
+	EtcdMemberOperation(context.Context, k8sdapi.EtcdMemberOperationRequest) (k8sdapi.EtcdMemberOperationResponse, error)
 }
 
 // UserClient implements methods to enable accessing the cluster.
 type UserClient interface {
 	// KubeConfig retrieves a kubeconfig file that can be used to access the cluster.
-	KubeConfig(context.Context, apiv2.KubeConfigRequest) (apiv2.KubeConfigResponse, error)
+	KubeConfig(context.Context, k8sdapi.KubeConfigRequest) (k8sdapi.KubeConfigResponse, error)
+	// RevokeKubeConfig revokes the client certificate of a kubeconfig.
+	RevokeKubeConfig(context.Context, k8sdapi.RevokeKubeConfigRequest) error
+	// GenerateKubernetesAuthToken creates a token that can be used to authenticate with the Kubernetes API server.
+	GenerateKubernetesAuthToken(context.Context, k8sdapi.GenerateKubernetesAuthTokenRequest) (apiv2.GenerateKubernetesAuthTokenResponse, error)
+	// ListKubernetesAuthTokens lists the Kubernetes auth tokens of the cluster, without the tokens themselves.
+	ListKubernetesAuthTokens(context.Context) (k8sdapi.ListKubernetesAuthTokensResponse, error)
+	// RevokeKubernetesAuthToken revokes a Kubernetes auth token.
+	RevokeKubernetesAuthToken(context.Context, k8sdapi.RevokeKubernetesAuthTokenRequest) error
 }
 
 // ClusterAPIClient implements methods related to ClusterAPI endpoints.
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 )
 
 func (c *k8sd) RefreshCertificatesPlan(ctx context.Context, request apiv2.RefreshCertificatesPlanRequest) (apiv2.RefreshCertificatesPlanResponse, error) {
@@ -21,3 +22,49 @@ func (c *k8sd) RefreshCertificatesUpdate(ctx context.Context, request apiv2.Refr
 func (c *k8sd) CertificatesStatus(ctx context.Context, request apiv2.CertificatesStatusRequest) (apiv2.CertificatesStatusResponse, error) {
 	return query(ctx, c, "GET", apiv2.CertificatesStatusRPC, request, &apiv2.CertificatesStatusResponse{})
 }
+
+func (c *k8sd) CARotationStatus(ctx context.Context) (k8sdapi.CARotationStatusResponse, error) {
+	return query(ctx, c, "GET", k8sdapi.CARotationRPC, nil, &k8sdapi.CARotationStatusResponse{})
+}
+
+func (c *k8sd) RotateCA(ctx context.Context, request k8sdapi.RotateCARequest) (k8sdapi.CARotationStatusResponse, error) {
+	return query(ctx, c, "POST", k8sdapi.CARotationRPC, request, &k8sdapi.CARotationStatusResponse{})
+}
+
+func (c *k8sd) ListEtcdMembers(ctx context.Context) (k8sdapi.ListEtcdMembersResponse, error) {
+	return query(ctx, c, "GET", k8sdapi.EtcdMembersRPC, nil, &k8sdapi.ListEtcdMembersResponse{})
+}
+
+func (c *k8sd) EtcdMaintenance(ctx context.Context, request k8sdapi.EtcdMaintenanceRequest) (k8sdapi.EtcdMaintenanceResponse, error) {
+	return query(ctx, c, "POST", k8sdapi.EtcdMembersRPC, request, &k8sdapi.EtcdMaintenanceResponse{})
+}
+
+func (c *k8sd) ListEtcdSnapshots(ctx context.Context, request k8sdapi.ListEtcdSnapshotsRequest) (k8sdapi.ListEtcdSnapshotsResponse, error) {
+	return query(ctx, c, "GET", k8sdapi.EtcdSnapshotsRPC, request, &k8sdapi.ListEtcdSnapshotsResponse{})
+}
+
+func (c *k8sd) TakeEtcdSnapshot(ctx context.Context, request k8sdapi.TakeEtcdSnapshotRequest) (k8sdapi.TakeEtcdSnapshotResponse, error) {
+	return query(ctx, c, "POST", k8sdapi.EtcdSnapshotsRPC, request, &k8sdapi.TakeEtcdSnapshotResponse{})
+}
+
+func (c *k8sd) DeleteEtcdSnapshot(ctx context.Context, request k8sdapi.DeleteEtcdSnapshotRequest) error {
+	_, err := query(ctx, c, "DELETE", k8sdapi.EtcdSnapshotsRPC, request, &struct{}{})
+	return err
+}
+
+func (c *k8sd) RestoreEtcd(ctx context.Context, request k8sdapi.RestoreEtcdRequest) (k8sdapi.EtcdRestoreStatusResponse, error) {
+	return query(ctx, c, "POST", k8sdapi.EtcdRestoreRPC, request, &k8sdapi.EtcdRestoreStatusResponse{})
+}
+
+func (c *k8sd) EtcdRestoreStatus(ctx context.Context) (k8sdapi.EtcdRestoreStatusResponse, error) {
+	return query(ctx, c, "GET", k8sdapi.EtcdRestoreRPC, nil, &k8sdapi.EtcdRestoreStatusResponse{})
+}
+
+func (c *k8sd) EtcdLocalMember(ctx context.Context, request k8sdapi.EtcdLocalMemberRequest) error {
+	_, err := query(ctx, c, "POST", k8sdapi.EtcdLocalMemberRPC, request, &struct{}{})
+	return err
+}
+
+func (c *k8sd) EtcdMemberOperation(ctx context.Context, request k8sdapi.EtcdMemberOperationRequest) (k8sdapi.EtcdMemberOperationResponse, error) {
+	return query(ctx, c, "GET", k8sdapi.EtcdLocalMemberRPC, request, &k8sdapi.EtcdMemberOperationResponse{})
+}
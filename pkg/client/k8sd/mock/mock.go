@@ -4,6 +4,7 @@ import (
 	"context"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/canonical/k8sd/pkg/client/k8sd"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
@@ -11,7 +12,7 @@ import (
 // Mock is a mock implementation of k8sd.Client.
 type Mock struct {
 	// k8sd.ClusterClient
-	BootstrapClusterCalledWith apiv2.BootstrapClusterRequest
+	BootstrapClusterCalledWith k8sdapi.BootstrapClusterRequest
 	BootstrapClusterResponse   apiv2.BootstrapClusterResponse
 	BootstrapClusterErr        error
 	GetJoinTokenCalledWith     apiv2.GetJoinTokenRequest
@@ -31,6 +32,10 @@ type Mock struct {
 	RemoveClusterMemberForce   bool
 	RemoveClusterMemberErr     error
 
+	ExportClusterBundleCalledWith k8sdapi.ExportClusterBundleRequest
+	ExportClusterBundleResponse   k8sdapi.ExportClusterBundleResponse
+	ExportClusterBundleErr        error
+
 	// k8sd.StatusClient
 	NodeStatusResponse    apiv2.NodeStatusResponse
 	NodeStatusInitialized bool
@@ -38,12 +43,32 @@ type Mock struct {
 	ClusterStatusResponse apiv2.ClusterStatusResponse
 	ClusterStatusErr      error
 
+	ClusterStatusWithAddonsResponse k8sdapi.ClusterStatusResponse
+	ClusterStatusWithAddonsErr      error
+
+	FeatureStatusHistoryCalledWith k8sdapi.FeatureStatusHistoryRequest
+	FeatureStatusHistoryResponse   k8sdapi.FeatureStatusHistoryResponse
+	FeatureStatusHistoryErr        error
+
 	// k8sd.ConfigClient
 	GetClusterConfigResponse   apiv2.GetClusterConfigResponse
 	GetClusterConfigErr        error
 	SetClusterConfigCalledWith apiv2.SetClusterConfigRequest
 	SetClusterConfigErr        error
 
+	GetClusterConfigWithAddonsResponse   k8sdapi.GetClusterConfigResponse
+	GetClusterConfigWithAddonsErr        error
+	SetClusterConfigWithAddonsCalledWith k8sdapi.SetClusterConfigRequest
+	SetClusterConfigWithAddonsResponse   k8sdapi.SetClusterConfigResponse
+	SetClusterConfigWithAddonsErr        error
+
+	ClusterConfigHistoryCalledWith  k8sdapi.ClusterConfigHistoryRequest
+	ClusterConfigHistoryResponse    k8sdapi.ClusterConfigHistoryResponse
+	ClusterConfigHistoryErr         error
+	RollbackClusterConfigCalledWith k8sdapi.ClusterConfigRollbackRequest
+	RollbackClusterConfigResponse   k8sdapi.SetClusterConfigResponse
+	RollbackClusterConfigErr        error
+
 	// k8sd.ClusterMaintenanceClient
 	RefreshCertificatesPlanCalledWith   apiv2.RefreshCertificatesPlanRequest
 	RefreshCertificatesPlanResponse     apiv2.RefreshCertificatesPlanResponse
@@ -59,17 +84,57 @@ type Mock struct {
 	CertificatesStatusResponse   apiv2.CertificatesStatusResponse
 	CertificatesStatusErr        error
 
+	CARotationStatusResponse k8sdapi.CARotationStatusResponse
+	CARotationStatusErr      error
+	RotateCACalledWith       k8sdapi.RotateCARequest
+	RotateCAResponse         k8sdapi.CARotationStatusResponse
+	RotateCAErr              error
+
+	ListEtcdMembersResponse       k8sdapi.ListEtcdMembersResponse
+	ListEtcdMembersErr            error
+	EtcdMaintenanceCalledWith     k8sdapi.EtcdMaintenanceRequest
+	EtcdMaintenanceResponse       k8sdapi.EtcdMaintenanceResponse
+	EtcdMaintenanceErr            error
+	ListEtcdSnapshotsCalledWith   k8sdapi.ListEtcdSnapshotsRequest
+	ListEtcdSnapshotsResponse     k8sdapi.ListEtcdSnapshotsResponse
+	ListEtcdSnapshotsErr          error
+	TakeEtcdSnapshotCalledWith    k8sdapi.TakeEtcdSnapshotRequest
+	TakeEtcdSnapshotResponse      k8sdapi.TakeEtcdSnapshotResponse
+	TakeEtcdSnapshotErr           error
+	DeleteEtcdSnapshotCalledWith  k8sdapi.DeleteEtcdSnapshotRequest
+	DeleteEtcdSnapshotErr         error
+	RestoreEtcdCalledWith         k8sdapi.RestoreEtcdRequest
+	RestoreEtcdResponse           k8sdapi.EtcdRestoreStatusResponse
+	RestoreEtcdErr                error
+	EtcdRestoreStatusResponse     k8sdapi.EtcdRestoreStatusResponse
+	EtcdRestoreStatusErr          error
+	EtcdLocalMemberCalledWith     k8sdapi.EtcdLocalMemberRequest
+	EtcdLocalMemberErr            error
+	EtcdMemberOperationCalledWith k8sdapi.EtcdMemberOperationRequest
+	EtcdMemberOperationResponse   k8sdapi.EtcdMemberOperationResponse
+	EtcdMemberOperationErr        error
+
 	// k8sd.UserClient
-	KubeConfigCalledWith apiv2.KubeConfigRequest
-	KubeConfigResponse   apiv2.KubeConfigResponse
-	KubeConfigErr        error
+	KubeConfigCalledWith       k8sdapi.KubeConfigRequest
+	KubeConfigResponse         k8sdapi.KubeConfigResponse
+	KubeConfigErr              error
+	RevokeKubeConfigCalledWith k8sdapi.RevokeKubeConfigRequest
+	RevokeKubeConfigErr        error
+
+	GenerateKubernetesAuthTokenCalledWith k8sdapi.GenerateKubernetesAuthTokenRequest
+	GenerateKubernetesAuthTokenResponse   apiv2.GenerateKubernetesAuthTokenResponse
+	GenerateKubernetesAuthTokenErr        error
+	ListKubernetesAuthTokensResponse      k8sdapi.ListKubernetesAuthTokensResponse
+	ListKubernetesAuthTokensErr           error
+	RevokeKubernetesAuthTokenCalledWith   k8sdapi.RevokeKubernetesAuthTokenRequest
+	RevokeKubernetesAuthTokenErr          error
 
 	// k8sd.ClusterAPIClient
 	SetClusterAPIAuthTokenCalledWith apiv2.ClusterAPISetAuthTokenRequest
 	SetClusterAPIAuthTokenErr        error
 }
 
-func (m *Mock) BootstrapCluster(_ context.Context, request apiv2.BootstrapClusterRequest) (apiv2.BootstrapClusterResponse, error) {
+func (m *Mock) BootstrapCluster(_ context.Context, request k8sdapi.BootstrapClusterRequest) (apiv2.BootstrapClusterResponse, error) {
 	m.BootstrapClusterCalledWith = request
 	return m.BootstrapClusterResponse, m.BootstrapClusterErr
 }
@@ -97,11 +162,22 @@ func (m *Mock) ClusterStatus(_ context.Context, waitReady bool) (apiv2.ClusterSt
 	return m.ClusterStatusResponse, m.ClusterStatusErr
 }
 
+func (m *Mock) ClusterStatusWithAddons(_ context.Context, waitReady bool) (k8sdapi.ClusterStatusResponse, error) {
+	return m.ClusterStatusWithAddonsResponse, m.ClusterStatusWithAddonsErr
+}
+
+func (m *Mock) FeatureStatusHistory(_ context.Context, request k8sdapi.FeatureStatusHistoryRequest) (k8sdapi.FeatureStatusHistoryResponse, error) {
+	m.FeatureStatusHistoryCalledWith = request
+	return m.FeatureStatusHistoryResponse, m.FeatureStatusHistoryErr
+}
+
 func (m *Mock) RefreshCertificatesPlan(_ context.Context, request apiv2.RefreshCertificatesPlanRequest) (apiv2.RefreshCertificatesPlanResponse, error) {
+	m.RefreshCertificatesPlanCalledWith = request
 	return m.RefreshCertificatesPlanResponse, m.RefreshCertificatesPlanErr
 }
 
 func (m *Mock) RefreshCertificatesRun(_ context.Context, request apiv2.RefreshCertificatesRunRequest) (apiv2.RefreshCertificatesRunResponse, error) {
+	m.RefreshCertificatesRunCalledWith = request
 	return m.RefreshCertificatesRunResponse, m.RefreshCertificatesRunErr
 }
 
@@ -115,6 +191,58 @@ func (m *Mock) CertificatesStatus(_ context.Context, request apiv2.CertificatesS
 	return m.CertificatesStatusResponse, m.CertificatesStatusErr
 }
 
+func (m *Mock) CARotationStatus(_ context.Context) (k8sdapi.CARotationStatusResponse, error) {
+	return m.CARotationStatusResponse, m.CARotationStatusErr
+}
+
+func (m *Mock) RotateCA(_ context.Context, request k8sdapi.RotateCARequest) (k8sdapi.CARotationStatusResponse, error) {
+	m.RotateCACalledWith = request
+	return m.RotateCAResponse, m.RotateCAErr
+}
+
+func (m *Mock) ListEtcdMembers(_ context.Context) (k8sdapi.ListEtcdMembersResponse, error) {
+	return m.ListEtcdMembersResponse, m.ListEtcdMembersErr
+}
+
+func (m *Mock) EtcdMaintenance(_ context.Context, request k8sdapi.EtcdMaintenanceRequest) (k8sdapi.EtcdMaintenanceResponse, error) {
+	m.EtcdMaintenanceCalledWith = request
+	return m.EtcdMaintenanceResponse, m.EtcdMaintenanceErr
+}
+
+func (m *Mock) ListEtcdSnapshots(_ context.Context, request k8sdapi.ListEtcdSnapshotsRequest) (k8sdapi.ListEtcdSnapshotsResponse, error) {
+	m.ListEtcdSnapshotsCalledWith = request
+	return m.ListEtcdSnapshotsResponse, m.ListEtcdSnapshotsErr
+}
+
+func (m *Mock) TakeEtcdSnapshot(_ context.Context, request k8sdapi.TakeEtcdSnapshotRequest) (k8sdapi.TakeEtcdSnapshotResponse, error) {
+	m.TakeEtcdSnapshotCalledWith = request
+	return m.TakeEtcdSnapshotResponse, m.TakeEtcdSnapshotErr
+}
+
+func (m *Mock) DeleteEtcdSnapshot(_ context.Context, request k8sdapi.DeleteEtcdSnapshotRequest) error {
+	m.DeleteEtcdSnapshotCalledWith = request
+	return m.DeleteEtcdSnapshotErr
+}
+
+func (m *Mock) RestoreEtcd(_ context.Context, request k8sdapi.RestoreEtcdRequest) (k8sdapi.EtcdRestoreStatusResponse, error) {
+	m.RestoreEtcdCalledWith = request
+	return m.RestoreEtcdResponse, m.RestoreEtcdErr
+}
+
+func (m *Mock) EtcdRestoreStatus(_ context.Context) (k8sdapi.EtcdRestoreStatusResponse, error) {
+	return m.EtcdRestoreStatusResponse, m.EtcdRestoreStatusErr
+}
+
+func (m *Mock) EtcdLocalMember(_ context.Context, request k8sdapi.EtcdLocalMemberRequest) error {
+	m.EtcdLocalMemberCalledWith = request
+	return m.EtcdLocalMemberErr
+}
+
+func (m *Mock) EtcdMemberOperation(_ context.Context, request k8sdapi.EtcdMemberOperationRequest) (k8sdapi.EtcdMemberOperationResponse, error) {
+	m.EtcdMemberOperationCalledWith = request
+	return m.EtcdMemberOperationResponse, m.EtcdMemberOperationErr
+}
+
 func (m *Mock) GetClusterConfig(_ context.Context) (apiv2.GetClusterConfigResponse, error) {
 	return m.GetClusterConfigResponse, m.GetClusterConfigErr
 }
@@ -124,11 +252,49 @@ func (m *Mock) SetClusterConfig(_ context.Context, request apiv2.SetClusterConfi
 	return m.SetClusterConfigErr
 }
 
-func (m *Mock) KubeConfig(_ context.Context, request apiv2.KubeConfigRequest) (apiv2.KubeConfigResponse, error) {
+func (m *Mock) GetClusterConfigWithAddons(_ context.Context) (k8sdapi.GetClusterConfigResponse, error) {
+	return m.GetClusterConfigWithAddonsResponse, m.GetClusterConfigWithAddonsErr
+}
+
+func (m *Mock) SetClusterConfigWithAddons(_ context.Context, request k8sdapi.SetClusterConfigRequest) (k8sdapi.SetClusterConfigResponse, error) {
+	m.SetClusterConfigWithAddonsCalledWith = request
+	return m.SetClusterConfigWithAddonsResponse, m.SetClusterConfigWithAddonsErr
+}
+
+func (m *Mock) ClusterConfigHistory(_ context.Context, request k8sdapi.ClusterConfigHistoryRequest) (k8sdapi.ClusterConfigHistoryResponse, error) {
+	m.ClusterConfigHistoryCalledWith = request
+	return m.ClusterConfigHistoryResponse, m.ClusterConfigHistoryErr
+}
+
+func (m *Mock) RollbackClusterConfig(_ context.Context, request k8sdapi.ClusterConfigRollbackRequest) (k8sdapi.SetClusterConfigResponse, error) {
+	m.RollbackClusterConfigCalledWith = request
+	return m.RollbackClusterConfigResponse, m.RollbackClusterConfigErr
+}
+
+func (m *Mock) KubeConfig(_ context.Context, request k8sdapi.KubeConfigRequest) (k8sdapi.KubeConfigResponse, error) {
 	m.KubeConfigCalledWith = request
 	return m.KubeConfigResponse, m.KubeConfigErr
 }
 
+func (m *Mock) RevokeKubeConfig(_ context.Context, request k8sdapi.RevokeKubeConfigRequest) error {
+	m.RevokeKubeConfigCalledWith = request
+	return m.RevokeKubeConfigErr
+}
+
+func (m *Mock) GenerateKubernetesAuthToken(_ context.Context, request k8sdapi.GenerateKubernetesAuthTokenRequest) (apiv2.GenerateKubernetesAuthTokenResponse, error) {
+	m.GenerateKubernetesAuthTokenCalledWith = request
+	return m.GenerateKubernetesAuthTokenResponse, m.GenerateKubernetesAuthTokenErr
+}
+
+func (m *Mock) ListKubernetesAuthTokens(_ context.Context) (k8sdapi.ListKubernetesAuthTokensResponse, error) {
+	return m.ListKubernetesAuthTokensResponse, m.ListKubernetesAuthTokensErr
+}
+
+func (m *Mock) RevokeKubernetesAuthToken(_ context.Context, request k8sdapi.RevokeKubernetesAuthTokenRequest) error {
+	m.RevokeKubernetesAuthTokenCalledWith = request
+	return m.RevokeKubernetesAuthTokenErr
+}
+
 func (m *Mock) SetClusterAPIAuthToken(_ context.Context, request apiv2.ClusterAPISetAuthTokenRequest) error {
 	m.SetClusterAPIAuthTokenCalledWith = request
 	return m.SetClusterAPIAuthTokenErr
@@ -150,4 +316,9 @@ func (m *Mock) RemoveClusterMember(_ context.Context, name string, addr string,
 	return m.RemoveClusterMemberErr
 }
 
+func (m *Mock) ExportClusterBundle(_ context.Context, request k8sdapi.ExportClusterBundleRequest) (k8sdapi.ExportClusterBundleResponse, error) {
+	m.ExportClusterBundleCalledWith = request
+	return m.ExportClusterBundleResponse, m.ExportClusterBundleErr
+}
+
 var _ k8sd.Client = &Mock{}
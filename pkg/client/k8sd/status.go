@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/canonical/k8sd/pkg/utils/control"
 	"github.com/canonical/lxd/shared/api"
 )
@@ -28,16 +29,28 @@ func (c *k8sd) NodeStatus(ctx context.Context) (apiv2.NodeStatusResponse, bool,
 }
 
 func (c *k8sd) ClusterStatus(ctx context.Context, waitReady bool) (apiv2.ClusterStatusResponse, error) {
-	var response apiv2.ClusterStatusResponse
+	response, err := c.ClusterStatusWithAddons(ctx, waitReady)
+	if err != nil {
+		return apiv2.ClusterStatusResponse{}, err
+	}
+	return response.ClusterStatusResponse, nil
+}
+
+func (c *k8sd) ClusterStatusWithAddons(ctx context.Context, waitReady bool) (k8sdapi.ClusterStatusResponse, error) {
+	var response k8sdapi.ClusterStatusResponse
 	if err := control.WaitUntilReady(ctx, func() (bool, error) {
 		var err error
-		response, err = query(ctx, c, "GET", apiv2.ClusterStatusRPC, nil, &apiv2.ClusterStatusResponse{})
+		response, err = query(ctx, c, "GET", apiv2.ClusterStatusRPC, nil, &k8sdapi.ClusterStatusResponse{})
 		if err != nil {
 			return false, err
 		}
 		return !waitReady || response.ClusterStatus.Ready, nil
 	}); err != nil {
-		return apiv2.ClusterStatusResponse{}, err
+		return k8sdapi.ClusterStatusResponse{}, err
 	}
 	return response, nil
 }
+
+func (c *k8sd) FeatureStatusHistory(ctx context.Context, request k8sdapi.FeatureStatusHistoryRequest) (k8sdapi.FeatureStatusHistoryResponse, error) {
+	return query(ctx, c, "GET", k8sdapi.FeatureStatusHistoryRPC, request, &k8sdapi.FeatureStatusHistoryResponse{})
+}
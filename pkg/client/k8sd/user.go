@@ -4,8 +4,32 @@ import (
 	"context"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 )
 
-func (c *k8sd) KubeConfig(ctx context.Context, request apiv2.KubeConfigRequest) (apiv2.KubeConfigResponse, error) {
-	return query(ctx, c, "GET", apiv2.KubeConfigRPC, request, &apiv2.KubeConfigResponse{})
+func (c *k8sd) KubeConfig(ctx context.Context, request k8sdapi.KubeConfigRequest) (k8sdapi.KubeConfigResponse, error) {
+	// NOTE: A dedicated client certificate is issued (and recorded) for requests with a user, groups or TTL.
+	method := "GET"
+	if request.User != "" || len(request.Groups) > 0 || request.TTL != 0 {
+		method = "POST"
+	}
+	return query(ctx, c, method, apiv2.KubeConfigRPC, request, &k8sdapi.KubeConfigResponse{})
+}
+
+func (c *k8sd) RevokeKubeConfig(ctx context.Context, request k8sdapi.RevokeKubeConfigRequest) error {
+	_, err := query(ctx, c, "POST", k8sdapi.RevokeKubeConfigRPC, request, &struct{}{})
+	return err
+}
+
+func (c *k8sd) GenerateKubernetesAuthToken(ctx context.Context, request k8sdapi.GenerateKubernetesAuthTokenRequest) (apiv2.GenerateKubernetesAuthTokenResponse, error) {
+	return query(ctx, c, "POST", k8sdapi.KubernetesAuthTokensRPC, request, &apiv2.GenerateKubernetesAuthTokenResponse{})
+}
+
+func (c *k8sd) ListKubernetesAuthTokens(ctx context.Context) (k8sdapi.ListKubernetesAuthTokensResponse, error) {
+	return query(ctx, c, "GET", k8sdapi.KubernetesAuthTokensRPC, nil, &k8sdapi.ListKubernetesAuthTokensResponse{})
+}
+
+func (c *k8sd) RevokeKubernetesAuthToken(ctx context.Context, request k8sdapi.RevokeKubernetesAuthTokenRequest) error {
+	_, err := query(ctx, c, "DELETE", k8sdapi.KubernetesAuthTokensRPC, request, &apiv2.RevokeKubernetesAuthTokenResponse{})
+	return err
 }
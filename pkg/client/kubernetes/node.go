@@ -2,12 +2,14 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/canonical/k8sd/pkg/log"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	versionutil "k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/util/retry"
 )
@@ -39,6 +41,20 @@ func (c *Client) DeleteNode(ctx context.Context, nodeName string) error {
 	})
 }
 
+// AnnotateNode sets an annotation on a node.
+func (c *Client) AnnotateNode(ctx context.Context, nodeName string, key string, value string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"annotations": map[string]string{key: value}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+	if _, err := c.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to patch node: %w", err)
+	}
+	return nil
+}
+
 func (c *Client) WatchNode(ctx context.Context, name string, reconcile func(node *v1.Node) error) error {
 	log := log.FromContext(ctx).WithValues("name", name)
 	w, err := c.CoreV1().Nodes().Watch(ctx, metav1.SingleObject(metav1.ObjectMeta{Name: name}))
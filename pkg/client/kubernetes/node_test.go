@@ -166,6 +166,26 @@ func TestDeleteNode(t *testing.T) {
 	})
 }
 
+func TestAnnotateNode(t *testing.T) {
+	g := NewWithT(t)
+
+	clientset := fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-node",
+			Annotations: map[string]string{"existing": "value"},
+		},
+	})
+	client := &Client{Interface: clientset}
+
+	g.Expect(client.AnnotateNode(context.Background(), "test-node", "k8sd.io/test", "a,b")).To(Succeed())
+
+	node, err := client.GetNode(context.Background(), "test-node")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(node.Annotations).To(Equal(map[string]string{"existing": "value", "k8sd.io/test": "a,b"}))
+
+	g.Expect(client.AnnotateNode(context.Background(), "missing-node", "k8sd.io/test", "a")).To(MatchError(ContainSubstring("failed to patch node")))
+}
+
 func TestNodeVersions(t *testing.T) {
 	g := NewWithT(t)
 
@@ -0,0 +1,138 @@
+package s3
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Config is the configuration of a bucket in an S3-compatible object storage, e.g. AWS S3 or MinIO.
+type Config struct {
+	// Endpoint is the URL of the object storage, e.g. "https://s3.eu-west-1.amazonaws.com" or "http://10.0.0.10:9000".
+	Endpoint string
+	// Region is the region of the bucket. Defaults to "us-east-1", which is also accepted by MinIO.
+	Region string
+	// Bucket is the name of the bucket. Buckets are addressed path-style, e.g. "<endpoint>/<bucket>/<key>".
+	Bucket string
+	// AccessKeyID and SecretAccessKey are the credentials used to sign requests.
+	AccessKeyID     string
+	SecretAccessKey string
+	// CACert is an optional PEM-encoded certificate authority to verify the endpoint, e.g. for a self-hosted MinIO.
+	CACert string
+}
+
+// Object describes an object in the bucket.
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Client is a client for a bucket in an S3-compatible object storage.
+// Client only exposes the operations needed to store files in a bucket, and is backed by the MinIO Go SDK.
+type Client struct {
+	client *minio.Client
+	bucket string
+}
+
+// New creates a new Client.
+func New(cfg Config) (*Client, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("endpoint and bucket cannot be empty")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("access key ID and secret access key cannot be empty")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse endpoint %q: %w", cfg.Endpoint, err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("endpoint %q must be an http or https URL", cfg.Endpoint)
+	}
+	if strings.Trim(endpoint.Path, "/") != "" {
+		return nil, fmt.Errorf("endpoint %q cannot have a path", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	transport, err := minio.DefaultTransport(endpoint.Scheme == "https")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transport: %w", err)
+	}
+	if cfg.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, fmt.Errorf("failed to parse CA certificate")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure:       endpoint.Scheme == "https",
+		Region:       cfg.Region,
+		BucketLookup: minio.BucketLookupPath,
+		Transport:    transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return &Client{client: client, bucket: cfg.Bucket}, nil
+}
+
+// PutObject uploads an object of the given size.
+// Large objects are uploaded in parts, so snapshots are not limited to the 5GiB of a single request.
+func (c *Client) PutObject(ctx context.Context, key string, body io.Reader, size int64) error {
+	if _, err := c.client.PutObject(ctx, c.bucket, key, body, size, minio.PutObjectOptions{ContentType: "application/octet-stream"}); err != nil {
+		return fmt.Errorf("failed to put %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetObject downloads an object. The caller must close the returned reader.
+func (c *Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := c.client.GetObject(ctx, c.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	// NOTE: GetObject does not send a request until the object is read, so check that it exists first.
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	return object, nil
+}
+
+// DeleteObject deletes an object. Deleting an object that does not exist is not an error.
+func (c *Client) DeleteObject(ctx context.Context, key string) error {
+	if err := c.client.RemoveObject(ctx, c.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// ListObjects lists the objects with the given key prefix, in lexicographical order of the keys.
+func (c *Client) ListObjects(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	for object := range c.client.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", object.Err)
+		}
+		objects = append(objects, Object{Key: object.Key, Size: object.Size, LastModified: object.LastModified})
+	}
+	return objects, nil
+}
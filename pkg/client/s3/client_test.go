@@ -0,0 +1,147 @@
+package s3
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// fakeS3 is an in-memory bucket that implements the subset of the S3 API used by Client.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	// pageSize is the maximum number of keys returned by a list request.
+	pageSize int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<Error><Code>AccessDenied</Code><Message>Access Denied.</Message></Error>")
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/bucket/")
+	switch {
+	case (r.URL.Path == "/bucket" || key == "") && r.Method == http.MethodGet:
+		var keys []string
+		for k := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) && k > r.URL.Query().Get("continuation-token") {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		truncated := len(keys) > f.pageSize
+		if truncated {
+			keys = keys[:f.pageSize]
+		}
+		fmt.Fprint(w, "<ListBucketResult>")
+		for _, k := range keys {
+			fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size><LastModified>2026-10-16T10:00:00.000Z</LastModified></Contents>", k, len(f.objects[k]))
+		}
+		if truncated {
+			fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[len(keys)-1])
+		}
+		fmt.Fprint(w, "</ListBucketResult>")
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPut:
+		b, _ := io.ReadAll(r.Body)
+		if strconv.Itoa(len(b)) != r.Header.Get("Content-Length") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = b
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		b, exists := f.objects[key]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>")
+			return
+		}
+		w.Header().Set("Last-Modified", "Fri, 16 Oct 2026 10:00:00 GMT")
+		w.Header().Set("ETag", `"etag"`)
+		w.Write(b)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestClient(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	server := httptest.NewTLSServer(&fakeS3{objects: map[string][]byte{}, pageSize: 2})
+	defer server.Close()
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	client, err := New(Config{Endpoint: server.URL, Bucket: "bucket", AccessKeyID: "access", SecretAccessKey: "secret", CACert: caCert})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	for _, key := range []string{"snapshots/a", "snapshots/b", "snapshots/c", "other/d"} {
+		g.Expect(client.PutObject(ctx, key, strings.NewReader(key), int64(len(key)))).To(Succeed())
+	}
+
+	objects, err := client.ListObjects(ctx, "snapshots/")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(objects).To(Equal([]Object{
+		{Key: "snapshots/a", Size: 11, LastModified: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)},
+		{Key: "snapshots/b", Size: 11, LastModified: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)},
+		{Key: "snapshots/c", Size: 11, LastModified: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)},
+	}))
+
+	r, err := client.GetObject(ctx, "snapshots/b")
+	g.Expect(err).ToNot(HaveOccurred())
+	b, err := io.ReadAll(r)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(r.Close()).To(Succeed())
+	g.Expect(string(b)).To(Equal("snapshots/b"))
+
+	g.Expect(client.DeleteObject(ctx, "snapshots/b")).To(Succeed())
+	_, err = client.GetObject(ctx, "snapshots/b")
+	g.Expect(err).To(MatchError(ContainSubstring("The specified key does not exist.")))
+
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client, err := New(Config{Endpoint: server.URL, Bucket: "bucket", AccessKeyID: "invalid", SecretAccessKey: "secret", CACert: caCert})
+		g.Expect(err).ToNot(HaveOccurred())
+		_, err = client.ListObjects(ctx, "")
+		g.Expect(err).To(MatchError(ContainSubstring("Access Denied.")))
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "NoEndpoint", cfg: Config{Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"}},
+		{name: "NoBucket", cfg: Config{Endpoint: "http://minio:9000", AccessKeyID: "a", SecretAccessKey: "s"}},
+		{name: "NoCredentials", cfg: Config{Endpoint: "http://minio:9000", Bucket: "b"}},
+		{name: "EndpointWithPath", cfg: Config{Endpoint: "http://minio:9000/s3", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"}},
+		{name: "InvalidScheme", cfg: Config{Endpoint: "minio:9000", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"}},
+		{name: "InvalidCA", cfg: Config{Endpoint: "https://minio:9000", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s", CACert: "invalid"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			_, err := New(tc.cfg)
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/database"
+	"github.com/canonical/k8sd/pkg/k8sd/pki"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	snaputil "github.com/canonical/k8sd/pkg/snap/util"
+	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (e *Endpoints) getCARotation(s mctypes.State, r *http.Request) mctypes.Response {
+	var rotation types.CARotation
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		if rotation, err = database.GetCARotation(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get CA rotation: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("database transaction failed: %w", err))
+	}
+
+	return mctypes.SyncResponse(true, caRotationToAPI(rotation))
+}
+
+// postCARotation performs a step of the cluster certificate authority rotation.
+// The rotation state is stored in the database, and each step updates the certificate authorities of the cluster
+// configuration. Control plane nodes pick up the new certificate authorities through the control plane configuration
+// controller, and worker nodes through the k8sd-config configmap. Node certificates are re-issued by the certificate
+// rotation controller of each node once the new certificate authorities are used for signing, or by refreshing the
+// certificates of the node. The rotation can only be finished once all nodes report that their certificates are
+// issued by the new certificate authorities.
+func (e *Endpoints) postCARotation(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.RotateCARequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+
+	if req.Action == k8sdapi.CARotationActionFinish && !req.Force {
+		pending, err := e.pendingCARotationNodes(r.Context(), s)
+		if err != nil {
+			return mctypes.InternalError(fmt.Errorf("failed to check the certificate authorities of the nodes: %w", err))
+		}
+		if len(pending) > 0 {
+			return mctypes.BadRequest(fmt.Errorf("the certificates of nodes %v are not issued by the new certificate authorities yet, run \"k8s refresh-certs\" on these nodes or wait for the certificate rotation controller", pending))
+		}
+	}
+
+	requestedBy := requestIdentity(r)
+	reason := fmt.Sprintf("CA rotation: %s", req.Action)
+	now := time.Now().UTC()
+
+	var (
+		rotation types.CARotation
+		phaseErr error
+	)
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		if rotation, err = database.GetCARotation(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get CA rotation: %w", err)
+		}
+		config, err := database.GetClusterConfig(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster configuration: %w", err)
+		}
+
+		var certificates types.Certificates
+		switch req.Action {
+		case k8sdapi.CARotationActionStart:
+			if rotation.InProgress() {
+				phaseErr = fmt.Errorf("a CA rotation is already in progress (phase %q)", rotation.Phase)
+				return phaseErr
+			}
+			if config.Certificates.GetCAKey() == "" || config.Certificates.GetClientCAKey() == "" || config.Certificates.GetFrontProxyCAKey() == "" {
+				phaseErr = fmt.Errorf("cannot rotate externally managed certificate authorities")
+				return phaseErr
+			}
+			caCert, _, err := pkiutil.LoadCertificate(config.Certificates.GetCACert(), "")
+			if err != nil {
+				return fmt.Errorf("failed to parse kubernetes CA: %w", err)
+			}
+			if !pkiutil.IsSelfSigned(caCert) {
+				phaseErr = fmt.Errorf("cannot rotate an intermediate certificate authority issued by an external PKI")
+				return phaseErr
+			}
+
+			newCAs, err := generateCertificateAuthorities(s.Name(), config, now)
+			if err != nil {
+				return fmt.Errorf("failed to generate new certificate authorities: %w", err)
+			}
+			rotation = types.CARotation{
+				Phase:     types.CARotationPhaseTrust,
+				StartedAt: now,
+				UpdatedAt: now,
+				Old: types.Certificates{
+					CACert:           utils.Pointer(config.Certificates.GetCACert()),
+					CAKey:            utils.Pointer(config.Certificates.GetCAKey()),
+					ClientCACert:     utils.Pointer(config.Certificates.GetClientCACert()),
+					ClientCAKey:      utils.Pointer(config.Certificates.GetClientCAKey()),
+					FrontProxyCACert: utils.Pointer(config.Certificates.GetFrontProxyCACert()),
+					FrontProxyCAKey:  utils.Pointer(config.Certificates.GetFrontProxyCAKey()),
+				},
+				New: types.Certificates{
+					CACert:           utils.Pointer(newCAs.CACert),
+					CAKey:            utils.Pointer(newCAs.CAKey),
+					ClientCACert:     utils.Pointer(newCAs.ClientCACert),
+					ClientCAKey:      utils.Pointer(newCAs.ClientCAKey),
+					FrontProxyCACert: utils.Pointer(newCAs.FrontProxyCACert),
+					FrontProxyCAKey:  utils.Pointer(newCAs.FrontProxyCAKey),
+				},
+			}
+			certificates = rotation.ClusterCertificates()
+
+		case k8sdapi.CARotationActionReissue:
+			if rotation.Phase != types.CARotationPhaseTrust {
+				phaseErr = fmt.Errorf("the CA rotation must be in phase %q to re-issue certificates, but is in phase %q", types.CARotationPhaseTrust, rotation.Phase)
+				return phaseErr
+			}
+			rotation.Phase = types.CARotationPhaseReissue
+			rotation.UpdatedAt = now
+			// NOTE: The old certificate authorities are only trusted from now on and the rotation can no longer be
+			// aborted, so their private keys are not kept.
+			rotation.Old.CAKey, rotation.Old.ClientCAKey, rotation.Old.FrontProxyCAKey = nil, nil, nil
+			certificates = rotation.ClusterCertificates()
+
+			// re-issue the client certificates that are shared by all control plane nodes
+			clientCerts, err := generateClusterClientCertificates(s.Name(), config, rotation.New, now)
+			if err != nil {
+				return fmt.Errorf("failed to generate cluster client certificates: %w", err)
+			}
+			certificates.AdminClientCert = utils.Pointer(clientCerts.AdminClientCert)
+			certificates.AdminClientKey = utils.Pointer(clientCerts.AdminClientKey)
+			certificates.APIServerKubeletClientCert = utils.Pointer(clientCerts.APIServerKubeletClientCert)
+			certificates.APIServerKubeletClientKey = utils.Pointer(clientCerts.APIServerKubeletClientKey)
+
+		case k8sdapi.CARotationActionFinish:
+			if rotation.Phase != types.CARotationPhaseReissue {
+				phaseErr = fmt.Errorf("the CA rotation must be in phase %q to finish, but is in phase %q", types.CARotationPhaseReissue, rotation.Phase)
+				return phaseErr
+			}
+			certificates = rotation.New
+			rotation = types.CARotation{}
+
+		case k8sdapi.CARotationActionAbort:
+			if rotation.Phase != types.CARotationPhaseTrust {
+				phaseErr = fmt.Errorf("the CA rotation can only be aborted in phase %q, but is in phase %q", types.CARotationPhaseTrust, rotation.Phase)
+				return phaseErr
+			}
+			certificates = rotation.Old
+			rotation = types.CARotation{}
+
+		default:
+			phaseErr = fmt.Errorf("unknown CA rotation action %q", req.Action)
+			return phaseErr
+		}
+
+		if _, err := database.SetClusterCertificateAuthorities(ctx, tx, certificates, requestedBy, reason); err != nil {
+			return fmt.Errorf("failed to update cluster certificate authorities: %w", err)
+		}
+		if rotation.InProgress() {
+			if err := database.SetCARotation(ctx, tx, rotation); err != nil {
+				return fmt.Errorf("failed to update CA rotation: %w", err)
+			}
+		} else if err := database.DeleteCARotation(ctx, tx); err != nil {
+			return fmt.Errorf("failed to delete CA rotation: %w", err)
+		}
+		return nil
+	}); err != nil {
+		if phaseErr != nil {
+			return mctypes.BadRequest(phaseErr)
+		}
+		return mctypes.InternalError(fmt.Errorf("database transaction to rotate certificate authorities failed: %w", err))
+	}
+
+	// distribute the certificate authorities to the worker nodes
+	e.provider.NotifyUpdateNodeConfigController()
+
+	return mctypes.SyncResponse(true, caRotationToAPI(rotation))
+}
+
+// pendingCARotationNodes returns the names of the Kubernetes nodes that do not use certificates of the new certificate
+// authorities yet during the reissue phase of a CA rotation. Nodes report the certificate authorities that issued their
+// certificates in the snaputil.NodeCertificateAuthoritiesAnnotation. Nodes that did not report them are pending too.
+func (e *Endpoints) pendingCARotationNodes(ctx context.Context, s mctypes.State) ([]string, error) {
+	var rotation types.CARotation
+	if err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		if rotation, err = database.GetCARotation(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get CA rotation: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("database transaction failed: %w", err)
+	}
+	if rotation.Phase != types.CARotationPhaseReissue {
+		// the phase is checked when performing the rotation step
+		return nil, nil
+	}
+
+	issuers := make(map[string]struct{})
+	for _, bundle := range []string{rotation.New.GetCACert(), rotation.New.GetClientCACert(), rotation.New.GetFrontProxyCACert()} {
+		if bundle == "" {
+			continue
+		}
+		cas, err := pkiutil.ParseCertificates(bundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse new certificate authorities: %w", err)
+		}
+		for _, ca := range cas {
+			issuers[utils.CertFingerprint(ca)] = struct{}{}
+		}
+	}
+
+	client, err := e.provider.Snap().KubernetesClient("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var pending []string
+	for _, node := range nodes.Items {
+		value := node.Annotations[snaputil.NodeCertificateAuthoritiesAnnotation]
+		if value == "" {
+			pending = append(pending, node.Name)
+			continue
+		}
+		for _, fingerprint := range strings.Split(value, ",") {
+			if _, ok := issuers[fingerprint]; !ok {
+				pending = append(pending, node.Name)
+				break
+			}
+		}
+	}
+	slices.Sort(pending)
+	return pending, nil
+}
+
+// generateCertificateAuthorities generates new kubernetes, kubernetes client and front-proxy certificate authorities.
+func generateCertificateAuthorities(hostname string, config types.ClusterConfig, notBefore time.Time) (*pki.ControlPlanePKI, error) {
+	certificates := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
+		Hostname:          hostname,
+		NotBefore:         notBefore,
+		NotAfter:          notBefore.AddDate(20, 0, 0),
+		AllowSelfSignedCA: true,
+		KeyAlgorithm:      config.KeyAlgorithm(),
+	})
+	// NOTE: Keep the existing keys, so that only the certificate authorities and leaf certificates are generated.
+	certificates.ServiceAccountKey = config.Certificates.GetServiceAccountKey()
+	certificates.K8sdPublicKey = config.Certificates.GetK8sdPublicKey()
+	certificates.K8sdPrivateKey = config.Certificates.GetK8sdPrivateKey()
+
+	if err := certificates.CompleteCertificates(); err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
+
+// generateClusterClientCertificates generates the admin and apiserver-kubelet-client certificates of the cluster
+// configuration, signed by the specified certificate authorities.
+func generateClusterClientCertificates(hostname string, config types.ClusterConfig, cas types.Certificates, notBefore time.Time) (*pki.ControlPlanePKI, error) {
+	certificates := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
+		Hostname:          hostname,
+		NotBefore:         notBefore,
+		NotAfter:          notBefore.AddDate(20, 0, 0),
+		AllowSelfSignedCA: true,
+		KeyAlgorithm:      config.KeyAlgorithm(),
+		Profiles:          config.CertificateProfiles(),
+	})
+	certificates.CACert = cas.GetCACert()
+	certificates.CAKey = cas.GetCAKey()
+	certificates.ClientCACert = cas.GetClientCACert()
+	certificates.ClientCAKey = cas.GetClientCAKey()
+	certificates.FrontProxyCACert = cas.GetFrontProxyCACert()
+	certificates.FrontProxyCAKey = cas.GetFrontProxyCAKey()
+	certificates.ServiceAccountKey = config.Certificates.GetServiceAccountKey()
+	certificates.K8sdPublicKey = config.Certificates.GetK8sdPublicKey()
+	certificates.K8sdPrivateKey = config.Certificates.GetK8sdPrivateKey()
+
+	if err := certificates.CompleteCertificates(); err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
+
+func caRotationToAPI(rotation types.CARotation) *k8sdapi.CARotationStatusResponse {
+	return &k8sdapi.CARotationStatusResponse{
+		Phase:     string(rotation.Phase),
+		StartedAt: rotation.StartedAt,
+		UpdatedAt: rotation.UpdatedAt,
+	}
+}
@@ -7,15 +7,19 @@ import (
 	"crypto/sha256"
 	"crypto/x509/pkix"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"slices"
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	"github.com/canonical/k8sd/pkg/client/kubernetes"
 	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
 	"github.com/canonical/k8sd/pkg/k8sd/pki"
 	"github.com/canonical/k8sd/pkg/k8sd/setup"
@@ -245,6 +249,8 @@ func refreshCertsRunControlPlane(s mctypes.State, r *http.Request, snap snap.Sna
 		DNSSANs:                   extraNames,
 		AllowSelfSignedCA:         true,
 		IncludeMachineAddressSANs: true,
+		KeyAlgorithm:              clusterConfig.KeyAlgorithm(),
+		Profiles:                  clusterConfig.CertificateProfiles(),
 	})
 
 	certificates.CACert = clusterConfig.Certificates.GetCACert()
@@ -289,6 +295,9 @@ func refreshCertsRunControlPlane(s mctypes.State, r *http.Request, snap snap.Sna
 		if err := snaputil.RestartControlPlaneServices(ctx, snap, clusterConfig); err != nil {
 			return fmt.Errorf("failed to restart control plane services: %w", err)
 		}
+		if err := snaputil.ReportNodeCertificateAuthorities(ctx, snap); err != nil {
+			log.Error(err, "Failed to report the certificate authorities of the node certificates")
+		}
 		return nil
 	}
 	readyCh := nodeutil.StartAsyncRestart(log, restartFn)
@@ -392,18 +401,34 @@ func refreshCertsRunWorker(s mctypes.State, r *http.Request, snap snap.Snap) mct
 				csrIPs = ips
 			}
 
+			// NOTE: A previous request with the same seed may have timed out before its CSR was approved.
+			// The pending CSR is reused, so that no new CSR has to be approved.
+			keyPath := pendingCSRKeyPath(snap, csrObjectName)
+			if keyPEM, err := os.ReadFile(keyPath); err == nil {
+				if _, err := client.CertificatesV1().CertificateSigningRequests().Get(ctx, csrObjectName, metav1.GetOptions{}); err == nil {
+					log.Info("Reusing pending certificate signing request", "csr", csrObjectName)
+					return watchWorkerCSR(ctx, client, csrObjectName, string(keyPEM), keyPath, localCSRDef)
+				}
+			}
+
 			csrPEM, keyPEM, err := pkiutil.GenerateCSR(
 				pkix.Name{
 					CommonName:   localCSRDef.CommonName,
 					Organization: localCSRDef.Organization,
 				},
-				2048,
+				clusterConfig.KeyAlgorithm(),
 				csrHostnames,
 				csrIPs,
 			)
 			if err != nil {
 				return fmt.Errorf("failed to generate CSR for %s: %w", csrObjectName, err)
 			}
+			if err := os.MkdirAll(filepath.Dir(keyPath), 0o700); err != nil {
+				return fmt.Errorf("failed to create directory for pending CSR keys: %w", err)
+			}
+			if err := utils.WriteFile(keyPath, []byte(keyPEM), 0o600); err != nil {
+				return fmt.Errorf("failed to store key of CSR %s: %w", csrObjectName, err)
+			}
 
 			// Obtain the SHA256 sum of the CSR request.
 			hash := sha256.New()
@@ -437,18 +462,7 @@ func refreshCertsRunWorker(s mctypes.State, r *http.Request, snap snap.Snap) mct
 				return fmt.Errorf("failed to create CSR for %s: %w", csrObjectName, err)
 			}
 
-			if err := client.WatchCertificateSigningRequest(
-				ctx,
-				csrObjectName,
-				func(request *certv1.CertificateSigningRequest) (bool, error) {
-					return verifyCSRAndSetPKI(request, keyPEM, localCSRDef.targetCert, csrDef.targetKey)
-				},
-			); err != nil {
-				log.Error(err, "Failed to watch CSR %s: %v", csrObjectName, err)
-				return fmt.Errorf("certificate signing request %s failed: %w", csrObjectName, err)
-			}
-
-			return nil
+			return watchWorkerCSR(ctx, client, csrObjectName, keyPEM, keyPath, localCSRDef)
 		})
 	}
 
@@ -488,6 +502,9 @@ func refreshCertsRunWorker(s mctypes.State, r *http.Request, snap snap.Snap) mct
 				return fmt.Errorf("failed to restart kube-proxy: %w", err)
 			}
 		}
+		if err := snaputil.ReportNodeCertificateAuthorities(ctx, snap); err != nil {
+			log.Error(err, "Failed to report the certificate authorities of the node certificates")
+		}
 		return nil
 	}
 	readyCh := nodeutil.StartAsyncRestart(log, restartFn)
@@ -504,6 +521,31 @@ func refreshCertsRunWorker(s mctypes.State, r *http.Request, snap snap.Snap) mct
 	})
 }
 
+// pendingCSRKeyPath is the path of the private key of a certificate signing request that was not approved yet.
+func pendingCSRKeyPath(snap snap.Snap, csrObjectName string) string {
+	return filepath.Join(snap.KubernetesPKIDir(), "pending-csrs", csrObjectName+".key")
+}
+
+// watchWorkerCSR waits for a certificate signing request to be approved and sets the worker PKI fields of its
+// definition. The stored private key of the request is removed once the certificate is issued.
+func watchWorkerCSR(ctx context.Context, client *kubernetes.Client, csrObjectName string, keyPEM string, keyPath string, csrDef csrDefinition) error {
+	if err := client.WatchCertificateSigningRequest(
+		ctx,
+		csrObjectName,
+		func(request *certv1.CertificateSigningRequest) (bool, error) {
+			return verifyCSRAndSetPKI(request, keyPEM, csrDef.targetCert, csrDef.targetKey)
+		},
+	); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to watch CSR", "csr", csrObjectName)
+		return fmt.Errorf("certificate signing request %s failed: %w", csrObjectName, err)
+	}
+
+	if err := os.Remove(keyPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.FromContext(ctx).Error(err, "Failed to remove key of issued CSR", "csr", csrObjectName)
+	}
+	return nil
+}
+
 // toCertificateNames converts a slice of strings to a slice of CertificateName.
 func toCertificateNames(certNames []string) []apiv2.CertificateName {
 	certNamesSlice := make([]apiv2.CertificateName, len(certNames))
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/database"
+	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// credentialIDExtra is the user extra of kube-apiserver that identifies the credential used to authenticate a request.
+// For client certificates, the value is "X509SHA256=<fingerprint>".
+const credentialIDExtra = "authentication.kubernetes.io/credential-id"
+
+// getCertificateRevocationList returns the revocation list of the client certificate authority.
+func (e *Endpoints) getCertificateRevocationList(s mctypes.State, r *http.Request) mctypes.Response {
+	cfg, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to get cluster config: %w", err))
+	}
+	caCert, caKey, err := pkiutil.LoadCertificate(cfg.Certificates.GetClientCACert(), cfg.Certificates.GetClientCAKey())
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to load client CA: %w", err))
+	}
+	if caKey == nil {
+		return mctypes.BadRequest(fmt.Errorf("the client CA is managed externally, revocation lists must be published by the external PKI"))
+	}
+	// NOTE: CAs generated by older versions of k8sd do not have the CRLSign key usage.
+	if caCert.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		return mctypes.BadRequest(fmt.Errorf("the client CA is not allowed to sign revocation lists, rotate the certificate authorities with 'k8s rotate-ca' to enable revocation lists"))
+	}
+
+	var revoked []types.IssuedCertificate
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		revoked, err = database.ListRevokedCertificates(ctx, tx)
+		return err
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to list revoked certificates: %w", err))
+	}
+
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, certificate := range revoked {
+		// NOTE: kubelet serving certificates are signed by the cluster CA, not the client CA.
+		if certificate.Name == "kubelet" {
+			continue
+		}
+		serial, ok := new(big.Int).SetString(certificate.Serial, 16)
+		if !ok {
+			return mctypes.InternalError(fmt.Errorf("invalid serial number %q of revoked certificate", certificate.Serial))
+		}
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: serial, RevocationTime: certificate.RevokedAt})
+	}
+
+	now := time.Now()
+	crl, err := pkiutil.CreateRevocationList(caCert, caKey, entries, now, now.Add(24*time.Hour))
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to create revocation list: %w", err))
+	}
+	return mctypes.SyncResponse(true, k8sdapi.CertificateRevocationListResponse{CRL: crl})
+}
+
+// postKubernetesAuthorizationWebhook is used by kube-apiserver to handle SubjectAccessReview objects.
+// The webhook denies requests authenticated with revoked certificates, and has no opinion on all other requests.
+// Note that we do not use the normal types.SyncResponse here, because it breaks the response format that kube-apiserver expects.
+func (e *Endpoints) postKubernetesAuthorizationWebhook(s mctypes.State, r *http.Request) mctypes.Response {
+	review := authorizationv1.SubjectAccessReview{}
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		review.Status.EvaluationError = fmt.Errorf("failed to parse SubjectAccessReview: %w", err).Error()
+		return utils.JSONResponse(http.StatusBadRequest, review)
+	}
+	review.APIVersion = "authorization.k8s.io/v1"
+	review.Kind = "SubjectAccessReview"
+	review.Status = authorizationv1.SubjectAccessReviewStatus{}
+
+	// NOTE: Only check credentials that k8sd may have issued, to avoid a database query for every other request.
+	var fingerprints []string
+	for _, credentialID := range review.Spec.Extra[credentialIDExtra] {
+		if fingerprint, ok := strings.CutPrefix(credentialID, "X509SHA256="); ok {
+			fingerprints = append(fingerprints, strings.ToLower(fingerprint))
+		}
+	}
+	isNode := strings.HasPrefix(review.Spec.User, "system:node:")
+	if len(fingerprints) == 0 && !isNode {
+		return utils.JSONResponse(http.StatusOK, review)
+	}
+
+	var revoked bool
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		for _, fingerprint := range fingerprints {
+			if isRevoked, err := database.IsCertificateRevoked(ctx, tx, fingerprint); err != nil {
+				return err
+			} else if isRevoked {
+				revoked = true
+				return nil
+			}
+		}
+		// NOTE: Node credentials are denied by name, in case kube-apiserver does not report the credential ID, or the
+		// certificate was renewed without being recorded.
+		if isNode {
+			var err error
+			revoked, err = database.IsSubjectRevoked(ctx, tx, review.Spec.User)
+			return err
+		}
+		return nil
+	}); err != nil {
+		review.Status.EvaluationError = fmt.Sprintf("failed to check revoked certificates: %v", err)
+		return utils.JSONResponse(http.StatusOK, review)
+	}
+
+	if revoked {
+		review.Status.Denied = true
+		review.Status.Reason = "the client certificate has been revoked"
+	}
+	return utils.JSONResponse(http.StatusOK, review)
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"fmt"
 	"net/http"
-	"path/filepath"
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
@@ -13,37 +12,6 @@ import (
 	snaputil "github.com/canonical/k8sd/pkg/snap/util"
 	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
-	"k8s.io/client-go/tools/clientcmd"
-)
-
-var (
-	controlPlaneCertificateNames = []string{
-		"apiserver",
-		"apiserver-kubelet-client",
-		"front-proxy-client",
-		"kubelet",
-	}
-
-	controlPlaneKubeconfigs = []string{
-		"admin.conf",
-		"controller.conf",
-		"kubelet.conf",
-		"proxy.conf",
-		"scheduler.conf",
-	}
-
-	dataStoreCertificateNames = []string{
-		"client",
-	}
-
-	workerCertificateNames = []string{
-		"kubelet",
-	}
-
-	workerKubeconfigs = []string{
-		"kubelet.conf",
-		"proxy.conf",
-	}
 )
 
 func (e *Endpoints) getCertificatesStatus(s mctypes.State, r *http.Request) mctypes.Response {
@@ -71,12 +39,12 @@ func getCertsStatusControlPlane(s mctypes.State, r *http.Request, snap snap.Snap
 		return mctypes.InternalError(fmt.Errorf("failed to read certificates authorities: %w", err))
 	}
 
-	nodeCerts, err := loadCertificateStatusesFromDir(snap.KubernetesPKIDir(), controlPlaneCertificateNames)
+	nodeCerts, err := loadCertificateStatusesFromDir(snap.KubernetesPKIDir(), snaputil.ControlPlaneCertificateNames)
 	if err != nil {
 		return mctypes.InternalError(fmt.Errorf("failed to read node certificates: %w", err))
 	}
 
-	kubeConfigCerts, err := readKubeconfigCertificates(snap.KubernetesConfigDir(), controlPlaneKubeconfigs)
+	kubeConfigCerts, err := readKubeconfigCertificates(snap.KubernetesConfigDir(), snaputil.ControlPlaneKubeconfigs)
 	if err != nil {
 		return mctypes.InternalError(fmt.Errorf("failed to read kubeconfig certificates: %w", err))
 	}
@@ -86,7 +54,7 @@ func getCertsStatusControlPlane(s mctypes.State, r *http.Request, snap snap.Snap
 	certificates = append(certificates, kubeConfigCerts...)
 
 	if clusterConfig.Datastore.GetType() == "external" {
-		dataStoreCerts, err := loadCertificateStatusesFromDir(snap.EtcdPKIDir(), dataStoreCertificateNames)
+		dataStoreCerts, err := loadCertificateStatusesFromDir(snap.EtcdPKIDir(), snaputil.DataStoreCertificateNames)
 		if err != nil {
 			return mctypes.InternalError(fmt.Errorf("failed to read datastore certificates: %w", err))
 		}
@@ -103,12 +71,12 @@ func getCertsStatusControlPlane(s mctypes.State, r *http.Request, snap snap.Snap
 // getCertsStatusWorker collects certificate status information for worker
 // nodes. It reads worker certificates and kubeconfig certificates.
 func getCertsStatusWorker(s mctypes.State, r *http.Request, snap snap.Snap) mctypes.Response {
-	nodeCerts, err := loadCertificateStatusesFromDir(snap.KubernetesPKIDir(), workerCertificateNames)
+	nodeCerts, err := loadCertificateStatusesFromDir(snap.KubernetesPKIDir(), snaputil.WorkerCertificateNames)
 	if err != nil {
 		return mctypes.InternalError(fmt.Errorf("failed to read node certificates: %w", err))
 	}
 
-	kubeConfigCerts, err := readKubeconfigCertificates(snap.KubernetesConfigDir(), workerKubeconfigs)
+	kubeConfigCerts, err := readKubeconfigCertificates(snap.KubernetesConfigDir(), snaputil.WorkerKubeconfigs)
 	if err != nil {
 		return mctypes.InternalError(fmt.Errorf("failed to read kubeconfig certificates: %w", err))
 	}
@@ -136,23 +104,9 @@ func readKubeconfigCertificates(kubeconfigDir string, configs []string) ([]apiv2
 	certificates := make([]apiv2.CertificateStatus, 0, len(configs))
 
 	for _, config := range configs {
-		kubeConfig, err := clientcmd.LoadFromFile(filepath.Join(kubeconfigDir, config))
-		if err != nil {
-			return nil, fmt.Errorf("failed to load kubeconfig %s: %w", config, err)
-		}
-
-		authInfo, exists := kubeConfig.AuthInfos["k8s-user"]
-		if !exists {
-			return nil, fmt.Errorf("user 'k8s-user' not found in kubeconfig %s", config)
-		}
-
-		if authInfo.ClientCertificateData == nil {
-			return nil, fmt.Errorf("no client certificate data found in kubeconfig %s", config)
-		}
-
-		cert, _, err := pkiutil.LoadCertificate(string(authInfo.ClientCertificateData), "")
+		cert, err := snaputil.ReadKubeconfigCertificate(kubeconfigDir, config)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load certificate data from kubeconfig %s: %w", config, err)
+			return nil, err
 		}
 
 		certificates = append(certificates, apiv2.CertificateStatus{
@@ -56,9 +56,10 @@ func refreshCertsUpdateControlPlane(s mctypes.State, r *http.Request, snap snap.
 	}
 
 	certificates := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
-		Hostname:  s.Name(),
-		IPSANs:    append([]net.IP{nodeIP}, serviceIPs...),
-		NotBefore: time.Now(),
+		Hostname:     s.Name(),
+		IPSANs:       append([]net.IP{nodeIP}, serviceIPs...),
+		NotBefore:    time.Now(),
+		KeyAlgorithm: clusterConfig.KeyAlgorithm(),
 	})
 
 	if err := setup.ReadControlPlanePKI(snap, certificates, true); err != nil {
@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/canonical/k8sd/pkg/client/kubernetes"
 	"github.com/canonical/k8sd/pkg/k8sd/api/impl"
 	"github.com/canonical/k8sd/pkg/k8sd/database"
@@ -66,8 +67,24 @@ func (e *Endpoints) getClusterStatus(s mctypes.State, r *http.Request) mctypes.R
 		return mctypes.InternalError(fmt.Errorf("database transaction failed: %w", err))
 	}
 
-	return mctypes.SyncResponse(true, &apiv2.ClusterStatusResponse{
-		ClusterStatus: apiv2.ClusterStatus{
+	var addonStatuses map[string]apiv2.FeatureStatus
+	for name, status := range statuses {
+		addon, ok := features.HelmAddonFromStatusName(name)
+		if !ok {
+			continue
+		}
+		// only report add-ons that are still part of the cluster configuration
+		if _, ok := config.Addons[addon]; !ok {
+			continue
+		}
+		if addonStatuses == nil {
+			addonStatuses = make(map[string]apiv2.FeatureStatus)
+		}
+		addonStatuses[addon] = status.ToAPI()
+	}
+
+	return mctypes.SyncResponse(true, &k8sdapi.ClusterStatusResponse{
+		ClusterStatusResponse: apiv2.ClusterStatusResponse{ClusterStatus: apiv2.ClusterStatus{
 			Ready:   ready,
 			Members: members,
 			Config:  config.ToUserFacing(),
@@ -82,7 +99,8 @@ func (e *Endpoints) getClusterStatus(s mctypes.State, r *http.Request) mctypes.R
 			Gateway:       statuses[features.Gateway].ToAPI(),
 			MetricsServer: statuses[features.MetricsServer].ToAPI(),
 			LocalStorage:  statuses[features.LocalStorage].ToAPI(),
-		},
+		}},
+		Addons: addonStatuses,
 	})
 }
 
@@ -2,17 +2,20 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/utils"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
 
 func (e *Endpoints) postClusterBootstrap(_ mctypes.State, r *http.Request) mctypes.Response {
-	req := apiv2.BootstrapClusterRequest{}
+	req := k8sdapi.BootstrapClusterRequest{}
 	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
 		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
 	}
@@ -23,6 +26,19 @@ func (e *Endpoints) postClusterBootstrap(_ mctypes.State, r *http.Request) mctyp
 		return mctypes.BadRequest(fmt.Errorf("failed to prepare bootstrap config: %w", err))
 	}
 
+	// Verify the cluster bundle before bootstrapping, so that a wrong passphrase is reported early.
+	if len(req.Bundle) > 0 {
+		bundle, err := types.OpenClusterBundle(req.Bundle, req.BundlePassphrase)
+		if err != nil {
+			return mctypes.BadRequest(fmt.Errorf("failed to open cluster bundle: %w", err))
+		}
+		b, err := json.Marshal(bundle)
+		if err != nil {
+			return mctypes.InternalError(fmt.Errorf("failed to prepare cluster bundle: %w", err))
+		}
+		config = utils.MicroclusterMapWithClusterBundle(config, string(b))
+	}
+
 	// Clean hostname
 	hostname, err := utils.CleanHostname(req.Name)
 	if err != nil {
@@ -7,15 +7,16 @@ import (
 	"net/http"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/canonical/k8sd/pkg/k8sd/database"
-	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
+	"github.com/canonical/k8sd/pkg/k8sd/features"
 	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/utils"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
 
 func (e *Endpoints) putClusterConfig(s mctypes.State, r *http.Request) mctypes.Response {
-	var req apiv2.SetClusterConfigRequest
+	var req k8sdapi.SetClusterConfigRequest
 
 	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
 		return mctypes.BadRequest(fmt.Errorf("failed to decode request: %w", err))
@@ -28,13 +29,58 @@ func (e *Endpoints) putClusterConfig(s mctypes.State, r *http.Request) mctypes.R
 	if requestedConfig.Datastore, err = types.DatastoreConfigFromUserFacing(req.Datastore); err != nil {
 		return mctypes.BadRequest(fmt.Errorf("failed to parse datastore config: %w", err))
 	}
+	requestedConfig.Addons = types.HelmAddonsFromUserFacing(req.Addons)
 
+	if req.DryRun {
+		return e.dryRunClusterConfig(s, r, requestedConfig)
+	}
+	return e.setClusterConfig(s, r, requestedConfig, req.ExpectedResourceVersion, requestIdentity(r), "")
+}
+
+// setClusterConfig merges and validates a cluster configuration update, stores it as a new revision
+// and notifies the controllers of the affected features.
+// If expectedResourceVersion is not zero, the update is rejected with a conflict if the configuration was changed since.
+func (e *Endpoints) setClusterConfig(s mctypes.State, r *http.Request, requestedConfig types.ClusterConfig, expectedResourceVersion int64, requestedBy string, reason string) mctypes.Response {
+	var (
+		requirementsErr error
+		conflictErr     error
+		resourceVersion int64
+	)
 	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
-		if _, err := database.SetClusterConfig(ctx, tx, requestedConfig); err != nil {
+		if expectedResourceVersion != 0 {
+			currentResourceVersion, err := database.GetClusterConfigResourceVersion(ctx, tx)
+			if err != nil {
+				return fmt.Errorf("failed to get cluster configuration resource version: %w", err)
+			}
+			if currentResourceVersion != expectedResourceVersion {
+				conflictErr = fmt.Errorf("cluster configuration was modified: resource version is %d, but %d was expected", currentResourceVersion, expectedResourceVersion)
+				return conflictErr
+			}
+		}
+		oldConfig, err := database.GetClusterConfig(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster configuration: %w", err)
+		}
+		mergedConfig, err := database.SetClusterConfigAs(ctx, tx, requestedConfig, requestedBy, reason)
+		if err != nil {
 			return fmt.Errorf("failed to update cluster configuration: %w", err)
 		}
+		// refuse to disable features that are required by features that remain enabled.
+		// returning the error rolls back the transaction.
+		if requirementsErr = features.CheckRequirements(oldConfig, mergedConfig); requirementsErr != nil {
+			return requirementsErr
+		}
+		if resourceVersion, err = database.GetClusterConfigResourceVersion(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get cluster configuration resource version: %w", err)
+		}
 		return nil
 	}); err != nil {
+		if conflictErr != nil {
+			return mctypes.ErrorResponse(http.StatusConflict, conflictErr.Error())
+		}
+		if requirementsErr != nil {
+			return mctypes.BadRequest(fmt.Errorf("invalid feature configuration, disable the dependent features first: %w", requirementsErr))
+		}
 		return mctypes.InternalError(fmt.Errorf("database transaction to update cluster configuration failed: %w", err))
 	}
 
@@ -43,29 +89,43 @@ func (e *Endpoints) putClusterConfig(s mctypes.State, r *http.Request) mctypes.R
 	// Annotations can affect any feature (e.g. advertise-all-pools affects LoadBalancer).
 	// Notify all feature controllers when annotations are present so they re-reconcile.
 	hasAnnotations := len(requestedConfig.Annotations) > 0
-	e.provider.NotifyFeatureController(
-		!requestedConfig.Network.Empty() || hasAnnotations,
-		!requestedConfig.Gateway.Empty() || hasAnnotations,
-		!requestedConfig.Ingress.Empty() || hasAnnotations,
-		!requestedConfig.LoadBalancer.Empty() || hasAnnotations,
-		!requestedConfig.LocalStorage.Empty() || hasAnnotations,
-		!requestedConfig.MetricsServer.Empty() || hasAnnotations,
-		!requestedConfig.DNS.Empty() || !requestedConfig.Kubelet.Empty() || hasAnnotations,
-	)
+	var changedFeatures []types.FeatureName
+	for _, feature := range features.Registered() {
+		if hasAnnotations || feature.HasConfig(requestedConfig) {
+			changedFeatures = append(changedFeatures, feature.Name)
+		}
+	}
+	e.provider.NotifyFeatureController(changedFeatures...)
 
-	return mctypes.SyncResponse(true, &apiv2.SetClusterConfigResponse{})
+	return mctypes.SyncResponse(true, &k8sdapi.SetClusterConfigResponse{ResourceVersion: resourceVersion})
 }
 
 func (e *Endpoints) getClusterConfig(s mctypes.State, r *http.Request) mctypes.Response {
-	config, err := databaseutil.GetClusterConfig(r.Context(), s)
-	if err != nil {
+	var (
+		config          types.ClusterConfig
+		resourceVersion int64
+	)
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		if config, err = database.GetClusterConfig(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get cluster configuration: %w", err)
+		}
+		if resourceVersion, err = database.GetClusterConfigResourceVersion(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get cluster configuration resource version: %w", err)
+		}
+		return nil
+	}); err != nil {
 		return mctypes.InternalError(fmt.Errorf("failed to retrieve cluster configuration: %w", err))
 	}
 
-	return mctypes.SyncResponse(true, &apiv2.GetClusterConfigResponse{
-		Config:      config.ToUserFacing(),
-		Datastore:   config.Datastore.ToUserFacing(),
-		PodCIDR:     config.Network.PodCIDR,
-		ServiceCIDR: config.Network.ServiceCIDR,
+	return mctypes.SyncResponse(true, &k8sdapi.GetClusterConfigResponse{
+		GetClusterConfigResponse: apiv2.GetClusterConfigResponse{
+			Config:      config.ToUserFacing(),
+			Datastore:   config.Datastore.ToUserFacing(),
+			PodCIDR:     config.Network.PodCIDR,
+			ServiceCIDR: config.Network.ServiceCIDR,
+		},
+		Addons:          config.Addons.ToUserFacing(),
+		ResourceVersion: resourceVersion,
 	})
 }
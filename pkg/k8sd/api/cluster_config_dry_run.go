@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/client/helm"
+	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
+	"github.com/canonical/k8sd/pkg/k8sd/features"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+)
+
+// dryRunRenderTimeout is the maximum time to render the Helm charts of a single feature.
+const dryRunRenderTimeout = 30 * time.Second
+
+// dryRunClusterConfig validates a cluster configuration update and returns the changes it would cause, without applying it.
+func (e *Endpoints) dryRunClusterConfig(s mctypes.State, r *http.Request, requestedConfig types.ClusterConfig) mctypes.Response {
+	oldConfig, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to retrieve cluster configuration: %w", err))
+	}
+	newConfig, err := types.MergeClusterConfig(oldConfig, requestedConfig)
+	if err != nil {
+		return mctypes.BadRequest(fmt.Errorf("invalid configuration: %w", err))
+	}
+	if err := features.CheckRequirements(oldConfig, newConfig); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("invalid feature configuration, disable the dependent features first: %w", err))
+	}
+
+	changes, err := types.DiffClusterConfig(oldConfig, newConfig)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to compare cluster configurations: %w", err))
+	}
+
+	env := features.Env{Snap: e.provider.Snap(), State: s}
+	hasAnnotations := len(requestedConfig.Annotations) > 0
+	var featureDiffs []k8sdapi.FeatureDiff
+	for _, feature := range features.Registered() {
+		if !hasAnnotations && !feature.HasConfig(requestedConfig) {
+			continue
+		}
+		diff := diffFeature(r.Context(), env, feature, oldConfig, newConfig)
+		if len(diff.Charts) == 0 && diff.Error == "" {
+			continue
+		}
+		featureDiffs = append(featureDiffs, diff)
+	}
+
+	return mctypes.SyncResponse(true, &k8sdapi.SetClusterConfigResponse{
+		Changes:  fieldChangesToAPI(changes),
+		Features: featureDiffs,
+	})
+}
+
+// diffFeature renders the Helm charts of a feature for the old and new cluster configuration and compares them.
+func diffFeature(ctx context.Context, env features.Env, feature features.Feature, oldConfig, newConfig types.ClusterConfig) k8sdapi.FeatureDiff {
+	diff := k8sdapi.FeatureDiff{Name: string(feature.Name)}
+
+	render := func(cfg types.ClusterConfig) ([]features.RenderedChart, error) {
+		ctx, cancel := context.WithTimeout(ctx, dryRunRenderTimeout)
+		defer cancel()
+		return features.Render(ctx, env, feature, cfg)
+	}
+	oldCharts, err := render(oldConfig)
+	if err != nil {
+		diff.Error = fmt.Sprintf("failed to render current configuration: %v", err)
+		return diff
+	}
+	newCharts, err := render(newConfig)
+	if err != nil {
+		diff.Error = fmt.Sprintf("failed to render new configuration: %v", err)
+		return diff
+	}
+
+	for _, newChart := range newCharts {
+		var oldChart *features.RenderedChart
+		for i := range oldCharts {
+			if oldCharts[i].Chart.Name == newChart.Chart.Name && oldCharts[i].Chart.Namespace == newChart.Chart.Namespace {
+				oldChart = &oldCharts[i]
+			}
+		}
+		wasPresent := oldChart != nil && oldChart.State != helm.StateDeleted
+		isPresent := newChart.State != helm.StateDeleted
+
+		chartDiff := k8sdapi.ChartDiff{Name: newChart.Chart.Name, Namespace: newChart.Chart.Namespace}
+		switch {
+		case !wasPresent && !isPresent:
+			continue
+		case wasPresent && !isPresent:
+			chartDiff.Action = "delete"
+		case !wasPresent && isPresent:
+			chartDiff.Action = "install"
+			if chartDiff.Changes, err = diffValues(nil, newChart.Values); err != nil {
+				diff.Error = err.Error()
+				return diff
+			}
+		default:
+			chartDiff.Action = "upgrade"
+			if chartDiff.Changes, err = diffValues(oldChart.Values, newChart.Values); err != nil {
+				diff.Error = err.Error()
+				return diff
+			}
+			if len(chartDiff.Changes) == 0 {
+				continue
+			}
+		}
+		diff.Charts = append(diff.Charts, chartDiff)
+	}
+	return diff
+}
+
+func diffValues(old, new map[string]any) ([]k8sdapi.FieldChange, error) {
+	changes, err := utils.DiffJSON(old, new)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare Helm values: %w", err)
+	}
+	return fieldChangesToAPI(changes), nil
+}
+
+func fieldChangesToAPI(changes []utils.FieldChange) []k8sdapi.FieldChange {
+	result := make([]k8sdapi.FieldChange, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, k8sdapi.FieldChange(change))
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/user"
+	"strconv"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/database"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	"github.com/canonical/lxd/lxd/request"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"golang.org/x/sys/unix"
+)
+
+func (e *Endpoints) getClusterConfigHistory(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.ClusterConfigHistoryRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+
+	limit := req.Limit
+	if limit > 0 {
+		// fetch one more revision to compute the changes of the oldest returned revision
+		limit++
+	}
+
+	var revisions []types.ClusterConfigRevision
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		if revisions, err = database.GetClusterConfigRevisions(ctx, tx, limit); err != nil {
+			return fmt.Errorf("failed to get cluster config revisions: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("database transaction failed: %w", err))
+	}
+
+	response := k8sdapi.ClusterConfigHistoryResponse{Revisions: make([]k8sdapi.ClusterConfigRevision, 0, len(revisions))}
+	for i, revision := range revisions {
+		if req.Limit > 0 && i == req.Limit {
+			break
+		}
+		entry := k8sdapi.ClusterConfigRevision{
+			Revision:    revision.Revision,
+			CreatedAt:   revision.CreatedAt,
+			RequestedBy: revision.RequestedBy,
+			Reason:      revision.Reason,
+		}
+		if i+1 < len(revisions) {
+			changes, err := types.DiffClusterConfig(revisions[i+1].Config, revision.Config)
+			if err != nil {
+				return mctypes.InternalError(fmt.Errorf("failed to compare revision %d: %w", revision.Revision, err))
+			}
+			entry.Changes = fieldChangesToAPI(changes)
+		}
+		response.Revisions = append(response.Revisions, entry)
+	}
+
+	return mctypes.SyncResponse(true, &response)
+}
+
+// postClusterConfigRollback restores the user-facing cluster configuration of a previous revision.
+// The rollback goes through the same merge, validation and feature reconciliation as any other change and is
+// stored as a new revision. The datastore configuration is not rolled back, and fields that were not set in the
+// previous revision keep their current value.
+func (e *Endpoints) postClusterConfigRollback(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.ClusterConfigRollbackRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+
+	var revision types.ClusterConfigRevision
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		if revision, err = database.GetClusterConfigRevision(ctx, tx, req.Revision); err != nil {
+			return fmt.Errorf("failed to get cluster config revision: %w", err)
+		}
+		return nil
+	}); err != nil {
+		if errors.Is(err, database.ErrClusterConfigRevisionNotFound) {
+			return mctypes.ErrorResponse(http.StatusNotFound, err.Error())
+		}
+		return mctypes.InternalError(fmt.Errorf("database transaction failed: %w", err))
+	}
+
+	requestedConfig, err := types.ClusterConfigFromUserFacing(revision.Config.ToUserFacing())
+	if err != nil {
+		return mctypes.BadRequest(fmt.Errorf("invalid configuration in revision %d: %w", req.Revision, err))
+	}
+	requestedConfig.Addons = revision.Config.Addons
+
+	if req.DryRun {
+		return e.dryRunClusterConfig(s, r, requestedConfig)
+	}
+	return e.setClusterConfig(s, r, requestedConfig, 0, requestIdentity(r), fmt.Sprintf("rollback to revision %d", req.Revision))
+}
+
+// requestIdentity describes who made a request, for the audit trail of the cluster configuration.
+// The identity is taken from the authenticated connection: the peer credentials of requests over the local unix
+// socket, or the client certificate of requests from other cluster members.
+func requestIdentity(r *http.Request) string {
+	if r.TLS != nil {
+		if len(r.TLS.PeerCertificates) > 0 {
+			return fmt.Sprintf("%s at %s", r.TLS.PeerCertificates[0].Subject.CommonName, r.RemoteAddr)
+		}
+		return r.RemoteAddr
+	}
+
+	cred, err := peerCredentials(r)
+	if err != nil {
+		return "local"
+	}
+	if u, err := user.LookupId(strconv.Itoa(int(cred.Uid))); err == nil {
+		return fmt.Sprintf("%s (uid %d)", u.Username, cred.Uid)
+	}
+	return fmt.Sprintf("uid %d", cred.Uid)
+}
+
+// peerCredentials returns the credentials of the process at the other end of the unix socket connection of a request.
+func peerCredentials(r *http.Request) (*unix.Ucred, error) {
+	conn, ok := r.Context().Value(request.CtxConn).(*net.UnixConn)
+	if !ok {
+		return nil, fmt.Errorf("request is not made over a unix socket")
+	}
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to access unix socket: %w", err)
+	}
+
+	var cred *unix.Ucred
+	var credErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to access unix socket: %w", err)
+	}
+	if credErr != nil {
+		return nil, fmt.Errorf("failed to get peer credentials: %w", credErr)
+	}
+	return cred, nil
+}
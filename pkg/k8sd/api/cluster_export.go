@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/database"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+)
+
+// minClusterBundlePassphraseLength is the minimum length of the passphrase that protects an exported cluster bundle.
+const minClusterBundlePassphraseLength = 12
+
+func (e *Endpoints) postClusterExport(s mctypes.State, r *http.Request) mctypes.Response {
+	// The bundle contains the CA keys of the cluster, only allow exporting it from the node itself.
+	if r.TLS != nil {
+		return mctypes.ErrorResponse(http.StatusForbidden, "cluster bundles can only be exported over the local unix socket")
+	}
+
+	var req k8sdapi.ExportClusterBundleRequest
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to decode request: %w", err))
+	}
+	if len(req.Passphrase) < minClusterBundlePassphraseLength {
+		return mctypes.BadRequest(fmt.Errorf("passphrase must be at least %d characters long", minClusterBundlePassphraseLength))
+	}
+
+	bundle := types.ClusterBundle{CreatedAt: time.Now().UTC()}
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		if bundle.Config, err = database.GetClusterConfig(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get cluster configuration: %w", err)
+		}
+		if bundle.ClusterAPIToken, err = database.GetClusterAPIToken(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get ClusterAPI token: %w", err)
+		}
+		if bundle.KubernetesAuthTokens, err = database.ListTokens(ctx, tx); err != nil {
+			return fmt.Errorf("failed to get Kubernetes auth tokens: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("database transaction to export cluster bundle failed: %w", err))
+	}
+
+	sealed, err := types.SealClusterBundle(bundle, req.Passphrase)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to seal cluster bundle: %w", err))
+	}
+
+	return mctypes.SyncResponse(true, &k8sdapi.ExportClusterBundleResponse{Bundle: sealed})
+}
@@ -2,12 +2,14 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	apiv1_annotations "github.com/canonical/k8s-snap-api/v2/api/annotations"
+	"github.com/canonical/k8sd/pkg/k8sd/database"
 	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
 	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/log"
@@ -19,7 +21,7 @@ import (
 )
 
 // postClusterRemove handles requests to remove a node from the cluster.
-// It will remove the node from etcd, microcluster and from Kubernetes.
+// It will remove the node from etcd, microcluster and from Kubernetes, and revoke the certificates issued for the node.
 // If force is true, the node is removed on a best-effort basis even if it is not reachable.
 func (e *Endpoints) postClusterRemove(s mctypes.State, r *http.Request) mctypes.Response {
 	snap := e.provider.Snap()
@@ -68,6 +70,23 @@ func (e *Endpoints) postClusterRemove(s mctypes.State, r *http.Request) mctypes.
 		log.Info("Skipping Kubernetes node removal as per annotation")
 	}
 
+	// NOTE: Revoke the certificates before removing the node from microcluster, in case the local node is removed.
+	log.Info("Revoke node certificates")
+	if err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		revoked, err := database.RevokeNodeCertificates(ctx, tx, req.Name)
+		if err != nil {
+			return err
+		}
+		log.Info("Revoked node certificates", "count", revoked)
+		return nil
+	}); err != nil {
+		if req.Force {
+			log.Error(err, "Failed to revoke node certificates, but continuing due to force=true")
+		} else {
+			return mctypes.InternalError(fmt.Errorf("failed to revoke node certificates: %w", err))
+		}
+	}
+
 	// The control-plane check relies on the microcluster membership being correct. If the membership is out-of-sync, we might
 	// mis-classify a control-plane node as a worker node. Hence we always proceed with the removal
 	// if force=true, regardless of the role of the node.
@@ -6,12 +6,19 @@ import (
 	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
 
 type Endpoints struct {
 	context  context.Context
 	provider Provider
+
+	// etcdRestore is the status of the last restore of the managed etcd cluster started on the node.
+	etcdRestore etcdRestoreTracker
+	// etcdOperations are the restores and rejoins in progress on the node, which reset or rejoin the etcd members of
+	// other control plane nodes.
+	etcdOperations etcdMemberOperations
 }
 
 // New creates a new API server instance.
@@ -116,6 +123,12 @@ func (e *Endpoints) Endpoints() []mctypes.Endpoint {
 			Name: "Kubeconfig",
 			Path: apiv2.KubeConfigRPC,
 			Get:  mctypes.EndpointAction{Handler: e.getKubeconfig, AccessHandler: e.restrictWorkers},
+			Post: mctypes.EndpointAction{Handler: e.postKubeconfig, AccessHandler: e.restrictWorkers},
+		},
+		{
+			Name: "RevokeKubeconfig",
+			Path: k8sdapi.RevokeKubeConfigRPC,
+			Post: mctypes.EndpointAction{Handler: e.postRevokeKubeconfig, AccessHandler: e.restrictWorkers},
 		},
 		// Get and modify the cluster configuration (e.g. to enable/disable features)
 		{
@@ -124,10 +137,76 @@ func (e *Endpoints) Endpoints() []mctypes.Endpoint {
 			Put:  mctypes.EndpointAction{Handler: e.putClusterConfig, AccessHandler: e.restrictWorkers},
 			Get:  mctypes.EndpointAction{Handler: e.getClusterConfig, AccessHandler: e.restrictWorkers},
 		},
+		// Cluster configuration revisions and rollback
+		{
+			Name: "ClusterConfigHistory",
+			Path: k8sdapi.ClusterConfigHistoryRPC,
+			Get:  mctypes.EndpointAction{Handler: e.getClusterConfigHistory, AccessHandler: e.restrictWorkers},
+		},
+		{
+			Name: "ClusterConfigRollback",
+			Path: k8sdapi.ClusterConfigRollbackRPC,
+			Post: mctypes.EndpointAction{Handler: e.postClusterConfigRollback, AccessHandler: e.restrictWorkers},
+		},
+		// Export the cluster configuration, certificate authorities and tokens for disaster recovery
+		{
+			Name: "ClusterExport",
+			Path: k8sdapi.ExportClusterBundleRPC,
+			Post: mctypes.EndpointAction{Handler: e.postClusterExport, AccessHandler: e.restrictWorkers},
+		},
+		// Rotate the cluster certificate authorities
+		{
+			Name: "CARotation",
+			Path: k8sdapi.CARotationRPC,
+			Get:  mctypes.EndpointAction{Handler: e.getCARotation, AccessHandler: e.restrictWorkers},
+			Post: mctypes.EndpointAction{Handler: e.postCARotation, AccessHandler: e.restrictWorkers},
+		},
+		// Managed etcd cluster members and maintenance
+		{
+			Name: "EtcdMembers",
+			Path: k8sdapi.EtcdMembersRPC,
+			Get:  mctypes.EndpointAction{Handler: e.getEtcdMembers, AccessHandler: e.restrictWorkers},
+			Post: mctypes.EndpointAction{Handler: e.postEtcdMaintenance, AccessHandler: e.restrictWorkers},
+		},
+		// Snapshots of the managed etcd cluster
+		{
+			Name:   "EtcdSnapshots",
+			Path:   k8sdapi.EtcdSnapshotsRPC,
+			Get:    mctypes.EndpointAction{Handler: e.getEtcdSnapshots, AccessHandler: e.restrictWorkers},
+			Post:   mctypes.EndpointAction{Handler: e.postEtcdSnapshots, AccessHandler: e.restrictWorkers},
+			Delete: mctypes.EndpointAction{Handler: e.deleteEtcdSnapshot, AccessHandler: e.restrictWorkers},
+		},
+		// Restore the managed etcd cluster from a snapshot, in the background
+		{
+			Name: "EtcdRestore",
+			Path: k8sdapi.EtcdRestoreRPC,
+			Get:  mctypes.EndpointAction{Handler: e.getEtcdRestore, AccessHandler: e.restrictWorkers},
+			Post: mctypes.EndpointAction{Handler: e.postEtcdRestore, AccessHandler: e.restrictWorkers},
+		},
+		// Reset or rejoin the local etcd member while restoring a snapshot or rejoining the member
+		{
+			Name: "EtcdLocalMember",
+			Path: k8sdapi.EtcdLocalMemberRPC,
+			Get:  mctypes.EndpointAction{Handler: e.getEtcdMemberOperation, AccessHandler: e.restrictWorkers},
+			Post: mctypes.EndpointAction{Handler: e.postEtcdLocalMember, AccessHandler: e.restrictWorkers},
+		},
+		// Revocation list of the client certificate authority
+		{
+			Name: "CertificateRevocationList",
+			Path: k8sdapi.CertificateRevocationListRPC,
+			Get:  mctypes.EndpointAction{Handler: e.getCertificateRevocationList, AllowUntrusted: true},
+		},
+		// Feature status history
+		{
+			Name: "FeatureStatusHistory",
+			Path: k8sdapi.FeatureStatusHistoryRPC,
+			Get:  mctypes.EndpointAction{Handler: e.getFeatureStatusHistory, AccessHandler: e.restrictWorkers},
+		},
 		// Kubernetes auth tokens and token review webhook for kube-apiserver
 		{
 			Name:   "KubernetesAuthTokens",
-			Path:   apiv2.GenerateKubernetesAuthTokenRPC, // == apiv2.RevokeKubernetesAuthTokenRPC
+			Path:   k8sdapi.KubernetesAuthTokensRPC, // == apiv2.GenerateKubernetesAuthTokenRPC == apiv2.RevokeKubernetesAuthTokenRPC
+			Get:    mctypes.EndpointAction{Handler: e.getKubernetesAuthTokens, AccessHandler: e.restrictWorkers},
 			Post:   mctypes.EndpointAction{Handler: e.postKubernetesAuthTokens},
 			Delete: mctypes.EndpointAction{Handler: e.deleteKubernetesAuthTokens},
 		},
@@ -136,6 +215,11 @@ func (e *Endpoints) Endpoints() []mctypes.Endpoint {
 			Path: apiv2.ReviewKubernetesAuthTokenRPC,
 			Post: mctypes.EndpointAction{Handler: e.postKubernetesAuthWebhook, AllowUntrusted: true},
 		},
+		{
+			Name: "KubernetesAuthorizationWebhook",
+			Path: k8sdapi.KubernetesAuthorizationWebhookRPC,
+			Post: mctypes.EndpointAction{Handler: e.postKubernetesAuthorizationWebhook, AllowUntrusted: true},
+		},
 		// ClusterAPI management endpoints.
 		{
 			Name: "ClusterAPI/GetJoinToken",
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/client/etcd"
+	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
+	"github.com/canonical/k8sd/pkg/log"
+	"github.com/canonical/k8sd/pkg/utils"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+)
+
+// localEtcdClient returns a client for the managed etcd cluster, connected through the local etcd member.
+// localEtcdClient returns a BadRequest response if the cluster does not use the managed etcd datastore.
+func (e *Endpoints) localEtcdClient(s mctypes.State, r *http.Request) (*etcd.Client, mctypes.Response) {
+	cfg, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return nil, mctypes.InternalError(fmt.Errorf("failed to get cluster config: %w", err))
+	}
+	if datastore := cfg.Datastore.GetType(); datastore != "etcd" {
+		return nil, mctypes.BadRequest(fmt.Errorf("the cluster uses the %q datastore, not the managed etcd", datastore))
+	}
+
+	client, err := e.provider.Snap().EtcdClient([]string{fmt.Sprintf("https://%s", utils.JoinHostPort("127.0.0.1", cfg.Datastore.GetEtcdPort()))})
+	if err != nil {
+		return nil, mctypes.InternalError(fmt.Errorf("failed to create etcd client: %w", err))
+	}
+	return client, nil
+}
+
+func (e *Endpoints) getEtcdMembers(s mctypes.State, r *http.Request) mctypes.Response {
+	client, errResponse := e.localEtcdClient(s, r)
+	if errResponse != nil {
+		return errResponse
+	}
+	defer client.Close()
+
+	statuses, err := client.MemberStatuses(r.Context())
+	if err != nil {
+		return mctypes.InternalError(err)
+	}
+
+	members := make([]k8sdapi.EtcdMember, 0, len(statuses))
+	for _, status := range statuses {
+		member := k8sdapi.EtcdMember{
+			ID:         strconv.FormatUint(status.Member.ID, 16),
+			Name:       status.Member.Name,
+			PeerURLs:   status.Member.PeerURLs,
+			ClientURLs: status.Member.ClientURLs,
+			IsLearner:  status.Member.IsLearner,
+		}
+		if status.Err != nil {
+			member.Error = status.Err.Error()
+		} else {
+			member.IsLeader = status.Status.Leader == status.Member.ID
+			member.DBSize = status.Status.DbSize
+			member.DBSizeInUse = status.Status.DbSizeInUse
+			member.RaftIndex = status.Status.RaftIndex
+			member.RaftTerm = status.Status.RaftTerm
+			member.Version = status.Status.Version
+		}
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+
+	return mctypes.SyncResponse(true, k8sdapi.ListEtcdMembersResponse{Members: members})
+}
+
+func (e *Endpoints) postEtcdMaintenance(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.EtcdMaintenanceRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+	switch req.Action {
+	case k8sdapi.EtcdMaintenanceActionPromote, k8sdapi.EtcdMaintenanceActionMoveLeader, k8sdapi.EtcdMaintenanceActionRejoin:
+		if req.Member == "" {
+			return mctypes.BadRequest(fmt.Errorf("member is required for the %s action", req.Action))
+		}
+	case k8sdapi.EtcdMaintenanceActionDefragment:
+	case k8sdapi.EtcdMaintenanceActionCompact:
+		if req.Revision < 0 || req.Retain < 0 {
+			return mctypes.BadRequest(fmt.Errorf("revision and retain cannot be negative"))
+		}
+		if req.Revision > 0 && req.Retain > 0 {
+			return mctypes.BadRequest(fmt.Errorf("revision and retain cannot be set at the same time"))
+		}
+		if req.Revision == 0 && req.Retain == 0 {
+			req.Retain = k8sdapi.DefaultEtcdCompactRetain
+		}
+	default:
+		return mctypes.BadRequest(fmt.Errorf("unknown action %q", req.Action))
+	}
+
+	client, errResponse := e.localEtcdClient(s, r)
+	if errResponse != nil {
+		return errResponse
+	}
+	defer client.Close()
+
+	log.FromContext(r.Context()).Info("Performing etcd maintenance", "action", req.Action, "member", req.Member, "revision", req.Revision, "retain", req.Retain)
+
+	var (
+		response k8sdapi.EtcdMaintenanceResponse
+		err      error
+	)
+	switch req.Action {
+	case k8sdapi.EtcdMaintenanceActionRejoin:
+		return e.rejoinEtcdNode(s, r, client, req.Member)
+	case k8sdapi.EtcdMaintenanceActionPromote:
+		err = client.PromoteMember(r.Context(), req.Member)
+	case k8sdapi.EtcdMaintenanceActionMoveLeader:
+		err = client.MoveLeader(r.Context(), req.Member)
+	case k8sdapi.EtcdMaintenanceActionDefragment:
+		err = client.Defragment(r.Context(), req.Member)
+	case k8sdapi.EtcdMaintenanceActionCompact:
+		response.Revision, err = client.CompactRevisions(r.Context(), req.Revision, req.Retain)
+	}
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("etcd %s failed: %w", req.Action, err))
+	}
+
+	return mctypes.SyncResponse(true, response)
+}
+
+// rejoinEtcdNode resets the etcd member of a remaining control plane node and adds it to the cluster again, e.g. after
+// "k8sd cluster-recover" forced a new etcd cluster without it. rejoinEtcdNode returns an Unavailable response if the
+// node cannot be reached yet, so that callers can retry while the node is starting.
+func (e *Endpoints) rejoinEtcdNode(s mctypes.State, r *http.Request, etcdClient *etcd.Client, name string) mctypes.Response {
+	if name == s.Name() {
+		return mctypes.BadRequest(fmt.Errorf("cannot rejoin the etcd member of the local node"))
+	}
+
+	snap := e.provider.Snap()
+	cfg, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to get cluster config: %w", err))
+	}
+	client, err := snap.K8sdClient("")
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to create k8sd client: %w", err))
+	}
+	member, err := client.GetClusterMember(r.Context(), name)
+	if err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to get cluster member %s: %w", name, err))
+	}
+
+	remote, err := snap.K8sdClient(member.Address.String())
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to create k8sd client for node %s: %w", name, err))
+	}
+	status, initialized, err := remote.NodeStatus(r.Context())
+	if err != nil {
+		return mctypes.Unavailable(fmt.Errorf("failed to get the status of node %s: %w", name, err))
+	}
+	if !initialized {
+		return mctypes.Unavailable(fmt.Errorf("node %s is not initialized", name))
+	}
+	if status.NodeStatus.ClusterRole == apiv2.ClusterRoleWorker {
+		return mctypes.BadRequest(fmt.Errorf("node %s is a worker node and does not run etcd", name))
+	}
+	if _, err := etcdClient.FindMember(r.Context(), name); err == nil {
+		return mctypes.BadRequest(fmt.Errorf("node %s is already a member of the etcd cluster", name))
+	}
+
+	op, err := e.etcdOperations.start(s.Name(), []string{name})
+	if err != nil {
+		return mctypes.InternalError(err)
+	}
+	defer e.etcdOperations.done(op)
+
+	// NOTE: The data directory of the node still holds the members of the old cluster, so it cannot be reused.
+	if err := remote.EtcdLocalMember(r.Context(), op.request(k8sdapi.EtcdLocalMemberActionReset)); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to reset the etcd member of node %s: %w", name, err))
+	}
+	peerURL := fmt.Sprintf("https://%s", utils.JoinHostPort(member.Address.Addr().String(), cfg.Datastore.GetEtcdPeerPort()))
+	if err := rejoinEtcdMember(r.Context(), snap, etcdClient, op, name, member.Address.String(), peerURL); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to rejoin node %s: %w", name, err))
+	}
+
+	return mctypes.SyncResponse(true, k8sdapi.EtcdMaintenanceResponse{})
+}
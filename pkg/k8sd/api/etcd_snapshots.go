@@ -0,0 +1,872 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/client/etcd"
+	k8sdclient "github.com/canonical/k8sd/pkg/client/k8sd"
+	"github.com/canonical/k8sd/pkg/client/s3"
+	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
+	"github.com/canonical/k8sd/pkg/k8sd/etcdsnapshot"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/log"
+	"github.com/canonical/k8sd/pkg/snap"
+	snaputil "github.com/canonical/k8sd/pkg/snap/util"
+	"github.com/canonical/k8sd/pkg/utils"
+	"github.com/canonical/k8sd/pkg/utils/control"
+	lxdapi "github.com/canonical/lxd/shared/api"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+)
+
+// etcdSnapshotDir is the local directory of the etcd snapshots. Snapshots are also downloaded here before uploading
+// them to, or after downloading them from, an S3 bucket.
+func etcdSnapshotDir(snap snap.Snap) string {
+	return filepath.Join(snap.K8sdStateDir(), "etcd-snapshots")
+}
+
+// etcdSnapshotStore returns the store of the etcd snapshots, as configured by the types.AnnotationEtcdSnapshots
+// annotation, along with a description of its location.
+func etcdSnapshotStore(snap snap.Snap, cfg types.ClusterConfig) (etcdsnapshot.Store, string, error) {
+	snapshotCfg, _, err := cfg.EtcdSnapshots()
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s annotation: %w", types.AnnotationEtcdSnapshots, err)
+	}
+	if bucket := snapshotCfg.S3; bucket != nil {
+		client, err := s3.New(s3.Config{
+			Endpoint:        bucket.Endpoint,
+			Region:          bucket.Region,
+			Bucket:          bucket.Bucket,
+			AccessKeyID:     bucket.AccessKeyID,
+			SecretAccessKey: bucket.SecretAccessKey,
+			CACert:          bucket.CACert,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create S3 client: %w", err)
+		}
+		return etcdsnapshot.S3Store{Client: client, Prefix: bucket.Prefix}, fmt.Sprintf("s3://%s/%s", bucket.Bucket, bucket.Prefix), nil
+	}
+	dir := etcdSnapshotDir(snap)
+	return etcdsnapshot.LocalStore{Dir: dir}, dir, nil
+}
+
+func etcdSnapshotToAPI(snapshot etcdsnapshot.Snapshot) k8sdapi.EtcdSnapshot {
+	return k8sdapi.EtcdSnapshot{Name: snapshot.Name, Size: snapshot.Size, CreatedAt: snapshot.CreatedAt}
+}
+
+// listEtcdSnapshots returns the stored snapshots, oldest first.
+// Without an S3 bucket, every control plane node keeps the snapshots it took in its local directory. The snapshots of
+// all control plane nodes are then listed, along with the node that stores them. The control plane nodes whose
+// snapshots could not be listed are returned with the error.
+func listEtcdSnapshots(ctx context.Context, snap snap.Snap, localName string, store etcdsnapshot.Store) ([]k8sdapi.EtcdSnapshot, map[string]string, error) {
+	stored, err := store.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, local := store.(etcdsnapshot.LocalStore)
+	snapshots := make([]k8sdapi.EtcdSnapshot, 0, len(stored))
+	for _, snapshot := range stored {
+		apiSnapshot := etcdSnapshotToAPI(snapshot)
+		if local {
+			apiSnapshot.Node = localName
+		}
+		snapshots = append(snapshots, apiSnapshot)
+	}
+	if !local {
+		return snapshots, nil, nil
+	}
+
+	client, err := snap.K8sdClient("")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+	members, err := client.GetClusterMembers(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cluster members: %w", err)
+	}
+	unavailable := map[string]string{}
+	for _, member := range members {
+		if member.Name == localName {
+			continue
+		}
+		remote, err := snap.K8sdClient(member.Address.String())
+		if err != nil {
+			unavailable[member.Name] = fmt.Sprintf("failed to create k8sd client: %v", err)
+			continue
+		}
+		callCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		response, err := remote.ListEtcdSnapshots(callCtx, k8sdapi.ListEtcdSnapshotsRequest{Local: true})
+		cancel()
+		if err != nil {
+			// NOTE: Worker nodes do not store snapshots, and reject the request.
+			var statusErr lxdapi.StatusError
+			if errors.As(err, &statusErr) && statusErr.Status() == http.StatusForbidden {
+				continue
+			}
+			unavailable[member.Name] = err.Error()
+			continue
+		}
+		for _, snapshot := range response.Snapshots {
+			snapshot.Node = member.Name
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt) })
+	return snapshots, unavailable, nil
+}
+
+// pruneEtcdSnapshots deletes the oldest snapshots, so that at most retention snapshots are kept.
+// Without an S3 bucket, the retention applies to the snapshots of all control plane nodes together, and the snapshots
+// of other nodes are deleted by those nodes. Snapshots of nodes that are unavailable are neither counted nor deleted,
+// and are pruned once the nodes are available again.
+// pruneEtcdSnapshots returns the names of the deleted snapshots.
+func pruneEtcdSnapshots(ctx context.Context, snap snap.Snap, localName string, store etcdsnapshot.Store, retention int) ([]string, error) {
+	if _, local := store.(etcdsnapshot.LocalStore); !local {
+		return etcdsnapshot.Prune(ctx, store, retention)
+	}
+
+	snapshots, _, err := listEtcdSnapshots(ctx, snap, localName, store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	client, err := snap.K8sdClient("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+	var deleted []string
+	for i := 0; i < len(snapshots)-retention; i++ {
+		snapshot := snapshots[i]
+		if snapshot.Node == localName {
+			err = store.Delete(ctx, snapshot.Name)
+		} else {
+			err = deleteRemoteEtcdSnapshot(ctx, snap, client, snapshot.Node, snapshot.Name)
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete snapshot %s of node %s: %w", snapshot.Name, snapshot.Node, err)
+		}
+		deleted = append(deleted, snapshot.Name)
+	}
+	return deleted, nil
+}
+
+// deleteRemoteEtcdSnapshot deletes a snapshot from the local directory of another control plane node.
+func deleteRemoteEtcdSnapshot(ctx context.Context, snap snap.Snap, client k8sdclient.Client, node string, name string) error {
+	member, err := client.GetClusterMember(ctx, node)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster member: %w", err)
+	}
+	remote, err := snap.K8sdClient(member.Address.String())
+	if err != nil {
+		return fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+	return remote.DeleteEtcdSnapshot(ctx, k8sdapi.DeleteEtcdSnapshotRequest{Name: name})
+}
+
+func (e *Endpoints) getEtcdSnapshots(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.ListEtcdSnapshotsRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+
+	cfg, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to get cluster config: %w", err))
+	}
+	if datastore := cfg.Datastore.GetType(); datastore != "etcd" {
+		return mctypes.BadRequest(fmt.Errorf("the cluster uses the %q datastore, not the managed etcd", datastore))
+	}
+
+	snap := e.provider.Snap()
+	store, location, err := etcdSnapshotStore(snap, cfg)
+	if err != nil {
+		return mctypes.InternalError(err)
+	}
+
+	response := k8sdapi.ListEtcdSnapshotsResponse{Location: location}
+	if req.Local {
+		stored, err := store.List(r.Context())
+		if err != nil {
+			return mctypes.InternalError(err)
+		}
+		response.Snapshots = make([]k8sdapi.EtcdSnapshot, 0, len(stored))
+		for _, snapshot := range stored {
+			response.Snapshots = append(response.Snapshots, etcdSnapshotToAPI(snapshot))
+		}
+		return mctypes.SyncResponse(true, response)
+	}
+
+	if response.Snapshots, response.Unavailable, err = listEtcdSnapshots(r.Context(), snap, s.Name(), store); err != nil {
+		return mctypes.InternalError(err)
+	}
+	return mctypes.SyncResponse(true, response)
+}
+
+// deleteEtcdSnapshot deletes a stored snapshot from the store of the node, see pruneEtcdSnapshots.
+func (e *Endpoints) deleteEtcdSnapshot(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.DeleteEtcdSnapshotRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+	if !etcdsnapshot.ValidName(req.Name) {
+		return mctypes.BadRequest(fmt.Errorf("invalid snapshot name %q", req.Name))
+	}
+
+	cfg, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to get cluster config: %w", err))
+	}
+	store, _, err := etcdSnapshotStore(e.provider.Snap(), cfg)
+	if err != nil {
+		return mctypes.InternalError(err)
+	}
+	if err := store.Delete(r.Context(), req.Name); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to delete etcd snapshot: %w", err))
+	}
+	log.FromContext(r.Context()).Info("Deleted etcd snapshot", "name", req.Name)
+	return mctypes.SyncResponse(true, nil)
+}
+
+// postEtcdSnapshots takes a snapshot of the managed etcd cluster and stores it.
+// Scheduled snapshots are requested periodically by the etcd snapshot controller of every control plane node. They
+// are only taken by the node of the etcd leader, and only if the last snapshot is older than the configured interval.
+func (e *Endpoints) postEtcdSnapshots(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.TakeEtcdSnapshotRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+
+	cfg, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to get cluster config: %w", err))
+	}
+	snapshotCfg, configured, err := cfg.EtcdSnapshots()
+	if err != nil {
+		return mctypes.BadRequest(fmt.Errorf("invalid %s annotation: %w", types.AnnotationEtcdSnapshots, err))
+	}
+	if req.Scheduled && (!configured || cfg.Datastore.GetType() != "etcd") {
+		return mctypes.SyncResponse(true, k8sdapi.TakeEtcdSnapshotResponse{})
+	}
+
+	client, errResponse := e.localEtcdClient(s, r)
+	if errResponse != nil {
+		return errResponse
+	}
+	defer client.Close()
+
+	snap := e.provider.Snap()
+	store, _, err := etcdSnapshotStore(snap, cfg)
+	if err != nil {
+		return mctypes.InternalError(err)
+	}
+
+	if req.Scheduled {
+		status, err := client.Status(r.Context(), client.Endpoints()[0])
+		if err != nil {
+			return mctypes.InternalError(fmt.Errorf("failed to get status of the local etcd member: %w", err))
+		}
+		if status.Leader != status.Header.MemberId {
+			return mctypes.SyncResponse(true, k8sdapi.TakeEtcdSnapshotResponse{})
+		}
+		snapshots, _, err := listEtcdSnapshots(r.Context(), snap, s.Name(), store)
+		if err != nil {
+			return mctypes.InternalError(err)
+		}
+		if len(snapshots) > 0 && time.Since(snapshots[len(snapshots)-1].CreatedAt) < snapshotCfg.Interval {
+			return mctypes.SyncResponse(true, k8sdapi.TakeEtcdSnapshotResponse{})
+		}
+	}
+
+	dir := etcdSnapshotDir(snap)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to create snapshot directory: %w", err))
+	}
+	now := time.Now()
+	file := filepath.Join(dir, fmt.Sprintf(".snapshot-%d.tmp", now.UnixNano()))
+	defer os.Remove(file)
+
+	leader, err := client.SaveSnapshot(r.Context(), file)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to take etcd snapshot: %w", err))
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to stat etcd snapshot: %w", err))
+	}
+	snapshot := etcdsnapshot.Snapshot{Name: etcdsnapshot.Name(leader, now), Size: info.Size(), CreatedAt: now.UTC().Truncate(time.Second)}
+	if err := store.Save(r.Context(), snapshot.Name, file); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to store etcd snapshot: %w", err))
+	}
+	log.FromContext(r.Context()).Info("Took etcd snapshot", "name", snapshot.Name, "size", snapshot.Size, "scheduled", req.Scheduled)
+
+	apiSnapshot := etcdSnapshotToAPI(snapshot)
+	if _, local := store.(etcdsnapshot.LocalStore); local {
+		apiSnapshot.Node = s.Name()
+	}
+	response := k8sdapi.TakeEtcdSnapshotResponse{Snapshot: &apiSnapshot}
+	if configured {
+		if response.Pruned, err = pruneEtcdSnapshots(r.Context(), snap, s.Name(), store, snapshotCfg.Retention); err != nil {
+			return mctypes.InternalError(fmt.Errorf("failed to prune etcd snapshots: %w", err))
+		}
+	}
+	return mctypes.SyncResponse(true, response)
+}
+
+// etcdRestoreTracker tracks the status of the last restore of the managed etcd cluster started on the node.
+type etcdRestoreTracker struct {
+	mu     sync.Mutex
+	status k8sdapi.EtcdRestoreStatusResponse
+}
+
+// start records a new running restore of snapshot. start returns false if a restore is already running.
+func (t *etcdRestoreTracker) start(snapshot string) (k8sdapi.EtcdRestoreStatusResponse, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status.State == k8sdapi.EtcdRestoreStateRunning {
+		return t.copyStatus(), false
+	}
+	t.status = k8sdapi.EtcdRestoreStatusResponse{
+		Snapshot:  snapshot,
+		State:     k8sdapi.EtcdRestoreStateRunning,
+		StartedAt: time.Now().UTC().Truncate(time.Second),
+		Failed:    map[string]string{},
+	}
+	return t.copyStatus(), true
+}
+
+// get returns the status of the last restore.
+func (t *etcdRestoreTracker) get() k8sdapi.EtcdRestoreStatusResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.copyStatus()
+}
+
+// update updates the status of the running restore.
+func (t *etcdRestoreTracker) update(f func(status *k8sdapi.EtcdRestoreStatusResponse)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f(&t.status)
+}
+
+// step records and logs the current step of the running restore.
+func (t *etcdRestoreTracker) step(ctx context.Context, step string) {
+	log.FromContext(ctx).Info(step)
+	t.update(func(status *k8sdapi.EtcdRestoreStatusResponse) { status.Step = step })
+}
+
+func (t *etcdRestoreTracker) copyStatus() k8sdapi.EtcdRestoreStatusResponse {
+	status := t.status
+	status.Rejoined = slices.Clone(t.status.Rejoined)
+	status.Failed = maps.Clone(t.status.Failed)
+	return status
+}
+
+// etcdMemberOperations tracks the restores and rejoins in progress on the node, along with the other control plane
+// nodes whose etcd members they reset or rejoin. Those nodes check with this node that the operation is in progress
+// before they touch their etcd member, see postEtcdLocalMember.
+type etcdMemberOperations struct {
+	mu    sync.Mutex
+	nodes map[string][]string
+}
+
+// etcdMemberOperation identifies a restore or rejoin in progress on the coordinating node.
+type etcdMemberOperation struct {
+	coordinator string
+	id          string
+}
+
+// request returns the EtcdLocalMember request to perform action as part of the operation.
+func (op etcdMemberOperation) request(action k8sdapi.EtcdLocalMemberAction) k8sdapi.EtcdLocalMemberRequest {
+	return k8sdapi.EtcdLocalMemberRequest{Action: action, Coordinator: op.coordinator, OperationID: op.id}
+}
+
+// start records a new operation of the local node on the etcd members of nodes. The operation must be finished with
+// done.
+func (o *etcdMemberOperations) start(localName string, nodes []string) (etcdMemberOperation, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return etcdMemberOperation{}, fmt.Errorf("is the system entropy low? failed to get random bytes: %w", err)
+	}
+	id := hex.EncodeToString(b)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.nodes == nil {
+		o.nodes = map[string][]string{}
+	}
+	o.nodes[id] = slices.Clone(nodes)
+	return etcdMemberOperation{coordinator: localName, id: id}, nil
+}
+
+// done removes a finished operation.
+func (o *etcdMemberOperations) done(op etcdMemberOperation) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.nodes, op.id)
+}
+
+// inProgress returns true if the operation is in progress and includes the etcd member of node.
+func (o *etcdMemberOperations) inProgress(id string, node string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return slices.Contains(o.nodes[id], node)
+}
+
+func (e *Endpoints) getEtcdMemberOperation(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.EtcdMemberOperationRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+	return mctypes.SyncResponse(true, k8sdapi.EtcdMemberOperationResponse{InProgress: e.etcdOperations.inProgress(req.OperationID, req.Node)})
+}
+
+func (e *Endpoints) getEtcdRestore(s mctypes.State, r *http.Request) mctypes.Response {
+	return mctypes.SyncResponse(true, e.etcdRestore.get())
+}
+
+// postEtcdRestore starts to rebuild the managed etcd cluster from a stored snapshot, see restoreEtcd.
+// The restore runs in the background, and its status is returned by getEtcdRestore. Only one restore can run at a time.
+func (e *Endpoints) postEtcdRestore(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.RestoreEtcdRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+	if req.Snapshot == "" {
+		return mctypes.BadRequest(fmt.Errorf("snapshot cannot be empty"))
+	}
+	// NOTE: Only stored snapshots can be restored, so that the request cannot point k8sd at arbitrary files.
+	if !etcdsnapshot.ValidName(req.Snapshot) {
+		return mctypes.BadRequest(fmt.Errorf("invalid snapshot name %q, see \"k8s x-etcd snapshots\" for the stored snapshots", req.Snapshot))
+	}
+
+	cfg, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to get cluster config: %w", err))
+	}
+	if datastore := cfg.Datastore.GetType(); datastore != "etcd" {
+		return mctypes.BadRequest(fmt.Errorf("the cluster uses the %q datastore, not the managed etcd", datastore))
+	}
+	snap := e.provider.Snap()
+	store, _, err := etcdSnapshotStore(snap, cfg)
+	if err != nil {
+		return mctypes.InternalError(err)
+	}
+
+	// NOTE: Without an S3 bucket, the snapshot must be restored on the control plane node that stores it.
+	snapshots, _, err := listEtcdSnapshots(r.Context(), snap, s.Name(), store)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to list etcd snapshots: %w", err))
+	}
+	idx := slices.IndexFunc(snapshots, func(snapshot k8sdapi.EtcdSnapshot) bool { return snapshot.Name == req.Snapshot })
+	if idx < 0 {
+		return mctypes.ErrorResponse(http.StatusNotFound, fmt.Sprintf("snapshot %q not found, see \"k8s x-etcd snapshots\" for the stored snapshots", req.Snapshot))
+	}
+	if node := snapshots[idx].Node; node != "" && node != s.Name() {
+		return mctypes.BadRequest(fmt.Errorf("snapshot %q is stored on node %s, restore it from that node", req.Snapshot, node))
+	}
+
+	status, ok := e.etcdRestore.start(req.Snapshot)
+	if !ok {
+		return mctypes.ErrorResponse(http.StatusConflict, fmt.Sprintf("a restore of snapshot %q is already running", status.Snapshot))
+	}
+
+	// NOTE: The restore outlives the request, so it runs with the context of the API server.
+	ctx := log.NewContext(e.Context(), log.FromContext(r.Context()).WithValues("snapshot", req.Snapshot))
+	go func() {
+		err := e.restoreEtcd(ctx, s, cfg, store, req.Snapshot)
+		e.etcdRestore.update(func(status *k8sdapi.EtcdRestoreStatusResponse) {
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Failed to restore etcd from snapshot")
+				status.State = k8sdapi.EtcdRestoreStateFailed
+				status.Error = err.Error()
+				return
+			}
+			status.State = k8sdapi.EtcdRestoreStateSucceeded
+		})
+	}()
+
+	return mctypes.SyncResponse(true, status)
+}
+
+// restoreEtcd rebuilds the managed etcd cluster from a stored snapshot.
+// The snapshot is fetched, verified and restored into a staging data directory first, so that the restore is
+// aborted before any etcd member is touched if the snapshot is not usable. The etcd members of the other control
+// plane nodes are then reset, so that they do not form a cluster with the old data. The restore is aborted if any of
+// them cannot be reset. The local member is then restored from the staging data directory as a single-member
+// cluster, and the other control plane nodes rejoin it one after another, as learners that are promoted once they
+// have caught up. Nodes that fail to rejoin are recorded in the status of the restore.
+func (e *Endpoints) restoreEtcd(ctx context.Context, s mctypes.State, cfg types.ClusterConfig, store etcdsnapshot.Store, name string) error {
+	snap := e.provider.Snap()
+	tracker := &e.etcdRestore
+
+	tracker.step(ctx, "Fetching the snapshot")
+	file := filepath.Join(etcdSnapshotDir(snap), ".restore.tmp")
+	defer os.Remove(file)
+	if err := fetchEtcdSnapshot(ctx, store, name, file); err != nil {
+		return fmt.Errorf("failed to fetch snapshot: %w", err)
+	}
+
+	tracker.step(ctx, "Verifying the snapshot")
+	if err := etcdsnapshot.Verify(file); err != nil {
+		return fmt.Errorf("failed to verify snapshot: %w", err)
+	}
+
+	tracker.step(ctx, "Finding the control plane nodes")
+	members, err := etcdRestoreMembers(ctx, snap, s.Name())
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.Name)
+	}
+	op, err := e.etcdOperations.start(s.Name(), names)
+	if err != nil {
+		return err
+	}
+	defer e.etcdOperations.done(op)
+
+	tracker.step(ctx, "Restoring the snapshot into a staging data directory")
+	stagingDir, err := stageEtcdRestore(ctx, snap, file)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	var reset []string
+	for _, member := range members {
+		tracker.step(ctx, fmt.Sprintf("Resetting the etcd member of node %s", member.Name))
+		remote, err := snap.K8sdClient(member.Address.String())
+		if err == nil {
+			err = remote.EtcdLocalMember(ctx, op.request(k8sdapi.EtcdLocalMemberActionReset))
+		}
+		if err != nil {
+			if len(reset) > 0 {
+				return fmt.Errorf("failed to reset the etcd member of node %s, after the etcd members of nodes %s were reset and their data directories moved aside: %w", member.Name, strings.Join(reset, ", "), err)
+			}
+			return fmt.Errorf("failed to reset the etcd member of node %s: %w", member.Name, err)
+		}
+		reset = append(reset, member.Name)
+	}
+
+	tracker.step(ctx, "Replacing the local etcd data directory")
+	if err := swapEtcdRestore(ctx, snap, stagingDir); err != nil {
+		return err
+	}
+
+	etcdClient, err := snap.EtcdClient([]string{fmt.Sprintf("https://%s", utils.JoinHostPort("127.0.0.1", cfg.Datastore.GetEtcdPort()))})
+	if err != nil {
+		return fmt.Errorf("failed to create etcd client: %w", err)
+	}
+	defer etcdClient.Close()
+
+	for _, member := range members {
+		tracker.step(ctx, fmt.Sprintf("Rejoining the etcd member of node %s", member.Name))
+		peerURL := fmt.Sprintf("https://%s", utils.JoinHostPort(member.Address.Addr().String(), cfg.Datastore.GetEtcdPeerPort()))
+		err := rejoinEtcdMember(ctx, snap, etcdClient, op, member.Name, member.Address.String(), peerURL)
+		tracker.update(func(status *k8sdapi.EtcdRestoreStatusResponse) {
+			if err != nil {
+				status.Failed[member.Name] = err.Error()
+				return
+			}
+			status.Rejoined = append(status.Rejoined, member.Name)
+		})
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to rejoin etcd member", "node", member.Name)
+		}
+	}
+
+	tracker.step(ctx, "Restored the etcd cluster")
+	return nil
+}
+
+// etcdRestoreMembers returns the other control plane nodes of the cluster, which run the other etcd members, ordered
+// by name. Worker nodes are skipped. etcdRestoreMembers fails if the role of any node cannot be determined, so that
+// a restore is not started while a control plane node is unreachable.
+func etcdRestoreMembers(ctx context.Context, snap snap.Snap, localName string) ([]mctypes.ClusterMember, error) {
+	client, err := snap.K8sdClient("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+	members, err := client.GetClusterMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster members: %w", err)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+
+	var controlPlane []mctypes.ClusterMember
+	for _, member := range members {
+		if member.Name == localName {
+			continue
+		}
+		remote, err := snap.K8sdClient(member.Address.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create k8sd client for node %s: %w", member.Name, err)
+		}
+		status, initialized, err := remote.NodeStatus(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the status of node %s: %w", member.Name, err)
+		}
+		if !initialized {
+			return nil, fmt.Errorf("node %s is not initialized", member.Name)
+		}
+		if status.NodeStatus.ClusterRole == apiv2.ClusterRoleWorker {
+			continue
+		}
+		controlPlane = append(controlPlane, member)
+	}
+	return controlPlane, nil
+}
+
+// postEtcdLocalMember resets or rejoins the local etcd member, as requested by the node that restores the cluster or
+// rejoins the member. The request is refused unless that node confirms that the restore or rejoin is in progress and
+// includes this node, since resetting the member wipes its data directory.
+func (e *Endpoints) postEtcdLocalMember(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.EtcdLocalMemberRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+	if req.Coordinator == "" || req.OperationID == "" {
+		return mctypes.BadRequest(fmt.Errorf("coordinator and operation ID are required"))
+	}
+	if req.Coordinator == s.Name() {
+		return mctypes.BadRequest(fmt.Errorf("the coordinator cannot be the node itself"))
+	}
+
+	snap := e.provider.Snap()
+	client, err := snap.K8sdClient("")
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to create k8sd client: %w", err))
+	}
+	coordinator, err := client.GetClusterMember(r.Context(), req.Coordinator)
+	if err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to get cluster member %s: %w", req.Coordinator, err))
+	}
+	remote, err := snap.K8sdClient(coordinator.Address.String())
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to create k8sd client for node %s: %w", req.Coordinator, err))
+	}
+	op, err := remote.EtcdMemberOperation(r.Context(), k8sdapi.EtcdMemberOperationRequest{OperationID: req.OperationID, Node: s.Name()})
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to check the operation with node %s: %w", req.Coordinator, err))
+	}
+	if !op.InProgress {
+		return mctypes.Forbidden(fmt.Errorf("node %s has no restore or rejoin of the etcd member of this node in progress", req.Coordinator))
+	}
+
+	log.FromContext(r.Context()).Info("Updating local etcd member", "action", req.Action, "coordinator", req.Coordinator)
+	switch req.Action {
+	case k8sdapi.EtcdLocalMemberActionReset:
+		if err := resetLocalEtcdMember(r.Context(), snap); err != nil {
+			return mctypes.InternalError(err)
+		}
+	case k8sdapi.EtcdLocalMemberActionRejoin:
+		if len(req.InitialCluster) == 0 {
+			return mctypes.BadRequest(fmt.Errorf("initial cluster cannot be empty"))
+		}
+		if err := startLocalEtcdMember(r.Context(), snap, req.InitialCluster); err != nil {
+			return mctypes.InternalError(err)
+		}
+	default:
+		return mctypes.BadRequest(fmt.Errorf("unknown action %q", req.Action))
+	}
+	return mctypes.SyncResponse(true, nil)
+}
+
+// fetchEtcdSnapshot copies a stored snapshot to file.
+func fetchEtcdSnapshot(ctx context.Context, store etcdsnapshot.Store, name string, file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	r, err := store.Open(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", file, err)
+	}
+	return nil
+}
+
+// resetLocalEtcdMember stops the local etcd member and moves its data directory aside, so that it can be restored
+// from a snapshot or rejoin a cluster.
+func resetLocalEtcdMember(ctx context.Context, snap snap.Snap) error {
+	if err := snaputil.StopEtcdServices(ctx, snap); err != nil {
+		return err
+	}
+	dataDir, err := snaputil.GetServiceArgument(snap, "etcd", "--data-dir")
+	if err != nil {
+		return fmt.Errorf("failed to get etcd data directory: %w", err)
+	}
+	if dataDir == "" {
+		return fmt.Errorf("etcd data directory is not configured")
+	}
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		return nil
+	}
+	backupDir := fmt.Sprintf("%s.%s.bak", dataDir, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.Rename(dataDir, backupDir); err != nil {
+		return fmt.Errorf("failed to move etcd data directory to %s: %w", backupDir, err)
+	}
+	log.FromContext(ctx).Info("Moved etcd data directory aside", "backup", backupDir)
+	return nil
+}
+
+// stageEtcdRestore restores a snapshot into a staging data directory next to the data directory of the local etcd
+// member, as the only member of a new cluster. The local member keeps running. stageEtcdRestore returns the staging
+// data directory, which is swapped in with swapEtcdRestore.
+// NOTE: The snapshot is restored with etcdutl, which is shipped with the etcd binaries of the snap. etcdutl also
+// checks the integrity hash of the snapshot.
+func stageEtcdRestore(ctx context.Context, snap snap.Snap, file string) (string, error) {
+	args := map[string]string{}
+	for _, arg := range []string{"--name", "--data-dir", "--initial-advertise-peer-urls"} {
+		value, err := snaputil.GetServiceArgument(snap, "etcd", arg)
+		if err != nil {
+			return "", fmt.Errorf("failed to get etcd argument %s: %w", arg, err)
+		}
+		if value == "" {
+			return "", fmt.Errorf("etcd argument %s is not configured", arg)
+		}
+		args[arg] = value
+	}
+	initialCluster := fmt.Sprintf("%s=%s", args["--name"], args["--initial-advertise-peer-urls"])
+
+	// NOTE: etcdutl refuses to restore into an existing directory, e.g. one left behind by an interrupted restore.
+	stagingDir := fmt.Sprintf("%s.restore", args["--data-dir"])
+	if err := os.RemoveAll(stagingDir); err != nil {
+		return "", fmt.Errorf("failed to remove staging directory %s: %w", stagingDir, err)
+	}
+
+	cmd := exec.CommandContext(ctx, filepath.Join(snap.K8sBinDir(), "etcdutl"), "snapshot", "restore", file,
+		"--name", args["--name"],
+		"--data-dir", stagingDir,
+		"--initial-advertise-peer-urls", args["--initial-advertise-peer-urls"],
+		"--initial-cluster", initialCluster,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(stagingDir)
+		return "", fmt.Errorf("etcdutl snapshot restore failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return stagingDir, nil
+}
+
+// swapEtcdRestore stops the local etcd member, moves its data directory aside and replaces it with the staging data
+// directory of stageEtcdRestore. The local member is then started as the only member of a new cluster.
+func swapEtcdRestore(ctx context.Context, snap snap.Snap, stagingDir string) error {
+	if err := resetLocalEtcdMember(ctx, snap); err != nil {
+		return err
+	}
+	dataDir, err := snaputil.GetServiceArgument(snap, "etcd", "--data-dir")
+	if err != nil {
+		return fmt.Errorf("failed to get etcd data directory: %w", err)
+	}
+	if err := os.Rename(stagingDir, dataDir); err != nil {
+		return fmt.Errorf("failed to move restored data directory to %s: %w", dataDir, err)
+	}
+	return startLocalEtcdMember(ctx, snap, nil)
+}
+
+// startLocalEtcdMember starts the local etcd member with the given initial cluster (peer URLs by name), and restarts
+// kube-apiserver so that it does not serve stale data from its cache. The local member is added to the initial
+// cluster. If initialCluster is empty, the local member starts a new cluster.
+func startLocalEtcdMember(ctx context.Context, snap snap.Snap, initialCluster map[string]string) error {
+	name, err := snaputil.GetServiceArgument(snap, "etcd", "--name")
+	if err != nil {
+		return fmt.Errorf("failed to get etcd member name: %w", err)
+	}
+	peerURL, err := snaputil.GetServiceArgument(snap, "etcd", "--initial-advertise-peer-urls")
+	if err != nil {
+		return fmt.Errorf("failed to get etcd peer URL: %w", err)
+	}
+
+	clusterState := "new"
+	if len(initialCluster) > 0 {
+		clusterState = "existing"
+		// NOTE: The data directory must be empty to join an existing cluster.
+		if err := resetLocalEtcdMember(ctx, snap); err != nil {
+			return err
+		}
+	}
+	members := []string{fmt.Sprintf("%s=%s", name, peerURL)}
+	for memberName, memberPeerURL := range initialCluster {
+		if memberName != name {
+			members = append(members, fmt.Sprintf("%s=%s", memberName, memberPeerURL))
+		}
+	}
+	sort.Strings(members)
+
+	if _, err := snaputil.UpdateServiceArguments(snap, "etcd", map[string]string{
+		"--initial-cluster":       strings.Join(members, ","),
+		"--initial-cluster-state": clusterState,
+	}, nil); err != nil {
+		return fmt.Errorf("failed to update etcd arguments: %w", err)
+	}
+
+	if err := snaputil.StartEtcdServices(ctx, snap); err != nil {
+		return err
+	}
+	if err := snap.RestartServices(ctx, []string{"kube-apiserver"}); err != nil {
+		return fmt.Errorf("failed to restart kube-apiserver: %w", err)
+	}
+	return nil
+}
+
+// rejoinEtcdMember adds the etcd member of a control plane node to the cluster as a learner, asks the node to start
+// it, and promotes it once it has caught up with the leader.
+func rejoinEtcdMember(ctx context.Context, snap snap.Snap, etcdClient *etcd.Client, op etcdMemberOperation, name string, address string, peerURL string) error {
+	resp, err := etcdClient.MemberAddAsLearner(ctx, []string{peerURL})
+	if err != nil {
+		return fmt.Errorf("failed to add etcd member: %w", err)
+	}
+
+	// NOTE: Members that have not started yet have no name, and are excluded from the initial cluster.
+	initialCluster := map[string]string{name: peerURL}
+	for _, member := range resp.Members {
+		if member.Name != "" && len(member.PeerURLs) > 0 {
+			initialCluster[member.Name] = member.PeerURLs[0]
+		}
+	}
+
+	remote, err := snap.K8sdClient(address)
+	if err != nil {
+		return fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+	request := op.request(k8sdapi.EtcdLocalMemberActionRejoin)
+	request.InitialCluster = initialCluster
+	if err := remote.EtcdLocalMember(ctx, request); err != nil {
+		return fmt.Errorf("failed to rejoin etcd member: %w", err)
+	}
+
+	if err := control.RetryFor(ctx, 30, 2*time.Second, func() error {
+		return etcdClient.PromoteMember(ctx, name)
+	}); err != nil {
+		return fmt.Errorf("failed to promote etcd member: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	. "github.com/onsi/gomega"
+)
+
+func TestEtcdRestoreTracker(t *testing.T) {
+	g := NewWithT(t)
+
+	var tracker etcdRestoreTracker
+	g.Expect(tracker.get()).To(BeZero())
+
+	status, ok := tracker.start("etcd-snapshot-cp1-20261016T120000Z.db")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(status.State).To(Equal(k8sdapi.EtcdRestoreStateRunning))
+
+	// only one restore runs at a time
+	status, ok = tracker.start("etcd-snapshot-cp1-20261017T120000Z.db")
+	g.Expect(ok).To(BeFalse())
+	g.Expect(status.Snapshot).To(Equal("etcd-snapshot-cp1-20261016T120000Z.db"))
+
+	tracker.update(func(status *k8sdapi.EtcdRestoreStatusResponse) {
+		status.Rejoined = append(status.Rejoined, "cp2")
+		status.Failed["cp3"] = "connection refused"
+	})
+	status = tracker.get()
+	g.Expect(status.Rejoined).To(Equal([]string{"cp2"}))
+	g.Expect(status.Failed).To(Equal(map[string]string{"cp3": "connection refused"}))
+
+	// the returned status is a copy
+	status.Failed["cp4"] = "modified"
+	g.Expect(tracker.get().Failed).ToNot(HaveKey("cp4"))
+
+	tracker.update(func(status *k8sdapi.EtcdRestoreStatusResponse) { status.State = k8sdapi.EtcdRestoreStateSucceeded })
+	status, ok = tracker.start("etcd-snapshot-cp1-20261017T120000Z.db")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(status.Rejoined).To(BeEmpty())
+	g.Expect(status.Failed).To(BeEmpty())
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/database"
+	"github.com/canonical/k8sd/pkg/k8sd/features"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+)
+
+func (e *Endpoints) getFeatureStatusHistory(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.FeatureStatusHistoryRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+
+	name := types.FeatureName(req.Feature)
+	if _, ok := features.Get(name); !ok {
+		if _, ok := features.HelmAddonFromStatusName(name); !ok {
+			return mctypes.BadRequest(fmt.Errorf("unknown feature %q", req.Feature))
+		}
+	}
+
+	var entries []types.FeatureStatusHistoryEntry
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		entries, err = database.GetFeatureStatusHistory(ctx, tx, name, req.Limit)
+		if err != nil {
+			return fmt.Errorf("failed to get feature status history: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("database transaction failed: %w", err))
+	}
+
+	response := k8sdapi.FeatureStatusHistoryResponse{Entries: make([]k8sdapi.FeatureStatusHistoryEntry, 0, len(entries))}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, entry.ToAPI())
+	}
+
+	return mctypes.SyncResponse(true, &response)
+}
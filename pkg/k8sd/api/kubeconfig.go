@@ -1,21 +1,37 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
+	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/database"
 	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
+	"github.com/canonical/k8sd/pkg/k8sd/pki"
 	"github.com/canonical/k8sd/pkg/k8sd/setup"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/log"
 	"github.com/canonical/k8sd/pkg/utils"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
 
+// defaultKubeconfigTTL is the validity of the client certificate of user kubeconfigs, if no TTL is requested.
+const defaultKubeconfigTTL = 24 * time.Hour
+
+// getKubeconfig returns a kubeconfig with the admin client certificate of the cluster.
 func (e *Endpoints) getKubeconfig(s mctypes.State, r *http.Request) mctypes.Response {
-	req := apiv2.KubeConfigRequest{}
+	req := k8sdapi.KubeConfigRequest{}
 	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
 		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
 	}
+	// NOTE: Issuing a client certificate changes the state of the cluster, which GET requests must not do.
+	if req.User != "" || len(req.Groups) > 0 || req.TTL != 0 {
+		return mctypes.BadRequest(fmt.Errorf("kubeconfigs with a dedicated client certificate must be requested with POST"))
+	}
 
 	// Fetch pieces needed to render an admin kubeconfig: ca, server, token
 	config, err := databaseutil.GetClusterConfig(r.Context(), s)
@@ -32,7 +48,104 @@ func (e *Endpoints) getKubeconfig(s mctypes.State, r *http.Request) mctypes.Resp
 		return mctypes.InternalError(fmt.Errorf("failed to get kubeconfig: %w", err))
 	}
 
-	return mctypes.SyncResponse(true, &apiv2.KubeConfigResponse{
-		KubeConfig: kubeconfig,
+	return mctypes.SyncResponse(true, &k8sdapi.KubeConfigResponse{
+		KubeConfigResponse: apiv2.KubeConfigResponse{KubeConfig: kubeconfig},
+	})
+}
+
+// postKubeconfig issues a client certificate for the requested user and returns a kubeconfig that uses it.
+// The issued certificate is recorded, so that it can be audited and revoked.
+func (e *Endpoints) postKubeconfig(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.KubeConfigRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+	if req.TTL < 0 {
+		return mctypes.BadRequest(fmt.Errorf("ttl cannot be negative"))
+	}
+	// NOTE: The default user is in the system:masters group, so extra groups would not restrict its permissions.
+	if req.User == "" && len(req.Groups) > 0 {
+		return mctypes.BadRequest(fmt.Errorf("groups cannot be set without a user"))
+	}
+
+	config, err := databaseutil.GetClusterConfig(r.Context(), s)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to retrieve cluster config: %w", err))
+	}
+	server := req.Server
+	if req.Server == "" {
+		server = fmt.Sprintf("%s:%d", s.Address().Hostname(), config.APIServer.GetSecurePort())
+	}
+
+	user, groups, ttl := req.User, req.Groups, req.TTL
+	if user == "" {
+		user, groups = "kubernetes-admin", []string{"system:masters"}
+	}
+	if ttl == 0 {
+		ttl = defaultKubeconfigTTL
+	}
+
+	notBefore := time.Now()
+	certificates := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(ttl),
+		KeyAlgorithm: config.KeyAlgorithm(),
+	})
+	certificates.ClientCACert = config.Certificates.GetClientCACert()
+	certificates.ClientCAKey = config.Certificates.GetClientCAKey()
+	cert, key, err := certificates.GenerateUserClientCertificate(user, groups)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to generate client certificate: %w", err))
+	}
+
+	issued, err := types.NewIssuedCertificate("", "kubeconfig", cert)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to parse client certificate: %w", err))
+	}
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		return database.RecordIssuedCertificate(ctx, tx, issued)
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to record client certificate: %w", err))
+	}
+	log.FromContext(r.Context()).Info("Issued kubeconfig", "user", user, "groups", groups, "serial", issued.Serial, "expires", issued.NotAfter)
+
+	kubeconfig, err := setup.KubeconfigString(server, config.Certificates.GetCACert(), cert, key)
+	if err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to get kubeconfig: %w", err))
+	}
+
+	return mctypes.SyncResponse(true, &k8sdapi.KubeConfigResponse{
+		KubeConfigResponse: apiv2.KubeConfigResponse{KubeConfig: kubeconfig},
+		Serial:             issued.Serial,
+		ExpiresAt:          issued.NotAfter,
 	})
 }
+
+func (e *Endpoints) postRevokeKubeconfig(s mctypes.State, r *http.Request) mctypes.Response {
+	req := k8sdapi.RevokeKubeConfigRequest{}
+	if err := utils.NewStrictJSONDecoder(r.Body).Decode(&req); err != nil {
+		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
+	}
+	if req.Serial == "" {
+		return mctypes.BadRequest(fmt.Errorf("serial cannot be empty"))
+	}
+	serial, ok := types.NormalizeSerial(req.Serial)
+	if !ok {
+		return mctypes.BadRequest(fmt.Errorf("serial %q is not a hex number", req.Serial))
+	}
+
+	var revoked bool
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		revoked, err = database.RevokeCertificate(ctx, tx, serial)
+		return err
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to revoke client certificate: %w", err))
+	}
+	if !revoked {
+		return mctypes.BadRequest(fmt.Errorf("no unrevoked certificate with serial %q was issued", req.Serial))
+	}
+	log.FromContext(r.Context()).Info("Revoked kubeconfig", "serial", serial)
+
+	return mctypes.SyncResponse(true, nil)
+}
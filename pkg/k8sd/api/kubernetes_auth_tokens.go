@@ -7,34 +7,90 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
 	"github.com/canonical/k8sd/pkg/k8sd/database"
 	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/log"
 	"github.com/canonical/k8sd/pkg/utils"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
 
 func (e *Endpoints) postKubernetesAuthTokens(s mctypes.State, r *http.Request) mctypes.Response {
-	request := apiv2.GenerateKubernetesAuthTokenRequest{}
+	request := k8sdapi.GenerateKubernetesAuthTokenRequest{}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
 	}
+	if request.TTL < 0 {
+		return mctypes.BadRequest(fmt.Errorf("ttl cannot be negative"))
+	}
 
-	token, err := databaseutil.GetOrCreateAuthToken(r.Context(), s, request.Username, request.Groups)
-	if err != nil {
-		return mctypes.InternalError(err)
+	// NOTE: Tokens are stored as salted hashes and cannot be returned again, so every request creates a new token.
+	var expiry time.Time
+	if request.TTL > 0 {
+		expiry = time.Now().Add(request.TTL)
+	}
+	var token string
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		token, err = database.CreateToken(ctx, tx, request.Username, request.Groups, request.Description, expiry)
+		return err
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to create auth token: %w", err))
 	}
 
 	return mctypes.SyncResponse(true, apiv2.GenerateKubernetesAuthTokenResponse{Token: token})
 }
 
+func (e *Endpoints) getKubernetesAuthTokens(s mctypes.State, r *http.Request) mctypes.Response {
+	var tokens []types.KubernetesAuthToken
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		tokens, err = database.ListTokens(ctx, tx)
+		return err
+	}); err != nil {
+		return mctypes.InternalError(fmt.Errorf("failed to list auth tokens: %w", err))
+	}
+
+	response := k8sdapi.ListKubernetesAuthTokensResponse{Tokens: make([]k8sdapi.KubernetesAuthToken, 0, len(tokens))}
+	for _, token := range tokens {
+		response.Tokens = append(response.Tokens, k8sdapi.KubernetesAuthToken{
+			ID:          token.ID,
+			Username:    token.Username,
+			Groups:      token.Groups,
+			Description: token.Description,
+			CreatedAt:   token.CreatedAt,
+			ExpiresAt:   token.ExpiresAt,
+			LastUsedAt:  token.LastUsedAt,
+		})
+	}
+	return mctypes.SyncResponse(true, response)
+}
+
 func (e *Endpoints) deleteKubernetesAuthTokens(s mctypes.State, r *http.Request) mctypes.Response {
-	request := apiv2.RevokeKubernetesAuthTokenRequest{}
+	request := k8sdapi.RevokeKubernetesAuthTokenRequest{}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		return mctypes.BadRequest(fmt.Errorf("failed to parse request: %w", err))
 	}
 
+	if request.Token == "" && request.ID != 0 {
+		var deleted bool
+		if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+			var err error
+			deleted, err = database.DeleteTokenByID(ctx, tx, request.ID)
+			return err
+		}); err != nil {
+			return mctypes.InternalError(fmt.Errorf("failed to revoke auth token: %w", err))
+		}
+		if !deleted {
+			return mctypes.BadRequest(fmt.Errorf("no auth token with id %d", request.ID))
+		}
+		return mctypes.SyncResponse(true, nil)
+	}
+
 	err := databaseutil.RevokeAuthToken(r.Context(), s, request.Token)
 	if err != nil {
 		return mctypes.InternalError(fmt.Errorf("failed to revoke auth token: %w", err))
@@ -88,6 +144,13 @@ func (e *Endpoints) postKubernetesAuthWebhook(s mctypes.State, r *http.Request)
 		return utils.JSONResponse(http.StatusUnauthorized, review)
 	}
 
+	// NOTE: Failing to record the last use of the token must not fail authentication.
+	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		return database.RecordTokenUse(ctx, tx, review.Spec.Token)
+	}); err != nil {
+		log.FromContext(r.Context()).Error(err, "Failed to record last use of auth token", "username", username)
+	}
+
 	review.Status = apiv2.TokenReviewStatus{
 		Audiences:     review.Spec.Audiences,
 		Authenticated: true,
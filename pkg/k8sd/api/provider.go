@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/snap"
 	"github.com/canonical/microcluster/v3/microcluster"
 )
@@ -10,5 +11,5 @@ type Provider interface {
 	MicroCluster() *microcluster.MicroCluster
 	Snap() snap.Snap
 	NotifyUpdateNodeConfigController()
-	NotifyFeatureController(network, gateway, ingress, loadBalancer, localStorage, metricsServer, dns bool)
+	NotifyFeatureController(features ...types.FeatureName)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/canonical/k8sd/pkg/k8sd/database"
 	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
 	"github.com/canonical/k8sd/pkg/k8sd/pki"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/utils"
 	"github.com/canonical/k8sd/pkg/utils/control"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
@@ -42,12 +43,17 @@ func (e *Endpoints) postWorkerInfo(s mctypes.State, r *http.Request) mctypes.Res
 	notBefore := time.Now()
 
 	// NOTE: Default certificate expiration is set to 10 years.
-	certificates := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{NotBefore: notBefore, NotAfter: notBefore.AddDate(10, 0, 0)})
+	certificates := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.AddDate(10, 0, 0),
+		KeyAlgorithm: cfg.KeyAlgorithm(),
+		Profiles:     cfg.CertificateProfiles(),
+	})
 	certificates.CACert = cfg.Certificates.GetCACert()
 	certificates.CAKey = cfg.Certificates.GetCAKey()
 	certificates.ClientCACert = cfg.Certificates.GetClientCACert()
 	certificates.ClientCAKey = cfg.Certificates.GetClientCAKey()
-	workerCertificates, err := certificates.CompleteWorkerNodePKI(workerName, nodeIP, 2048)
+	workerCertificates, err := certificates.CompleteWorkerNodePKI(workerName, nodeIP)
 	if err != nil {
 		return mctypes.InternalError(fmt.Errorf("failed to generate worker PKI: %w", err))
 	}
@@ -80,8 +86,30 @@ func (e *Endpoints) postWorkerInfo(s mctypes.State, r *http.Request) mctypes.Res
 		return mctypes.InternalError(fmt.Errorf("failed after retry: %w", err))
 	}
 
+	// NOTE: Record the issued certificates, so that they are revoked when the node is removed.
+	var issued []types.IssuedCertificate
+	for _, certificate := range []struct {
+		name    string
+		certPEM string
+	}{
+		{name: "kubelet", certPEM: workerCertificates.KubeletCert},
+		{name: "kubelet.conf", certPEM: workerCertificates.KubeletClientCert},
+		{name: "proxy.conf", certPEM: workerCertificates.KubeProxyClientCert},
+	} {
+		issuedCertificate, err := types.NewIssuedCertificate(workerName, certificate.name, certificate.certPEM)
+		if err != nil {
+			return mctypes.InternalError(fmt.Errorf("failed to record issued certificate: %w", err))
+		}
+		issued = append(issued, issuedCertificate)
+	}
+
 	workerToken := r.Header.Get("Worker-Token")
 	if err := s.Database().Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+		for _, certificate := range issued {
+			if err := database.RecordIssuedCertificate(ctx, tx, certificate); err != nil {
+				return fmt.Errorf("failed to record issued certificate %s: %w", certificate.Name, err)
+			}
+		}
 		return database.DeleteWorkerNodeToken(ctx, tx, workerToken)
 	}); err != nil {
 		return mctypes.InternalError(fmt.Errorf("delete worker node token transaction failed: %w", err))
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
@@ -14,12 +15,14 @@ import (
 	"github.com/canonical/k8sd/pkg/k8sd/controllers/upgrade"
 	"github.com/canonical/k8sd/pkg/k8sd/database"
 	"github.com/canonical/k8sd/pkg/k8sd/features"
+	"github.com/canonical/k8sd/pkg/k8sd/metrics"
 	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/log"
 	"github.com/canonical/k8sd/pkg/snap"
 	"github.com/canonical/k8sd/pkg/utils/control"
 	"github.com/canonical/microcluster/v3/microcluster"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Config defines configuration for the k8sd app.
@@ -38,6 +41,8 @@ type Config struct {
 	Snap snap.Snap
 	// PprofAddress is the address to listen for pprof debug endpoints. Empty to disable.
 	PprofAddress string
+	// MetricsAddress is the address to listen for the Prometheus metrics endpoint. Empty to disable.
+	MetricsAddress string
 	// DisableNodeConfigController is a bool flag to disable node config controller
 	DisableNodeConfigController bool
 	// NodeConfigControllerWatchDuration specifies how long watch is in progress before getting
@@ -68,6 +73,27 @@ type Config struct {
 	// ServiceArgsControllerCheckInterval is the interval at which the service args controller checks for
 	// argument drift between the args file and the running process. Should be greater than 30 seconds.
 	ServiceArgsControllerCheckInterval time.Duration
+	// DisableCertificateRotationController is a bool flag to disable the certificate rotation controller.
+	DisableCertificateRotationController bool
+	// CertificateRotationCheckInterval is the interval at which the certificate rotation controller checks
+	// the expiry of the node certificates. Should be greater than 30 seconds.
+	CertificateRotationCheckInterval time.Duration
+	// CertificateRotationThreshold is the fraction of their lifetime below which certificates are renewed automatically.
+	CertificateRotationThreshold float64
+	// CertificateRotationValidity is the validity of automatically renewed certificates.
+	CertificateRotationValidity time.Duration
+	// DisableCertificateExpiryController is a bool flag to disable the certificate expiry controller.
+	DisableCertificateExpiryController bool
+	// CertificateExpiryCheckInterval is the interval at which the certificate expiry controller updates the
+	// certificate metrics. Should be greater than 30 seconds.
+	CertificateExpiryCheckInterval time.Duration
+	// CertificateExpiryWarningWindow is the remaining validity below which warning events are recorded on the node.
+	CertificateExpiryWarningWindow time.Duration
+	// DisableEtcdSnapshotController is a bool flag to disable the etcd snapshot controller.
+	DisableEtcdSnapshotController bool
+	// EtcdSnapshotCheckInterval is the interval at which the etcd snapshot controller checks whether a scheduled
+	// etcd snapshot is due. Should be greater than 30 seconds.
+	EtcdSnapshotCheckInterval time.Duration
 }
 
 // App is the k8sd microcluster instance.
@@ -79,6 +105,8 @@ type App struct {
 
 	// profilingAddress
 	profilingAddress string
+	// metricsAddress
+	metricsAddress string
 
 	// readyWg is used to denote that the microcluster node is now running
 	readyWg sync.WaitGroup
@@ -87,6 +115,9 @@ type App struct {
 	nodeLabelController          *controllers.NodeLabelController
 	controlPlaneConfigController *controllers.ControlPlaneConfigurationController
 	serviceArgsController        *controllers.ServiceArgsController
+	certRotationController       *controllers.CertificateRotationController
+	certExpiryController         *controllers.CertificateExpiryController
+	etcdSnapshotController       *controllers.EtcdSnapshotController
 	controllerCoordinator        *controllers.Coordinator
 
 	// updateNodeConfigController
@@ -94,14 +125,8 @@ type App struct {
 	updateNodeConfigController          *controllers.UpdateNodeConfigurationController
 
 	// featureController
-	triggerFeatureControllerNetworkCh       chan struct{}
-	triggerFeatureControllerGatewayCh       chan struct{}
-	triggerFeatureControllerIngressCh       chan struct{}
-	triggerFeatureControllerLoadBalancerCh  chan struct{}
-	triggerFeatureControllerLocalStorageCh  chan struct{}
-	triggerFeatureControllerMetricsServerCh chan struct{}
-	triggerFeatureControllerDNSCh           chan struct{}
-	featureController                       *controllers.FeatureController
+	triggerFeatureControllerChs map[types.FeatureName]chan struct{}
+	featureController           *controllers.FeatureController
 }
 
 // New initializes a new microcluster instance from configuration.
@@ -126,6 +151,7 @@ func New(cfg Config) (*App, error) {
 		client:           client,
 		snap:             cfg.Snap,
 		profilingAddress: cfg.PprofAddress,
+		metricsAddress:   cfg.MetricsAddress,
 	}
 	app.readyWg.Add(1)
 
@@ -139,17 +165,19 @@ func New(cfg Config) (*App, error) {
 		log.L().Info("node-config-controller disabled via config")
 	}
 
+	getNodeName := func(ctx context.Context) (string, error) {
+		serverStatus, err := cluster.Status(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to retrieve microcluster status: %w", err)
+		}
+		return serverStatus.Name, nil
+	}
+
 	if !cfg.DisableNodeLabelController {
 		app.nodeLabelController = controllers.NewNodeLabelController(
 			cfg.Snap,
 			app.readyWg.Wait,
-			func(ctx context.Context) (string, error) {
-				serverStatus, err := cluster.Status(ctx)
-				if err != nil {
-					return "", fmt.Errorf("failed to retrieve microcluster status: %w", err)
-				}
-				return serverStatus.Name, nil
-			},
+			getNodeName,
 		)
 	} else {
 		log.L().Info("node-label-controller disabled via config")
@@ -174,6 +202,40 @@ func New(cfg Config) (*App, error) {
 		log.L().Info("service-args-controller disabled via config")
 	}
 
+	if !cfg.DisableCertificateRotationController {
+		app.certRotationController = controllers.NewCertificateRotationController(controllers.CertificateRotationControllerOpts{
+			Snap:      cfg.Snap,
+			WaitReady: app.readyWg.Wait,
+			TriggerCh: time.NewTicker(max(cfg.CertificateRotationCheckInterval, 30*time.Second)).C,
+			Threshold: cfg.CertificateRotationThreshold,
+			Validity:  cfg.CertificateRotationValidity,
+		})
+	} else {
+		log.L().Info("certificate-rotation-controller disabled via config")
+	}
+
+	if !cfg.DisableCertificateExpiryController {
+		app.certExpiryController = controllers.NewCertificateExpiryController(controllers.CertificateExpiryControllerOpts{
+			Snap:          cfg.Snap,
+			WaitReady:     app.readyWg.Wait,
+			TriggerCh:     time.NewTicker(max(cfg.CertificateExpiryCheckInterval, 30*time.Second)).C,
+			GetNodeName:   getNodeName,
+			WarningWindow: cfg.CertificateExpiryWarningWindow,
+		})
+	} else {
+		log.L().Info("certificate-expiry-controller disabled via config")
+	}
+
+	if !cfg.DisableEtcdSnapshotController {
+		app.etcdSnapshotController = controllers.NewEtcdSnapshotController(controllers.EtcdSnapshotControllerOpts{
+			Snap:      cfg.Snap,
+			WaitReady: app.readyWg.Wait,
+			TriggerCh: time.NewTicker(max(cfg.EtcdSnapshotCheckInterval, 30*time.Second)).C,
+		})
+	} else {
+		log.L().Info("etcd-snapshot-controller disabled via config")
+	}
+
 	app.triggerUpdateNodeConfigControllerCh = make(chan struct{}, 1)
 
 	if !cfg.DisableUpdateNodeConfigController {
@@ -186,25 +248,17 @@ func New(cfg Config) (*App, error) {
 		log.L().Info("update-node-config-controller disabled via config")
 	}
 
-	app.triggerFeatureControllerNetworkCh = make(chan struct{}, 1)
-	app.triggerFeatureControllerGatewayCh = make(chan struct{}, 1)
-	app.triggerFeatureControllerIngressCh = make(chan struct{}, 1)
-	app.triggerFeatureControllerLoadBalancerCh = make(chan struct{}, 1)
-	app.triggerFeatureControllerLocalStorageCh = make(chan struct{}, 1)
-	app.triggerFeatureControllerMetricsServerCh = make(chan struct{}, 1)
-	app.triggerFeatureControllerDNSCh = make(chan struct{}, 1)
+	app.triggerFeatureControllerChs = make(map[types.FeatureName]chan struct{})
+	for _, name := range features.DefaultRegistry.Names() {
+		app.triggerFeatureControllerChs[name] = make(chan struct{}, 1)
+	}
 
 	if !cfg.DisableFeatureController {
 		app.featureController = controllers.NewFeatureController(controllers.FeatureControllerOpts{
 			Snap:                          cfg.Snap,
 			WaitReady:                     app.readyWg.Wait,
-			TriggerNetworkCh:              app.triggerFeatureControllerNetworkCh,
-			TriggerGatewayCh:              app.triggerFeatureControllerGatewayCh,
-			TriggerIngressCh:              app.triggerFeatureControllerIngressCh,
-			TriggerLoadBalancerCh:         app.triggerFeatureControllerLoadBalancerCh,
-			TriggerDNSCh:                  app.triggerFeatureControllerDNSCh,
-			TriggerLocalStorageCh:         app.triggerFeatureControllerLocalStorageCh,
-			TriggerMetricsServerCh:        app.triggerFeatureControllerMetricsServerCh,
+			Registry:                      features.DefaultRegistry,
+			TriggerChs:                    app.triggerFeatureControllerChs,
 			ReconcileLoopMaxRetryAttempts: cfg.FeatureControllerMaxRetryAttempts,
 		})
 	} else {
@@ -215,23 +269,9 @@ func New(cfg Config) (*App, error) {
 	if !cfg.DisableFeatureController {
 		upgradeCtrlOpts = controllers.UpgradeControllerOptions{
 			ControllerOptions: upgrade.ControllerOptions{
-				FeatureControllerReadyCh:   app.featureController.ReadyCh(),
-				NotifyNetworkFeature:       app.NotifyNetwork,
-				NotifyGatewayFeature:       app.NotifyGateway,
-				NotifyIngressFeature:       app.NotifyIngress,
-				NotifyLoadBalancerFeature:  app.NotifyLoadBalancer,
-				NotifyLocalStorageFeature:  app.NotifyLocalStorage,
-				NotifyMetricsServerFeature: app.NotifyMetricsServer,
-				NotifyDNSFeature:           app.NotifyDNS,
-				FeatureToReconciledCh: map[types.FeatureName]<-chan struct{}{
-					features.Network:       app.featureController.ReconciledNetworkCh(),
-					features.Gateway:       app.featureController.ReconciledGatewayCh(),
-					features.Ingress:       app.featureController.ReconciledIngressCh(),
-					features.DNS:           app.featureController.ReconciledDNSCh(),
-					features.LoadBalancer:  app.featureController.ReconciledLoadBalancerCh(),
-					features.LocalStorage:  app.featureController.ReconciledLocalStorageCh(),
-					features.MetricsServer: app.featureController.ReconciledMetricsServerCh(),
-				},
+				FeatureControllerReadyCh:          app.featureController.ReadyCh(),
+				NotifyFeature:                     func(name types.FeatureName) { app.NotifyFeatureController(name) },
+				FeatureToReconciledCh:             app.featureController.ReconciledChs(),
 				FeatureControllerReadyTimeout:     10 * time.Minute,
 				FeatureControllerReconcileTimeout: 2 * time.Minute,
 			},
@@ -303,6 +343,29 @@ func (a *App) Run(ctx context.Context, customHooks *mctypes.Hooks) error {
 		}()
 	}
 
+	// start metrics server
+	if a.metricsAddress != "" {
+		log.WithValues("address", fmt.Sprintf("http://%s/metrics", a.metricsAddress)).Info("Enable metrics endpoint")
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+		server := &http.Server{Addr: a.metricsAddress, Handler: mux}
+
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error(err, "Failed to serve metrics endpoint")
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				log.Error(err, "Failed to shut down metrics endpoint")
+			}
+		}()
+	}
+
 	err := a.cluster.Start(mctypes.ContextWithLogger(ctx), microcluster.DaemonArgs{
 		Version:                 string(apiv2.K8sdAPIVersion),
 		Hooks:                   hooks,
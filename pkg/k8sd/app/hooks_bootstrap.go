@@ -15,6 +15,7 @@ import (
 
 	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
 	"github.com/canonical/k8sd/pkg/k8sd/database"
+	"github.com/canonical/k8sd/pkg/k8sd/features"
 	"github.com/canonical/k8sd/pkg/k8sd/pki"
 	"github.com/canonical/k8sd/pkg/k8sd/setup"
 	"github.com/canonical/k8sd/pkg/k8sd/types"
@@ -50,7 +51,15 @@ func (a *App) onBootstrap(ctx context.Context, s mctypes.State, initConfig map[s
 		return fmt.Errorf("failed to unmarshal bootstrap config: %w", err)
 	}
 
-	return a.onBootstrapControlPlane(ctx, s, bootstrapConfig)
+	var bundle *types.ClusterBundle
+	if bundleJSON, ok := utils.MicroclusterClusterBundleFromMap(initConfig); ok {
+		bundle = &types.ClusterBundle{}
+		if err := json.Unmarshal([]byte(bundleJSON), bundle); err != nil {
+			return fmt.Errorf("failed to unmarshal cluster bundle: %w", err)
+		}
+	}
+
+	return a.onBootstrapControlPlane(ctx, s, bootstrapConfig, bundle)
 }
 
 func (a *App) onBootstrapWorkerNode(ctx context.Context, s mctypes.State, encodedToken string, joinConfig apiv2.WorkerJoinConfig) (rerr error) {
@@ -266,7 +275,7 @@ func (a *App) onBootstrapWorkerNode(ctx context.Context, s mctypes.State, encode
 	if err := setup.KubeProxy(ctx, snap, s.Name(), response.PodCIDR, joinConfig.ExtraNodeKubeProxyArgs); err != nil {
 		return fmt.Errorf("failed to configure kube-proxy: %w", err)
 	}
-	if err := setup.K8sAPIServerProxy(snap, response.APIServers, securePort, joinConfig.ExtraNodeK8sAPIServerProxyArgs); err != nil {
+	if err := setup.K8sAPIServerProxy(snap, s.Name(), response.APIServers, securePort, joinConfig.ExtraNodeK8sAPIServerProxyArgs); err != nil {
 		return fmt.Errorf("failed to configure k8s-apiserver-proxy: %w", err)
 	}
 	if err := setup.ExtraNodeConfigFiles(snap, joinConfig.ExtraNodeConfigFiles); err != nil {
@@ -292,15 +301,29 @@ func (a *App) onBootstrapWorkerNode(ctx context.Context, s mctypes.State, encode
 	return nil
 }
 
-func (a *App) onBootstrapControlPlane(ctx context.Context, s mctypes.State, bootstrapConfig apiv2.BootstrapConfig) (rerr error) {
+// onBootstrapControlPlane bootstraps the first control plane node of the cluster.
+// If bundle is not nil, the cluster configuration, certificate authorities and tokens are imported from the bundle.
+func (a *App) onBootstrapControlPlane(ctx context.Context, s mctypes.State, bootstrapConfig apiv2.BootstrapConfig, bundle *types.ClusterBundle) (rerr error) {
 	snap := a.Snap()
 
 	log := log.FromContext(ctx).WithValues("hook", "bootstrap")
 
+	if bundle != nil {
+		log.Info("Importing cluster bundle", "created-at", bundle.CreatedAt)
+		var err error
+		if bootstrapConfig, err = bundle.BootstrapConfig(bootstrapConfig); err != nil {
+			return fmt.Errorf("invalid cluster bundle: %w", err)
+		}
+	}
+
 	cfg, err := types.ClusterConfigFromBootstrapConfig(bootstrapConfig)
 	if err != nil {
 		return fmt.Errorf("invalid bootstrap config: %w", err)
 	}
+	// NOTE: Clusters imported from a bundle keep the key algorithm of the exported cluster.
+	if bundle == nil {
+		cfg.SetBootstrapKeyAlgorithm()
+	}
 	cfg.SetDefaults()
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid cluster configuration: %w", err)
@@ -354,6 +377,8 @@ func (a *App) onBootstrapControlPlane(ctx context.Context, s mctypes.State, boot
 			DNSSANs:           append([]string{s.Name()}, extraNames...),
 			NotBefore:         notBefore,
 			NotAfter:          notBefore.AddDate(20, 0, 0),
+			KeyAlgorithm:      cfg.KeyAlgorithm(),
+			Profiles:          cfg.CertificateProfiles(),
 		})
 
 		certificates.CACert = bootstrapConfig.GetEtcdCACert()
@@ -403,6 +428,8 @@ func (a *App) onBootstrapControlPlane(ctx context.Context, s mctypes.State, boot
 		NotAfter:                  notBefore.AddDate(20, 0, 0),
 		AllowSelfSignedCA:         true,
 		IncludeMachineAddressSANs: true,
+		KeyAlgorithm:              cfg.KeyAlgorithm(),
+		Profiles:                  cfg.CertificateProfiles(),
 	})
 
 	certificates.CACert = bootstrapConfig.GetCACert()
@@ -432,6 +459,12 @@ func (a *App) onBootstrapControlPlane(ctx context.Context, s mctypes.State, boot
 	certificates.KubeletClientCert = bootstrapConfig.GetKubeletClientCert()
 	certificates.KubeletClientKey = bootstrapConfig.GetKubeletClientKey()
 
+	if bundle != nil {
+		// keep the k8sd keys, so that worker nodes of the exported cluster can still verify the k8sd-config
+		certificates.K8sdPublicKey = bundle.Config.Certificates.GetK8sdPublicKey()
+		certificates.K8sdPrivateKey = bundle.Config.Certificates.GetK8sdPrivateKey()
+	}
+
 	if err := certificates.CompleteCertificates(); err != nil {
 		return fmt.Errorf("failed to initialize control plane certificates: %w", err)
 	}
@@ -499,7 +532,7 @@ func (a *App) onBootstrapControlPlane(ctx context.Context, s mctypes.State, boot
 	if err := setup.KubeScheduler(snap, bootstrapConfig.ExtraNodeKubeSchedulerArgs); err != nil {
 		return fmt.Errorf("failed to configure kube-scheduler: %w", err)
 	}
-	if err := setup.KubeAPIServer(snap, cfg.APIServer.GetSecurePort(), nodeIP, cfg.Network.GetServiceCIDR(), utils.Path(s.Address(), "1.0", "kubernetes", "auth", "webhook").String(), true, cfg.Datastore, cfg.APIServer.GetAuthorizationMode(), bootstrapConfig.ExtraNodeKubeAPIServerArgs); err != nil {
+	if err := setup.KubeAPIServer(snap, cfg.APIServer.GetSecurePort(), nodeIP, cfg.Network.GetServiceCIDR(), utils.Path(s.Address(), "1.0", "kubernetes", "auth", "webhook").String(), utils.Path(s.Address(), "1.0", "kubernetes", "auth", "authorize").String(), cfg.RevocationWebhookEnabled(), true, cfg.Datastore, cfg.APIServer.GetAuthorizationMode(), bootstrapConfig.ExtraNodeKubeAPIServerArgs); err != nil {
 		return fmt.Errorf("failed to configure kube-apiserver: %w", err)
 	}
 
@@ -507,11 +540,20 @@ func (a *App) onBootstrapControlPlane(ctx context.Context, s mctypes.State, boot
 		return fmt.Errorf("failed to write extra node config files: %w", err)
 	}
 
+	if bundle != nil {
+		cfg.Addons = bundle.Config.Addons
+	}
+
 	// Write cluster configuration to datastore.
 	if err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		if _, err := database.SetClusterConfig(ctx, tx, cfg); err != nil {
 			return fmt.Errorf("failed to write cluster configuration: %w", err)
 		}
+		if bundle != nil {
+			if err := importClusterBundleTokens(ctx, tx, *bundle); err != nil {
+				return fmt.Errorf("failed to import cluster bundle tokens: %w", err)
+			}
+		}
 		return nil
 	}); err != nil {
 		return fmt.Errorf("database transaction to update cluster configuration failed: %w", err)
@@ -540,15 +582,28 @@ func (a *App) onBootstrapControlPlane(ctx context.Context, s mctypes.State, boot
 	}
 	log.Info("API server is ready - notify controllers")
 
-	a.NotifyFeatureController(
-		cfg.Network.GetEnabled(),
-		cfg.Gateway.GetEnabled(),
-		cfg.Ingress.GetEnabled(),
-		cfg.LoadBalancer.GetEnabled(),
-		cfg.LocalStorage.GetEnabled(),
-		cfg.MetricsServer.GetEnabled(),
-		cfg.DNS.GetEnabled(),
-	)
+	var enabledFeatures []types.FeatureName
+	for _, feature := range features.Registered() {
+		if feature.Enabled(cfg) {
+			enabledFeatures = append(enabledFeatures, feature.Name)
+		}
+	}
+	a.NotifyFeatureController(enabledFeatures...)
 	a.NotifyUpdateNodeConfigController()
 	return nil
 }
+
+// importClusterBundleTokens restores the ClusterAPI and Kubernetes auth tokens of a cluster bundle.
+func importClusterBundleTokens(ctx context.Context, tx *sql.Tx, bundle types.ClusterBundle) error {
+	if bundle.ClusterAPIToken != "" {
+		if err := database.SetClusterAPIToken(ctx, tx, bundle.ClusterAPIToken); err != nil {
+			return fmt.Errorf("failed to set ClusterAPI token: %w", err)
+		}
+	}
+	for _, token := range bundle.KubernetesAuthTokens {
+		if err := database.RestoreToken(ctx, tx, token); err != nil {
+			return fmt.Errorf("failed to restore token of user %q: %w", token.Username, err)
+		}
+	}
+	return nil
+}
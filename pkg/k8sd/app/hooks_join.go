@@ -86,11 +86,13 @@ func (a *App) onPostJoin(ctx context.Context, s mctypes.State, initConfig map[st
 	switch cfg.Datastore.GetType() {
 	case "etcd":
 		certificates := pki.NewEtcdPKI(pki.EtcdPKIOpts{
-			Hostname:  s.Name(),
-			IPSANs:    append([]net.IP{nodeIP, net.ParseIP("127.0.0.1"), net.ParseIP("::1")}, extraIPs...),
-			DNSSANs:   append([]string{s.Name()}, extraNames...),
-			NotBefore: notBefore,
-			NotAfter:  notBefore.AddDate(20, 0, 0),
+			Hostname:     s.Name(),
+			IPSANs:       append([]net.IP{nodeIP, net.ParseIP("127.0.0.1"), net.ParseIP("::1")}, extraIPs...),
+			DNSSANs:      append([]string{s.Name()}, extraNames...),
+			NotBefore:    notBefore,
+			NotAfter:     notBefore.AddDate(20, 0, 0),
+			KeyAlgorithm: cfg.KeyAlgorithm(),
+			Profiles:     cfg.CertificateProfiles(),
 		})
 
 		certificates.CACert = cfg.Datastore.GetEtcdCACert()
@@ -133,6 +135,8 @@ func (a *App) onPostJoin(ctx context.Context, s mctypes.State, initConfig map[st
 		NotBefore:                 notBefore,
 		NotAfter:                  notBefore.AddDate(20, 0, 0),
 		IncludeMachineAddressSANs: true,
+		KeyAlgorithm:              cfg.KeyAlgorithm(),
+		Profiles:                  cfg.CertificateProfiles(),
 	})
 
 	// load shared cluster certificates
@@ -351,7 +355,7 @@ func (a *App) onPostJoin(ctx context.Context, s mctypes.State, initConfig map[st
 	if err := setup.KubeScheduler(snap, joinConfig.ExtraNodeKubeSchedulerArgs); err != nil {
 		return fmt.Errorf("failed to configure kube-scheduler: %w", err)
 	}
-	if err := setup.KubeAPIServer(snap, cfg.APIServer.GetSecurePort(), nodeIP, cfg.Network.GetServiceCIDR(), utils.Path(s.Address(), "1.0", "kubernetes", "auth", "webhook").String(), true, cfg.Datastore, cfg.APIServer.GetAuthorizationMode(), joinConfig.ExtraNodeKubeAPIServerArgs); err != nil {
+	if err := setup.KubeAPIServer(snap, cfg.APIServer.GetSecurePort(), nodeIP, cfg.Network.GetServiceCIDR(), utils.Path(s.Address(), "1.0", "kubernetes", "auth", "webhook").String(), utils.Path(s.Address(), "1.0", "kubernetes", "auth", "authorize").String(), cfg.RevocationWebhookEnabled(), true, cfg.Datastore, cfg.APIServer.GetAuthorizationMode(), joinConfig.ExtraNodeKubeAPIServerArgs); err != nil {
 		return fmt.Errorf("failed to configure kube-apiserver: %w", err)
 	}
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/canonical/k8sd/pkg/k8sd/database"
 	databaseutil "github.com/canonical/k8sd/pkg/k8sd/database/util"
+	"github.com/canonical/k8sd/pkg/k8sd/features"
 	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/log"
 	snaputil "github.com/canonical/k8sd/pkg/snap/util"
@@ -113,19 +114,17 @@ func (a *App) onStart(ctx context.Context, s mctypes.State) error {
 			func() mctypes.State {
 				return s
 			},
-			func(ctx context.Context, dnsIP string) error {
+			func(ctx context.Context, config types.ClusterConfig) error {
 				if err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
-					if _, err := database.SetClusterConfig(ctx, tx, types.ClusterConfig{
-						Kubelet: types.Kubelet{ClusterDNS: utils.Pointer(dnsIP)},
-					}); err != nil {
-						return fmt.Errorf("failed to update cluster configuration for dns=%s: %w", dnsIP, err)
+					if _, err := database.SetClusterConfig(ctx, tx, config); err != nil {
+						return fmt.Errorf("failed to update cluster configuration: %w", err)
 					}
 					return nil
 				}); err != nil {
 					return fmt.Errorf("database transaction to update cluster configuration failed: %w", err)
 				}
 
-				// DNS IP has changed, notify node config controller
+				// cluster configuration has changed (e.g. DNS IP), notify node config controller
 				a.NotifyUpdateNodeConfigController()
 
 				return nil
@@ -145,6 +144,28 @@ func (a *App) onStart(ctx context.Context, s mctypes.State) error {
 				}
 				return nil
 			},
+			func(ctx context.Context, name types.FeatureName, entry types.FeatureStatusHistoryEntry) error {
+				if err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+					if err := database.AddFeatureStatusHistory(ctx, tx, name, entry); err != nil {
+						return fmt.Errorf("failed to add feature status history in db for %q: %w", name, err)
+					}
+					return nil
+				}); err != nil {
+					return fmt.Errorf("database transaction to add feature status history failed: %w", err)
+				}
+				return nil
+			},
+			func(ctx context.Context, name types.FeatureName) error {
+				if err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+					if err := database.DeleteFeatureStatus(ctx, tx, name); err != nil {
+						return fmt.Errorf("failed to delete feature status in db for %q: %w", name, err)
+					}
+					return nil
+				}); err != nil {
+					return fmt.Errorf("database transaction to delete feature status failed: %w", err)
+				}
+				return nil
+			},
 		)
 	}
 
@@ -156,6 +177,11 @@ func (a *App) onStart(ctx context.Context, s mctypes.State) error {
 				func(ctx context.Context) (types.ClusterConfig, error) {
 					return databaseutil.GetClusterConfig(ctx, s)
 				},
+				func(ctx context.Context, certificate types.IssuedCertificate) error {
+					return s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+						return database.RecordIssuedCertificate(ctx, tx, certificate)
+					})
+				},
 			); err != nil {
 				log.FromContext(ctx).Error(err, "Failed to start controller coordinator")
 			}
@@ -165,12 +191,24 @@ func (a *App) onStart(ctx context.Context, s mctypes.State) error {
 	// NOTE(Hue): We notify all features here to ensure that they are
 	// reconciled at least once after the app starts. This is important specifically
 	// when k8sd gets restarted before getting the chance to reconcile features.
-	a.NotifyFeatureController(true, true, true, true, true, true, true)
+	a.NotifyFeatureController(features.DefaultRegistry.Names()...)
 
 	if a.serviceArgsController != nil {
 		go a.serviceArgsController.Run(ctx)
 	}
 
+	if a.certRotationController != nil {
+		go a.certRotationController.Run(ctx)
+	}
+
+	if a.certExpiryController != nil {
+		go a.certExpiryController.Run(ctx)
+	}
+
+	if a.etcdSnapshotController != nil {
+		go a.etcdSnapshotController.Run(ctx)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/k8sd/metrics"
+	"github.com/canonical/k8sd/pkg/log"
+	"github.com/canonical/k8sd/pkg/snap"
+	snaputil "github.com/canonical/k8sd/pkg/snap/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+// CertificateExpiryControllerOpts holds configuration for CertificateExpiryController.
+type CertificateExpiryControllerOpts struct {
+	// Snap is the snap interface.
+	Snap snap.Snap
+	// WaitReady is a function that blocks until the node is ready.
+	WaitReady func()
+	// TriggerCh drives the reconciliation loop. Typically time.NewTicker(<interval>).C.
+	TriggerCh <-chan time.Time
+	// GetNodeName returns the name of the Kubernetes node, which the events are recorded for.
+	GetNodeName func(ctx context.Context) (string, error)
+	// WarningWindow is the remaining validity below which a warning event is recorded for a certificate.
+	// Defaults to 14 days when zero.
+	WarningWindow time.Duration
+	// WarningInterval is the minimum interval between warning events for the same certificate.
+	// Defaults to 24 hours when zero.
+	WarningInterval time.Duration
+}
+
+// CertificateExpiryController periodically exports the validity of the certificates and kubeconfigs that k8sd
+// manages on the node as Prometheus metrics. It also records warning events on the Kubernetes node for
+// certificates that expire within the warning window, repeated at most once per warning interval.
+type CertificateExpiryController struct {
+	snap            snap.Snap
+	waitReady       func()
+	triggerCh       <-chan time.Time
+	getNodeName     func(ctx context.Context) (string, error)
+	warningWindow   time.Duration
+	warningInterval time.Duration
+	reconciledCh    chan struct{}
+
+	// lastWarning is the time of the last warning event per certificate.
+	lastWarning map[string]time.Time
+	// exported are the certificates that the metrics are exported for.
+	exported map[certificateLabels]struct{}
+}
+
+// certificateLabels are the label values of the certificate metrics.
+type certificateLabels struct {
+	name, kind string
+}
+
+// NewCertificateExpiryController creates a new CertificateExpiryController.
+func NewCertificateExpiryController(opts CertificateExpiryControllerOpts) *CertificateExpiryController {
+	if opts.WaitReady == nil {
+		opts.WaitReady = func() {}
+	}
+	if opts.WarningWindow <= 0 {
+		opts.WarningWindow = 14 * 24 * time.Hour
+	}
+	if opts.WarningInterval <= 0 {
+		opts.WarningInterval = 24 * time.Hour
+	}
+	return &CertificateExpiryController{
+		snap:            opts.Snap,
+		waitReady:       opts.WaitReady,
+		triggerCh:       opts.TriggerCh,
+		getNodeName:     opts.GetNodeName,
+		warningWindow:   opts.WarningWindow,
+		warningInterval: opts.WarningInterval,
+		reconciledCh:    make(chan struct{}, 1),
+		lastWarning:     make(map[string]time.Time),
+		exported:        make(map[certificateLabels]struct{}),
+	}
+}
+
+// Run starts the controller and blocks until ctx is cancelled.
+func (c *CertificateExpiryController) Run(ctx context.Context) {
+	ctx = log.NewContext(ctx, log.FromContext(ctx).WithValues("controller", "certificate-expiry"))
+	log := log.FromContext(ctx)
+
+	c.waitReady()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.triggerCh:
+		}
+
+		if err := c.reconcile(ctx); err != nil {
+			log.Error(err, "failed to check certificate expiry")
+		}
+
+		select {
+		case c.reconciledCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *CertificateExpiryController) reconcile(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	certificates, err := snaputil.ReadNodeCertificates(c.snap)
+	if err != nil {
+		return fmt.Errorf("failed to read node certificates: %w", err)
+	}
+
+	now := time.Now()
+	exported := make(map[certificateLabels]struct{}, len(certificates))
+	var expiring []snaputil.NodeCertificate
+	for _, certificate := range certificates {
+		metrics.CertificateNotBefore.WithLabelValues(certificate.Name, certificate.Type).Set(float64(certificate.Certificate.NotBefore.Unix()))
+		metrics.CertificateNotAfter.WithLabelValues(certificate.Name, certificate.Type).Set(float64(certificate.Certificate.NotAfter.Unix()))
+		exported[certificateLabels{name: certificate.Name, kind: certificate.Type}] = struct{}{}
+
+		if !certificate.Certificate.NotAfter.Before(now.Add(c.warningWindow)) {
+			continue
+		}
+		if last, ok := c.lastWarning[certificate.Type+"/"+certificate.Name]; ok && now.Sub(last) < c.warningInterval {
+			continue
+		}
+		expiring = append(expiring, certificate)
+	}
+
+	// NOTE: Only delete the metrics of certificates that are no longer managed (e.g. after switching to an internal
+	// datastore) once the current ones are set, so that scrapes never miss a certificate.
+	for labels := range c.exported {
+		if _, ok := exported[labels]; !ok {
+			metrics.CertificateNotBefore.DeleteLabelValues(labels.name, labels.kind)
+			metrics.CertificateNotAfter.DeleteLabelValues(labels.name, labels.kind)
+		}
+	}
+	c.exported = exported
+
+	if len(expiring) == 0 {
+		return nil
+	}
+
+	nodeName, err := c.getNodeName(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get node name: %w", err)
+	}
+	client, err := c.snap.KubernetesNodeClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	for _, certificate := range expiring {
+		notAfter := certificate.Certificate.NotAfter.UTC().Format(time.RFC3339)
+		reason, message := "CertificateExpiring", fmt.Sprintf("The %s %s expires at %s", certificate.Type, certificate.Name, notAfter)
+		if certificate.Certificate.NotAfter.Before(now) {
+			reason, message = "CertificateExpired", fmt.Sprintf("The %s %s expired at %s", certificate.Type, certificate.Name, notAfter)
+		}
+
+		log.Info("Certificate expires within the warning window", "name", certificate.Name, "type", certificate.Type, "expires", notAfter)
+		if err := client.CreateNodeEvent(ctx, nodeName, v1.EventTypeWarning, reason, message); err != nil {
+			return fmt.Errorf("failed to record expiry of %s %s: %w", certificate.Type, certificate.Name, err)
+		}
+		c.lastWarning[certificate.Type+"/"+certificate.Name] = now
+	}
+	return nil
+}
+
+// ReconciledCh returns the channel that receives a value after each reconciliation loop.
+func (c *CertificateExpiryController) ReconciledCh() <-chan struct{} {
+	return c.reconciledCh
+}
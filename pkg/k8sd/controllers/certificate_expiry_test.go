@@ -0,0 +1,105 @@
+package controllers_test
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/client/kubernetes"
+	"github.com/canonical/k8sd/pkg/k8sd/controllers"
+	"github.com/canonical/k8sd/pkg/k8sd/metrics"
+	"github.com/canonical/k8sd/pkg/k8sd/setup"
+	"github.com/canonical/k8sd/pkg/snap/mock"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCertificateExpiryController(t *testing.T) {
+	g := NewWithT(t)
+
+	pkiDir := t.TempDir()
+	configDir := t.TempDir()
+	lockDir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(lockDir, "worker"), nil, 0o600)).To(Succeed())
+
+	newCertificate := func(cn string, notBefore time.Time, notAfter time.Time) (string, string) {
+		template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: cn}, notBefore, notAfter, false, nil, nil)
+		g.Expect(err).To(Not(HaveOccurred()))
+		cert, key, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmECDSAP256, template, nil, nil)
+		g.Expect(err).To(Not(HaveOccurred()))
+		return cert, key
+	}
+
+	notBefore := time.Now().Add(-time.Hour).Truncate(time.Second)
+	kubeletNotAfter := notBefore.Add(7 * 24 * time.Hour)
+	cert, key := newCertificate("system:node:worker", notBefore, kubeletNotAfter)
+	g.Expect(os.WriteFile(filepath.Join(pkiDir, "kubelet.crt"), []byte(cert), 0o600)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(pkiDir, "kubelet.key"), []byte(key), 0o600)).To(Succeed())
+	for _, name := range []string{"kubelet.conf", "proxy.conf"} {
+		cert, key := newCertificate(name, notBefore, notBefore.AddDate(1, 0, 0))
+		g.Expect(setup.Kubeconfig(filepath.Join(configDir, name), "127.0.0.1:6443", cert, cert, key)).To(Succeed())
+	}
+
+	clientset := fake.NewSimpleClientset()
+	s := &mock.Snap{Mock: mock.Mock{
+		KubernetesPKIDir:     pkiDir,
+		KubernetesConfigDir:  configDir,
+		LockFilesDir:         lockDir,
+		KubernetesNodeClient: &kubernetes.Client{Interface: clientset},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	triggerCh := make(chan time.Time)
+	ctrl := controllers.NewCertificateExpiryController(controllers.CertificateExpiryControllerOpts{
+		Snap:          s,
+		TriggerCh:     triggerCh,
+		GetNodeName:   func(context.Context) (string, error) { return "worker", nil },
+		WarningWindow: 14 * 24 * time.Hour,
+	})
+	go ctrl.Run(ctx)
+
+	reconcile := func(g Gomega) {
+		triggerCh <- time.Now()
+		select {
+		case <-ctrl.ReconciledCh():
+		case <-time.After(channelSendTimeout):
+			g.Expect(false).To(BeTrue(), "timed out waiting for reconciliation")
+		}
+	}
+
+	reconcile(g)
+
+	g.Expect(testutil.ToFloat64(metrics.CertificateNotBefore.WithLabelValues("kubelet", "certificate"))).To(Equal(float64(notBefore.Unix())))
+	g.Expect(testutil.ToFloat64(metrics.CertificateNotAfter.WithLabelValues("kubelet", "certificate"))).To(Equal(float64(kubeletNotAfter.Unix())))
+	g.Expect(testutil.ToFloat64(metrics.CertificateNotAfter.WithLabelValues("proxy.conf", "kubeconfig"))).To(Equal(float64(notBefore.AddDate(1, 0, 0).Unix())))
+
+	events, err := clientset.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(events.Items).To(HaveLen(1))
+	g.Expect(events.Items[0].Type).To(Equal("Warning"))
+	g.Expect(events.Items[0].Reason).To(Equal("CertificateExpiring"))
+	g.Expect(events.Items[0].InvolvedObject.Kind).To(Equal("Node"))
+	g.Expect(events.Items[0].InvolvedObject.Name).To(Equal("worker"))
+	g.Expect(events.Items[0].Message).To(ContainSubstring("kubelet"))
+
+	t.Run("WarningNotRepeated", func(t *testing.T) {
+		g := NewWithT(t)
+
+		reconcile(g)
+
+		events, err := clientset.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(events.Items).To(HaveLen(1))
+
+		// the metrics of all certificates are kept across reconciliations
+		g.Expect(testutil.CollectAndCount(metrics.CertificateNotAfter)).To(Equal(3))
+	})
+}
@@ -0,0 +1,389 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/log"
+	"github.com/canonical/k8sd/pkg/snap"
+	snaputil "github.com/canonical/k8sd/pkg/snap/util"
+	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+)
+
+// CertificateRotationControllerOpts holds configuration for CertificateRotationController.
+type CertificateRotationControllerOpts struct {
+	// Snap is the snap interface.
+	Snap snap.Snap
+	// WaitReady is a function that blocks until the node is ready.
+	WaitReady func()
+	// TriggerCh drives the reconciliation loop. Typically time.NewTicker(<interval>).C.
+	TriggerCh <-chan time.Time
+	// Threshold is the fraction of the lifetime of a certificate that must remain before it is renewed, e.g. 0.25
+	// renews a certificate with a lifetime of 1 year about 3 months before it expires.
+	// Defaults to 1/3 when zero.
+	Threshold float64
+	// Validity is the validity of the renewed certificates.
+	// Defaults to 1 year when zero.
+	Validity time.Duration
+	// RefreshTimeout is the maximum time a single renewal attempt may take. On worker nodes, this includes
+	// waiting for the certificate signing requests to be approved. Certificate signing requests that are not
+	// approved in time are reused by the next attempt. Defaults to 5 minutes when zero.
+	RefreshTimeout time.Duration
+}
+
+// CertificateRotationController periodically checks the expiry of the node certificates and
+// renews the certificates that have less than the configured fraction of their lifetime left.
+// All node certificates are also re-issued when they are not signed by the current cluster
+// certificate authority, e.g. during the reissue phase of a CA rotation.
+// Control plane certificates are renewed in place. Worker certificates are renewed through
+// certificate signing requests, which must be approved (or auto-approved) in the cluster.
+type CertificateRotationController struct {
+	snap           snap.Snap
+	waitReady      func()
+	triggerCh      <-chan time.Time
+	threshold      float64
+	validity       time.Duration
+	refreshTimeout time.Duration
+	reconciledCh   chan struct{}
+
+	// pendingSeed is the seed of a worker renewal whose certificate signing requests were not approved yet.
+	// The next renewal attempt reuses the seed, so that it waits for the same certificate signing requests.
+	pendingSeed int
+}
+
+// NewCertificateRotationController creates a new CertificateRotationController.
+func NewCertificateRotationController(opts CertificateRotationControllerOpts) *CertificateRotationController {
+	if opts.WaitReady == nil {
+		opts.WaitReady = func() {}
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = 1.0 / 3
+	}
+	if opts.Validity <= 0 {
+		opts.Validity = 365 * 24 * time.Hour
+	}
+	if opts.RefreshTimeout <= 0 {
+		opts.RefreshTimeout = 5 * time.Minute
+	}
+	return &CertificateRotationController{
+		snap:           opts.Snap,
+		waitReady:      opts.WaitReady,
+		triggerCh:      opts.TriggerCh,
+		threshold:      opts.Threshold,
+		validity:       opts.Validity,
+		refreshTimeout: opts.RefreshTimeout,
+		reconciledCh:   make(chan struct{}, 1),
+	}
+}
+
+// Run starts the controller and blocks until ctx is cancelled.
+func (c *CertificateRotationController) Run(ctx context.Context) {
+	ctx = log.NewContext(ctx, log.FromContext(ctx).WithValues("controller", "certificate-rotation"))
+	log := log.FromContext(ctx)
+
+	c.waitReady()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.triggerCh:
+		}
+
+		if err := c.reconcile(ctx); err != nil {
+			log.Error(err, "failed to rotate certificates")
+		}
+
+		select {
+		case c.reconciledCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *CertificateRotationController) reconcile(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	client, err := c.snap.K8sdClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+
+	if _, initialized, err := client.NodeStatus(ctx); err != nil {
+		return fmt.Errorf("failed to check node status: %w", err)
+	} else if !initialized {
+		log.V(1).Info("Node is not initialized, skipping certificate rotation")
+		return nil
+	}
+
+	isWorker, err := snaputil.IsWorker(c.snap)
+	if err != nil {
+		return fmt.Errorf("failed to determine node type: %w", err)
+	}
+	role := apiv2.ClusterRoleControlPlane
+	if isWorker {
+		role = apiv2.ClusterRoleWorker
+	}
+
+	status, err := client.CertificatesStatus(ctx, apiv2.CertificatesStatusRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to get certificates status: %w", err)
+	}
+
+	lifetime, err := c.certificateLifetime(ctx, isWorker)
+	if err != nil {
+		return fmt.Errorf("failed to determine certificate lifetimes: %w", err)
+	}
+	expiring, err := expiringCertificates(status.Certificates, role, time.Now(), c.threshold, lifetime)
+	if err != nil {
+		return fmt.Errorf("failed to check certificate expiry: %w", err)
+	}
+	reissue, err := c.signedByPreviousCA(isWorker)
+	if err != nil {
+		return fmt.Errorf("failed to check certificate authority of node certificates: %w", err)
+	}
+	if len(expiring) == 0 && !reissue && c.pendingSeed == 0 {
+		// NOTE: Renewed certificates are reported when they are written. The report is repeated here, so that nodes
+		// that did not renew their certificates recently are not blocking the end of a CA rotation.
+		if err := snaputil.ReportNodeCertificateAuthorities(ctx, c.snap); err != nil {
+			log.Error(err, "Failed to report the certificate authorities of the node certificates")
+		}
+		return nil
+	}
+	if reissue {
+		log.Info("Node certificates are not signed by the current certificate authority, re-issuing all certificates")
+	} else {
+		log.Info("Certificates expire soon, renewing", "certificates", expiring, "threshold", c.threshold)
+	}
+
+	extraSANs, err := c.currentExtraSANs(isWorker)
+	if err != nil {
+		return fmt.Errorf("failed to read current certificate SANs: %w", err)
+	}
+
+	// NOTE: Worker nodes renew all their certificates, since the kubeconfigs are regenerated
+	// from the refreshed certificates only.
+	var certificates []string
+	if !isWorker && !reissue {
+		certificates = expiring
+	}
+
+	seed := c.pendingSeed
+	if seed == 0 {
+		plan, err := client.RefreshCertificatesPlan(ctx, apiv2.RefreshCertificatesPlanRequest{Certificates: certificates})
+		if err != nil {
+			return fmt.Errorf("failed to plan certificates refresh: %w", err)
+		}
+		if len(plan.CertificateSigningRequests) > 0 {
+			log.Info("Waiting for certificate signing requests to be approved", "csrs", plan.CertificateSigningRequests)
+		}
+		seed = plan.Seed
+	} else {
+		log.Info("Waiting for pending certificate signing requests to be approved", "seed", seed)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.refreshTimeout)
+	defer cancel()
+
+	run, err := client.RefreshCertificatesRun(ctx, apiv2.RefreshCertificatesRunRequest{
+		Seed:              seed,
+		Certificates:      certificates,
+		ExpirationSeconds: int(c.validity.Seconds()),
+		ExtraSANs:         extraSANs,
+	})
+	if err != nil {
+		if isWorker {
+			// the certificate signing requests of the seed are reused by the next attempt
+			c.pendingSeed = seed
+		}
+		return fmt.Errorf("failed to refresh certificates: %w", err)
+	}
+	c.pendingSeed = 0
+
+	log.Info("Renewed certificates", "certificates", certificates, "expires", time.Unix(int64(run.ExpirationSeconds), 0).UTC())
+	return nil
+}
+
+// certificateLifetime returns a function that returns the expected lifetime of a node certificate.
+// The lifetime is the configured validity, capped by the lifetime of the certificate profiles on control plane
+// nodes. On worker nodes, the lifetime is also capped by the lifetime of the current kubelet certificate, since the
+// certificates are signed by the control plane.
+func (c *CertificateRotationController) certificateLifetime(ctx context.Context, isWorker bool) (func(name string) time.Duration, error) {
+	maxLifetime := c.validity
+	var profiles pkiutil.Profiles
+	if isWorker {
+		cert, _, err := pkiutil.LoadCertificatePairFromDir(c.snap.KubernetesPKIDir(), "kubelet")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubelet certificate: %w", err)
+		}
+		maxLifetime = min(maxLifetime, cert.NotAfter.Sub(cert.NotBefore))
+	} else {
+		client, err := c.snap.K8sdClient("")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create k8sd client: %w", err)
+		}
+		config, err := client.GetClusterConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster configuration: %w", err)
+		}
+		if value, ok := config.Config.Annotations[types.AnnotationCertificateProfiles]; ok {
+			if profiles, err = types.ParseCertificateProfiles(value); err != nil {
+				return nil, fmt.Errorf("failed to parse certificate profiles: %w", err)
+			}
+		}
+	}
+
+	return func(name string) time.Duration {
+		if lifetime := profiles.Get(name).Lifetime; lifetime > 0 {
+			return min(maxLifetime, lifetime)
+		}
+		return maxLifetime
+	}, nil
+}
+
+// currentExtraSANs returns the SANs of the current node serving certificate, so that they are kept
+// on the renewed certificates.
+// The SANs that k8sd adds to the certificates itself (the hostname, the addresses of the node and the kubernetes
+// service names and addresses) are not returned, so that they are not duplicated on every renewal.
+func (c *CertificateRotationController) currentExtraSANs(isWorker bool) ([]string, error) {
+	name := "apiserver"
+	if isWorker {
+		name = "kubelet"
+	}
+	cert, _, err := pkiutil.LoadCertificatePairFromDir(c.snap.KubernetesPKIDir(), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s certificate: %w", name, err)
+	}
+
+	defaultIPs, err := c.defaultIPSANs(isWorker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the default IP SANs: %w", err)
+	}
+
+	var sans []string
+	for _, dnsName := range cert.DNSNames {
+		// NOTE: The default kubernetes service names are always added to the apiserver certificate.
+		if dnsName == "kubernetes" || strings.HasPrefix(dnsName, "kubernetes.default") || dnsName == c.snap.Hostname() {
+			continue
+		}
+		if !slices.Contains(sans, dnsName) {
+			sans = append(sans, dnsName)
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		if slices.ContainsFunc(defaultIPs, ip.Equal) || slices.Contains(sans, ip.String()) {
+			continue
+		}
+		sans = append(sans, ip.String())
+	}
+	return sans, nil
+}
+
+// defaultIPSANs returns the IP SANs that k8sd adds to the node serving certificate itself, which are the addresses
+// of the node and, on control plane nodes, the kubernetes service addresses.
+func (c *CertificateRotationController) defaultIPSANs(isWorker bool) ([]net.IP, error) {
+	addresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve machine addresses: %w", err)
+	}
+	var ips []net.IP
+	for _, addr := range addresses {
+		if ip, _, err := net.ParseCIDR(addr.String()); err == nil && ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	if isWorker {
+		return ips, nil
+	}
+
+	serviceCIDR, err := snaputil.GetServiceArgument(c.snap, "kube-apiserver", "--service-cluster-ip-range")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the service CIDR of kube-apiserver: %w", err)
+	}
+	if serviceCIDR == "" {
+		return ips, nil
+	}
+	serviceIPs, err := utils.GetKubernetesServiceIPsFromServiceCIDRs(serviceCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the kubernetes service addresses from %q: %w", serviceCIDR, err)
+	}
+	return append(ips, serviceIPs...), nil
+}
+
+// signedByPreviousCA returns true if the node serving certificate is not signed by the current certificate authority,
+// which is the first certificate of ca.crt. On control plane nodes, this is only checked if the certificate authority
+// is managed by k8sd.
+func (c *CertificateRotationController) signedByPreviousCA(isWorker bool) (bool, error) {
+	pkiDir := c.snap.KubernetesPKIDir()
+	if !isWorker {
+		if _, err := os.Stat(filepath.Join(pkiDir, "ca.key")); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to check CA key: %w", err)
+		}
+	}
+
+	caPEM, err := os.ReadFile(filepath.Join(pkiDir, "ca.crt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	ca, _, err := pkiutil.LoadCertificate(string(caPEM), "")
+	if err != nil {
+		return false, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	name := "apiserver"
+	if isWorker {
+		name = "kubelet"
+	}
+	cert, _, err := pkiutil.LoadCertificatePairFromDir(pkiDir, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to load %s certificate: %w", name, err)
+	}
+	return cert.CheckSignatureFrom(ca) != nil, nil
+}
+
+// expiringCertificates returns the names of the certificates managed by k8sd for the given role
+// that have less than the threshold fraction of their lifetime left.
+// Externally managed certificates are skipped on control plane nodes. On worker nodes, all certificates
+// are reported as externally managed, but are renewed through certificate signing requests.
+func expiringCertificates(certificates []apiv2.CertificateStatus, role apiv2.ClusterRole, now time.Time, threshold float64, lifetime func(name string) time.Duration) ([]string, error) {
+	var expiring []string
+	for _, certificate := range certificates {
+		if _, ok := apiv2.CertificatesByRole[role][apiv2.CertificateName(certificate.Name)]; !ok {
+			continue
+		}
+		if role == apiv2.ClusterRoleControlPlane && certificate.ExternallyManaged {
+			continue
+		}
+
+		expires, err := time.Parse(time.RFC3339, certificate.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse expiry %q of certificate %s: %w", certificate.Expires, certificate.Name, err)
+		}
+		deadline := now.Add(time.Duration(threshold * float64(lifetime(certificate.Name))))
+		if expires.Before(deadline) && !slices.Contains(expiring, certificate.Name) {
+			expiring = append(expiring, certificate.Name)
+		}
+	}
+	return expiring, nil
+}
+
+// ReconciledCh returns the channel that receives a value after each reconciliation loop.
+func (c *CertificateRotationController) ReconciledCh() <-chan struct{} {
+	return c.reconciledCh
+}
@@ -0,0 +1,241 @@
+package controllers_test
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdmock "github.com/canonical/k8sd/pkg/client/k8sd/mock"
+	"github.com/canonical/k8sd/pkg/k8sd/controllers"
+	"github.com/canonical/k8sd/pkg/snap/mock"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+)
+
+func TestCertificateRotationController(t *testing.T) {
+	newSnap := func(g Gomega, t *testing.T, certName string, worker bool, client *k8sdmock.Mock) *mock.Snap {
+		pkiDir := t.TempDir()
+		lockDir := t.TempDir()
+		argsDir := t.TempDir()
+
+		// the hostname, the kubernetes service address and the loopback address are added by k8sd itself
+		notBefore := time.Now()
+		template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: certName}, notBefore, notBefore.AddDate(1, 0, 0), false,
+			[]string{"kubernetes", "kubernetes.default.svc", "node-1", "lb.example.com", "lb.example.com"},
+			[]net.IP{net.ParseIP("10.152.183.1"), net.ParseIP("127.0.0.1"), net.ParseIP("10.0.0.10")})
+		g.Expect(err).To(Not(HaveOccurred()))
+		cert, key, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmRSA2048, template, nil, nil)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(os.WriteFile(filepath.Join(pkiDir, certName+".crt"), []byte(cert), 0o600)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(pkiDir, certName+".key"), []byte(key), 0o600)).To(Succeed())
+
+		if worker {
+			g.Expect(os.WriteFile(filepath.Join(lockDir, "worker"), nil, 0o600)).To(Succeed())
+		} else {
+			g.Expect(os.WriteFile(filepath.Join(argsDir, "kube-apiserver"), []byte("--service-cluster-ip-range=10.152.183.0/24\n"), 0o600)).To(Succeed())
+		}
+
+		return &mock.Snap{Mock: mock.Mock{
+			Hostname:            "node-1",
+			KubernetesPKIDir:    pkiDir,
+			LockFilesDir:        lockDir,
+			ServiceArgumentsDir: argsDir,
+			K8sdClient:          client,
+		}}
+	}
+
+	// reconcile runs a reconciliation loop of the controller, or one loop after each of the steps
+	reconcile := func(g Gomega, s *mock.Snap, steps ...func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		triggerCh := make(chan time.Time)
+		ctrl := controllers.NewCertificateRotationController(controllers.CertificateRotationControllerOpts{
+			Snap:      s,
+			TriggerCh: triggerCh,
+			Threshold: 0.25,
+			Validity:  120 * 24 * time.Hour,
+		})
+		go ctrl.Run(ctx)
+
+		if len(steps) == 0 {
+			steps = []func(){func() {}}
+		}
+		for _, step := range steps {
+			step()
+			triggerCh <- time.Now()
+			select {
+			case <-ctrl.ReconciledCh():
+			case <-time.After(channelSendTimeout):
+				g.Expect(false).To(BeTrue(), "timed out waiting for reconciliation")
+			}
+		}
+	}
+
+	expiresIn := func(d time.Duration) string {
+		return time.Now().Add(d).Format(time.RFC3339)
+	}
+
+	t.Run("NoRenewalWhenNotExpiring", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &k8sdmock.Mock{
+			NodeStatusInitialized: true,
+			CertificatesStatusResponse: apiv2.CertificatesStatusResponse{
+				Certificates: []apiv2.CertificateStatus{
+					{Name: "apiserver", Expires: expiresIn(300 * 24 * time.Hour)},
+					{Name: "admin.conf", Expires: expiresIn(300 * 24 * time.Hour)},
+				},
+			},
+		}
+		reconcile(g, newSnap(g, t, "apiserver", false, client))
+
+		g.Expect(client.RefreshCertificatesRunCalledWith).To(Equal(apiv2.RefreshCertificatesRunRequest{}))
+	})
+
+	t.Run("NoRenewalWhenNotInitialized", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &k8sdmock.Mock{
+			CertificatesStatusResponse: apiv2.CertificatesStatusResponse{
+				Certificates: []apiv2.CertificateStatus{
+					{Name: "apiserver", Expires: expiresIn(time.Hour)},
+				},
+			},
+		}
+		reconcile(g, newSnap(g, t, "apiserver", false, client))
+
+		g.Expect(client.RefreshCertificatesRunCalledWith).To(Equal(apiv2.RefreshCertificatesRunRequest{}))
+	})
+
+	t.Run("ControlPlane", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &k8sdmock.Mock{
+			NodeStatusInitialized: true,
+			CertificatesStatusResponse: apiv2.CertificatesStatusResponse{
+				Certificates: []apiv2.CertificateStatus{
+					{Name: "apiserver", Expires: expiresIn(10 * 24 * time.Hour)},
+					{Name: "front-proxy-client", Expires: expiresIn(10 * 24 * time.Hour), ExternallyManaged: true},
+					{Name: "admin.conf", Expires: expiresIn(-time.Hour)},
+					{Name: "scheduler.conf", Expires: expiresIn(300 * 24 * time.Hour)},
+					{Name: "client", Expires: expiresIn(time.Hour)},
+				},
+			},
+			RefreshCertificatesPlanResponse: apiv2.RefreshCertificatesPlanResponse{Seed: 42},
+		}
+		reconcile(g, newSnap(g, t, "apiserver", false, client))
+
+		g.Expect(client.RefreshCertificatesPlanCalledWith.Certificates).To(ConsistOf("apiserver", "admin.conf"))
+		g.Expect(client.RefreshCertificatesRunCalledWith).To(Equal(apiv2.RefreshCertificatesRunRequest{
+			Seed:              42,
+			Certificates:      []string{"apiserver", "admin.conf"},
+			ExpirationSeconds: int((120 * 24 * time.Hour).Seconds()),
+			ExtraSANs:         []string{"lb.example.com", "10.0.0.10"},
+		}))
+	})
+
+	t.Run("Worker", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &k8sdmock.Mock{
+			NodeStatusInitialized: true,
+			CertificatesStatusResponse: apiv2.CertificatesStatusResponse{
+				Certificates: []apiv2.CertificateStatus{
+					{Name: "kubelet", Expires: expiresIn(10 * 24 * time.Hour), ExternallyManaged: true},
+					{Name: "kubelet.conf", Expires: expiresIn(300 * 24 * time.Hour), ExternallyManaged: true},
+				},
+			},
+			RefreshCertificatesPlanResponse: apiv2.RefreshCertificatesPlanResponse{Seed: 7},
+		}
+		reconcile(g, newSnap(g, t, "kubelet", true, client))
+
+		g.Expect(client.RefreshCertificatesPlanCalledWith.Certificates).To(BeEmpty())
+		g.Expect(client.RefreshCertificatesRunCalledWith.Seed).To(Equal(7))
+		g.Expect(client.RefreshCertificatesRunCalledWith.Certificates).To(BeEmpty())
+		// the kubernetes service address is only added by k8sd on control plane nodes
+		g.Expect(client.RefreshCertificatesRunCalledWith.ExtraSANs).To(Equal([]string{"lb.example.com", "10.152.183.1", "10.0.0.10"}))
+	})
+
+	t.Run("Reissue", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &k8sdmock.Mock{
+			NodeStatusInitialized: true,
+			CertificatesStatusResponse: apiv2.CertificatesStatusResponse{
+				Certificates: []apiv2.CertificateStatus{
+					{Name: "apiserver", Expires: expiresIn(300 * 24 * time.Hour)},
+					{Name: "admin.conf", Expires: expiresIn(300 * 24 * time.Hour)},
+				},
+			},
+			RefreshCertificatesPlanResponse: apiv2.RefreshCertificatesPlanResponse{Seed: 3},
+		}
+		s := newSnap(g, t, "apiserver", false, client)
+
+		// the apiserver certificate is not signed by the current certificate authority
+		caCert, caKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, time.Now(), time.Now().AddDate(10, 0, 0), pkiutil.KeyAlgorithmRSA2048)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(os.WriteFile(filepath.Join(s.Mock.KubernetesPKIDir, "ca.crt"), []byte(caCert), 0o600)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(s.Mock.KubernetesPKIDir, "ca.key"), []byte(caKey), 0o600)).To(Succeed())
+
+		reconcile(g, s)
+
+		g.Expect(client.RefreshCertificatesPlanCalledWith.Certificates).To(BeEmpty())
+		g.Expect(client.RefreshCertificatesRunCalledWith.Seed).To(Equal(3))
+		g.Expect(client.RefreshCertificatesRunCalledWith.Certificates).To(BeEmpty())
+	})
+
+	t.Run("ProfileLifetime", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &k8sdmock.Mock{
+			NodeStatusInitialized: true,
+			GetClusterConfigResponse: apiv2.GetClusterConfigResponse{
+				Config: apiv2.UserFacingClusterConfig{
+					Annotations: map[string]string{"k8sd/v1alpha1/pki/profiles": "default:\n  lifetime: 20d\n"},
+				},
+			},
+			CertificatesStatusResponse: apiv2.CertificatesStatusResponse{
+				Certificates: []apiv2.CertificateStatus{
+					// a quarter of the 20 day lifetime is left
+					{Name: "apiserver", Expires: expiresIn(4 * 24 * time.Hour)},
+					{Name: "admin.conf", Expires: expiresIn(10 * 24 * time.Hour)},
+				},
+			},
+			RefreshCertificatesPlanResponse: apiv2.RefreshCertificatesPlanResponse{Seed: 5},
+		}
+		reconcile(g, newSnap(g, t, "apiserver", false, client))
+
+		g.Expect(client.RefreshCertificatesRunCalledWith.Certificates).To(Equal([]string{"apiserver"}))
+	})
+
+	t.Run("WorkerReusesPendingCSRs", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &k8sdmock.Mock{
+			NodeStatusInitialized: true,
+			CertificatesStatusResponse: apiv2.CertificatesStatusResponse{
+				Certificates: []apiv2.CertificateStatus{
+					{Name: "kubelet", Expires: expiresIn(10 * 24 * time.Hour), ExternallyManaged: true},
+				},
+			},
+			RefreshCertificatesPlanResponse: apiv2.RefreshCertificatesPlanResponse{Seed: 7},
+			RefreshCertificatesRunErr:       context.DeadlineExceeded,
+		}
+		reconcile(g, newSnap(g, t, "kubelet", true, client),
+			func() {},
+			func() {
+				// the certificate signing requests of the first attempt are reused instead of planning new ones
+				client.RefreshCertificatesPlanResponse = apiv2.RefreshCertificatesPlanResponse{Seed: 8}
+				client.RefreshCertificatesRunCalledWith = apiv2.RefreshCertificatesRunRequest{}
+			},
+		)
+
+		g.Expect(client.RefreshCertificatesRunCalledWith.Seed).To(Equal(7))
+	})
+}
@@ -3,6 +3,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/canonical/k8sd/pkg/k8sd/pki"
@@ -78,6 +80,34 @@ func (c *ControlPlaneConfigurationController) Run(ctx context.Context, getCluste
 }
 
 func (c *ControlPlaneConfigurationController) reconcile(ctx context.Context, config types.ClusterConfig) error {
+	// certificate authorities, e.g. during a CA rotation
+	// NOTE: externally managed certificate authorities are not reconciled.
+	if config.Certificates.GetCAKey() != "" && config.Certificates.GetClientCAKey() != "" && config.Certificates.GetFrontProxyCAKey() != "" {
+		certificatesChanged, err := setup.EnsureControlPlaneCAs(c.snap, &pki.ControlPlanePKI{
+			CACert:           config.Certificates.GetCACert(),
+			CAKey:            config.Certificates.GetCAKey(),
+			ClientCACert:     config.Certificates.GetClientCACert(),
+			ClientCAKey:      config.Certificates.GetClientCAKey(),
+			FrontProxyCACert: config.Certificates.GetFrontProxyCACert(),
+			FrontProxyCAKey:  config.Certificates.GetFrontProxyCAKey(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reconcile certificate authorities: %w", err)
+		}
+
+		kubeconfigsChanged, err := setup.UpdateKubeconfigsCA(c.snap.KubernetesConfigDir(), []string{"admin.conf", "controller.conf", "proxy.conf", "scheduler.conf", "kubelet.conf"}, config.Certificates.GetCACert())
+		if err != nil {
+			return fmt.Errorf("failed to update certificate authority of kubeconfigs: %w", err)
+		}
+
+		if certificatesChanged || kubeconfigsChanged {
+			log.FromContext(ctx).Info("Certificate authorities changed, restarting control plane services")
+			if err := snaputil.RestartControlPlaneServices(ctx, c.snap, config); err != nil {
+				return fmt.Errorf("failed to restart control plane services to apply certificate authorities: %w", err)
+			}
+		}
+	}
+
 	// kube-apiserver: external datastore
 	if config.Datastore.GetType() == "external" {
 		// certificates
@@ -108,6 +138,13 @@ func (c *ControlPlaneConfigurationController) reconcile(ctx context.Context, con
 		}
 	}
 
+	// kube-apiserver: revocation webhook
+	// NOTE: errors are logged so that they do not block the reconciliation of other services, e.g. when the
+	// webhook is enabled while kube-apiserver already uses a different authorization webhook.
+	if err := c.reconcileRevocationWebhook(ctx, config); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to reconcile kube-apiserver revocation webhook")
+	}
+
 	// kube-controller-manager: cloud-provider
 	if v := config.Kubelet.CloudProvider; v != nil {
 		mustRestart, err := snaputil.UpdateServiceArguments(c.snap, "kube-controller-manager", map[string]string{"--cloud-provider": *v}, nil)
@@ -132,6 +169,38 @@ func (c *ControlPlaneConfigurationController) reconcile(ctx context.Context, con
 	return nil
 }
 
+// reconcileRevocationWebhook enables or disables the k8sd authorization webhook of kube-apiserver.
+func (c *ControlPlaneConfigurationController) reconcileRevocationWebhook(ctx context.Context, config types.ClusterConfig) error {
+	// NOTE: the webhook configuration is written when kube-apiserver is set up, nodes that were set up by older
+	// versions of k8sd do not have it.
+	if _, err := os.Stat(filepath.Join(c.snap.ServiceExtraConfigDir(), "authorization-webhook.conf")); err != nil {
+		if config.RevocationWebhookEnabled() {
+			return fmt.Errorf("cannot enable the revocation webhook, kube-apiserver must be set up again: %w", err)
+		}
+		return nil
+	}
+
+	updateArgs, deleteArgs, err := setup.KubeAPIServerRevocationWebhookArgs(c.snap, config.RevocationWebhookEnabled())
+	if err != nil {
+		return fmt.Errorf("failed to get revocation webhook arguments for kube-apiserver: %w", err)
+	}
+	if len(updateArgs) == 0 && len(deleteArgs) == 0 {
+		return nil
+	}
+
+	argsChanged, err := snaputil.UpdateServiceArguments(c.snap, "kube-apiserver", updateArgs, deleteArgs)
+	if err != nil {
+		return fmt.Errorf("failed to update kube-apiserver revocation webhook arguments: %w", err)
+	}
+
+	if argsChanged {
+		if err := c.snap.RestartServices(ctx, []string{"kube-apiserver"}); err != nil {
+			return fmt.Errorf("failed to restart kube-apiserver to apply configuration: %w", err)
+		}
+	}
+	return nil
+}
+
 // ReconciledCh returns the channel where the controller pushes when a reconciliation loop is finished.
 func (c *ControlPlaneConfigurationController) ReconciledCh() <-chan struct{} {
 	return c.reconciledCh
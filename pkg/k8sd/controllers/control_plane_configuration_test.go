@@ -33,15 +33,19 @@ func TestControlPlaneConfigController(t *testing.T) {
 
 		s := &mock.Snap{
 			Mock: mock.Mock{
-				EtcdPKIDir:          filepath.Join(dir, "etcd-pki"),
-				ServiceArgumentsDir: filepath.Join(dir, "args"),
-				UID:                 os.Getuid(),
-				GID:                 os.Getgid(),
+				EtcdPKIDir:            filepath.Join(dir, "etcd-pki"),
+				KubernetesPKIDir:      filepath.Join(dir, "pki"),
+				KubernetesConfigDir:   filepath.Join(dir, "config"),
+				ServiceArgumentsDir:   filepath.Join(dir, "args"),
+				ServiceExtraConfigDir: filepath.Join(dir, "args", "conf.d"),
+				UID:                   os.Getuid(),
+				GID:                   os.Getgid(),
 			},
 		}
 
 		g := NewWithT(t)
 		g.Expect(setup.EnsureAllDirectories(s)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(dir, "args", "conf.d", "authorization-webhook.conf"), nil, 0o600)).To(Succeed())
 
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -172,6 +176,69 @@ func TestControlPlaneConfigController(t *testing.T) {
 				},
 				expectServiceRestarts: []string{"kube-apiserver", "kube-controller-manager"},
 			},
+			{
+				name: "CertificateAuthorities",
+				config: types.ClusterConfig{
+					Certificates: types.Certificates{
+						CACert:           utils.Pointer("CA DATA"),
+						CAKey:            utils.Pointer("CA KEY DATA"),
+						ClientCACert:     utils.Pointer("CLIENT CA DATA"),
+						ClientCAKey:      utils.Pointer("CLIENT CA KEY DATA"),
+						FrontProxyCACert: utils.Pointer("FRONT PROXY CA DATA"),
+						FrontProxyCAKey:  utils.Pointer("FRONT PROXY CA KEY DATA"),
+					},
+				},
+				expectFilesToExist: map[string]bool{
+					filepath.Join(dir, "pki", "ca.crt"):             true,
+					filepath.Join(dir, "pki", "ca.key"):             true,
+					filepath.Join(dir, "pki", "client-ca.crt"):      true,
+					filepath.Join(dir, "pki", "client-ca.key"):      true,
+					filepath.Join(dir, "pki", "front-proxy-ca.crt"): true,
+					filepath.Join(dir, "pki", "front-proxy-ca.key"): true,
+				},
+				expectServiceRestarts: []string{"kube-apiserver", "kube-controller-manager", "kubelet"},
+			},
+			{
+				name: "CertificateAuthoritiesNoUpdates",
+				config: types.ClusterConfig{
+					Certificates: types.Certificates{
+						CACert:           utils.Pointer("CA DATA"),
+						CAKey:            utils.Pointer("CA KEY DATA"),
+						ClientCACert:     utils.Pointer("CLIENT CA DATA"),
+						ClientCAKey:      utils.Pointer("CLIENT CA KEY DATA"),
+						FrontProxyCACert: utils.Pointer("FRONT PROXY CA DATA"),
+						FrontProxyCAKey:  utils.Pointer("FRONT PROXY CA KEY DATA"),
+					},
+				},
+			},
+			{
+				name: "RevocationWebhook",
+				config: types.ClusterConfig{
+					Annotations: types.Annotations{types.AnnotationRevocationWebhook: "true"},
+				},
+				expectKubeAPIServerArgs: map[string]string{
+					"--authorization-mode":                "Webhook",
+					"--authorization-webhook-config-file": filepath.Join(dir, "args", "conf.d", "authorization-webhook.conf"),
+					"--authorization-webhook-version":     "v1",
+				},
+				expectServiceRestarts: []string{"kube-apiserver"},
+			},
+			{
+				name: "RevocationWebhookNoUpdates",
+				config: types.ClusterConfig{
+					Annotations: types.Annotations{types.AnnotationRevocationWebhook: "true"},
+				},
+			},
+			{
+				name:   "RevocationWebhookDisabled",
+				config: types.ClusterConfig{},
+				expectKubeAPIServerArgs: map[string]string{
+					"--authorization-mode":                "",
+					"--authorization-webhook-config-file": "",
+					"--authorization-webhook-version":     "",
+				},
+				expectServiceRestarts: []string{"kube-apiserver"},
+			},
 		} {
 			t.Run(tc.name, func(t *testing.T) {
 				g := NewWithT(t)
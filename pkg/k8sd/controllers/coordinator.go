@@ -63,9 +63,11 @@ func NewCoordinator(
 }
 
 // Run creates a manager, setup the controllers with the manager and starts the manager.
+// recordCertificate records the certificates signed for the nodes, so that they can be revoked.
 func (c *Coordinator) Run(
 	ctx context.Context,
 	getClusterConfig func(context.Context) (types.ClusterConfig, error),
+	recordCertificate func(context.Context, types.IssuedCertificate) error,
 ) error {
 	logger := log.FromContext(ctx).WithName("controller-coordinator")
 
@@ -120,7 +122,7 @@ func (c *Coordinator) Run(
 		return fmt.Errorf("failed to create manager: %w", err)
 	}
 
-	if err := c.setupControllers(ctx, getClusterConfig, mgr); err != nil {
+	if err := c.setupControllers(ctx, getClusterConfig, recordCertificate, mgr); err != nil {
 		return fmt.Errorf("failed to setup controllers: %w", err)
 	}
 
@@ -134,13 +136,14 @@ func (c *Coordinator) Run(
 func (c *Coordinator) setupControllers(
 	ctx context.Context,
 	getClusterConfig func(context.Context) (types.ClusterConfig, error),
+	recordCertificate func(context.Context, types.IssuedCertificate) error,
 	mgr manager.Manager,
 ) error {
 	if err := c.setupUpgradeController(ctx, getClusterConfig, mgr); err != nil {
 		return fmt.Errorf("failed to setup upgrade controller: %w", err)
 	}
 
-	if err := c.setupCSRSigningController(getClusterConfig, mgr); err != nil {
+	if err := c.setupCSRSigningController(getClusterConfig, recordCertificate, mgr); err != nil {
 		return fmt.Errorf("failed to setup CSR signing controller: %w", err)
 	}
 
@@ -188,6 +191,7 @@ func (c *Coordinator) setupUpgradeController(
 
 func (c *Coordinator) setupCSRSigningController(
 	getClusterConfig func(context.Context) (types.ClusterConfig, error),
+	recordCertificate func(context.Context, types.IssuedCertificate) error,
 	mgr manager.Manager,
 ) error {
 	logger := mgr.GetLogger()
@@ -201,6 +205,7 @@ func (c *Coordinator) setupCSRSigningController(
 		logger,
 		mgr.GetClient(),
 		getClusterConfig,
+		recordCertificate,
 	)
 
 	if err := csrsigningController.SetupWithManager(mgr); err != nil {
@@ -18,6 +18,7 @@ type Controller struct {
 	client               client.Client
 	managedSignerNames   map[string]struct{}
 	getClusterConfig     func(context.Context) (types.ClusterConfig, error)
+	recordCertificate    func(context.Context, types.IssuedCertificate) error
 	reconcileAutoApprove func(context.Context, log.Logger, *certv1.CertificateSigningRequest, *rsa.PrivateKey, client.Client) (ctrl.Result, error)
 }
 
@@ -25,6 +26,7 @@ func NewController(
 	logger logr.Logger,
 	client client.Client,
 	getClusterConfig func(context.Context) (types.ClusterConfig, error),
+	recordCertificate func(context.Context, types.IssuedCertificate) error,
 ) *Controller {
 	return &Controller{
 		logger: logger,
@@ -35,6 +37,7 @@ func NewController(
 			"k8sd.io/kube-proxy-client": {},
 		},
 		getClusterConfig:     getClusterConfig,
+		recordCertificate:    recordCertificate,
 		reconcileAutoApprove: reconcileAutoApprove,
 	}
 }
@@ -3,12 +3,15 @@ package csrsigning
 import (
 	"context"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/utils"
 	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
 	certv1 "k8s.io/api/certificates/v1"
@@ -108,10 +111,20 @@ func (r *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		notAfter = time.Now().AddDate(10, 0, 0)
 	}
 
-	var crtPEM []byte
+	// NOTE: Certificate profiles are keyed by the names of the certificates that the worker nodes request.
+	profiles := config.CertificateProfiles()
+
+	var (
+		crtPEM   []byte
+		caBundle string
+		// name is the name of the certificate, as used by certificate profiles and the issued certificates records.
+		name string
+	)
 	switch obj.Spec.SignerName {
 	case "k8sd.io/kubelet-serving":
-		caCert, caKey, err := pkiutil.LoadCertificate(config.Certificates.GetCACert(), config.Certificates.GetCAKey())
+		name = "kubelet"
+		caBundle = config.Certificates.GetCACert()
+		caCert, caKey, err := pkiutil.LoadCertificate(caBundle, config.Certificates.GetCAKey())
 		if err != nil {
 			log.Error(err, "Failed to load CA certificate and key")
 			return ctrl.Result{}, err
@@ -137,8 +150,10 @@ func (r *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			DNSNames:              certRequest.DNSNames,
 			BasicConstraintsValid: true,
 			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-			KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+			KeyUsage:              keyUsageForPublicKey(certRequest.PublicKey),
 		}
+		profiles.Get(name).Apply(cert)
+		capNotAfterToIssuer(cert, caCert)
 
 		derBytes, err := x509.CreateCertificate(rand.Reader, cert, caCert, certRequest.PublicKey, caKey)
 		if err != nil {
@@ -151,7 +166,9 @@ func (r *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			return ctrl.Result{RequeueAfter: requeueAfterSigningFailure}, nil
 		}
 	case "k8sd.io/kubelet-client":
-		caCert, caKey, err := pkiutil.LoadCertificate(config.Certificates.GetClientCACert(), config.Certificates.GetClientCAKey())
+		name = "kubelet.conf"
+		caBundle = config.Certificates.GetClientCACert()
+		caCert, caKey, err := pkiutil.LoadCertificate(caBundle, config.Certificates.GetClientCAKey())
 		if err != nil {
 			log.Error(err, "Failed to load client CA certificate and key")
 			return ctrl.Result{}, err
@@ -175,8 +192,10 @@ func (r *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			NotAfter:              notAfter,
 			BasicConstraintsValid: true,
 			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
-			KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+			KeyUsage:              keyUsageForPublicKey(certRequest.PublicKey),
 		}
+		profiles.Get(name).Apply(cert)
+		capNotAfterToIssuer(cert, caCert)
 
 		derBytes, err := x509.CreateCertificate(rand.Reader, cert, caCert, certRequest.PublicKey, caKey)
 		if err != nil {
@@ -189,7 +208,9 @@ func (r *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			return ctrl.Result{RequeueAfter: requeueAfterSigningFailure}, nil
 		}
 	case "k8sd.io/kube-proxy-client":
-		caCert, caKey, err := pkiutil.LoadCertificate(config.Certificates.GetClientCACert(), config.Certificates.GetClientCAKey())
+		name = "proxy.conf"
+		caBundle = config.Certificates.GetClientCACert()
+		caCert, caKey, err := pkiutil.LoadCertificate(caBundle, config.Certificates.GetClientCAKey())
 		if err != nil {
 			log.Error(err, "Failed to load client CA certificate and key")
 			return ctrl.Result{}, err
@@ -212,8 +233,10 @@ func (r *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			NotAfter:              notAfter,
 			BasicConstraintsValid: true,
 			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
-			KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+			KeyUsage:              keyUsageForPublicKey(certRequest.PublicKey),
 		}
+		profiles.Get(name).Apply(cert)
+		capNotAfterToIssuer(cert, caCert)
 
 		derBytes, err := x509.CreateCertificate(rand.Reader, cert, caCert, certRequest.PublicKey, caKey)
 		if err != nil {
@@ -231,7 +254,27 @@ func (r *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
-	obj.Status.Certificate = crtPEM
+	// NOTE: Certificates signed by an intermediate CA include the issuer chain.
+	chain, err := pkiutil.IssuerChain(caBundle)
+	if err != nil {
+		log.Error(err, "Failed to get CA certificate chain")
+		return ctrl.Result{}, err
+	}
+
+	// NOTE: Record the signed certificate, so that it is revoked when the node is removed.
+	if node := csrNodeName(obj); node != "" && r.recordCertificate != nil {
+		issued, err := types.NewIssuedCertificate(node, name, string(crtPEM))
+		if err != nil {
+			log.Error(err, "Failed to parse signed certificate")
+			return ctrl.Result{}, err
+		}
+		if err := r.recordCertificate(ctx, issued); err != nil {
+			log.Error(err, "Failed to record signed certificate")
+			return ctrl.Result{}, err
+		}
+	}
+
+	obj.Status.Certificate = append(crtPEM, chain...)
 	if err := r.client.Status().Update(ctx, obj); err != nil {
 		log.Error(err, "Failed to update CSR with signed certificate")
 		return ctrl.Result{}, err
@@ -241,6 +284,15 @@ func (r *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{}, nil
 }
 
+// csrNodeName returns the name of the node that requested the CSR.
+// Nodes request certificates with their kubelet credentials, and set the k8sd.io/node annotation.
+func csrNodeName(obj *certv1.CertificateSigningRequest) string {
+	if node, ok := strings.CutPrefix(obj.Spec.Username, "system:node:"); ok {
+		return node
+	}
+	return obj.GetAnnotations()["k8sd.io/node"]
+}
+
 func setFailedCSR(csr *certv1.CertificateSigningRequest, reason string, message string) {
 	failedCondition := certv1.CertificateSigningRequestCondition{
 		Type:           certv1.CertificateFailed,
@@ -252,3 +304,18 @@ func setFailedCSR(csr *certv1.CertificateSigningRequest, reason string, message
 
 	csr.Status.Conditions = append(csr.Status.Conditions, failedCondition)
 }
+
+// capNotAfterToIssuer ensures that the signed certificate does not outlive its issuer, e.g. an intermediate CA.
+func capNotAfterToIssuer(cert *x509.Certificate, issuer *x509.Certificate) {
+	if cert.NotAfter.After(issuer.NotAfter) {
+		cert.NotAfter = issuer.NotAfter
+	}
+}
+
+// keyUsageForPublicKey returns the key usage of a signed certificate. Key encipherment only applies to RSA keys.
+func keyUsageForPublicKey(pub any) x509.KeyUsage {
+	if _, ok := pub.(*rsa.PublicKey); ok {
+		return x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature
+	}
+	return x509.KeyUsageDigitalSignature
+}
@@ -29,7 +29,7 @@ func TestAutoApprove(t *testing.T) {
 			CommonName:   "system:node:valid-node",
 			Organization: []string{"system:nodes"},
 		},
-		2048,
+		pkiutil.KeyAlgorithmRSA2048,
 		nil,
 		nil,
 	)
@@ -3,6 +3,7 @@ package csrsigning
 import (
 	"context"
 	"crypto/rsa"
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"errors"
 	"testing"
@@ -364,7 +365,7 @@ func TestInvalidCACertificate(t *testing.T) {
 			CommonName:   "system:node:valid-node",
 			Organization: []string{"system:nodes"},
 		},
-		2048,
+		pkiutil.KeyAlgorithmRSA2048,
 		nil,
 		nil,
 	)
@@ -420,7 +421,7 @@ func TestUpdateCSRFailed(t *testing.T) {
 			CommonName:   "system:node:valid-node",
 			Organization: []string{"system:nodes"},
 		},
-		2048,
+		pkiutil.KeyAlgorithmRSA2048,
 		nil,
 		nil,
 	)
@@ -453,7 +454,7 @@ func TestUpdateCSRFailed(t *testing.T) {
 		nil,
 	)
 
-	caCert, caKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, time.Now(), time.Now().AddDate(10, 0, 0), 2048)
+	caCert, caKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, time.Now(), time.Now().AddDate(10, 0, 0), pkiutil.KeyAlgorithmRSA2048)
 	g.Expect(err).ToNot(HaveOccurred())
 
 	reconciler := &Controller{
@@ -484,7 +485,7 @@ func TestUpdateCSRSucceed(t *testing.T) {
 			CommonName:   "system:node:valid-node",
 			Organization: []string{"system:nodes"},
 		},
-		2048,
+		pkiutil.KeyAlgorithmRSA2048,
 		nil,
 		nil,
 	)
@@ -514,7 +515,7 @@ func TestUpdateCSRSucceed(t *testing.T) {
 		nil,
 	)
 
-	caCert, caKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, time.Now(), time.Now().AddDate(10, 0, 0), 2048)
+	caCert, caKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, time.Now(), time.Now().AddDate(10, 0, 0), pkiutil.KeyAlgorithmRSA2048)
 	g.Expect(err).ToNot(HaveOccurred())
 
 	reconciler := &Controller{
@@ -539,6 +540,161 @@ func TestUpdateCSRSucceed(t *testing.T) {
 	k8sM.AssertUpdateCalled(t)
 }
 
+func TestUpdateCSRWithCertificateProfile(t *testing.T) {
+	csrPEM, _, err := pkiutil.GenerateCSR(
+		pkix.Name{
+			CommonName:   "system:node:valid-node",
+			Organization: []string{"system:nodes"},
+		},
+		pkiutil.KeyAlgorithmRSA2048,
+		[]string{"valid-node"},
+		nil,
+	)
+
+	g := NewWithT(t)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	managedSigner := "k8sd.io/kubelet-serving"
+	csr := certv1.CertificateSigningRequest{
+		Spec: certv1.CertificateSigningRequestSpec{
+			SignerName:        managedSigner,
+			Request:           []byte(csrPEM),
+			ExpirationSeconds: ptr.To(int32(365 * 24 * 60 * 60)),
+		},
+		Status: certv1.CertificateSigningRequestStatus{
+			Conditions: []certv1.CertificateSigningRequestCondition{
+				{
+					Type: certv1.CertificateApproved,
+				},
+			},
+		},
+	}
+
+	k8sM := k8smock.New(
+		t,
+		k8smock.NewSubResourceClientMock(nil),
+		csr,
+		nil,
+	)
+
+	caCert, caKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, time.Now(), time.Now().AddDate(10, 0, 0), pkiutil.KeyAlgorithmRSA2048)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	reconciler := &Controller{
+		client: k8sM,
+		managedSignerNames: map[string]struct{}{
+			managedSigner: {},
+		},
+		getClusterConfig: func(context.Context) (types.ClusterConfig, error) {
+			return types.ClusterConfig{
+				Certificates: types.Certificates{
+					CACert: ptr.To(caCert),
+					CAKey:  ptr.To(caKey),
+				},
+				Annotations: types.Annotations{
+					types.AnnotationCertificateProfiles: "kubelet: {lifetime: 90d, extra-sans: [node.example.com], subject: {country: [GB]}}",
+				},
+			}, nil
+		},
+	}
+
+	result, err := reconciler.Reconcile(context.Background(), getDefaultRequest())
+
+	g.Expect(result).To(Equal(ctrl.Result{}))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	updated := k8sM.UpdatedCSR()
+	g.Expect(updated).ToNot(BeNil())
+	cert, _, err := pkiutil.LoadCertificate(string(updated.Status.Certificate), "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(90*24*time.Hour), time.Minute))
+	g.Expect(cert.DNSNames).To(Equal([]string{"valid-node", "node.example.com"}))
+	g.Expect(cert.Subject.Country).To(Equal([]string{"GB"}))
+	g.Expect(cert.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}))
+}
+
+func TestUpdateCSRRecordsCertificate(t *testing.T) {
+	csrPEM, _, err := pkiutil.GenerateCSR(
+		pkix.Name{
+			CommonName:   "system:node:valid-node",
+			Organization: []string{"system:nodes"},
+		},
+		pkiutil.KeyAlgorithmRSA2048,
+		nil,
+		nil,
+	)
+
+	g := NewWithT(t)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	managedSigner := "k8sd.io/kubelet-client"
+	csr := certv1.CertificateSigningRequest{
+		Spec: certv1.CertificateSigningRequestSpec{
+			SignerName: managedSigner,
+			Request:    []byte(csrPEM),
+			Username:   "system:node:valid-node",
+		},
+		Status: certv1.CertificateSigningRequestStatus{
+			Conditions: []certv1.CertificateSigningRequestCondition{
+				{
+					Type: certv1.CertificateApproved,
+				},
+			},
+		},
+	}
+
+	k8sM := k8smock.New(
+		t,
+		k8smock.NewSubResourceClientMock(nil),
+		csr,
+		nil,
+	)
+
+	caCert, caKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca-client"}, time.Now(), time.Now().AddDate(10, 0, 0), pkiutil.KeyAlgorithmRSA2048)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var recorded []types.IssuedCertificate
+	reconciler := &Controller{
+		client: k8sM,
+		managedSignerNames: map[string]struct{}{
+			managedSigner: {},
+		},
+		getClusterConfig: func(context.Context) (types.ClusterConfig, error) {
+			return types.ClusterConfig{
+				Certificates: types.Certificates{
+					ClientCACert: ptr.To(caCert),
+					ClientCAKey:  ptr.To(caKey),
+				},
+			}, nil
+		},
+		recordCertificate: func(_ context.Context, certificate types.IssuedCertificate) error {
+			recorded = append(recorded, certificate)
+			return nil
+		},
+	}
+
+	result, err := reconciler.Reconcile(context.Background(), getDefaultRequest())
+
+	g.Expect(result).To(Equal(ctrl.Result{}))
+	g.Expect(err).ToNot(HaveOccurred())
+	k8sM.AssertUpdateCalled(t)
+
+	g.Expect(recorded).To(HaveLen(1))
+	g.Expect(recorded[0].Node).To(Equal("valid-node"))
+	g.Expect(recorded[0].Name).To(Equal("kubelet.conf"))
+	g.Expect(recorded[0].Subject).To(Equal("system:node:valid-node"))
+
+	t.Run("RecordFailed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		recordErr := errors.New("failed to record")
+		reconciler.recordCertificate = func(context.Context, types.IssuedCertificate) error { return recordErr }
+
+		_, err := reconciler.Reconcile(context.Background(), getDefaultRequest())
+		g.Expect(err).To(MatchError(recordErr))
+	})
+}
+
 func getDefaultRequest() ctrl.Request {
 	return ctrl.Request{
 		NamespacedName: k8stypes.NamespacedName{
@@ -74,6 +74,15 @@ func (m *K8sMock) AssertUpdateCalled(t *testing.T) {
 	m.srcm.assertUpdateCalled(t)
 }
 
+// UpdatedCSR returns the CSR of the last update call, or nil if update was not called.
+func (m *K8sMock) UpdatedCSR() *certv1.CertificateSigningRequest {
+	if len(m.srcm.updateCalledWith) == 0 {
+		return nil
+	}
+	csr, _ := m.srcm.updateCalledWith[len(m.srcm.updateCalledWith)-1].obj.(*certv1.CertificateSigningRequest)
+	return csr
+}
+
 type updateArgs struct {
 	obj  client.Object
 	opts []client.SubResourceUpdateOption
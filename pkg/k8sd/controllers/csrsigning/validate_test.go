@@ -25,7 +25,7 @@ func TestValidateCSREncryption(t *testing.T) {
 			CommonName:   "system:node:valid-node",
 			Organization: []string{"system:nodes"},
 		},
-		2048,
+		pkiutil.KeyAlgorithmRSA2048,
 		nil,
 		nil,
 	)
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/k8sd/metrics"
+	"github.com/canonical/k8sd/pkg/log"
+	"github.com/canonical/k8sd/pkg/snap"
+	snaputil "github.com/canonical/k8sd/pkg/snap/util"
+)
+
+// EtcdSnapshotControllerOpts holds configuration for EtcdSnapshotController.
+type EtcdSnapshotControllerOpts struct {
+	// Snap is the snap interface.
+	Snap snap.Snap
+	// WaitReady is a function that blocks until the node is ready.
+	WaitReady func()
+	// TriggerCh drives the reconciliation loop. Typically time.NewTicker(<interval>).C.
+	TriggerCh <-chan time.Time
+}
+
+// EtcdSnapshotController periodically requests a scheduled snapshot of the managed etcd datastore.
+// The controller runs on all control plane nodes. Whether a snapshot is due is decided by k8sd, which only takes
+// snapshots on the node of the etcd leader, at the interval configured with the etcd snapshots annotation.
+type EtcdSnapshotController struct {
+	snap         snap.Snap
+	waitReady    func()
+	triggerCh    <-chan time.Time
+	reconciledCh chan struct{}
+}
+
+// NewEtcdSnapshotController creates a new EtcdSnapshotController.
+func NewEtcdSnapshotController(opts EtcdSnapshotControllerOpts) *EtcdSnapshotController {
+	if opts.WaitReady == nil {
+		opts.WaitReady = func() {}
+	}
+	return &EtcdSnapshotController{
+		snap:         opts.Snap,
+		waitReady:    opts.WaitReady,
+		triggerCh:    opts.TriggerCh,
+		reconciledCh: make(chan struct{}, 1),
+	}
+}
+
+// Run starts the controller and blocks until ctx is cancelled.
+func (c *EtcdSnapshotController) Run(ctx context.Context) {
+	ctx = log.NewContext(ctx, log.FromContext(ctx).WithValues("controller", "etcd-snapshot"))
+	log := log.FromContext(ctx)
+
+	c.waitReady()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.triggerCh:
+		}
+
+		if err := c.reconcile(ctx); err != nil {
+			metrics.EtcdSnapshotFailures.Inc()
+			log.Error(err, "failed to take etcd snapshot")
+		}
+
+		select {
+		case c.reconciledCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *EtcdSnapshotController) reconcile(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	if isWorker, err := snaputil.IsWorker(c.snap); err != nil {
+		return fmt.Errorf("failed to determine node type: %w", err)
+	} else if isWorker {
+		return nil
+	}
+
+	client, err := c.snap.K8sdClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create k8sd client: %w", err)
+	}
+
+	if _, initialized, err := client.NodeStatus(ctx); err != nil {
+		return fmt.Errorf("failed to check node status: %w", err)
+	} else if !initialized {
+		log.V(1).Info("Node is not initialized, skipping etcd snapshot")
+		return nil
+	}
+
+	resp, err := client.TakeEtcdSnapshot(ctx, k8sdapi.TakeEtcdSnapshotRequest{Scheduled: true})
+	if err != nil {
+		return fmt.Errorf("failed to take etcd snapshot: %w", err)
+	}
+	if resp.Snapshot == nil {
+		return nil
+	}
+
+	metrics.EtcdSnapshotLastSuccess.Set(float64(resp.Snapshot.CreatedAt.Unix()))
+	log.Info("Took scheduled etcd snapshot", "name", resp.Snapshot.Name, "size", resp.Snapshot.Size, "pruned", resp.Pruned)
+	return nil
+}
+
+// ReconciledCh returns the channel that receives a value after each reconciliation loop.
+func (c *EtcdSnapshotController) ReconciledCh() <-chan struct{} {
+	return c.reconciledCh
+}
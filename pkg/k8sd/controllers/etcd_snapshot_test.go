@@ -0,0 +1,85 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	k8sdmock "github.com/canonical/k8sd/pkg/client/k8sd/mock"
+	"github.com/canonical/k8sd/pkg/k8sd/controllers"
+	"github.com/canonical/k8sd/pkg/k8sd/metrics"
+	"github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEtcdSnapshotController(t *testing.T) {
+	reconcile := func(g Gomega, s *mock.Snap) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		triggerCh := make(chan time.Time)
+		ctrl := controllers.NewEtcdSnapshotController(controllers.EtcdSnapshotControllerOpts{
+			Snap:      s,
+			TriggerCh: triggerCh,
+		})
+		go ctrl.Run(ctx)
+
+		triggerCh <- time.Now()
+		select {
+		case <-ctrl.ReconciledCh():
+		case <-time.After(channelSendTimeout):
+			g.Expect(false).To(BeTrue(), "timed out waiting for reconciliation")
+		}
+	}
+
+	t.Run("TakesScheduledSnapshot", func(t *testing.T) {
+		g := NewWithT(t)
+
+		createdAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+		client := &k8sdmock.Mock{
+			NodeStatusInitialized: true,
+			TakeEtcdSnapshotResponse: k8sdapi.TakeEtcdSnapshotResponse{
+				Snapshot: &k8sdapi.EtcdSnapshot{Name: "etcd-snapshot-cp1-20261016T120000Z.db", Size: 1024, CreatedAt: createdAt},
+			},
+		}
+		reconcile(g, &mock.Snap{Mock: mock.Mock{LockFilesDir: t.TempDir(), K8sdClient: client}})
+
+		g.Expect(client.TakeEtcdSnapshotCalledWith).To(Equal(k8sdapi.TakeEtcdSnapshotRequest{Scheduled: true}))
+		g.Expect(testutil.ToFloat64(metrics.EtcdSnapshotLastSuccess)).To(Equal(float64(createdAt.Unix())))
+	})
+
+	t.Run("SkipsUninitializedNode", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &k8sdmock.Mock{}
+		reconcile(g, &mock.Snap{Mock: mock.Mock{LockFilesDir: t.TempDir(), K8sdClient: client}})
+
+		g.Expect(client.TakeEtcdSnapshotCalledWith).To(BeZero())
+	})
+
+	t.Run("SkipsWorker", func(t *testing.T) {
+		g := NewWithT(t)
+
+		lockDir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(lockDir, "worker"), nil, 0o600)).To(Succeed())
+		client := &k8sdmock.Mock{NodeStatusInitialized: true}
+		reconcile(g, &mock.Snap{Mock: mock.Mock{LockFilesDir: lockDir, K8sdClient: client}})
+
+		g.Expect(client.TakeEtcdSnapshotCalledWith).To(BeZero())
+	})
+
+	t.Run("CountsFailures", func(t *testing.T) {
+		g := NewWithT(t)
+
+		failures := testutil.ToFloat64(metrics.EtcdSnapshotFailures)
+		client := &k8sdmock.Mock{NodeStatusInitialized: true, TakeEtcdSnapshotErr: errors.New("etcd unavailable")}
+		reconcile(g, &mock.Snap{Mock: mock.Mock{LockFilesDir: t.TempDir(), K8sdClient: client}})
+
+		g.Expect(testutil.ToFloat64(metrics.EtcdSnapshotFailures)).To(Equal(failures + 1))
+	})
+}
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,119 +20,124 @@ import (
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 )
 
-// FeatureController manages the lifecycle of built-in Canonical Kubernetes features on a running cluster.
-// The controller has separate trigger channels for each feature.
+// FeatureController manages the lifecycle of Canonical Kubernetes features on a running cluster.
+// The controller reconciles every feature of its registry on a separate loop, with a trigger channel per feature.
 type FeatureController struct {
 	snap      snap.Snap
 	waitReady func()
 
 	readyCh chan struct{}
 
-	triggerNetworkCh       chan struct{}
-	triggerGatewayCh       chan struct{}
-	triggerIngressCh       chan struct{}
-	triggerLoadBalancerCh  chan struct{}
-	triggerDNSCh           chan struct{}
-	triggerLocalStorageCh  chan struct{}
-	triggerMetricsServerCh chan struct{}
+	registry *features.Registry
+
+	triggerChs map[types.FeatureName]chan struct{}
 
 	// TODO(Hue): (KU-3219) Change these with an atomic bool or something similar.
 	// Because we don't close them when the feature is reconciled, we simply
 	// put something into them. And that thing is going to be gone as soon as we
 	// read from these channels. So "checking to see if a feature is reconciled",
 	// will technically cause it to be considered "not-reconciled" immediately.
-	reconciledNetworkCh       chan struct{}
-	reconciledGatewayCh       chan struct{}
-	reconciledIngressCh       chan struct{}
-	reconciledLoadBalancerCh  chan struct{}
-	reconciledDNSCh           chan struct{}
-	reconciledLocalStorageCh  chan struct{}
-	reconciledMetricsServerCh chan struct{}
+	reconciledChs map[types.FeatureName]chan struct{}
 
 	// reconcileLoopMaxRetryAttempts is the maximum number of retry attempts for the reconcile loop.
 	// Zero or negative values mean unlimited retries.
 	reconcileLoopMaxRetryAttempts int
 
-	// ciliumLock is a mutex to ensure that only one reconciliation is
-	// happening for Cilium-related features (that operate on the `ck-network` chart) at a time.
-	// Currently, these features include:
-	// - Network
-	// - Gateway
-	// - Ingress
-	ciliumLock sync.Mutex
+	// locks holds a mutex per lock name (see features.Feature.Lock), to ensure that only one
+	// reconciliation is happening at a time for features that share a lock, e.g. the Cilium-related
+	// features that operate on the `ck-network` chart.
+	locks map[string]*sync.Mutex
+
+	// appliedMu guards applied, lastStatus and unhealthy.
+	appliedMu sync.RWMutex
+	// applied tracks the features that were last applied successfully and are enabled.
+	// Features wait for their enabled dependencies to be applied and healthy before being applied.
+	applied map[types.FeatureName]bool
+
+	// lastStatus holds the status of the last successful apply of the applied features.
+	// It is restored when an unhealthy feature recovers.
+	lastStatus map[types.FeatureName]types.FeatureStatus
+	// unhealthy holds the last reported health check error of the applied features that are unhealthy.
+	unhealthy map[types.FeatureName]string
+
+	// dependencyRetryInterval is the interval to re-check the dependencies of a feature that is waiting for them.
+	dependencyRetryInterval time.Duration
+	// healthCheckInterval is the interval to check the health of the applied features.
+	healthCheckInterval time.Duration
 }
 
+// errWaitingForDependencies is returned when a feature cannot be applied because its dependencies are not healthy yet.
+var errWaitingForDependencies = errors.New("waiting for dependencies")
+
 // ReadyCh returns a channel that is closed when the controller is ready.
 // This is used to signal to other components that they can start using the controller.
 func (c *FeatureController) ReadyCh() <-chan struct{} {
 	return c.readyCh
 }
 
-func (c *FeatureController) ReconciledNetworkCh() <-chan struct{} {
-	return c.reconciledNetworkCh
-}
-
-func (c *FeatureController) ReconciledGatewayCh() <-chan struct{} {
-	return c.reconciledGatewayCh
-}
-
-func (c *FeatureController) ReconciledIngressCh() <-chan struct{} {
-	return c.reconciledIngressCh
-}
-
-func (c *FeatureController) ReconciledLoadBalancerCh() <-chan struct{} {
-	return c.reconciledLoadBalancerCh
-}
-
-func (c *FeatureController) ReconciledDNSCh() <-chan struct{} {
-	return c.reconciledDNSCh
-}
-
-func (c *FeatureController) ReconciledLocalStorageCh() <-chan struct{} {
-	return c.reconciledLocalStorageCh
-}
-
-func (c *FeatureController) ReconciledMetricsServerCh() <-chan struct{} {
-	return c.reconciledMetricsServerCh
+// ReconciledChs returns a map of feature names to channels that are full when the feature has been reconciled.
+func (c *FeatureController) ReconciledChs() map[types.FeatureName]<-chan struct{} {
+	chs := make(map[types.FeatureName]<-chan struct{}, len(c.reconciledChs))
+	for name, ch := range c.reconciledChs {
+		chs[name] = ch
+	}
+	return chs
 }
 
 type FeatureControllerOpts struct {
 	Snap      snap.Snap
 	WaitReady func()
 
-	TriggerNetworkCh       chan struct{}
-	TriggerGatewayCh       chan struct{}
-	TriggerIngressCh       chan struct{}
-	TriggerLoadBalancerCh  chan struct{}
-	TriggerDNSCh           chan struct{}
-	TriggerLocalStorageCh  chan struct{}
-	TriggerMetricsServerCh chan struct{}
+	// Registry is the registry of features to manage.
+	Registry *features.Registry
+	// TriggerChs maps feature names to the channels that trigger their reconciliation.
+	// Features of the registry without a trigger channel are never reconciled.
+	TriggerChs map[types.FeatureName]chan struct{}
 
 	// ReconcileLoopMaxRetryAttempts is the maximum number of retry attempts for the reconcile loop.
 	// Zero or negative values mean unlimited retries.
 	ReconcileLoopMaxRetryAttempts int
+
+	// DependencyRetryInterval is the interval to re-check the dependencies of a feature that is waiting for them.
+	// Defaults to 10 seconds.
+	DependencyRetryInterval time.Duration
+
+	// HealthCheckInterval is the interval to check the health of the applied features.
+	// Defaults to 1 minute.
+	HealthCheckInterval time.Duration
 }
 
 func NewFeatureController(opts FeatureControllerOpts) *FeatureController {
+	reconciledChs := make(map[types.FeatureName]chan struct{}, len(opts.TriggerChs))
+	locks := make(map[string]*sync.Mutex)
+	for _, feature := range opts.Registry.Features() {
+		reconciledChs[feature.Name] = make(chan struct{}, 1)
+		if feature.Lock != "" && locks[feature.Lock] == nil {
+			locks[feature.Lock] = &sync.Mutex{}
+		}
+	}
+
+	if opts.DependencyRetryInterval == 0 {
+		opts.DependencyRetryInterval = 10 * time.Second
+	}
+	if opts.HealthCheckInterval == 0 {
+		opts.HealthCheckInterval = time.Minute
+	}
+
 	return &FeatureController{
 		snap:                          opts.Snap,
 		waitReady:                     opts.WaitReady,
 		readyCh:                       make(chan struct{}),
-		triggerNetworkCh:              opts.TriggerNetworkCh,
-		triggerGatewayCh:              opts.TriggerGatewayCh,
-		triggerIngressCh:              opts.TriggerIngressCh,
-		triggerLoadBalancerCh:         opts.TriggerLoadBalancerCh,
-		triggerDNSCh:                  opts.TriggerDNSCh,
-		triggerLocalStorageCh:         opts.TriggerLocalStorageCh,
-		triggerMetricsServerCh:        opts.TriggerMetricsServerCh,
-		reconciledNetworkCh:           make(chan struct{}, 1),
-		reconciledGatewayCh:           make(chan struct{}, 1),
-		reconciledIngressCh:           make(chan struct{}, 1),
-		reconciledLoadBalancerCh:      make(chan struct{}, 1),
-		reconciledDNSCh:               make(chan struct{}, 1),
-		reconciledLocalStorageCh:      make(chan struct{}, 1),
-		reconciledMetricsServerCh:     make(chan struct{}, 1),
+		registry:                      opts.Registry,
+		triggerChs:                    opts.TriggerChs,
+		reconciledChs:                 reconciledChs,
 		reconcileLoopMaxRetryAttempts: opts.ReconcileLoopMaxRetryAttempts,
+		locks:                         locks,
+		applied:                       make(map[types.FeatureName]bool),
+		lastStatus:                    make(map[types.FeatureName]types.FeatureStatus),
+		unhealthy:                     make(map[types.FeatureName]string),
+		dependencyRetryInterval:       opts.DependencyRetryInterval,
+		healthCheckInterval:           opts.HealthCheckInterval,
 	}
 }
 
@@ -138,8 +145,10 @@ func (c *FeatureController) Run(
 	ctx context.Context,
 	getClusterConfig func(context.Context) (types.ClusterConfig, error),
 	getState func() mctypes.State,
-	notifyDNSChangedIP func(ctx context.Context, dnsIP string) error,
+	updateClusterConfig func(ctx context.Context, config types.ClusterConfig) error,
 	setFeatureStatus func(ctx context.Context, name types.FeatureName, featureStatus types.FeatureStatus) error,
+	addFeatureStatusHistory func(ctx context.Context, name types.FeatureName, entry types.FeatureStatusHistoryEntry) error,
+	deleteFeatureStatus func(ctx context.Context, name types.FeatureName) error,
 ) {
 	ctx = log.NewContext(ctx, log.FromContext(ctx).WithValues("controller", "feature"))
 	log := log.FromContext(ctx)
@@ -157,57 +166,101 @@ func (c *FeatureController) Run(
 	log.Info("Starting feature controller")
 	c.waitReady()
 
-	s := getState()
+	env := features.Env{
+		Snap:                c.snap,
+		State:               getState(),
+		UpdateClusterConfig: updateClusterConfig,
+		SetFeatureStatus:    setFeatureStatus,
+		DeleteFeatureStatus: deleteFeatureStatus,
+	}
 
-	go c.reconcileLoop(ctx, getClusterConfig, setFeatureStatus, features.Network, c.triggerNetworkCh, c.reconciledNetworkCh, func(cfg types.ClusterConfig) (types.FeatureStatus, error) {
-		c.ciliumLock.Lock()
-		defer c.ciliumLock.Unlock()
-		return features.Implementation.ApplyNetwork(ctx, c.snap, s, cfg.APIServer, cfg.Network, cfg.Annotations)
-	})
+	for _, feature := range c.registry.Features() {
+		triggerCh, ok := c.triggerChs[feature.Name]
+		if !ok {
+			log.Info("No trigger channel for feature - skipping", "feature", feature.Name)
+			continue
+		}
 
-	go c.reconcileLoop(ctx, getClusterConfig, setFeatureStatus, features.Gateway, c.triggerGatewayCh, c.reconciledGatewayCh, func(cfg types.ClusterConfig) (types.FeatureStatus, error) {
-		c.ciliumLock.Lock()
-		defer c.ciliumLock.Unlock()
-		return features.Implementation.ApplyGateway(ctx, c.snap, cfg.Gateway, cfg.Network, cfg.Annotations)
-	})
+		go c.reconcileLoop(ctx, getClusterConfig, setFeatureStatus, addFeatureStatusHistory, feature.Name, triggerCh, c.reconciledChs[feature.Name], func(cfg types.ClusterConfig) (types.FeatureStatus, error) {
+			return c.apply(ctx, env, feature, cfg)
+		})
+	}
 
-	go c.reconcileLoop(ctx, getClusterConfig, setFeatureStatus, features.Ingress, c.triggerIngressCh, c.reconciledIngressCh, func(cfg types.ClusterConfig) (types.FeatureStatus, error) {
-		c.ciliumLock.Lock()
-		defer c.ciliumLock.Unlock()
-		return features.Implementation.ApplyIngress(ctx, c.snap, cfg.Ingress, cfg.Network, cfg.Annotations)
-	})
+	go c.healthCheckLoop(ctx, setFeatureStatus, addFeatureStatusHistory)
 
-	go c.reconcileLoop(ctx, getClusterConfig, setFeatureStatus, features.LoadBalancer, c.triggerLoadBalancerCh, c.reconciledLoadBalancerCh, func(cfg types.ClusterConfig) (types.FeatureStatus, error) {
-		return features.Implementation.ApplyLoadBalancer(ctx, c.snap, cfg.LoadBalancer, cfg.Network, cfg.Annotations)
-	})
+	close(c.readyCh)
+	log.Info("Feature controller ready")
+}
 
-	go c.reconcileLoop(ctx, getClusterConfig, setFeatureStatus, features.LocalStorage, c.triggerLocalStorageCh, c.reconciledLocalStorageCh, func(cfg types.ClusterConfig) (types.FeatureStatus, error) {
-		return features.Implementation.ApplyLocalStorage(ctx, c.snap, cfg.LocalStorage, cfg.Annotations)
-	})
+// apply applies a feature once its dependencies are healthy.
+// When the feature becomes available, the reconciliation of its dependents is triggered.
+func (c *FeatureController) apply(ctx context.Context, env features.Env, feature features.Feature, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+	// dependencies are only awaited when enabling a feature, disabling is always possible
+	if feature.Enabled(cfg) {
+		if waiting := c.pendingDependencies(ctx, feature, cfg); len(waiting) > 0 {
+			return types.FeatureStatus{
+				Enabled: false,
+				Message: fmt.Sprintf("waiting for %s", strings.Join(waiting, ", ")),
+			}, fmt.Errorf("%w: %s", errWaitingForDependencies, strings.Join(waiting, ", "))
+		}
+	}
 
-	go c.reconcileLoop(ctx, getClusterConfig, setFeatureStatus, features.MetricsServer, c.triggerMetricsServerCh, c.reconciledMetricsServerCh, func(cfg types.ClusterConfig) (types.FeatureStatus, error) {
-		return features.Implementation.ApplyMetricsServer(ctx, c.snap, cfg.MetricsServer, cfg.Annotations)
-	})
+	if lock, ok := c.locks[feature.Lock]; ok {
+		lock.Lock()
+		defer lock.Unlock()
+	}
 
-	go c.reconcileLoop(ctx, getClusterConfig, setFeatureStatus, features.DNS, c.triggerDNSCh, c.reconciledDNSCh, func(cfg types.ClusterConfig) (types.FeatureStatus, error) {
-		featureStatus, dnsIP, err := features.Implementation.ApplyDNS(ctx, c.snap, cfg.DNS, cfg.Kubelet, cfg.Annotations)
+	status, err := feature.Apply(ctx, env, cfg)
+	applied := err == nil && status.Enabled
 
-		if err != nil {
-			return featureStatus, fmt.Errorf("failed to apply DNS configuration: %w", err)
-		} else if dnsIP != "" {
-			if err := notifyDNSChangedIP(ctx, dnsIP); err != nil {
-				// we already have featureStatus.Message which contains wrapped error of the Apply<Feature>
-				// (or empty if no error occurs). we further wrap the error to add the DNS IP change error to the message
-				changeErr := fmt.Errorf("failed to update DNS IP address to %s: %w", dnsIP, err)
-				featureStatus.Message = fmt.Sprintf("%s: %v", featureStatus.Message, changeErr)
-				return featureStatus, changeErr
+	c.appliedMu.Lock()
+	wasApplied := c.applied[feature.Name]
+	c.applied[feature.Name] = applied
+	if applied {
+		c.lastStatus[feature.Name] = status
+	} else {
+		delete(c.lastStatus, feature.Name)
+	}
+	c.appliedMu.Unlock()
+
+	// only trigger the dependents when the feature becomes available, they are retried periodically otherwise
+	if applied && !wasApplied {
+		for _, dependent := range c.registry.Dependents(feature.Name) {
+			if ch, ok := c.triggerChs[dependent]; ok {
+				utils.MaybeNotify(ch)
 			}
 		}
-		return featureStatus, nil
-	})
+	}
 
-	close(c.readyCh)
-	log.Info("Feature controller ready")
+	return status, err
+}
+
+// pendingDependencies returns the enabled dependencies of a feature that are not healthy yet.
+// Disabled dependencies are not awaited, e.g. to allow using a custom CNI instead of the network feature.
+func (c *FeatureController) pendingDependencies(ctx context.Context, feature features.Feature, cfg types.ClusterConfig) []string {
+	var pending []string
+	for _, name := range feature.Dependencies() {
+		dep, ok := c.registry.Get(name)
+		if !ok || !dep.Enabled(cfg) {
+			continue
+		}
+
+		c.appliedMu.RLock()
+		applied := c.applied[name]
+		c.appliedMu.RUnlock()
+
+		if !applied {
+			pending = append(pending, string(name))
+			continue
+		}
+		if dep.CheckStatus != nil {
+			if err := dep.CheckStatus(ctx, c.snap); err != nil {
+				log.FromContext(ctx).V(1).Info("Dependency is not healthy yet", "feature", feature.Name, "dependency", name, "reason", err.Error())
+				pending = append(pending, string(name))
+			}
+		}
+	}
+	return pending
 }
 
 func (c *FeatureController) reconcile(
@@ -215,18 +268,39 @@ func (c *FeatureController) reconcile(
 	getClusterConfig func(context.Context) (types.ClusterConfig, error),
 	apply func(cfg types.ClusterConfig) (types.FeatureStatus, error),
 	updateFeatureStatus func(context.Context, types.FeatureStatus) error,
+	addFeatureStatusHistory func(context.Context, types.FeatureStatusHistoryEntry) error,
 ) error {
 	cfg, err := getClusterConfig(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve cluster configuration: %w", err)
 	}
 
+	startedAt := time.Now()
 	status, applyErr := apply(cfg)
 	if err := updateFeatureStatus(ctx, status); err != nil {
 		// NOTE (hue): status update errors are not returned but only logged. we might need some retry logic in the future.
 		log.FromContext(ctx).WithValues("message", status.Message, "applied-successfully", applyErr == nil).Error(err, "Failed to update feature status")
 	}
 
+	entry := types.FeatureStatusHistoryEntry{
+		Outcome:   types.FeatureStatusOutcomeSucceeded,
+		Status:    status,
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt),
+	}
+	if errors.Is(applyErr, errWaitingForDependencies) {
+		entry.Outcome = types.FeatureStatusOutcomeWaiting
+	} else if applyErr != nil {
+		entry.Outcome = types.FeatureStatusOutcomeFailed
+		entry.Error = applyErr.Error()
+	}
+	if entry.ConfigHash, err = cfg.Hash(); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to compute cluster configuration hash")
+	}
+	if err := addFeatureStatusHistory(ctx, entry); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to record feature status history")
+	}
+
 	if applyErr != nil {
 		return fmt.Errorf("failed to apply configuration: %w", applyErr)
 	}
@@ -238,6 +312,7 @@ func (c *FeatureController) reconcileLoop(
 	ctx context.Context,
 	getClusterConfig func(context.Context) (types.ClusterConfig, error),
 	setFeatureStatus func(ctx context.Context, name types.FeatureName, status types.FeatureStatus) error,
+	addFeatureStatusHistory func(ctx context.Context, name types.FeatureName, entry types.FeatureStatusHistoryEntry) error,
 	featureName types.FeatureName,
 	triggerCh chan struct{},
 	reconciledCh chan struct{},
@@ -268,8 +343,17 @@ func (c *FeatureController) reconcileLoop(
 			}
 
 			if err := c.reconcile(ctx, getClusterConfig, apply, func(ctx context.Context, status types.FeatureStatus) error {
+				// the status of the apply replaces any reported health check error, so the health is reported again on the next check
+				defer c.resetHealth(featureName)
 				return setFeatureStatus(ctx, featureName, status)
-			}); err != nil {
+			}, func(ctx context.Context, entry types.FeatureStatusHistoryEntry) error {
+				return addFeatureStatusHistory(ctx, featureName, entry)
+			}); errors.Is(err, errWaitingForDependencies) {
+				// waiting for dependencies does not count as a failed attempt.
+				// dependents are also triggered as soon as a dependency is applied.
+				log.Info("Feature is waiting for its dependencies", "reason", err.Error())
+				time.AfterFunc(c.dependencyRetryInterval, func() { utils.MaybeNotify(triggerCh) })
+			} else if err != nil {
 				log.Error(err, "Failed to apply feature configuration")
 				attempts++
 
@@ -299,6 +383,98 @@ func (c *FeatureController) reconcileLoop(
 	}
 }
 
+// healthCheckLoop periodically checks the health of the applied features.
+func (c *FeatureController) healthCheckLoop(
+	ctx context.Context,
+	setFeatureStatus func(ctx context.Context, name types.FeatureName, status types.FeatureStatus) error,
+	addFeatureStatusHistory func(ctx context.Context, name types.FeatureName, entry types.FeatureStatusHistoryEntry) error,
+) {
+	ticker := time.NewTicker(c.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkHealth(ctx, setFeatureStatus, addFeatureStatusHistory)
+		}
+	}
+}
+
+// checkHealth checks the health of the applied features that implement CheckStatus.
+// The feature status is only updated when the health of a feature changes. Unhealthy features report the
+// health check error, and the status of the last apply is restored once they recover.
+// Health changes are also recorded in the feature status history.
+func (c *FeatureController) checkHealth(
+	ctx context.Context,
+	setFeatureStatus func(ctx context.Context, name types.FeatureName, status types.FeatureStatus) error,
+	addFeatureStatusHistory func(ctx context.Context, name types.FeatureName, entry types.FeatureStatusHistoryEntry) error,
+) {
+	for _, feature := range c.registry.Features() {
+		if feature.CheckStatus == nil {
+			continue
+		}
+
+		c.appliedMu.RLock()
+		applied := c.applied[feature.Name]
+		c.appliedMu.RUnlock()
+		if !applied {
+			continue
+		}
+
+		var message string
+		startedAt := time.Now()
+		if err := feature.CheckStatus(ctx, c.snap); err != nil {
+			message = err.Error()
+		}
+		duration := time.Since(startedAt)
+
+		c.appliedMu.Lock()
+		status, ok := c.lastStatus[feature.Name]
+		if !ok || c.unhealthy[feature.Name] == message {
+			c.appliedMu.Unlock()
+			continue
+		}
+		if message != "" {
+			c.unhealthy[feature.Name] = message
+			status.Message = fmt.Sprintf("enabled, but unhealthy: %s", message)
+		} else {
+			delete(c.unhealthy, feature.Name)
+		}
+		c.appliedMu.Unlock()
+
+		log := log.FromContext(ctx).WithValues("feature", feature.Name)
+		entry := types.FeatureStatusHistoryEntry{
+			Outcome:   types.FeatureStatusOutcomeRecovered,
+			Status:    status,
+			StartedAt: startedAt,
+			Duration:  duration,
+		}
+		if message != "" {
+			log.Info("Feature is unhealthy", "reason", message)
+			entry.Outcome = types.FeatureStatusOutcomeUnhealthy
+			entry.Error = message
+		} else {
+			log.Info("Feature recovered")
+		}
+		if err := setFeatureStatus(ctx, feature.Name, status); err != nil {
+			log.Error(err, "Failed to update feature status")
+		}
+		if err := addFeatureStatusHistory(ctx, feature.Name, entry); err != nil {
+			log.Error(err, "Failed to record feature status history")
+		}
+	}
+}
+
+// resetHealth forgets the health of a feature, e.g. after its status was overwritten by an apply.
+func (c *FeatureController) resetHealth(name types.FeatureName) {
+	c.appliedMu.Lock()
+	defer c.appliedMu.Unlock()
+
+	delete(c.unhealthy, name)
+}
+
 // isBlocked checks if the feature controller is blocked by an in-progress upgrade.
 // If an upgrade is in progress, the feature controller will not apply any configuration changes.
 func (c *FeatureController) isBlocked(ctx context.Context, getClusterConfig func(context.Context) (types.ClusterConfig, error)) (bool, error) {
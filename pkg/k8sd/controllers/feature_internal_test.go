@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/k8sd/features"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/snap"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+)
+
+func TestFeatureControllerApplyDependencies(t *testing.T) {
+	newFeature := func(name types.FeatureName, enabled bool, applied *[]types.FeatureName, dependsOn ...types.FeatureName) features.Feature {
+		return features.Feature{
+			Name:      name,
+			HasConfig: func(types.ClusterConfig) bool { return true },
+			Enabled:   func(types.ClusterConfig) bool { return enabled },
+			DependsOn: dependsOn,
+			Apply: func(context.Context, features.Env, types.ClusterConfig) (types.FeatureStatus, error) {
+				*applied = append(*applied, name)
+				return types.FeatureStatus{Enabled: enabled}, nil
+			},
+		}
+	}
+
+	t.Run("WaitForDependency", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var applied []types.FeatureName
+		registry := features.NewRegistry()
+		g.Expect(registry.Register(newFeature("a", true, &applied))).To(Succeed())
+		g.Expect(registry.Register(newFeature("b", true, &applied, "a"))).To(Succeed())
+
+		triggerB := make(chan struct{}, 1)
+		c := NewFeatureController(FeatureControllerOpts{
+			Snap:       &snapmock.Snap{},
+			Registry:   registry,
+			TriggerChs: map[types.FeatureName]chan struct{}{"b": triggerB},
+		})
+		a, _ := registry.Get("a")
+		b, _ := registry.Get("b")
+
+		status, err := c.apply(context.Background(), features.Env{}, b, types.ClusterConfig{})
+		g.Expect(err).To(MatchError(errWaitingForDependencies))
+		g.Expect(status.Message).To(Equal("waiting for a"))
+		g.Expect(applied).To(BeEmpty())
+
+		// applying the dependency triggers the dependent feature
+		_, err = c.apply(context.Background(), features.Env{}, a, types.ClusterConfig{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(triggerB).To(Receive())
+
+		_, err = c.apply(context.Background(), features.Env{}, b, types.ClusterConfig{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(applied).To(Equal([]types.FeatureName{"a", "b"}))
+	})
+
+	t.Run("DisabledDependency", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var applied []types.FeatureName
+		registry := features.NewRegistry()
+		g.Expect(registry.Register(newFeature("a", false, &applied))).To(Succeed())
+		g.Expect(registry.Register(newFeature("b", true, &applied, "a"))).To(Succeed())
+
+		c := NewFeatureController(FeatureControllerOpts{Snap: &snapmock.Snap{}, Registry: registry})
+		b, _ := registry.Get("b")
+
+		_, err := c.apply(context.Background(), features.Env{}, b, types.ClusterConfig{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(applied).To(Equal([]types.FeatureName{"b"}))
+	})
+
+	t.Run("UnhealthyDependency", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var applied []types.FeatureName
+		registry := features.NewRegistry()
+		a := newFeature("a", true, &applied)
+		a.CheckStatus = func(context.Context, snap.Snap) error { return errors.New("not ready") }
+		g.Expect(registry.Register(a)).To(Succeed())
+		g.Expect(registry.Register(newFeature("b", true, &applied, "a"))).To(Succeed())
+
+		c := NewFeatureController(FeatureControllerOpts{Snap: &snapmock.Snap{}, Registry: registry})
+		b, _ := registry.Get("b")
+
+		_, err := c.apply(context.Background(), features.Env{}, a, types.ClusterConfig{})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		status, err := c.apply(context.Background(), features.Env{}, b, types.ClusterConfig{})
+		g.Expect(err).To(MatchError(errWaitingForDependencies))
+		g.Expect(status.Message).To(Equal("waiting for a"))
+		g.Expect(applied).To(Equal([]types.FeatureName{"a"}))
+	})
+
+	t.Run("DisableWithoutWaiting", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var applied []types.FeatureName
+		registry := features.NewRegistry()
+		g.Expect(registry.Register(newFeature("a", true, &applied))).To(Succeed())
+		g.Expect(registry.Register(newFeature("b", false, &applied, "a"))).To(Succeed())
+
+		c := NewFeatureController(FeatureControllerOpts{Snap: &snapmock.Snap{}, Registry: registry})
+		b, _ := registry.Get("b")
+
+		_, err := c.apply(context.Background(), features.Env{}, b, types.ClusterConfig{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(applied).To(Equal([]types.FeatureName{"b"}))
+	})
+}
+
+func TestFeatureControllerCheckHealth(t *testing.T) {
+	g := NewWithT(t)
+
+	var healthErr error
+	registry := features.NewRegistry()
+	g.Expect(registry.Register(features.Feature{
+		Name:      "a",
+		HasConfig: func(types.ClusterConfig) bool { return true },
+		Enabled:   func(types.ClusterConfig) bool { return true },
+		Apply: func(context.Context, features.Env, types.ClusterConfig) (types.FeatureStatus, error) {
+			return types.FeatureStatus{Enabled: true, Version: "v1", Message: "enabled"}, nil
+		},
+		CheckStatus: func(context.Context, snap.Snap) error { return healthErr },
+	})).To(Succeed())
+
+	c := NewFeatureController(FeatureControllerOpts{Snap: &snapmock.Snap{}, Registry: registry})
+	a, _ := registry.Get("a")
+
+	statuses := map[types.FeatureName][]types.FeatureStatus{}
+	setFeatureStatus := func(_ context.Context, name types.FeatureName, status types.FeatureStatus) error {
+		statuses[name] = append(statuses[name], status)
+		return nil
+	}
+	var outcomes []types.FeatureStatusOutcome
+	addFeatureStatusHistory := func(_ context.Context, _ types.FeatureName, entry types.FeatureStatusHistoryEntry) error {
+		outcomes = append(outcomes, entry.Outcome)
+		return nil
+	}
+
+	// features that are not applied are not checked
+	healthErr = errors.New("pods not ready")
+	c.checkHealth(context.Background(), setFeatureStatus, addFeatureStatusHistory)
+	g.Expect(statuses).To(BeEmpty())
+
+	_, err := c.apply(context.Background(), features.Env{}, a, types.ClusterConfig{})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c.checkHealth(context.Background(), setFeatureStatus, addFeatureStatusHistory)
+	g.Expect(statuses["a"]).To(Equal([]types.FeatureStatus{
+		{Enabled: true, Version: "v1", Message: "enabled, but unhealthy: pods not ready"},
+	}))
+
+	// the status is only updated when the health changes
+	c.checkHealth(context.Background(), setFeatureStatus, addFeatureStatusHistory)
+	g.Expect(statuses["a"]).To(HaveLen(1))
+
+	healthErr = nil
+	c.checkHealth(context.Background(), setFeatureStatus, addFeatureStatusHistory)
+	g.Expect(statuses["a"]).To(HaveLen(2))
+	g.Expect(statuses["a"][1]).To(Equal(types.FeatureStatus{Enabled: true, Version: "v1", Message: "enabled"}))
+
+	// the health is reported again after the status was overwritten
+	healthErr = errors.New("pods not ready")
+	c.checkHealth(context.Background(), setFeatureStatus, addFeatureStatusHistory)
+	c.resetHealth("a")
+	c.checkHealth(context.Background(), setFeatureStatus, addFeatureStatusHistory)
+	g.Expect(statuses["a"]).To(HaveLen(4))
+
+	g.Expect(outcomes).To(Equal([]types.FeatureStatusOutcome{
+		types.FeatureStatusOutcomeUnhealthy,
+		types.FeatureStatusOutcomeRecovered,
+		types.FeatureStatusOutcomeUnhealthy,
+		types.FeatureStatusOutcomeUnhealthy,
+	}))
+}
+
+func TestFeatureControllerReconcileHistory(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		applyErr      error
+		expectOutcome types.FeatureStatusOutcome
+		expectError   string
+	}{
+		{name: "Succeeded", expectOutcome: types.FeatureStatusOutcomeSucceeded},
+		{name: "Failed", applyErr: errors.New("helm failed"), expectOutcome: types.FeatureStatusOutcomeFailed, expectError: "helm failed"},
+		{name: "Waiting", applyErr: errWaitingForDependencies, expectOutcome: types.FeatureStatusOutcomeWaiting},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := NewFeatureController(FeatureControllerOpts{Snap: &snapmock.Snap{}, Registry: features.NewRegistry()})
+			cfg := types.ClusterConfig{Annotations: types.Annotations{"key": "value"}}
+			hash, err := cfg.Hash()
+			g.Expect(err).ToNot(HaveOccurred())
+
+			var entries []types.FeatureStatusHistoryEntry
+			_ = c.reconcile(
+				context.Background(),
+				func(context.Context) (types.ClusterConfig, error) { return cfg, nil },
+				func(types.ClusterConfig) (types.FeatureStatus, error) {
+					return types.FeatureStatus{Enabled: tc.applyErr == nil, Message: "message"}, tc.applyErr
+				},
+				func(context.Context, types.FeatureStatus) error { return nil },
+				func(_ context.Context, entry types.FeatureStatusHistoryEntry) error {
+					entries = append(entries, entry)
+					return nil
+				},
+			)
+
+			g.Expect(entries).To(HaveLen(1))
+			g.Expect(entries[0].Outcome).To(Equal(tc.expectOutcome))
+			g.Expect(entries[0].Error).To(Equal(tc.expectError))
+			g.Expect(entries[0].ConfigHash).To(Equal(hash))
+			g.Expect(entries[0].Status.Message).To(Equal("message"))
+		})
+	}
+}
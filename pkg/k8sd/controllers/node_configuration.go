@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/canonical/k8sd/pkg/k8sd/pki"
+	"github.com/canonical/k8sd/pkg/k8sd/setup"
 	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/log"
 	"github.com/canonical/k8sd/pkg/snap"
@@ -121,12 +123,28 @@ func (c *NodeConfigurationController) reconcile(ctx context.Context, configMap *
 		return fmt.Errorf("failed to update kubelet arguments: %w", err)
 	}
 
-	if mustRestartKubelet {
-		log.Info("Kubelet arguments changed, restarting kubelet", "updateArgs", updateArgs, "deleteArgs", deleteArgs)
+	certificatesChanged, err := c.reconcileCertificateAuthorities(nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile certificate authorities: %w", err)
+	}
+
+	kubeProxyEnabled := nodeConfig.Network.GetKubeProxyEnabled()
+
+	if mustRestartKubelet || certificatesChanged {
+		services := []string{"kubelet"}
+		if certificatesChanged {
+			log.Info("Certificate authorities changed, restarting worker services")
+			services = append(services, "k8s-apiserver-proxy")
+			if kubeProxyEnabled {
+				services = append(services, "kube-proxy")
+			}
+		} else {
+			log.Info("Kubelet arguments changed, restarting kubelet", "updateArgs", updateArgs, "deleteArgs", deleteArgs)
+		}
 		// This may fail if other controllers try to restart the services at the same time, hence the retry.
 		if err := control.RetryFor(ctx, 5, 5*time.Second, func() error {
-			if err := c.snap.RestartServices(ctx, []string{"kubelet"}); err != nil {
-				return fmt.Errorf("failed to restart kubelet to apply node configuration: %w", err)
+			if err := c.snap.RestartServices(ctx, services); err != nil {
+				return fmt.Errorf("failed to restart %v to apply node configuration: %w", services, err)
 			}
 			return nil
 		}); err != nil {
@@ -134,7 +152,6 @@ func (c *NodeConfigurationController) reconcile(ctx context.Context, configMap *
 		}
 	}
 
-	kubeProxyEnabled := nodeConfig.Network.GetKubeProxyEnabled()
 	if err := updateKubeProxyEnabled(ctx, kubeProxyEnabled); err != nil {
 		return fmt.Errorf("failed to update kube-proxy enabled state: %w", err)
 	}
@@ -157,6 +174,37 @@ func (c *NodeConfigurationController) reconcile(ctx context.Context, configMap *
 	return nil
 }
 
+// reconcileCertificateAuthorities writes the CA certificates of the node configuration on worker nodes, and updates
+// the kubeconfig files to trust them. Control plane nodes reconcile the certificate authorities from the cluster
+// configuration instead, see ControlPlaneConfigurationController.
+// reconcileCertificateAuthorities returns true if any file was changed.
+func (c *NodeConfigurationController) reconcileCertificateAuthorities(nodeConfig types.ClusterConfig) (bool, error) {
+	if nodeConfig.Certificates.CACert == nil || nodeConfig.Certificates.ClientCACert == nil {
+		return false, nil
+	}
+
+	if isWorker, err := snaputil.IsWorker(c.snap); err != nil {
+		return false, fmt.Errorf("failed to check if running on a worker node: %w", err)
+	} else if !isWorker {
+		return false, nil
+	}
+
+	certificatesChanged, err := setup.EnsureWorkerCAs(c.snap, &pki.WorkerNodePKI{
+		CACert:       nodeConfig.Certificates.GetCACert(),
+		ClientCACert: nodeConfig.Certificates.GetClientCACert(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to write CA certificates: %w", err)
+	}
+
+	kubeconfigsChanged, err := setup.UpdateKubeconfigsCA(c.snap.KubernetesConfigDir(), []string{"kubelet.conf", "proxy.conf"}, nodeConfig.Certificates.GetCACert())
+	if err != nil {
+		return false, fmt.Errorf("failed to update certificate authority of kubeconfigs: %w", err)
+	}
+
+	return certificatesChanged || kubeconfigsChanged, nil
+}
+
 // ReconciledCh returns the channel where the controller pushes when a reconciliation loop is finished.
 func (c *NodeConfigurationController) ReconciledCh() <-chan struct{} {
 	return c.reconciledCh
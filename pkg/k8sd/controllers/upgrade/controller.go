@@ -18,13 +18,7 @@ type Controller struct {
 	logger                            logr.Logger
 	client                            client.Client
 	featureControllerReadyCh          <-chan struct{}
-	notifyNetworkFeature              func()
-	notifyGatewayFeature              func()
-	notifyIngressFeature              func()
-	notifyLoadBalancerFeature         func()
-	notifyLocalStorageFeature         func()
-	notifyMetricsServerFeature        func()
-	notifyDNSFeature                  func()
+	notifyFeature                     func(types.FeatureName)
 	featureToReconciledCh             map[types.FeatureName]<-chan struct{}
 	featureControllerReadyTimeout     time.Duration
 	featureControllerReconcileTimeout time.Duration
@@ -33,20 +27,8 @@ type Controller struct {
 type ControllerOptions struct {
 	// FeatureControllerReadyCh is a channel that is closed when the feature controller is ready.
 	FeatureControllerReadyCh <-chan struct{}
-	// NotifyNetworkFeature is a function that notifies the network feature to reconcile.
-	NotifyNetworkFeature func()
-	// NotifyGatewayFeature is a function that notifies the gateway feature to reconcile.
-	NotifyGatewayFeature func()
-	// NotifyIngressFeature is a function that notifies the ingress feature to reconcile.
-	NotifyIngressFeature func()
-	// NotifyLoadBalancerFeature is a function that notifies the load balancer feature to reconcile.
-	NotifyLoadBalancerFeature func()
-	// NotifyLocalStorageFeature is a function that notifies the local storage feature to reconcile.
-	NotifyLocalStorageFeature func()
-	// NotifyMetricsServerFeature is a function that notifies the metrics server feature to reconcile.
-	NotifyMetricsServerFeature func()
-	// NotifyDNSFeature is a function that notifies the DNS feature to reconcile.
-	NotifyDNSFeature func()
+	// NotifyFeature is a function that notifies the given feature to reconcile.
+	NotifyFeature func(types.FeatureName)
 	// FeatureToReconciledCh is a map of feature names to channels that are full
 	// when the feature controller has reconciled the feature.
 	FeatureToReconciledCh map[types.FeatureName]<-chan struct{}
@@ -65,13 +47,7 @@ func NewController(
 		logger:                            logger,
 		client:                            client,
 		featureControllerReadyCh:          opts.FeatureControllerReadyCh,
-		notifyNetworkFeature:              opts.NotifyNetworkFeature,
-		notifyGatewayFeature:              opts.NotifyGatewayFeature,
-		notifyIngressFeature:              opts.NotifyIngressFeature,
-		notifyLoadBalancerFeature:         opts.NotifyLoadBalancerFeature,
-		notifyLocalStorageFeature:         opts.NotifyLocalStorageFeature,
-		notifyMetricsServerFeature:        opts.NotifyMetricsServerFeature,
-		notifyDNSFeature:                  opts.NotifyDNSFeature,
+		notifyFeature:                     opts.NotifyFeature,
 		featureToReconciledCh:             opts.FeatureToReconciledCh,
 		featureControllerReadyTimeout:     opts.FeatureControllerReadyTimeout,
 		featureControllerReconcileTimeout: opts.FeatureControllerReconcileTimeout,
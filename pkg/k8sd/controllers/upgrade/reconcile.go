@@ -210,7 +210,14 @@ func (c *Controller) allNodesUpgraded(ctx context.Context, upgradedNodes []strin
 }
 
 func (c *Controller) waitForFeatureReconciliations(ctx context.Context, log logr.Logger) error {
-	for name, ch := range c.featureToReconciledCh {
+	// NOTE: features are reconciled in registration order, so that dependencies are reconciled first.
+	for _, feature := range features.Registered() {
+		name := feature.Name
+		ch, ok := c.featureToReconciledCh[name]
+		if !ok {
+			continue
+		}
+
 		if err := c.triggerFeature(name); err != nil {
 			return fmt.Errorf("failed to trigger feature %q: %w", name, err)
 		}
@@ -257,24 +264,10 @@ func (c *Controller) addToUpgradedNodes(ctx context.Context, upgrade *upgradesv1
 }
 
 func (c *Controller) triggerFeature(name types.FeatureName) error {
-	switch name {
-	case features.Network:
-		c.notifyNetworkFeature()
-	case features.Gateway:
-		c.notifyGatewayFeature()
-	case features.Ingress:
-		c.notifyIngressFeature()
-	case features.LoadBalancer:
-		c.notifyLoadBalancerFeature()
-	case features.LocalStorage:
-		c.notifyLocalStorageFeature()
-	case features.MetricsServer:
-		c.notifyMetricsServerFeature()
-	case features.DNS:
-		c.notifyDNSFeature()
-	default:
+	if _, ok := features.Get(name); !ok {
 		return fmt.Errorf("unknown feature %q", name)
 	}
 
+	c.notifyFeature(name)
 	return nil
 }
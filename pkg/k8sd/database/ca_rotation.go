@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/microcluster/v3/microcluster/db"
+)
+
+var caRotationStmts = map[string]int{
+	"insert-ca-rotation": MustPrepareStatement("cluster-configs", "insert-ca-rotation.sql"),
+	"select-ca-rotation": MustPrepareStatement("cluster-configs", "select-ca-rotation.sql"),
+	"delete-ca-rotation": MustPrepareStatement("cluster-configs", "delete-ca-rotation.sql"),
+}
+
+// SetCARotation stores the state of the in-progress certificate authority rotation.
+func SetCARotation(ctx context.Context, tx *sql.Tx, rotation types.CARotation) error {
+	b, err := json.Marshal(rotation)
+	if err != nil {
+		return fmt.Errorf("failed to encode CA rotation: %w", err)
+	}
+
+	insertTxStmt, err := db.Stmt(tx, caRotationStmts["insert-ca-rotation"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	if _, err := insertTxStmt.ExecContext(ctx, string(b)); err != nil {
+		return fmt.Errorf("failed to execute insert statement: %w", err)
+	}
+	return nil
+}
+
+// GetCARotation returns the state of the in-progress certificate authority rotation.
+// GetCARotation returns an empty CARotation if no rotation is in progress.
+func GetCARotation(ctx context.Context, tx *sql.Tx) (types.CARotation, error) {
+	selectTxStmt, err := db.Stmt(tx, caRotationStmts["select-ca-rotation"])
+	if err != nil {
+		return types.CARotation{}, fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+
+	var s string
+	if err := selectTxStmt.QueryRowContext(ctx).Scan(&s); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.CARotation{}, nil
+		}
+		return types.CARotation{}, fmt.Errorf("failed to retrieve CA rotation: %w", err)
+	}
+
+	var rotation types.CARotation
+	if err := json.Unmarshal([]byte(s), &rotation); err != nil {
+		return types.CARotation{}, fmt.Errorf("failed to parse CA rotation: %w", err)
+	}
+	return rotation, nil
+}
+
+// DeleteCARotation removes the state of the certificate authority rotation, once it is finished or aborted.
+func DeleteCARotation(ctx context.Context, tx *sql.Tx) error {
+	deleteTxStmt, err := db.Stmt(tx, caRotationStmts["delete-ca-rotation"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare delete statement: %w", err)
+	}
+	if _, err := deleteTxStmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to execute delete statement: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/k8sd/database"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	testenv "github.com/canonical/k8sd/pkg/utils/microcluster"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestCARotation(t *testing.T) {
+	testenv.WithState(t, func(ctx context.Context, s mctypes.State) {
+		t.Run("NotInProgress", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				rotation, err := database.GetCARotation(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(rotation.InProgress()).To(BeFalse())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+
+		t.Run("SetGetDelete", func(t *testing.T) {
+			g := NewWithT(t)
+			expected := types.CARotation{
+				Phase:     types.CARotationPhaseTrust,
+				StartedAt: time.Now().UTC().Truncate(time.Second),
+				UpdatedAt: time.Now().UTC().Truncate(time.Second),
+				Old:       types.Certificates{CACert: utils.Pointer("old-ca"), CAKey: utils.Pointer("old-ca-key")},
+				New:       types.Certificates{CACert: utils.Pointer("new-ca"), CAKey: utils.Pointer("new-ca-key")},
+			}
+
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				g.Expect(database.SetCARotation(ctx, tx, expected)).To(Succeed())
+
+				rotation, err := database.GetCARotation(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(rotation).To(Equal(expected))
+
+				// rotation state does not affect the cluster configuration
+				config, err := database.GetClusterConfig(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(config.Certificates.CACert).To(BeNil())
+
+				g.Expect(database.DeleteCARotation(ctx, tx)).To(Succeed())
+				rotation, err = database.GetCARotation(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(rotation.InProgress()).To(BeFalse())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+
+		t.Run("SetClusterCertificateAuthorities", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				_, err := database.SetClusterConfig(ctx, tx, types.ClusterConfig{
+					Certificates: types.Certificates{
+						CACert:            utils.Pointer("old-ca"),
+						CAKey:             utils.Pointer("old-ca-key"),
+						ServiceAccountKey: utils.Pointer("sa-key"),
+					},
+				})
+				g.Expect(err).To(Not(HaveOccurred()))
+
+				config, err := database.SetClusterCertificateAuthorities(ctx, tx, types.Certificates{
+					CACert: utils.Pointer("new-ca"),
+					CAKey:  utils.Pointer("new-ca-key"),
+				}, "test", "rotate")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(config.Certificates.GetCACert()).To(Equal("new-ca"))
+				g.Expect(config.Certificates.GetCAKey()).To(Equal("new-ca-key"))
+				g.Expect(config.Certificates.GetServiceAccountKey()).To(Equal("sa-key"))
+
+				config, err = database.GetClusterConfig(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(config.Certificates.GetCACert()).To(Equal("new-ca"))
+
+				revisions, err := database.GetClusterConfigRevisions(ctx, tx, 1)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(revisions).To(HaveLen(1))
+				g.Expect(revisions[0].RequestedBy).To(Equal("test"))
+				g.Expect(revisions[0].Reason).To(Equal("rotate"))
+				// the certificate authorities and their keys are not stored in revisions
+				g.Expect(revisions[0].Config.Certificates).To(BeZero())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+}
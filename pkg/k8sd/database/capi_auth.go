@@ -3,14 +3,16 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/canonical/microcluster/v3/microcluster/db"
 )
 
 var clusterAPIConfigsStmts = map[string]int{
-	"insert-capi-token": MustPrepareStatement("cluster-configs", "insert-capi-token.sql"),
-	"select-capi-token": MustPrepareStatement("cluster-configs", "select-capi-token.sql"),
+	"insert-capi-token":       MustPrepareStatement("cluster-configs", "insert-capi-token.sql"),
+	"select-capi-token":       MustPrepareStatement("cluster-configs", "select-capi-token.sql"),
+	"select-capi-token-value": MustPrepareStatement("cluster-configs", "select-capi-token-value.sql"),
 }
 
 // SetClusterAPIToken stores the ClusterAPI token in the cluster config.
@@ -45,3 +47,22 @@ func ValidateClusterAPIToken(ctx context.Context, tx *sql.Tx, token string) (boo
 
 	return exists, nil
 }
+
+// GetClusterAPIToken returns the stored ClusterAPI token.
+// GetClusterAPIToken returns an empty string if no token is set.
+func GetClusterAPIToken(ctx context.Context, tx *sql.Tx) (string, error) {
+	selectTxStmt, err := db.Stmt(tx, clusterAPIConfigsStmts["select-capi-token-value"])
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+
+	var token string
+	if err := selectTxStmt.QueryRowContext(ctx).Scan(&token); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to query ClusterAPI token: %w", err)
+	}
+
+	return token, nil
+}
@@ -18,8 +18,16 @@ func TestClusterAPIAuthTokens(t *testing.T) {
 		t.Run("SetAuthToken", func(t *testing.T) {
 			g := NewWithT(t)
 			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
-				err := database.SetClusterAPIToken(ctx, tx, token)
+				stored, err := database.GetClusterAPIToken(ctx, tx)
 				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(stored).To(BeEmpty())
+
+				err = database.SetClusterAPIToken(ctx, tx, token)
+				g.Expect(err).To(Not(HaveOccurred()))
+
+				stored, err = database.GetClusterAPIToken(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(stored).To(Equal(token))
 				return nil
 			})
 			g.Expect(err).To(Not(HaveOccurred()))
@@ -4,21 +4,42 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/log"
 	"github.com/canonical/microcluster/v3/microcluster/db"
 )
 
 var clusterConfigsStmts = map[string]int{
-	"insert-v1alpha2": MustPrepareStatement("cluster-configs", "insert-v1alpha2.sql"),
-	"select-v1alpha2": MustPrepareStatement("cluster-configs", "select-v1alpha2.sql"),
+	"insert-v1alpha2":        MustPrepareStatement("cluster-configs", "insert-v1alpha2.sql"),
+	"select-v1alpha2":        MustPrepareStatement("cluster-configs", "select-v1alpha2.sql"),
+	"insert-revision":        MustPrepareStatement("cluster-configs", "insert-revision.sql"),
+	"select-revision":        MustPrepareStatement("cluster-configs", "select-revision.sql"),
+	"select-revisions":       MustPrepareStatement("cluster-configs", "select-revisions.sql"),
+	"prune-revisions":        MustPrepareStatement("cluster-configs", "prune-revisions.sql"),
+	"select-latest-revision": MustPrepareStatement("cluster-configs", "select-latest-revision.sql"),
 }
 
+// ClusterConfigRevisionLimit is the number of cluster configuration revisions that are retained.
+const ClusterConfigRevisionLimit = 100
+
+// ErrClusterConfigRevisionNotFound is returned when a cluster configuration revision does not exist.
+var ErrClusterConfigRevisionNotFound = errors.New("cluster configuration revision not found")
+
 // SetClusterConfig updates the cluster configuration with any non-empty values that are set.
 // SetClusterConfig will attempt to merge the existing and new configs, and return an error if any protected fields have changed.
 // SetClusterConfig will return the merged cluster configuration on success.
+// Changes made with SetClusterConfig are recorded as requested by k8sd itself.
 func SetClusterConfig(ctx context.Context, tx *sql.Tx, new types.ClusterConfig) (types.ClusterConfig, error) {
+	return SetClusterConfigAs(ctx, tx, new, "k8sd", "")
+}
+
+// SetClusterConfigAs is like SetClusterConfig, but records who requested the change and why.
+// If the merged configuration differs from the latest revision, it is stored as a new revision.
+func SetClusterConfigAs(ctx context.Context, tx *sql.Tx, new types.ClusterConfig, requestedBy string, reason string) (types.ClusterConfig, error) {
 	old, err := GetClusterConfig(ctx, tx)
 	if err != nil {
 		return types.ClusterConfig{}, fmt.Errorf("failed to fetch existing cluster config: %w", err)
@@ -27,19 +48,272 @@ func SetClusterConfig(ctx context.Context, tx *sql.Tx, new types.ClusterConfig)
 	if err != nil {
 		return types.ClusterConfig{}, fmt.Errorf("failed to merge new cluster configuration options: %w", err)
 	}
+	if err := putClusterConfig(ctx, tx, config, requestedBy, reason); err != nil {
+		return types.ClusterConfig{}, err
+	}
+	return config, nil
+}
+
+// SetClusterCertificateAuthorities replaces the certificate authorities of the cluster configuration, as well as any
+// other certificates that are set. Unlike SetClusterConfig, SetClusterCertificateAuthorities allows changing the
+// certificate authorities, and must only be used to rotate them.
+// SetClusterCertificateAuthorities will return the updated cluster configuration on success.
+func SetClusterCertificateAuthorities(ctx context.Context, tx *sql.Tx, certificates types.Certificates, requestedBy string, reason string) (types.ClusterConfig, error) {
+	config, err := GetClusterConfig(ctx, tx)
+	if err != nil {
+		return types.ClusterConfig{}, fmt.Errorf("failed to fetch existing cluster config: %w", err)
+	}
 
+	for _, i := range []struct {
+		val **string
+		new *string
+	}{
+		{val: &config.Certificates.CACert, new: certificates.CACert},
+		{val: &config.Certificates.CAKey, new: certificates.CAKey},
+		{val: &config.Certificates.ClientCACert, new: certificates.ClientCACert},
+		{val: &config.Certificates.ClientCAKey, new: certificates.ClientCAKey},
+		{val: &config.Certificates.FrontProxyCACert, new: certificates.FrontProxyCACert},
+		{val: &config.Certificates.FrontProxyCAKey, new: certificates.FrontProxyCAKey},
+		{val: &config.Certificates.AdminClientCert, new: certificates.AdminClientCert},
+		{val: &config.Certificates.AdminClientKey, new: certificates.AdminClientKey},
+		{val: &config.Certificates.APIServerKubeletClientCert, new: certificates.APIServerKubeletClientCert},
+		{val: &config.Certificates.APIServerKubeletClientKey, new: certificates.APIServerKubeletClientKey},
+	} {
+		if i.new != nil {
+			*i.val = i.new
+		}
+	}
+
+	if err := putClusterConfig(ctx, tx, config, requestedBy, reason); err != nil {
+		return types.ClusterConfig{}, err
+	}
+	return config, nil
+}
+
+// putClusterConfig stores the cluster configuration and records it as a new revision.
+func putClusterConfig(ctx context.Context, tx *sql.Tx, config types.ClusterConfig, requestedBy string, reason string) error {
 	b, err := json.Marshal(config)
 	if err != nil {
-		return types.ClusterConfig{}, fmt.Errorf("failed to encode cluster config: %w", err)
+		return fmt.Errorf("failed to encode cluster config: %w", err)
 	}
 	insertTxStmt, err := db.Stmt(tx, clusterConfigsStmts["insert-v1alpha2"])
 	if err != nil {
-		return types.ClusterConfig{}, fmt.Errorf("failed to prepare insert statement: %w", err)
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
 	}
 	if _, err := insertTxStmt.ExecContext(ctx, string(b)); err != nil {
-		return types.ClusterConfig{}, fmt.Errorf("failed to insert v1alpha2 config: %w", err)
+		return fmt.Errorf("failed to insert v1alpha2 config: %w", err)
 	}
-	return config, nil
+	revisionConfig, err := config.RevisionConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get cluster config revision: %w", err)
+	}
+	revision, err := json.Marshal(revisionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to encode cluster config revision: %w", err)
+	}
+	if err := addClusterConfigRevision(ctx, tx, string(revision), requestedBy, reason); err != nil {
+		return fmt.Errorf("failed to record cluster config revision: %w", err)
+	}
+	return nil
+}
+
+// schemaStripClusterConfigRevisions returns a schema update that removes the certificates, private keys and datastore
+// configuration from the stored cluster configuration revisions, see types.ClusterConfig.RevisionConfig.
+func schemaStripClusterConfigRevisions() db.Update {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, "SELECT revision, value FROM cluster_config_revisions")
+		if err != nil {
+			return fmt.Errorf("failed to list cluster config revisions: %w", err)
+		}
+		values := make(map[int64]string)
+		for rows.Next() {
+			var id int64
+			var value string
+			if err := rows.Scan(&id, &value); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan cluster config revision: %w", err)
+			}
+			values[id] = value
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to list cluster config revisions: %w", err)
+		}
+
+		for id, value := range values {
+			var config types.ClusterConfig
+			if err := json.Unmarshal([]byte(value), &config); err != nil {
+				return fmt.Errorf("failed to parse cluster config revision %d: %w", id, err)
+			}
+			revisionConfig, err := config.RevisionConfig()
+			if err != nil {
+				// NOTE: An etcd snapshots annotation that cannot be sealed is invalid, so drop it instead of recording
+				// a secret that it may contain.
+				delete(config.Annotations, types.AnnotationEtcdSnapshots)
+				if revisionConfig, err = config.RevisionConfig(); err != nil {
+					return fmt.Errorf("failed to strip cluster config revision %d: %w", id, err)
+				}
+			}
+			b, err := json.Marshal(revisionConfig)
+			if err != nil {
+				return fmt.Errorf("failed to encode cluster config revision %d: %w", id, err)
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE cluster_config_revisions SET value = ? WHERE revision = ?", string(b), id); err != nil {
+				return fmt.Errorf("failed to update cluster config revision %d: %w", id, err)
+			}
+		}
+		return nil
+	}
+}
+
+// schemaSealEtcdSnapshotSecret returns a schema update that moves the S3 secret access key of the etcd snapshots out of
+// the annotations of the stored cluster configuration and its revisions, see types.ClusterConfig.SealEtcdSnapshotSecret.
+func schemaSealEtcdSnapshotSecret() db.Update {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		var value string
+		if err := tx.QueryRowContext(ctx, "SELECT value FROM cluster_configs WHERE key = 'v1alpha2'").Scan(&value); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to get cluster config: %w", err)
+			}
+		} else {
+			var config types.ClusterConfig
+			if err := json.Unmarshal([]byte(value), &config); err != nil {
+				return fmt.Errorf("failed to parse cluster config: %w", err)
+			}
+			if err := config.SealEtcdSnapshotSecret(); err != nil {
+				return fmt.Errorf("failed to seal etcd snapshot secret: %w", err)
+			}
+			b, err := json.Marshal(config)
+			if err != nil {
+				return fmt.Errorf("failed to encode cluster config: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE cluster_configs SET value = ? WHERE key = 'v1alpha2'", string(b)); err != nil {
+				return fmt.Errorf("failed to update cluster config: %w", err)
+			}
+		}
+		return schemaStripClusterConfigRevisions()(ctx, tx)
+	}
+}
+
+// addClusterConfigRevision stores an encoded cluster configuration as a new revision, unless it is the same as the latest revision.
+// Only the latest ClusterConfigRevisionLimit revisions are retained.
+func addClusterConfigRevision(ctx context.Context, tx *sql.Tx, value string, requestedBy string, reason string) error {
+	selectTxStmt, err := db.Stmt(tx, clusterConfigsStmts["select-revisions"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+	var (
+		latestRevision                                  int64
+		latestValue, latestBy, latestReason, latestTime string
+	)
+	err = selectTxStmt.QueryRowContext(ctx, 1).Scan(&latestRevision, &latestValue, &latestBy, &latestReason, &latestTime)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to retrieve latest revision: %w", err)
+	} else if err == nil && latestValue == value {
+		// configuration is unchanged, e.g. on node restarts
+		return nil
+	}
+
+	insertTxStmt, err := db.Stmt(tx, clusterConfigsStmts["insert-revision"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	if _, err := insertTxStmt.ExecContext(ctx, value, requestedBy, reason, time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to execute insert statement: %w", err)
+	}
+
+	pruneTxStmt, err := db.Stmt(tx, clusterConfigsStmts["prune-revisions"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare prune statement: %w", err)
+	}
+	if _, err := pruneTxStmt.ExecContext(ctx, ClusterConfigRevisionLimit); err != nil {
+		return fmt.Errorf("failed to execute prune statement: %w", err)
+	}
+	return nil
+}
+
+// GetClusterConfigResourceVersion returns the resource version of the cluster configuration, which is the number of the
+// latest revision. GetClusterConfigResourceVersion returns 0 if no revision was stored yet.
+func GetClusterConfigResourceVersion(ctx context.Context, tx *sql.Tx) (int64, error) {
+	selectTxStmt, err := db.Stmt(tx, clusterConfigsStmts["select-latest-revision"])
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+
+	var revision int64
+	if err := selectTxStmt.QueryRowContext(ctx).Scan(&revision); err != nil {
+		return 0, fmt.Errorf("failed to retrieve latest revision: %w", err)
+	}
+	return revision, nil
+}
+
+// GetClusterConfigRevisions returns the stored cluster configuration revisions, newest first.
+// A non-positive limit returns all retained revisions.
+func GetClusterConfigRevisions(ctx context.Context, tx *sql.Tx, limit int) ([]types.ClusterConfigRevision, error) {
+	selectTxStmt, err := db.Stmt(tx, clusterConfigsStmts["select-revisions"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+
+	if limit <= 0 {
+		// a negative LIMIT means no limit
+		limit = -1
+	}
+
+	rows, err := selectTxStmt.QueryContext(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select statement: %w", err)
+	}
+	defer rows.Close()
+
+	var result []types.ClusterConfigRevision
+	for rows.Next() {
+		revision, err := scanClusterConfigRevision(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetClusterConfigRevision returns a single cluster configuration revision.
+// GetClusterConfigRevision returns ErrClusterConfigRevisionNotFound if the revision does not exist or was pruned.
+func GetClusterConfigRevision(ctx context.Context, tx *sql.Tx, revision int64) (types.ClusterConfigRevision, error) {
+	selectTxStmt, err := db.Stmt(tx, clusterConfigsStmts["select-revision"])
+	if err != nil {
+		return types.ClusterConfigRevision{}, fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+
+	result, err := scanClusterConfigRevision(ctx, selectTxStmt.QueryRowContext(ctx, revision))
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.ClusterConfigRevision{}, fmt.Errorf("revision %d: %w", revision, ErrClusterConfigRevisionNotFound)
+	}
+	return result, err
+}
+
+func scanClusterConfigRevision(ctx context.Context, row interface{ Scan(...any) error }) (types.ClusterConfigRevision, error) {
+	var (
+		revision  types.ClusterConfigRevision
+		value     string
+		createdAt string
+	)
+	if err := row.Scan(&revision.Revision, &value, &revision.RequestedBy, &revision.Reason, &createdAt); err != nil {
+		return types.ClusterConfigRevision{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+	if err := json.Unmarshal([]byte(value), &revision.Config); err != nil {
+		return types.ClusterConfigRevision{}, fmt.Errorf("failed to parse config of revision %d: %w", revision.Revision, err)
+	}
+	var err error
+	if revision.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		log.FromContext(ctx).Error(err, "failed to parse time", "original", createdAt)
+	}
+	return revision, nil
 }
 
 // GetClusterConfig retrieves the cluster configuration from the database.
@@ -113,3 +113,65 @@ func TestClusterConfig(t *testing.T) {
 		})
 	})
 }
+
+func TestClusterConfigRevisions(t *testing.T) {
+	testenv.WithState(t, func(ctx context.Context, s mctypes.State) {
+		g := NewWithT(t)
+		initialConfig := types.ClusterConfig{
+			Certificates: types.Certificates{CACert: utils.Pointer("CA CERT DATA"), CAKey: utils.Pointer("CA KEY DATA")},
+			Datastore:    types.Datastore{Type: utils.Pointer("k8s-dqlite")},
+			Ingress:      types.Ingress{Enabled: utils.Pointer(true)},
+		}
+		initialConfig.SetDefaults()
+
+		err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			if _, err := database.SetClusterConfig(ctx, tx, initialConfig); err != nil {
+				return err
+			}
+			if _, err := database.SetClusterConfigAs(ctx, tx, types.ClusterConfig{
+				Ingress: types.Ingress{Enabled: utils.Pointer(false)},
+			}, "alice", "disable ingress"); err != nil {
+				return err
+			}
+			// unchanged configuration does not create a new revision
+			_, err := database.SetClusterConfigAs(ctx, tx, types.ClusterConfig{}, "bob", "")
+			return err
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		err = s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			revisions, err := database.GetClusterConfigRevisions(ctx, tx, 0)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(revisions).To(HaveLen(2))
+			g.Expect(revisions[0].Revision).To(BeNumerically(">", revisions[1].Revision))
+			g.Expect(revisions[0].RequestedBy).To(Equal("alice"))
+			g.Expect(revisions[0].Reason).To(Equal("disable ingress"))
+			g.Expect(revisions[0].Config.Ingress.GetEnabled()).To(BeFalse())
+			g.Expect(revisions[0].CreatedAt).ToNot(BeZero())
+			g.Expect(revisions[1].RequestedBy).To(Equal("k8sd"))
+			g.Expect(revisions[1].Config.Ingress.GetEnabled()).To(BeTrue())
+			for _, revision := range revisions {
+				// credentials and the datastore configuration are never recorded in revisions
+				g.Expect(revision.Config.Certificates).To(BeZero())
+				g.Expect(revision.Config.Datastore).To(BeZero())
+			}
+
+			resourceVersion, err := database.GetClusterConfigResourceVersion(ctx, tx)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resourceVersion).To(Equal(revisions[0].Revision))
+
+			limited, err := database.GetClusterConfigRevisions(ctx, tx, 1)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(limited).To(Equal(revisions[:1]))
+
+			revision, err := database.GetClusterConfigRevision(ctx, tx, revisions[1].Revision)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(revision).To(Equal(revisions[1]))
+
+			_, err = database.GetClusterConfigRevision(ctx, tx, revisions[0].Revision+1)
+			g.Expect(err).To(MatchError(database.ErrClusterConfigRevisionNotFound))
+			return nil
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+}
@@ -0,0 +1,4 @@
+package database
+
+// SchemaHashTokens exposes schemaHashTokens to the tests of database_test.
+var SchemaHashTokens = schemaHashTokens
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,10 +13,20 @@ import (
 )
 
 var featureStatusStmts = map[string]int{
-	"select": MustPrepareStatement("feature-status", "select.sql"),
-	"upsert": MustPrepareStatement("feature-status", "upsert.sql"),
+	"select":                  MustPrepareStatement("feature-status", "select.sql"),
+	"upsert":                  MustPrepareStatement("feature-status", "upsert.sql"),
+	"delete":                  MustPrepareStatement("feature-status", "delete.sql"),
+	"delete-history":          MustPrepareStatement("feature-status", "delete-history.sql"),
+	"insert-history":          MustPrepareStatement("feature-status", "insert-history.sql"),
+	"select-history":          MustPrepareStatement("feature-status", "select-history.sql"),
+	"select-latest-history":   MustPrepareStatement("feature-status", "select-latest-history.sql"),
+	"update-history-repeated": MustPrepareStatement("feature-status", "update-history-repeated.sql"),
+	"prune-history":           MustPrepareStatement("feature-status", "prune-history.sql"),
 }
 
+// FeatureStatusHistoryLimit is the number of history entries that are retained per feature.
+const FeatureStatusHistoryLimit = 100
+
 // SetFeatureStatus updates the status of the given feature.
 func SetFeatureStatus(ctx context.Context, tx *sql.Tx, name types.FeatureName, status types.FeatureStatus) error {
 	upsertTxStmt, err := db.Stmt(tx, featureStatusStmts["upsert"])
@@ -36,6 +47,27 @@ func SetFeatureStatus(ctx context.Context, tx *sql.Tx, name types.FeatureName, s
 	return nil
 }
 
+// DeleteFeatureStatus deletes the status and the status history of the given feature.
+func DeleteFeatureStatus(ctx context.Context, tx *sql.Tx, name types.FeatureName) error {
+	deleteTxStmt, err := db.Stmt(tx, featureStatusStmts["delete"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare delete statement: %w", err)
+	}
+	if _, err := deleteTxStmt.ExecContext(ctx, name); err != nil {
+		return fmt.Errorf("failed to execute delete statement: %w", err)
+	}
+
+	deleteHistoryTxStmt, err := db.Stmt(tx, featureStatusStmts["delete-history"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare delete history statement: %w", err)
+	}
+	if _, err := deleteHistoryTxStmt.ExecContext(ctx, name); err != nil {
+		return fmt.Errorf("failed to execute delete history statement: %w", err)
+	}
+
+	return nil
+}
+
 // GetFeatureStatuses returns a map of feature names to their status.
 func GetFeatureStatuses(ctx context.Context, tx *sql.Tx) (map[types.FeatureName]types.FeatureStatus, error) {
 	selectTxStmt, err := db.Stmt(tx, featureStatusStmts["select"])
@@ -74,3 +106,119 @@ func GetFeatureStatuses(ctx context.Context, tx *sql.Tx) (map[types.FeatureName]
 
 	return result, nil
 }
+
+// AddFeatureStatusHistory appends an entry to the status history of the given feature.
+// If the entry repeats the latest entry of the feature (e.g. while waiting for a dependency), the count and last
+// seen time of the latest entry are updated instead, so that retries do not evict older entries.
+// Only the latest FeatureStatusHistoryLimit entries are retained for each feature.
+func AddFeatureStatusHistory(ctx context.Context, tx *sql.Tx, name types.FeatureName, entry types.FeatureStatusHistoryEntry) error {
+	selectTxStmt, err := db.Stmt(tx, featureStatusStmts["select-latest-history"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+
+	var (
+		latestID int64
+		latest   types.FeatureStatusHistoryEntry
+		outcome  string
+	)
+	err = selectTxStmt.QueryRowContext(ctx, name).Scan(&latestID, &outcome, &latest.Status.Message, &latest.Status.Version, &latest.Status.Enabled, &latest.Error, &latest.ConfigHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to retrieve latest history entry: %w", err)
+	}
+	latest.Outcome = types.FeatureStatusOutcome(outcome)
+	if err == nil && latest.Repeats(entry) {
+		updateTxStmt, err := db.Stmt(tx, featureStatusStmts["update-history-repeated"])
+		if err != nil {
+			return fmt.Errorf("failed to prepare update statement: %w", err)
+		}
+		if _, err := updateTxStmt.ExecContext(ctx, entry.StartedAt.Format(time.RFC3339Nano), entry.Duration.Milliseconds(), latestID); err != nil {
+			return fmt.Errorf("failed to execute update statement: %w", err)
+		}
+		return nil
+	}
+
+	insertTxStmt, err := db.Stmt(tx, featureStatusStmts["insert-history"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+
+	if _, err := insertTxStmt.ExecContext(ctx,
+		name,
+		entry.Outcome,
+		entry.Status.Message,
+		entry.Status.Version,
+		entry.Status.Enabled,
+		entry.Error,
+		entry.ConfigHash,
+		entry.StartedAt.Format(time.RFC3339Nano),
+		entry.Duration.Milliseconds(),
+	); err != nil {
+		return fmt.Errorf("failed to execute insert statement: %w", err)
+	}
+
+	pruneTxStmt, err := db.Stmt(tx, featureStatusStmts["prune-history"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare prune statement: %w", err)
+	}
+
+	if _, err := pruneTxStmt.ExecContext(ctx, name, name, FeatureStatusHistoryLimit); err != nil {
+		return fmt.Errorf("failed to execute prune statement: %w", err)
+	}
+
+	return nil
+}
+
+// GetFeatureStatusHistory returns the status history of the given feature, newest first.
+// A non-positive limit returns all retained entries.
+func GetFeatureStatusHistory(ctx context.Context, tx *sql.Tx, name types.FeatureName, limit int) ([]types.FeatureStatusHistoryEntry, error) {
+	selectTxStmt, err := db.Stmt(tx, featureStatusStmts["select-history"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+
+	if limit <= 0 {
+		// a negative LIMIT means no limit
+		limit = -1
+	}
+
+	rows, err := selectTxStmt.QueryContext(ctx, name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute select statement: %w", err)
+	}
+	defer rows.Close()
+
+	var result []types.FeatureStatusHistoryEntry
+	for rows.Next() {
+		var (
+			entry      types.FeatureStatusHistoryEntry
+			outcome    string
+			startedAt  string
+			durationMs int64
+			lastSeenAt string
+		)
+
+		if err := rows.Scan(&outcome, &entry.Status.Message, &entry.Status.Version, &entry.Status.Enabled, &entry.Error, &entry.ConfigHash, &startedAt, &durationMs, &entry.Count, &lastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		entry.Outcome = types.FeatureStatusOutcome(outcome)
+		entry.Duration = time.Duration(durationMs) * time.Millisecond
+		if entry.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+			log.FromContext(ctx).Error(err, "failed to parse time", "original", startedAt)
+		}
+		if lastSeenAt != "" {
+			if entry.LastSeenAt, err = time.Parse(time.RFC3339Nano, lastSeenAt); err != nil {
+				log.FromContext(ctx).Error(err, "failed to parse time", "original", lastSeenAt)
+			}
+		}
+
+		result = append(result, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return result, nil
+}
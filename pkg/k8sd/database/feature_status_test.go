@@ -3,6 +3,7 @@ package database_test
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"testing"
 	"time"
 
@@ -115,3 +116,136 @@ func TestFeatureStatus(t *testing.T) {
 		})
 	})
 }
+
+func TestFeatureStatusHistory(t *testing.T) {
+	testenv.WithState(t, func(ctx context.Context, s mctypes.State) {
+		_ = s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			t0, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+			t.Run("ReturnNothingInitially", func(t *testing.T) {
+				g := NewWithT(t)
+				entries, err := database.GetFeatureStatusHistory(ctx, tx, features.Network, 0)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(entries).To(BeEmpty())
+			})
+
+			t.Run("AddingEntries", func(t *testing.T) {
+				g := NewWithT(t)
+
+				failed := types.FeatureStatusHistoryEntry{
+					Outcome:    types.FeatureStatusOutcomeFailed,
+					Status:     types.FeatureStatus{Message: "failed to deploy", Version: "1.2.3"},
+					Error:      "failed to apply configuration",
+					ConfigHash: "abcdef",
+					StartedAt:  t0,
+					Duration:   1500 * time.Millisecond,
+					Count:      1,
+				}
+				succeeded := types.FeatureStatusHistoryEntry{
+					Outcome:    types.FeatureStatusOutcomeSucceeded,
+					Status:     types.FeatureStatus{Enabled: true, Message: "enabled", Version: "1.2.3"},
+					ConfigHash: "abcdef",
+					StartedAt:  t0.Add(time.Minute),
+					Duration:   2 * time.Second,
+					Count:      1,
+				}
+
+				g.Expect(database.AddFeatureStatusHistory(ctx, tx, features.Network, failed)).To(Succeed())
+				g.Expect(database.AddFeatureStatusHistory(ctx, tx, features.Network, succeeded)).To(Succeed())
+				g.Expect(database.AddFeatureStatusHistory(ctx, tx, features.DNS, succeeded)).To(Succeed())
+
+				entries, err := database.GetFeatureStatusHistory(ctx, tx, features.Network, 0)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(entries).To(Equal([]types.FeatureStatusHistoryEntry{succeeded, failed}))
+
+				entries, err = database.GetFeatureStatusHistory(ctx, tx, features.Network, 1)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(entries).To(Equal([]types.FeatureStatusHistoryEntry{succeeded}))
+			})
+
+			t.Run("Pruning", func(t *testing.T) {
+				g := NewWithT(t)
+
+				for i := 0; i < database.FeatureStatusHistoryLimit+10; i++ {
+					g.Expect(database.AddFeatureStatusHistory(ctx, tx, features.Gateway, types.FeatureStatusHistoryEntry{
+						Outcome:    types.FeatureStatusOutcomeSucceeded,
+						ConfigHash: fmt.Sprintf("hash-%d", i),
+						StartedAt:  t0.Add(time.Duration(i) * time.Second),
+					})).To(Succeed())
+				}
+
+				entries, err := database.GetFeatureStatusHistory(ctx, tx, features.Gateway, 0)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(entries).To(HaveLen(database.FeatureStatusHistoryLimit))
+				g.Expect(entries[0].StartedAt).To(Equal(t0.Add(time.Duration(database.FeatureStatusHistoryLimit+9) * time.Second)))
+
+				// other features are not pruned
+				entries, err = database.GetFeatureStatusHistory(ctx, tx, features.DNS, 0)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(entries).To(HaveLen(1))
+			})
+
+			t.Run("CollapseRepeatedEntries", func(t *testing.T) {
+				g := NewWithT(t)
+
+				waiting := func(i int) types.FeatureStatusHistoryEntry {
+					return types.FeatureStatusHistoryEntry{
+						Outcome:   types.FeatureStatusOutcomeWaiting,
+						Status:    types.FeatureStatus{Message: "waiting for network"},
+						StartedAt: t0.Add(time.Duration(i) * 10 * time.Second),
+						Duration:  time.Duration(i) * time.Millisecond,
+					}
+				}
+
+				for i := 0; i < database.FeatureStatusHistoryLimit+10; i++ {
+					g.Expect(database.AddFeatureStatusHistory(ctx, tx, features.LoadBalancer, waiting(i))).To(Succeed())
+				}
+				g.Expect(database.AddFeatureStatusHistory(ctx, tx, features.LoadBalancer, types.FeatureStatusHistoryEntry{
+					Outcome:   types.FeatureStatusOutcomeSucceeded,
+					StartedAt: t0.Add(time.Hour),
+				})).To(Succeed())
+
+				entries, err := database.GetFeatureStatusHistory(ctx, tx, features.LoadBalancer, 0)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(entries).To(HaveLen(2))
+				g.Expect(entries[0].Outcome).To(Equal(types.FeatureStatusOutcomeSucceeded))
+				g.Expect(entries[0].Count).To(Equal(1))
+				g.Expect(entries[0].LastSeenAt.IsZero()).To(BeTrue())
+
+				last := waiting(database.FeatureStatusHistoryLimit + 9)
+				g.Expect(entries[1].Outcome).To(Equal(types.FeatureStatusOutcomeWaiting))
+				g.Expect(entries[1].Count).To(Equal(database.FeatureStatusHistoryLimit + 10))
+				g.Expect(entries[1].StartedAt).To(Equal(t0))
+				g.Expect(entries[1].LastSeenAt).To(Equal(last.StartedAt))
+				g.Expect(entries[1].Duration).To(Equal(last.Duration))
+			})
+
+			t.Run("Delete", func(t *testing.T) {
+				g := NewWithT(t)
+
+				addon := features.HelmAddonStatusName("my-addon")
+				g.Expect(database.SetFeatureStatus(ctx, tx, addon, types.FeatureStatus{Message: "disabled", UpdatedAt: t0})).To(Succeed())
+				g.Expect(database.AddFeatureStatusHistory(ctx, tx, addon, types.FeatureStatusHistoryEntry{
+					Outcome:   types.FeatureStatusOutcomeSucceeded,
+					StartedAt: t0,
+				})).To(Succeed())
+
+				g.Expect(database.DeleteFeatureStatus(ctx, tx, addon)).To(Succeed())
+
+				ss, err := database.GetFeatureStatuses(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(ss).ToNot(HaveKey(addon))
+				entries, err := database.GetFeatureStatusHistory(ctx, tx, addon, 0)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(entries).To(BeEmpty())
+
+				// other features are not deleted
+				entries, err = database.GetFeatureStatusHistory(ctx, tx, features.DNS, 0)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(entries).To(HaveLen(1))
+			})
+
+			return nil
+		})
+	})
+}
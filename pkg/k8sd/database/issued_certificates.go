@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/microcluster/v3/microcluster/db"
+)
+
+var issuedCertificatesStmts = map[string]int{
+	"insert":                        MustPrepareStatement("issued-certificates", "insert.sql"),
+	"prune":                         MustPrepareStatement("issued-certificates", "prune.sql"),
+	"revoke-by-node":                MustPrepareStatement("issued-certificates", "revoke-by-node.sql"),
+	"revoke-by-serial":              MustPrepareStatement("issued-certificates", "revoke-by-serial.sql"),
+	"select-revoked":                MustPrepareStatement("issued-certificates", "select-revoked.sql"),
+	"select-revoked-by-fingerprint": MustPrepareStatement("issued-certificates", "select-revoked-by-fingerprint.sql"),
+	"count-by-subject":              MustPrepareStatement("issued-certificates", "count-by-subject.sql"),
+}
+
+// RecordIssuedCertificate records a certificate issued by k8sd, so that it can be revoked later.
+// RecordIssuedCertificate also removes expired certificates, which no longer need to be revoked.
+// NOTE: Times are stored in UTC, so that they can be compared in queries.
+func RecordIssuedCertificate(ctx context.Context, tx *sql.Tx, certificate types.IssuedCertificate) error {
+	if certificate.Serial == "" || certificate.Fingerprint == "" {
+		return fmt.Errorf("serial and fingerprint cannot be empty")
+	}
+
+	pruneTxStmt, err := db.Stmt(tx, issuedCertificatesStmts["prune"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare prune statement: %w", err)
+	}
+	if _, err := pruneTxStmt.ExecContext(ctx, time.Now().UTC()); err != nil {
+		return fmt.Errorf("prune issued certificates query failed: %w", err)
+	}
+
+	insertTxStmt, err := db.Stmt(tx, issuedCertificatesStmts["insert"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	if _, err := insertTxStmt.ExecContext(ctx, certificate.Serial, certificate.Fingerprint, certificate.Node, certificate.Name, certificate.Subject, certificate.NotAfter.UTC()); err != nil {
+		return fmt.Errorf("insert issued certificate query failed: %w", err)
+	}
+	return nil
+}
+
+// RevokeNodeCertificates revokes all certificates issued for a node.
+// RevokeNodeCertificates returns the number of certificates that were revoked.
+func RevokeNodeCertificates(ctx context.Context, tx *sql.Tx, node string) (int64, error) {
+	if node == "" {
+		return 0, fmt.Errorf("node cannot be empty")
+	}
+
+	revokeTxStmt, err := db.Stmt(tx, issuedCertificatesStmts["revoke-by-node"])
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare revoke statement: %w", err)
+	}
+	result, err := revokeTxStmt.ExecContext(ctx, time.Now().UTC(), node)
+	if err != nil {
+		return 0, fmt.Errorf("revoke certificates query failed: %w", err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get number of revoked certificates: %w", err)
+	}
+	return revoked, nil
+}
+
+// RevokeCertificate revokes the certificate with the given serial number (in hex, see types.NormalizeSerial).
+// RevokeCertificate returns false if no unrevoked certificate with that serial number was recorded.
+func RevokeCertificate(ctx context.Context, tx *sql.Tx, serial string) (bool, error) {
+	if serial == "" {
+		return false, fmt.Errorf("serial cannot be empty")
+	}
+	if normalized, ok := types.NormalizeSerial(serial); ok {
+		serial = normalized
+	}
+
+	revokeTxStmt, err := db.Stmt(tx, issuedCertificatesStmts["revoke-by-serial"])
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare revoke statement: %w", err)
+	}
+	result, err := revokeTxStmt.ExecContext(ctx, time.Now().UTC(), serial)
+	if err != nil {
+		return false, fmt.Errorf("revoke certificate query failed: %w", err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get number of revoked certificates: %w", err)
+	}
+	return revoked > 0, nil
+}
+
+// ListRevokedCertificates returns the revoked certificates that have not expired yet, ordered by revocation time.
+func ListRevokedCertificates(ctx context.Context, tx *sql.Tx) ([]types.IssuedCertificate, error) {
+	txStmt, err := db.Stmt(tx, issuedCertificatesStmts["select-revoked"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	rows, err := txStmt.QueryContext(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revoked certificates: %w", err)
+	}
+	defer rows.Close()
+
+	var certificates []types.IssuedCertificate
+	for rows.Next() {
+		var certificate types.IssuedCertificate
+		if err := rows.Scan(&certificate.Serial, &certificate.Fingerprint, &certificate.Node, &certificate.Name, &certificate.Subject, &certificate.NotAfter, &certificate.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revoked certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list revoked certificates: %w", err)
+	}
+	return certificates, nil
+}
+
+// IsCertificateRevoked returns true if the certificate with the given SHA256 fingerprint (in hex) is revoked.
+func IsCertificateRevoked(ctx context.Context, tx *sql.Tx, fingerprint string) (bool, error) {
+	txStmt, err := db.Stmt(tx, issuedCertificatesStmts["select-revoked-by-fingerprint"])
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	var count int
+	if err := txStmt.QueryRowContext(ctx, fingerprint).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check certificate: %w", err)
+	}
+	return count > 0, nil
+}
+
+// IsSubjectRevoked returns true if unexpired certificates were issued for the subject, and all of them are revoked.
+// This is the case for the credentials of removed nodes (e.g. "system:node:<name>"), until the node joins again.
+func IsSubjectRevoked(ctx context.Context, tx *sql.Tx, subject string) (bool, error) {
+	txStmt, err := db.Stmt(tx, issuedCertificatesStmts["count-by-subject"])
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	var active, revoked int
+	if err := txStmt.QueryRowContext(ctx, subject, time.Now().UTC()).Scan(&active, &revoked); err != nil {
+		return false, fmt.Errorf("failed to check subject: %w", err)
+	}
+	return active == 0 && revoked > 0, nil
+}
@@ -0,0 +1,118 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/k8sd/database"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	testenv "github.com/canonical/k8sd/pkg/utils/microcluster"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestIssuedCertificates(t *testing.T) {
+	testenv.WithState(t, func(ctx context.Context, s mctypes.State) {
+		notAfter := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		certificates := []types.IssuedCertificate{
+			{Serial: "1", Fingerprint: "f1", Node: "w1", Name: "kubelet.conf", Subject: "system:node:w1", NotAfter: notAfter},
+			{Serial: "2", Fingerprint: "f2", Node: "w1", Name: "proxy.conf", Subject: "system:kube-proxy", NotAfter: notAfter},
+			{Serial: "3", Fingerprint: "f3", Node: "w2", Name: "proxy.conf", Subject: "system:kube-proxy", NotAfter: notAfter},
+			{Serial: "4", Fingerprint: "f4", Node: "w3", Name: "kubelet.conf", Subject: "system:node:w3", NotAfter: time.Now().Add(-time.Hour)},
+		}
+
+		t.Run("Record", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				for _, certificate := range certificates {
+					g.Expect(database.RecordIssuedCertificate(ctx, tx, certificate)).To(Succeed())
+				}
+				// recording the same certificate again is a no-op
+				g.Expect(database.RecordIssuedCertificate(ctx, tx, certificates[0])).To(Succeed())
+
+				// certificates of users are not issued for a node
+				g.Expect(database.RecordIssuedCertificate(ctx, tx, types.IssuedCertificate{Serial: "5", Fingerprint: "f5", Name: "kubeconfig", Subject: "alice", NotAfter: notAfter})).To(Succeed())
+				g.Expect(database.RecordIssuedCertificate(ctx, tx, types.IssuedCertificate{Serial: "6a", Fingerprint: "f6", Name: "kubeconfig", Subject: "bob", NotAfter: notAfter})).To(Succeed())
+
+				g.Expect(database.RecordIssuedCertificate(ctx, tx, types.IssuedCertificate{Node: "w4"})).ToNot(Succeed())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+
+		t.Run("Revoke", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				revoked, err := database.IsSubjectRevoked(ctx, tx, "system:node:w1")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(revoked).To(BeFalse())
+
+				count, err := database.RevokeNodeCertificates(ctx, tx, "w1")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(count).To(Equal(int64(2)))
+
+				// certificates are only revoked once
+				count, err = database.RevokeNodeCertificates(ctx, tx, "w1")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(count).To(BeZero())
+
+				for fingerprint, expectRevoked := range map[string]bool{"f1": true, "f2": true, "f3": false, "unknown": false} {
+					revoked, err := database.IsCertificateRevoked(ctx, tx, fingerprint)
+					g.Expect(err).To(Not(HaveOccurred()))
+					g.Expect(revoked).To(Equal(expectRevoked), fingerprint)
+				}
+
+				for subject, expectRevoked := range map[string]bool{
+					"system:node:w1":    true,
+					"system:kube-proxy": false, // still used by w2
+					"system:node:w3":    false, // expired
+					"unknown":           false,
+				} {
+					revoked, err := database.IsSubjectRevoked(ctx, tx, subject)
+					g.Expect(err).To(Not(HaveOccurred()))
+					g.Expect(revoked).To(Equal(expectRevoked), subject)
+				}
+
+				revokedCertificates, err := database.ListRevokedCertificates(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(revokedCertificates).To(HaveLen(2))
+				g.Expect(revokedCertificates[0].Serial).To(Equal("1"))
+				g.Expect(revokedCertificates[0].RevokedAt).ToNot(BeZero())
+				g.Expect(revokedCertificates[1].Serial).To(Equal("2"))
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+
+		t.Run("RevokeBySerial", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				revoked, err := database.RevokeCertificate(ctx, tx, "5")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(revoked).To(BeTrue())
+
+				isRevoked, err := database.IsCertificateRevoked(ctx, tx, "f5")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(isRevoked).To(BeTrue())
+
+				// already revoked
+				revoked, err = database.RevokeCertificate(ctx, tx, "5")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(revoked).To(BeFalse())
+
+				// serials are not case sensitive
+				revoked, err = database.RevokeCertificate(ctx, tx, "0x6A")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(revoked).To(BeTrue())
+
+				revoked, err = database.RevokeCertificate(ctx, tx, "unknown")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(revoked).To(BeFalse())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+}
@@ -9,18 +9,26 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/microcluster/v3/microcluster/db"
 )
 
 var k8sdTokensStmts = map[string]int{
-	"insert-token":       MustPrepareStatement("kubernetes-auth-tokens", "insert-token.sql"),
-	"select-by-token":    MustPrepareStatement("kubernetes-auth-tokens", "select-by-token.sql"),
-	"select-by-username": MustPrepareStatement("kubernetes-auth-tokens", "select-by-username.sql"),
-	"delete-by-token":    MustPrepareStatement("kubernetes-auth-tokens", "delete-by-token.sql"),
-	"delete-by-username": MustPrepareStatement("kubernetes-auth-tokens", "delete-by-username.sql"),
+	"insert-token":     MustPrepareStatement("kubernetes-auth-tokens", "insert-token.sql"),
+	"select-by-lookup": MustPrepareStatement("kubernetes-auth-tokens", "select-by-lookup.sql"),
+	"delete-by-hash":   MustPrepareStatement("kubernetes-auth-tokens", "delete-by-hash.sql"),
+	"select-all":       MustPrepareStatement("kubernetes-auth-tokens", "select-all.sql"),
+	"update-last-used": MustPrepareStatement("kubernetes-auth-tokens", "update-last-used.sql"),
+	"delete-by-id":     MustPrepareStatement("kubernetes-auth-tokens", "delete-by-id.sql"),
+	"delete-expired":   MustPrepareStatement("kubernetes-auth-tokens", "delete-expired.sql"),
 }
 
+// tokenLastUsedResolution is the resolution of the last use of tokens.
+// The last use of a token is only updated once per resolution, to avoid a database write for every request.
+const tokenLastUsedResolution = time.Minute
+
 func groupsToString(inGroups []string) (string, error) {
 	groupMap := make(map[string]struct{}, len(inGroups))
 	groups := make([]string, 0, len(inGroups))
@@ -45,29 +53,104 @@ func groupsToList(inGroups string) []string {
 	return strings.Split(inGroups, ",")
 }
 
-// CheckToken returns the username and groups of a token (if valid).
-// CheckToken returns an error in case the token is not valid.
-func CheckToken(ctx context.Context, tx *sql.Tx, token string) (string, []string, error) {
-	txStmt, err := db.Stmt(tx, k8sdTokensStmts["select-by-token"])
+// nullTime returns a NULL value for zero times, and the time in UTC otherwise.
+// NOTE: Times are stored in UTC, so that they can be compared in queries.
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// generateToken generates a new random Kubernetes auth token.
+func generateToken() (string, error) {
+	// generate random bytes for the token
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("is the system entropy low? failed to get random bytes: %w", err)
+	}
+	return fmt.Sprintf("token::%s", hex.EncodeToString(b)), nil
+}
+
+// storedToken is a Kubernetes auth token as found by findToken.
+type storedToken struct {
+	id       int64
+	username string
+	groups   string
+	expiry   sql.NullTime
+}
+
+// findToken returns the stored token that matches the specified token.
+// findToken returns false if no stored token matches.
+func findToken(ctx context.Context, tx *sql.Tx, token string) (storedToken, bool, error) {
+	txStmt, err := db.Stmt(tx, k8sdTokensStmts["select-by-lookup"])
+	if err != nil {
+		return storedToken{}, false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	rows, err := txStmt.QueryContext(ctx, tokenLookup(token))
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to prepare statement: %w", err)
+		return storedToken{}, false, fmt.Errorf("failed to find token: %w", err)
 	}
-	var username, groupsString string
-	if err := txStmt.QueryRowContext(ctx, token).Scan(&username, &groupsString); err != nil {
-		if err == sql.ErrNoRows {
-			return "", nil, fmt.Errorf("invalid token")
+	defer rows.Close()
+
+	for rows.Next() {
+		var stored storedToken
+		var hash tokenHash
+		if err := rows.Scan(&stored.id, &stored.username, &stored.groups, &hash.Salt, &hash.Hash, &stored.expiry); err != nil {
+			return storedToken{}, false, fmt.Errorf("failed to scan token: %w", err)
+		}
+		if hash.matches(token) {
+			return stored, true, nil
 		}
+	}
+	if err := rows.Err(); err != nil {
+		return storedToken{}, false, fmt.Errorf("failed to find token: %w", err)
+	}
+	return storedToken{}, false, nil
+}
+
+// CheckToken returns the username and groups of a token (if valid).
+// CheckToken returns an error in case the token is not valid or has expired.
+func CheckToken(ctx context.Context, tx *sql.Tx, token string) (string, []string, error) {
+	stored, ok, err := findToken(ctx, tx, token)
+	if err != nil {
 		return "", nil, fmt.Errorf("failed to check token: %w", err)
 	}
+	if !ok {
+		return "", nil, fmt.Errorf("invalid token")
+	}
+	if stored.expiry.Valid && !time.Now().Before(stored.expiry.Time) {
+		return "", nil, fmt.Errorf("token expired")
+	}
 
-	return username, groupsToList(groupsString), nil
+	return stored.username, groupsToList(stored.groups), nil
 }
 
-// GetOrCreateToken returns a token that matches the specified identify (username and groups).
-// GetOrCreateToken will create an existing token (if available).
-// GetOrCreateToken will create a new token otherwise.
-// GetOrCreateToken returns an error in case the auth is empty or a token could not be generated.
-func GetOrCreateToken(ctx context.Context, tx *sql.Tx, username string, groups []string) (string, error) {
+// RecordTokenUse records that a token was used to authenticate with the Kubernetes API server.
+func RecordTokenUse(ctx context.Context, tx *sql.Tx, token string) error {
+	stored, ok, err := findToken(ctx, tx, token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	txStmt, err := db.Stmt(tx, k8sdTokensStmts["update-last-used"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare update statement: %w", err)
+	}
+	now := time.Now().UTC()
+	if _, err := txStmt.ExecContext(ctx, now, stored.id, now.Add(-tokenLastUsedResolution)); err != nil {
+		return fmt.Errorf("update token query failed: %w", err)
+	}
+	return nil
+}
+
+// CreateToken creates a new token for the specified identity (username and groups), which expires at the specified
+// time (if not zero). Only a salted hash of the token is stored, so the token cannot be retrieved again.
+// CreateToken also removes expired tokens.
+func CreateToken(ctx context.Context, tx *sql.Tx, username string, groups []string, description string, expiry time.Time) (string, error) {
 	if username == "" {
 		return "", fmt.Errorf("username cannot be empty")
 	}
@@ -75,27 +158,29 @@ func GetOrCreateToken(ctx context.Context, tx *sql.Tx, username string, groups [
 	if err != nil {
 		return "", fmt.Errorf("invalid groups: %w", err)
 	}
-	selectTxStmt, err := db.Stmt(tx, k8sdTokensStmts["select-by-username"])
+
+	deleteTxStmt, err := db.Stmt(tx, k8sdTokensStmts["delete-expired"])
 	if err != nil {
-		return "", fmt.Errorf("failed to prepare select statement: %w", err)
+		return "", fmt.Errorf("failed to prepare delete statement: %w", err)
 	}
-	var token string
-	if selectTxStmt.QueryRowContext(ctx, username, groupsString).Scan(&token) == nil {
-		return token, nil
+	if _, err := deleteTxStmt.ExecContext(ctx, time.Now().UTC()); err != nil {
+		return "", fmt.Errorf("delete expired tokens query failed: %w", err)
 	}
 
-	// generate random bytes for the token
-	b := make([]byte, 20)
-	if _, err := rand.Read(b); err != nil {
-		return "", fmt.Errorf("is the system entropy low? failed to get random bytes: %w", err)
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	hash, err := newTokenHash(token)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash token: %w", err)
 	}
-	token = fmt.Sprintf("token::%s", hex.EncodeToString(b))
 
 	insertTxStmt, err := db.Stmt(tx, k8sdTokensStmts["insert-token"])
 	if err != nil {
 		return "", fmt.Errorf("failed to prepare insert statement: %w", err)
 	}
-	if _, err := insertTxStmt.ExecContext(ctx, username, groupsString, token); err != nil {
+	if _, err := insertTxStmt.ExecContext(ctx, username, groupsString, hash.Lookup, hash.Salt, hash.Hash, description, nullTime(expiry), nullTime(time.Now())); err != nil {
 		return "", fmt.Errorf("insert token query failed: %w", err)
 	}
 
@@ -109,12 +194,108 @@ func DeleteToken(ctx context.Context, tx *sql.Tx, token string) error {
 		return fmt.Errorf("token cannot be empty")
 	}
 
-	deleteTxStmt, err := db.Stmt(tx, k8sdTokensStmts["delete-by-token"])
+	stored, ok, err := findToken(ctx, tx, token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	if _, err := DeleteTokenByID(ctx, tx, stored.id); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteTokenByID deletes the token with the specified ID, as returned by ListTokens.
+// DeleteTokenByID returns false if no such token exists.
+func DeleteTokenByID(ctx context.Context, tx *sql.Tx, id int64) (bool, error) {
+	deleteTxStmt, err := db.Stmt(tx, k8sdTokensStmts["delete-by-id"])
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare delete statement: %w", err)
+	}
+	result, err := deleteTxStmt.ExecContext(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("delete token query failed: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get number of deleted tokens: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// ListTokens returns all Kubernetes auth tokens, ordered by username.
+func ListTokens(ctx context.Context, tx *sql.Tx) ([]types.KubernetesAuthToken, error) {
+	txStmt, err := db.Stmt(tx, k8sdTokensStmts["select-all"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	rows, err := txStmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []types.KubernetesAuthToken
+	for rows.Next() {
+		var token types.KubernetesAuthToken
+		var groupsString string
+		var expiry, createdAt, lastUsedAt sql.NullTime
+		if err := rows.Scan(&token.ID, &token.Username, &groupsString, &token.TokenLookup, &token.TokenSalt, &token.TokenHash, &token.Description, &expiry, &createdAt, &lastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+		token.Groups = groupsToList(groupsString)
+		token.ExpiresAt, token.CreatedAt, token.LastUsedAt = expiry.Time, createdAt.Time, lastUsedAt.Time
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RestoreToken stores an existing token for the specified identity (username and groups).
+// RestoreToken is used to import tokens from a cluster bundle. The token is either the salted hash of a token, or a
+// plaintext token (as exported by older versions), which is hashed before it is stored. Any stored token with the
+// same hash is replaced.
+func RestoreToken(ctx context.Context, tx *sql.Tx, token types.KubernetesAuthToken) error {
+	if token.Username == "" {
+		return fmt.Errorf("username cannot be empty")
+	}
+	groupsString, err := groupsToString(token.Groups)
+	if err != nil {
+		return fmt.Errorf("invalid groups: %w", err)
+	}
+
+	hash := tokenHash{Lookup: token.TokenLookup, Salt: token.TokenSalt, Hash: token.TokenHash}
+	switch {
+	case token.Token != "":
+		if hash, err = newTokenHash(token.Token); err != nil {
+			return fmt.Errorf("failed to hash token: %w", err)
+		}
+		// NOTE: Remove any token that was restored from the same plaintext token before.
+		if err := DeleteToken(ctx, tx, token.Token); err != nil {
+			return err
+		}
+	case hash.Salt == "" || hash.Hash == "":
+		return fmt.Errorf("token cannot be empty")
+	}
+
+	deleteTxStmt, err := db.Stmt(tx, k8sdTokensStmts["delete-by-hash"])
 	if err != nil {
 		return fmt.Errorf("failed to prepare delete statement: %w", err)
 	}
-	if _, err := deleteTxStmt.ExecContext(ctx, token); err != nil {
+	if _, err := deleteTxStmt.ExecContext(ctx, hash.Hash); err != nil {
 		return fmt.Errorf("delete token query failed: %w", err)
 	}
+
+	insertTxStmt, err := db.Stmt(tx, k8sdTokensStmts["insert-token"])
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	if _, err := insertTxStmt.ExecContext(ctx, token.Username, groupsString, hash.Lookup, hash.Salt, hash.Hash, token.Description, nullTime(token.ExpiresAt), nullTime(token.CreatedAt)); err != nil {
+		return fmt.Errorf("insert token query failed: %w", err)
+	}
 	return nil
 }
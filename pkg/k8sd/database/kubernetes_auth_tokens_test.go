@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/canonical/k8sd/pkg/k8sd/database"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
 	testenv "github.com/canonical/k8sd/pkg/utils/microcluster"
 	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
 	. "github.com/onsi/gomega"
@@ -15,34 +17,23 @@ func TestKubernetesAuthTokens(t *testing.T) {
 	testenv.WithState(t, func(ctx context.Context, s mctypes.State) {
 		var token1, token2 string
 
-		t.Run("GetOrCreateToken", func(t *testing.T) {
+		t.Run("GenerateTokens", func(t *testing.T) {
 			g := NewWithT(t)
 			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
 				var err error
 
-				token1, err = database.GetOrCreateToken(ctx, tx, "user1", []string{"group1", "group2"})
+				token1, err = database.CreateToken(ctx, tx, "user1", []string{"group1", "group2"}, "", time.Time{})
 				g.Expect(err).To(Not(HaveOccurred()))
-				g.Expect(token1).To(Not(BeEmpty()))
+				g.Expect(token1).To(HavePrefix("token::"))
 
-				token2, err = database.GetOrCreateToken(ctx, tx, "user2", []string{"group1", "group2"})
+				token2, err = database.CreateToken(ctx, tx, "user2", []string{"group1", "group2"}, "", time.Time{})
 				g.Expect(err).To(Not(HaveOccurred()))
-				g.Expect(token2).To(Not(BeEmpty()))
+				g.Expect(token2).To(HavePrefix("token::"))
 
 				g.Expect(token1).To(Not(Equal(token2)))
 				return nil
 			})
 			g.Expect(err).To(Not(HaveOccurred()))
-
-			t.Run("Existing", func(t *testing.T) {
-				g := NewWithT(t)
-				err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
-					token, err := database.GetOrCreateToken(ctx, tx, "user1", []string{"group1", "group2"})
-					g.Expect(err).To(Not(HaveOccurred()))
-					g.Expect(token).To(Equal(token1))
-					return nil
-				})
-				g.Expect(err).To(Not(HaveOccurred()))
-			})
 		})
 
 		t.Run("CheckToken", func(t *testing.T) {
@@ -68,6 +59,18 @@ func TestKubernetesAuthTokens(t *testing.T) {
 				})
 				g.Expect(err).To(Not(HaveOccurred()))
 			})
+			t.Run("Invalid", func(t *testing.T) {
+				g := NewWithT(t)
+				err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+					// same lookup prefix, different token
+					for _, token := range []string{token1[:len(token1)-1], token1 + "0", "token::", ""} {
+						_, _, err := database.CheckToken(ctx, tx, token)
+						g.Expect(err).To(MatchError("invalid token"), token)
+					}
+					return nil
+				})
+				g.Expect(err).To(Not(HaveOccurred()))
+			})
 		})
 
 		t.Run("DeleteToken", func(t *testing.T) {
@@ -84,5 +87,192 @@ func TestKubernetesAuthTokens(t *testing.T) {
 			})
 			g.Expect(err).To(Not(HaveOccurred()))
 		})
+
+		t.Run("ListTokens", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				tokens, err := database.ListTokens(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(tokens).To(HaveLen(1))
+				g.Expect(tokens[0].ID).ToNot(BeZero())
+				g.Expect(tokens[0].Username).To(Equal("user1"))
+				g.Expect(tokens[0].Groups).To(Equal([]string{"group1", "group2"}))
+				// only a salted hash of the token is stored
+				g.Expect(tokens[0].Token).To(BeEmpty())
+				g.Expect(tokens[0].TokenLookup).To(Equal(token1[:len("token::")+8]))
+				g.Expect(tokens[0].TokenSalt).ToNot(BeEmpty())
+				g.Expect(tokens[0].TokenHash).ToNot(BeEmpty())
+				g.Expect(tokens[0].TokenHash).ToNot(ContainSubstring(token1[len("token::"):]))
+				g.Expect(tokens[0].CreatedAt).To(BeTemporally("~", time.Now(), time.Minute))
+				g.Expect(tokens[0].ExpiresAt).To(BeZero())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+
+		t.Run("RestoreToken", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				// plaintext tokens of older cluster bundles are hashed
+				for range 2 {
+					err := database.RestoreToken(ctx, tx, types.KubernetesAuthToken{Username: "user1", Groups: []string{"group2", "group1"}, Token: "token::restored"})
+					g.Expect(err).To(Not(HaveOccurred()))
+				}
+
+				username, groups, err := database.CheckToken(ctx, tx, "token::restored")
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(username).To(Equal("user1"))
+				g.Expect(groups).To(ConsistOf("group1", "group2"))
+
+				tokens, err := database.ListTokens(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(tokens).To(HaveLen(2))
+
+				// other tokens of the same identity are kept
+				_, _, err = database.CheckToken(ctx, tx, token1)
+				g.Expect(err).To(Not(HaveOccurred()))
+
+				// hashed tokens are restored as is
+				for _, token := range tokens {
+					deleted, err := database.DeleteTokenByID(ctx, tx, token.ID)
+					g.Expect(err).To(Not(HaveOccurred()))
+					g.Expect(deleted).To(BeTrue())
+				}
+				_, _, err = database.CheckToken(ctx, tx, token1)
+				g.Expect(err).To(HaveOccurred())
+				for _, token := range tokens {
+					g.Expect(database.RestoreToken(ctx, tx, token)).To(Succeed())
+					g.Expect(database.RestoreToken(ctx, tx, token)).To(Succeed())
+				}
+				_, _, err = database.CheckToken(ctx, tx, token1)
+				g.Expect(err).To(Not(HaveOccurred()))
+				_, _, err = database.CheckToken(ctx, tx, "token::restored")
+				g.Expect(err).To(Not(HaveOccurred()))
+				restored, err := database.ListTokens(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(restored).To(HaveLen(2))
+
+				expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+				err = database.RestoreToken(ctx, tx, types.KubernetesAuthToken{Username: "user1", Groups: []string{"group1", "group2"}, Token: "token::restored-ci", ExpiresAt: expiry})
+				g.Expect(err).To(Not(HaveOccurred()))
+				_, _, err = database.CheckToken(ctx, tx, "token::restored-ci")
+				g.Expect(err).To(Not(HaveOccurred()))
+
+				err = database.RestoreToken(ctx, tx, types.KubernetesAuthToken{Username: "user1"})
+				g.Expect(err).To(HaveOccurred())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+
+		t.Run("CreateToken", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				expiring, err := database.CreateToken(ctx, tx, "ci", []string{"group1"}, "nightly", time.Now().Add(time.Hour))
+				g.Expect(err).To(Not(HaveOccurred()))
+				expired, err := database.CreateToken(ctx, tx, "ci", []string{"group1"}, "expired", time.Now().Add(-time.Second))
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(expired).ToNot(Equal(expiring))
+
+				username, groups, err := database.CheckToken(ctx, tx, expiring)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(username).To(Equal("ci"))
+				g.Expect(groups).To(ConsistOf("group1"))
+
+				_, _, err = database.CheckToken(ctx, tx, expired)
+				g.Expect(err).To(MatchError("token expired"))
+
+				// expired tokens are removed when creating new tokens
+				_, err = database.CreateToken(ctx, tx, "ci", nil, "", time.Time{})
+				g.Expect(err).To(Not(HaveOccurred()))
+				_, _, err = database.CheckToken(ctx, tx, expired)
+				g.Expect(err).To(MatchError("invalid token"))
+
+				_, err = database.CreateToken(ctx, tx, "", nil, "", time.Time{})
+				g.Expect(err).To(HaveOccurred())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+
+		t.Run("RecordTokenUse", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				g.Expect(database.RecordTokenUse(ctx, tx, token1)).To(Succeed())
+				// unknown tokens are ignored
+				g.Expect(database.RecordTokenUse(ctx, tx, "token::unknown")).To(Succeed())
+
+				tokens, err := database.ListTokens(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(tokens).To(ContainElement(SatisfyAll(
+					HaveField("TokenLookup", token1[:len("token::")+8]),
+					HaveField("LastUsedAt", BeTemporally("~", time.Now(), time.Minute)),
+				)))
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+
+		t.Run("DeleteTokenByID", func(t *testing.T) {
+			g := NewWithT(t)
+			err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+				tokens, err := database.ListTokens(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(tokens).ToNot(BeEmpty())
+
+				deleted, err := database.DeleteTokenByID(ctx, tx, tokens[0].ID)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(deleted).To(BeTrue())
+
+				remaining, err := database.ListTokens(ctx, tx)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(remaining).ToNot(ContainElement(HaveField("ID", tokens[0].ID)))
+
+				deleted, err = database.DeleteTokenByID(ctx, tx, tokens[0].ID)
+				g.Expect(err).To(Not(HaveOccurred()))
+				g.Expect(deleted).To(BeFalse())
+				return nil
+			})
+			g.Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+}
+
+func TestSchemaHashTokens(t *testing.T) {
+	testenv.WithState(t, func(ctx context.Context, s mctypes.State) {
+		g := NewWithT(t)
+		err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			// tokens created before the upgrade are stored in plaintext
+			_, err := tx.ExecContext(ctx, "INSERT INTO kubernetes_auth_tokens(username, groups, token) VALUES ('user1', 'group1,group2', 'token::0123456789abcdef')")
+			g.Expect(err).To(Not(HaveOccurred()))
+			_, err = tx.ExecContext(ctx, "INSERT INTO worker_tokens(name, token, expiry) VALUES ('w1', 'worker::0123456789abcdef', ?)", time.Now().Add(time.Hour))
+			g.Expect(err).To(Not(HaveOccurred()))
+
+			g.Expect(database.SchemaHashTokens("kubernetes_auth_tokens")(ctx, tx)).To(Succeed())
+			g.Expect(database.SchemaHashTokens("worker_tokens")(ctx, tx)).To(Succeed())
+
+			var plaintext int
+			g.Expect(tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM kubernetes_auth_tokens WHERE token != ''").Scan(&plaintext)).To(Succeed())
+			g.Expect(plaintext).To(BeZero())
+			g.Expect(tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM worker_tokens WHERE token != ''").Scan(&plaintext)).To(Succeed())
+			g.Expect(plaintext).To(BeZero())
+
+			// tokens created before the upgrade still authenticate
+			username, groups, err := database.CheckToken(ctx, tx, "token::0123456789abcdef")
+			g.Expect(err).To(Not(HaveOccurred()))
+			g.Expect(username).To(Equal("user1"))
+			g.Expect(groups).To(ConsistOf("group1", "group2"))
+
+			valid, err := database.CheckWorkerNodeToken(ctx, tx, "w1", "worker::0123456789abcdef")
+			g.Expect(err).To(Not(HaveOccurred()))
+			g.Expect(valid).To(BeTrue())
+
+			// hashed tokens are not hashed again
+			g.Expect(database.SchemaHashTokens("kubernetes_auth_tokens")(ctx, tx)).To(Succeed())
+			_, _, err = database.CheckToken(ctx, tx, "token::0123456789abcdef")
+			g.Expect(err).To(Not(HaveOccurred()))
+			return nil
+		})
+		g.Expect(err).To(Not(HaveOccurred()))
 	})
 }
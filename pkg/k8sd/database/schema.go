@@ -22,6 +22,27 @@ var (
 		schemaApplyMigration("feature-status", "000-feature-status.sql"),
 		schemaApplyMigration("worker-tokens", "001-add-expiry.sql"),
 		schemaApplyMigration("worker-nodes", "001-delete.sql"),
+		schemaApplyMigration("feature-status", "001-create-history.sql"),
+		schemaApplyMigration("feature-status", "002-index-history.sql"),
+		schemaApplyMigration("cluster-configs", "001-create-revisions.sql"),
+		schemaApplyMigration("cluster-configs", "002-seed-revisions.sql"),
+		schemaApplyMigration("issued-certificates", "000-create.sql"),
+		schemaApplyMigration("kubernetes-auth-tokens", "001-add-description.sql"),
+		schemaApplyMigration("kubernetes-auth-tokens", "002-add-expiry.sql"),
+		schemaApplyMigration("kubernetes-auth-tokens", "003-add-created-at.sql"),
+		schemaApplyMigration("kubernetes-auth-tokens", "004-add-last-used-at.sql"),
+		schemaApplyMigration("kubernetes-auth-tokens", "005-add-token-lookup.sql"),
+		schemaApplyMigration("kubernetes-auth-tokens", "006-add-token-salt.sql"),
+		schemaApplyMigration("kubernetes-auth-tokens", "007-add-token-hash.sql"),
+		schemaHashTokens("kubernetes_auth_tokens"),
+		schemaApplyMigration("worker-tokens", "002-add-token-lookup.sql"),
+		schemaApplyMigration("worker-tokens", "003-add-token-salt.sql"),
+		schemaApplyMigration("worker-tokens", "004-add-token-hash.sql"),
+		schemaHashTokens("worker_tokens"),
+		schemaApplyMigration("feature-status", "003-add-history-count.sql"),
+		schemaApplyMigration("feature-status", "004-add-history-last-seen-at.sql"),
+		schemaStripClusterConfigRevisions(),
+		schemaSealEtcdSnapshotSecret(),
 	}
 
 	//go:embed sql/migrations
@@ -0,0 +1,7 @@
+CREATE TABLE cluster_config_revisions (
+    revision     INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    value        TEXT NOT NULL,
+    requested_by TEXT NOT NULL,
+    reason       TEXT NOT NULL,
+    created_at   TEXT NOT NULL
+)
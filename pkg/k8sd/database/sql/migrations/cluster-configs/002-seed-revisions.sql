@@ -0,0 +1,8 @@
+INSERT INTO
+    cluster_config_revisions(value, requested_by, reason, created_at)
+SELECT
+    c.value, 'k8sd', 'configuration before revision history', strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM
+    cluster_configs AS c
+WHERE
+    c.key = 'v1alpha2'
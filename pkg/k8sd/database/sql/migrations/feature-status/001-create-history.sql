@@ -0,0 +1,12 @@
+CREATE TABLE feature_status_history (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name        TEXT NOT NULL,
+    outcome     TEXT NOT NULL,
+    message     TEXT NOT NULL,
+    version     TEXT NOT NULL,
+    enabled     BOOLEAN NOT NULL,
+    error       TEXT NOT NULL,
+    config_hash TEXT NOT NULL,
+    started_at  TEXT NOT NULL,
+    duration_ms INTEGER NOT NULL
+)
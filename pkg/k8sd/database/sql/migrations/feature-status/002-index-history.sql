@@ -0,0 +1 @@
+CREATE INDEX feature_status_history_name ON feature_status_history (name, id)
@@ -0,0 +1,2 @@
+ALTER TABLE feature_status_history
+ADD COLUMN count INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+ALTER TABLE feature_status_history
+ADD COLUMN last_seen_at TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+CREATE TABLE issued_certificates (
+    id          INTEGER     PRIMARY KEY AUTOINCREMENT NOT NULL,
+    serial      TEXT        NOT NULL,
+    fingerprint TEXT        NOT NULL,
+    node        TEXT        NOT NULL,
+    name        TEXT        NOT NULL,
+    subject     TEXT        NOT NULL,
+    not_after   DATETIME    NOT NULL,
+    revoked_at  DATETIME,
+    UNIQUE(fingerprint)
+)
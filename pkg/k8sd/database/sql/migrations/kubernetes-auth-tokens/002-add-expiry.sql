@@ -0,0 +1,2 @@
+ALTER TABLE kubernetes_auth_tokens
+ADD COLUMN expiry DATETIME;
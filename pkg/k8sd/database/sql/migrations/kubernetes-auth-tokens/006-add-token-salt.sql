@@ -0,0 +1,2 @@
+ALTER TABLE kubernetes_auth_tokens
+ADD COLUMN token_salt TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE worker_tokens
+ADD COLUMN token_lookup TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE worker_tokens
+ADD COLUMN token_hash TEXT NOT NULL DEFAULT '';
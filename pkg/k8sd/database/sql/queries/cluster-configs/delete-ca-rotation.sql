@@ -0,0 +1,4 @@
+DELETE FROM
+    cluster_configs AS c
+WHERE
+    ( c.key = 'ca-rotation' )
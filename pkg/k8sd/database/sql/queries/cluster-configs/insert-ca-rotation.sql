@@ -0,0 +1,6 @@
+INSERT INTO
+    cluster_configs(key, value)
+VALUES
+    ("ca-rotation", ?)
+ON CONFLICT(key) DO
+    UPDATE SET value = EXCLUDED.value;
@@ -0,0 +1,4 @@
+INSERT INTO
+    cluster_config_revisions(value, requested_by, reason, created_at)
+VALUES
+    ( ?, ?, ?, ? )
@@ -0,0 +1,6 @@
+DELETE FROM
+    cluster_config_revisions
+WHERE
+    revision NOT IN (
+        SELECT revision FROM cluster_config_revisions AS r ORDER BY r.revision DESC LIMIT ?
+    )
@@ -0,0 +1,6 @@
+SELECT
+    value
+FROM
+    cluster_configs AS c
+WHERE
+    ( c.key = 'ca-rotation' )
@@ -0,0 +1,4 @@
+SELECT
+    COALESCE(MAX(r.revision), 0)
+FROM
+    cluster_config_revisions AS r
@@ -0,0 +1,6 @@
+SELECT
+    revision, value, requested_by, reason, created_at
+FROM
+    cluster_config_revisions AS r
+WHERE
+    ( r.revision = ? )
@@ -0,0 +1,7 @@
+SELECT
+    revision, value, requested_by, reason, created_at
+FROM
+    cluster_config_revisions AS r
+ORDER BY
+    r.revision DESC
+LIMIT ?
@@ -0,0 +1,4 @@
+DELETE FROM
+    feature_status_history AS h
+WHERE
+    ( h.name = ? )
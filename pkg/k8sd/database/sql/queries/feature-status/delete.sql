@@ -0,0 +1,4 @@
+DELETE FROM
+    feature_status AS s
+WHERE
+    ( s.name = ? )
@@ -0,0 +1,4 @@
+INSERT INTO
+    feature_status_history(name, outcome, message, version, enabled, error, config_hash, started_at, duration_ms)
+VALUES
+    ( ?, ?, ?, ?, ?, ?, ?, ?, ? )
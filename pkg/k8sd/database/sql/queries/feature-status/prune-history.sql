@@ -0,0 +1,6 @@
+DELETE FROM
+    feature_status_history
+WHERE
+    name = ? AND id NOT IN (
+        SELECT id FROM feature_status_history AS h WHERE ( h.name = ? ) ORDER BY h.id DESC LIMIT ?
+    )
@@ -0,0 +1,9 @@
+SELECT
+    outcome, message, version, enabled, error, config_hash, started_at, duration_ms, count, last_seen_at
+FROM
+    feature_status_history AS h
+WHERE
+    ( h.name = ? )
+ORDER BY
+    h.id DESC
+LIMIT ?
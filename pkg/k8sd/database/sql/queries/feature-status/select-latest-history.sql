@@ -0,0 +1,9 @@
+SELECT
+    id, outcome, message, version, enabled, error, config_hash
+FROM
+    feature_status_history AS h
+WHERE
+    ( h.name = ? )
+ORDER BY
+    h.id DESC
+LIMIT 1
@@ -0,0 +1,6 @@
+UPDATE
+    feature_status_history
+SET
+    count = count + 1, last_seen_at = ?, duration_ms = ?
+WHERE
+    ( id = ? )
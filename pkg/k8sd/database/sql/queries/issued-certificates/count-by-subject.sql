@@ -0,0 +1,6 @@
+SELECT
+    COUNT(CASE WHEN t.revoked_at IS NULL THEN 1 END), COUNT(t.revoked_at)
+FROM
+    issued_certificates AS t
+WHERE
+    ( t.subject = ? AND t.not_after > ? )
@@ -0,0 +1,5 @@
+INSERT INTO
+    issued_certificates(serial, fingerprint, node, name, subject, not_after)
+VALUES
+    ( ?, ?, ?, ?, ?, ? )
+ON CONFLICT(fingerprint) DO NOTHING
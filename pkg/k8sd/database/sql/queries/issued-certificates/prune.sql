@@ -0,0 +1,4 @@
+DELETE FROM
+    issued_certificates
+WHERE
+    ( not_after <= ? )
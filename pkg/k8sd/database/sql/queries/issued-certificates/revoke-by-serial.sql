@@ -0,0 +1,6 @@
+UPDATE
+    issued_certificates
+SET
+    revoked_at = ?
+WHERE
+    ( serial = ? AND revoked_at IS NULL )
@@ -0,0 +1,6 @@
+SELECT
+    COUNT(*)
+FROM
+    issued_certificates AS t
+WHERE
+    ( t.fingerprint = ? AND t.revoked_at IS NOT NULL )
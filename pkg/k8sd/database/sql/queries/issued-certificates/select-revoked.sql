@@ -0,0 +1,8 @@
+SELECT
+    t.serial, t.fingerprint, t.node, t.name, t.subject, t.not_after, t.revoked_at
+FROM
+    issued_certificates AS t
+WHERE
+    ( t.revoked_at IS NOT NULL AND t.not_after > ? )
+ORDER BY
+    t.revoked_at, t.serial
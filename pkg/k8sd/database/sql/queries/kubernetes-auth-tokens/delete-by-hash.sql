@@ -1,4 +1,4 @@
 DELETE FROM
     kubernetes_auth_tokens AS t
 WHERE
-    ( t.username = ? AND t.groups = ? )
+    ( t.token_hash = ? )
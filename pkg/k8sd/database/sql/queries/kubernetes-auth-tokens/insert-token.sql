@@ -1,4 +1,4 @@
 INSERT INTO
-    kubernetes_auth_tokens(username, groups, token)
+    kubernetes_auth_tokens(username, groups, token, token_lookup, token_salt, token_hash, description, expiry, created_at)
 VALUES
-    ( ?, ?, ? )
+    ( ?, ?, '', ?, ?, ?, ?, ?, ? )
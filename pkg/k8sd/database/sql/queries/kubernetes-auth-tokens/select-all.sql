@@ -0,0 +1,6 @@
+SELECT
+    id, username, groups, token_lookup, token_salt, token_hash, description, expiry, created_at, last_used_at
+FROM
+    kubernetes_auth_tokens AS t
+ORDER BY
+    t.username, t.groups, t.id
@@ -0,0 +1,6 @@
+SELECT
+    t.id, t.username, t.groups, t.token_salt, t.token_hash, t.expiry
+FROM
+    kubernetes_auth_tokens AS t
+WHERE
+    ( t.token_lookup = ? )
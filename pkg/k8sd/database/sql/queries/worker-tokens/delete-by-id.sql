@@ -1,4 +1,4 @@
 DELETE FROM
     worker_tokens AS t
 WHERE
-    t.token = ?
+    t.id = ?
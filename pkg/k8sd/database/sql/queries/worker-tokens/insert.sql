@@ -1,4 +1,4 @@
 INSERT INTO
-    worker_tokens(name, token, expiry)
+    worker_tokens(name, token, token_lookup, token_salt, token_hash, expiry)
 VALUES
-    ( ?, ?, ? )
+    ( ?, '', ?, ?, ?, ? )
@@ -1,7 +1,6 @@
 SELECT
-    t.name, t.expiry
+    t.id, t.name, t.token_salt, t.token_hash, t.expiry
 FROM
     worker_tokens AS t
 WHERE
-    ( t.token = ? )
-LIMIT 1
+    ( t.token_lookup = ? )
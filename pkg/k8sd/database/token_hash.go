@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/microcluster/v3/microcluster/db"
+)
+
+// tokenLookupLength is the number of characters after the prefix of a token (e.g. "token::") that are stored in
+// plaintext, so that a token can be found without hashing it with the salt of every stored token.
+// Tokens have 160 random bits, so 128 random bits are only stored as a salted hash.
+const tokenLookupLength = 8
+
+// tokenHash is the salted hash of a token, as stored in the database.
+type tokenHash struct {
+	// Lookup is the plaintext prefix of the token, see tokenLookup.
+	Lookup string
+	// Salt is the random salt of the hash, in hex.
+	Salt string
+	// Hash is the SHA256 hash of the salt and the token, in hex.
+	// NOTE: Tokens are random, so a fast hash is sufficient to prevent recovering them from the hash.
+	Hash string
+}
+
+// tokenLookup returns the plaintext prefix of a token, which is used to find the hash of the token.
+func tokenLookup(token string) string {
+	prefixLength := 0
+	if idx := strings.Index(token, "::"); idx >= 0 {
+		prefixLength = idx + 2
+	}
+	if len(token) <= prefixLength+tokenLookupLength {
+		return token[:prefixLength]
+	}
+	return token[:prefixLength+tokenLookupLength]
+}
+
+func computeTokenHash(salt []byte, token string) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(token))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newTokenSalt returns a new random salt.
+func newTokenSalt() ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("is the system entropy low? failed to get random bytes: %w", err)
+	}
+	return salt, nil
+}
+
+// hashToken hashes a token with the given salt.
+func hashToken(salt []byte, token string) tokenHash {
+	return tokenHash{
+		Lookup: tokenLookup(token),
+		Salt:   hex.EncodeToString(salt),
+		Hash:   computeTokenHash(salt, token),
+	}
+}
+
+// newTokenHash hashes a token with a new random salt.
+func newTokenHash(token string) (tokenHash, error) {
+	salt, err := newTokenSalt()
+	if err != nil {
+		return tokenHash{}, err
+	}
+	return hashToken(salt, token), nil
+}
+
+// matches returns true if the token matches the hash. The hashes are compared in constant time.
+func (h tokenHash) matches(token string) bool {
+	salt, err := hex.DecodeString(h.Salt)
+	if err != nil || h.Hash == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(computeTokenHash(salt, token)), []byte(h.Hash)) == 1
+}
+
+// schemaHashTokens returns a schema update that replaces the plaintext tokens of a table with salted hashes.
+// The table must have the id, token, token_lookup, token_salt and token_hash columns.
+func schemaHashTokens(table string) db.Update {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT id, token FROM %s WHERE token != ''", table))
+		if err != nil {
+			return fmt.Errorf("failed to list tokens of %s: %w", table, err)
+		}
+		tokens := make(map[int64]string)
+		for rows.Next() {
+			var id int64
+			var token string
+			if err := rows.Scan(&id, &token); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan token of %s: %w", table, err)
+			}
+			tokens[id] = token
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to list tokens of %s: %w", table, err)
+		}
+
+		for id, token := range tokens {
+			hash, err := newTokenHash(token)
+			if err != nil {
+				return fmt.Errorf("failed to hash token %d of %s: %w", id, table, err)
+			}
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET token = '', token_lookup = ?, token_salt = ?, token_hash = ? WHERE id = ?", table), hash.Lookup, hash.Salt, hash.Hash, id); err != nil {
+				return fmt.Errorf("failed to store hash of token %d of %s: %w", id, table, err)
+			}
+		}
+		return nil
+	}
+}
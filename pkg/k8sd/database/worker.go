@@ -15,30 +15,60 @@ import (
 var workerStmts = map[string]int{
 	"insert-token": MustPrepareStatement("worker-tokens", "insert.sql"),
 	"select-token": MustPrepareStatement("worker-tokens", "select.sql"),
-	"delete-token": MustPrepareStatement("worker-tokens", "delete-by-token.sql"),
+	"delete-token": MustPrepareStatement("worker-tokens", "delete-by-id.sql"),
+}
+
+// findWorkerNodeToken returns the ID, node name and expiry of the stored worker token that matches the specified token.
+// findWorkerNodeToken returns false if no stored token matches.
+func findWorkerNodeToken(ctx context.Context, tx *sql.Tx, token string) (int64, string, time.Time, bool, error) {
+	selectTxStmt, err := db.Stmt(tx, workerStmts["select-token"])
+	if err != nil {
+		return 0, "", time.Time{}, false, fmt.Errorf("failed to prepare select statement: %w", err)
+	}
+	rows, err := selectTxStmt.QueryContext(ctx, tokenLookup(token))
+	if err != nil {
+		return 0, "", time.Time{}, false, fmt.Errorf("select token query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var tokenNodeName string
+		var expiry time.Time
+		var hash tokenHash
+		if err := rows.Scan(&id, &tokenNodeName, &hash.Salt, &hash.Hash, &expiry); err != nil {
+			return 0, "", time.Time{}, false, fmt.Errorf("failed to scan token: %w", err)
+		}
+		if hash.matches(token) {
+			return id, tokenNodeName, expiry, true, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, "", time.Time{}, false, fmt.Errorf("select token query failed: %w", err)
+	}
+	return 0, "", time.Time{}, false, nil
 }
 
 // CheckWorkerNodeToken returns true if the specified token can be used to join the specified node on the cluster.
 // CheckWorkerNodeToken will return true if the token is empty or if the token is associated with the specified node
 // and has not expired.
 func CheckWorkerNodeToken(ctx context.Context, tx *sql.Tx, nodeName string, token string) (bool, error) {
-	selectTxStmt, err := db.Stmt(tx, workerStmts["select-token"])
+	_, tokenNodeName, expiry, ok, err := findWorkerNodeToken(ctx, tx, token)
 	if err != nil {
-		return false, fmt.Errorf("failed to prepare select statement: %w", err)
+		return false, err
 	}
-	var tokenNodeName string
-	var expiry time.Time
-	if selectTxStmt.QueryRowContext(ctx, token).Scan(&tokenNodeName, &expiry) == nil {
-		isValidToken := tokenNodeName == "" || subtle.ConstantTimeCompare([]byte(nodeName), []byte(tokenNodeName)) == 1
-		notExpired := time.Now().Before(expiry)
-		return isValidToken && notExpired, nil
+	if !ok {
+		return false, nil
 	}
-	return false, nil
+	isValidToken := tokenNodeName == "" || subtle.ConstantTimeCompare([]byte(nodeName), []byte(tokenNodeName)) == 1
+	notExpired := time.Now().Before(expiry)
+	return isValidToken && notExpired, nil
 }
 
-// GetOrCreateWorkerNodeToken returns a token that can be used to join a worker node on the cluster.
-// GetOrCreateWorkerNodeToken will return the existing token, if one already exists for the node.
-func GetOrCreateWorkerNodeToken(ctx context.Context, tx *sql.Tx, nodeName string, expiry time.Time) (string, error) {
+// CreateWorkerNodeToken creates a new token that can be used to join a worker node on the cluster.
+// Only a salted hash of the token is stored, so existing tokens of the node cannot be returned again and remain
+// valid until they are deleted or expire.
+func CreateWorkerNodeToken(ctx context.Context, tx *sql.Tx, nodeName string, expiry time.Time) (string, error) {
 	insertTxStmt, err := db.Stmt(tx, workerStmts["insert-token"])
 	if err != nil {
 		return "", fmt.Errorf("failed to prepare insert statement: %w", err)
@@ -50,19 +80,31 @@ func GetOrCreateWorkerNodeToken(ctx context.Context, tx *sql.Tx, nodeName string
 		return "", fmt.Errorf("is the system entropy low? failed to get random bytes: %w", err)
 	}
 	token := fmt.Sprintf("worker::%s", hex.EncodeToString(b))
-	if _, err := insertTxStmt.ExecContext(ctx, nodeName, token, expiry); err != nil {
+	hash, err := newTokenHash(token)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash token: %w", err)
+	}
+	if _, err := insertTxStmt.ExecContext(ctx, nodeName, hash.Lookup, hash.Salt, hash.Hash, expiry); err != nil {
 		return "", fmt.Errorf("insert token query failed: %w", err)
 	}
 	return token, nil
 }
 
-// DeleteWorkerNodeToken returns a token that can be used to join worker nodes on the cluster.
-func DeleteWorkerNodeToken(ctx context.Context, tx *sql.Tx, nodeName string) error {
+// DeleteWorkerNodeToken deletes the specified worker token (if any).
+func DeleteWorkerNodeToken(ctx context.Context, tx *sql.Tx, token string) error {
+	id, _, _, ok, err := findWorkerNodeToken(ctx, tx, token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
 	deleteTxStmt, err := db.Stmt(tx, workerStmts["delete-token"])
 	if err != nil {
 		return fmt.Errorf("failed to prepare delete statement: %w", err)
 	}
-	if _, err := deleteTxStmt.ExecContext(ctx, nodeName); err != nil {
+	if _, err := deleteTxStmt.ExecContext(ctx, id); err != nil {
 		return fmt.Errorf("delete token query failed: %w", err)
 	}
 	return nil
@@ -22,11 +22,11 @@ func TestWorkerNodeToken(t *testing.T) {
 				g.Expect(err).To(Not(HaveOccurred()))
 				g.Expect(exists).To(BeFalse())
 
-				token, err := database.GetOrCreateWorkerNodeToken(ctx, tx, "somenode", tokenExpiry)
+				token, err := database.CreateWorkerNodeToken(ctx, tx, "somenode", tokenExpiry)
 				g.Expect(err).To(Not(HaveOccurred()))
 				g.Expect(token).To(HaveLen(48))
 
-				othertoken, err := database.GetOrCreateWorkerNodeToken(ctx, tx, "someothernode", tokenExpiry)
+				othertoken, err := database.CreateWorkerNodeToken(ctx, tx, "someothernode", tokenExpiry)
 				g.Expect(err).To(Not(HaveOccurred()))
 				g.Expect(othertoken).To(HaveLen(48))
 				g.Expect(othertoken).NotTo(Equal(token))
@@ -50,7 +50,7 @@ func TestWorkerNodeToken(t *testing.T) {
 				g.Expect(err).To(Not(HaveOccurred()))
 				g.Expect(valid).To(BeFalse())
 
-				newToken, err := database.GetOrCreateWorkerNodeToken(ctx, tx, "somenode", tokenExpiry)
+				newToken, err := database.CreateWorkerNodeToken(ctx, tx, "somenode", tokenExpiry)
 				g.Expect(err).To(Not(HaveOccurred()))
 				g.Expect(newToken).To(HaveLen(48))
 				g.Expect(newToken).ToNot(Equal(token))
@@ -59,7 +59,7 @@ func TestWorkerNodeToken(t *testing.T) {
 			t.Run("Expiry", func(t *testing.T) {
 				t.Run("Valid", func(t *testing.T) {
 					g := NewWithT(t)
-					token, err := database.GetOrCreateWorkerNodeToken(ctx, tx, "nodeExpiry1", time.Now().Add(time.Hour))
+					token, err := database.CreateWorkerNodeToken(ctx, tx, "nodeExpiry1", time.Now().Add(time.Hour))
 					g.Expect(err).To(Not(HaveOccurred()))
 					g.Expect(token).To(HaveLen(48))
 
@@ -70,7 +70,7 @@ func TestWorkerNodeToken(t *testing.T) {
 
 				t.Run("Expired", func(t *testing.T) {
 					g := NewWithT(t)
-					token, err := database.GetOrCreateWorkerNodeToken(ctx, tx, "nodeExpiry2", time.Now().Add(-time.Hour))
+					token, err := database.CreateWorkerNodeToken(ctx, tx, "nodeExpiry2", time.Now().Add(-time.Hour))
 					g.Expect(err).To(Not(HaveOccurred()))
 					g.Expect(token).To(HaveLen(48))
 
@@ -82,7 +82,7 @@ func TestWorkerNodeToken(t *testing.T) {
 
 			t.Run("AnyNodeName", func(t *testing.T) {
 				g := NewWithT(t)
-				token, err := database.GetOrCreateWorkerNodeToken(ctx, tx, "", tokenExpiry)
+				token, err := database.CreateWorkerNodeToken(ctx, tx, "", tokenExpiry)
 				g.Expect(err).To(Not(HaveOccurred()))
 				g.Expect(token).To(HaveLen(48))
 
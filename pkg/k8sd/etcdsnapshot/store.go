@@ -0,0 +1,254 @@
+// Package etcdsnapshot stores snapshots of the managed etcd datastore, either in a local directory or in an
+// S3-compatible bucket.
+package etcdsnapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/client/s3"
+)
+
+const (
+	namePrefix = "etcd-snapshot-"
+	nameSuffix = ".db"
+	timeFormat = "20060102T150405Z"
+)
+
+// Snapshot describes a stored etcd snapshot.
+type Snapshot struct {
+	// Name is the name of the snapshot, e.g. "etcd-snapshot-cp1-20261016T120000Z.db".
+	Name string
+	// Size is the size of the snapshot in bytes.
+	Size int64
+	// CreatedAt is the time the snapshot was taken, as encoded in the name.
+	CreatedAt time.Time
+}
+
+// Store stores etcd snapshots.
+type Store interface {
+	// Save stores the snapshot file with the given name.
+	// NOTE: Save may move the file into the store, so callers must not rely on the file afterwards.
+	Save(ctx context.Context, name string, file string) error
+	// List returns the stored snapshots, oldest first. Files that are not named like snapshots are ignored.
+	List(ctx context.Context) ([]Snapshot, error)
+	// Open opens a stored snapshot. The caller must close the returned reader.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Delete deletes a stored snapshot.
+	Delete(ctx context.Context, name string) error
+}
+
+// Name returns the name of a snapshot taken on node at time t.
+func Name(node string, t time.Time) string {
+	return fmt.Sprintf("%s%s-%s%s", namePrefix, node, t.UTC().Format(timeFormat), nameSuffix)
+}
+
+// ValidName returns true if name is the name of a snapshot, as returned by Name.
+func ValidName(name string) bool {
+	_, ok := parseName(name)
+	return ok
+}
+
+// parseName returns the creation time of a snapshot from its name.
+func parseName(name string) (time.Time, bool) {
+	if strings.ContainsAny(name, `/\`) {
+		return time.Time{}, false
+	}
+	trimmed, ok := strings.CutPrefix(name, namePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	if trimmed, ok = strings.CutSuffix(trimmed, nameSuffix); !ok || len(trimmed) <= len(timeFormat) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(timeFormat, trimmed[len(trimmed)-len(timeFormat):])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// sortSnapshots orders snapshots by creation time, oldest first.
+func sortSnapshots(snapshots []Snapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+}
+
+// Prune deletes the oldest snapshots, so that at most retention snapshots are kept.
+// Prune returns the names of the deleted snapshots.
+func Prune(ctx context.Context, store Store, retention int) ([]string, error) {
+	snapshots, err := store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	var deleted []string
+	for i := 0; i < len(snapshots)-retention; i++ {
+		if err := store.Delete(ctx, snapshots[i].Name); err != nil {
+			return deleted, fmt.Errorf("failed to delete snapshot %s: %w", snapshots[i].Name, err)
+		}
+		deleted = append(deleted, snapshots[i].Name)
+	}
+	return deleted, nil
+}
+
+// LocalStore stores snapshots in a local directory.
+type LocalStore struct {
+	// Dir is the directory of the snapshots. Dir is created when the first snapshot is saved.
+	Dir string
+}
+
+func (s LocalStore) path(name string) (string, error) {
+	if _, ok := parseName(name); !ok {
+		return "", fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return filepath.Join(s.Dir, name), nil
+}
+
+func (s LocalStore) Save(_ context.Context, name string, file string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := os.Rename(file, path); err != nil {
+		return fmt.Errorf("failed to move snapshot to %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("failed to set permissions of %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s LocalStore) List(_ context.Context) ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		createdAt, ok := parseName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entry.Name(), err)
+		}
+		snapshots = append(snapshots, Snapshot{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+	sortSnapshots(snapshots)
+	return snapshots, nil
+}
+
+func (s LocalStore) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	return f, nil
+}
+
+func (s LocalStore) Delete(_ context.Context, name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove snapshot: %w", err)
+	}
+	return nil
+}
+
+// S3Store stores snapshots in an S3-compatible bucket.
+type S3Store struct {
+	Client *s3.Client
+	// Prefix is prepended to the snapshot names to form the object keys, e.g. "cluster-a/".
+	Prefix string
+}
+
+func (s S3Store) key(name string) (string, error) {
+	if _, ok := parseName(name); !ok {
+		return "", fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return s.Prefix + name, nil
+}
+
+func (s S3Store) Save(ctx context.Context, name string, file string) error {
+	key, err := s.key(name)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat snapshot: %w", err)
+	}
+	if err := s.Client.PutObject(ctx, key, f, info.Size()); err != nil {
+		return fmt.Errorf("failed to upload snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s S3Store) List(ctx context.Context) ([]Snapshot, error) {
+	objects, err := s.Client.ListObjects(ctx, s.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	var snapshots []Snapshot
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, s.Prefix)
+		if createdAt, ok := parseName(name); ok {
+			snapshots = append(snapshots, Snapshot{Name: name, Size: object.Size, CreatedAt: createdAt})
+		}
+	}
+	sortSnapshots(snapshots)
+	return snapshots, nil
+}
+
+func (s S3Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.Client.GetObject(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download snapshot: %w", err)
+	}
+	return r, nil
+}
+
+func (s S3Store) Delete(ctx context.Context, name string) error {
+	key, err := s.key(name)
+	if err != nil {
+		return err
+	}
+	if err := s.Client.DeleteObject(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+var (
+	_ Store = LocalStore{}
+	_ Store = S3Store{}
+)
@@ -0,0 +1,74 @@
+package etcdsnapshot_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/k8sd/etcdsnapshot"
+	. "github.com/onsi/gomega"
+)
+
+func TestLocalStore(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	store := etcdsnapshot.LocalStore{Dir: filepath.Join(t.TempDir(), "etcd-snapshots")}
+
+	// the directory is created with the first snapshot
+	snapshots, err := store.List(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(snapshots).To(BeEmpty())
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for i, node := range []string{"cp2", "cp1", "cp3"} {
+		file := filepath.Join(t.TempDir(), "snapshot")
+		g.Expect(os.WriteFile(file, []byte(node), 0o644)).To(Succeed())
+		g.Expect(store.Save(ctx, etcdsnapshot.Name(node, start.Add(time.Duration(i)*time.Hour)), file)).To(Succeed())
+	}
+	g.Expect(os.WriteFile(filepath.Join(store.Dir, "notes.txt"), []byte("not a snapshot"), 0o644)).To(Succeed())
+
+	snapshots, err = store.List(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(snapshots).To(Equal([]etcdsnapshot.Snapshot{
+		{Name: "etcd-snapshot-cp2-20261016T120000Z.db", Size: 3, CreatedAt: start},
+		{Name: "etcd-snapshot-cp1-20261016T130000Z.db", Size: 3, CreatedAt: start.Add(time.Hour)},
+		{Name: "etcd-snapshot-cp3-20261016T140000Z.db", Size: 3, CreatedAt: start.Add(2 * time.Hour)},
+	}))
+
+	info, err := os.Stat(filepath.Join(store.Dir, snapshots[0].Name))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+
+	r, err := store.Open(ctx, "etcd-snapshot-cp1-20261016T130000Z.db")
+	g.Expect(err).ToNot(HaveOccurred())
+	b, err := io.ReadAll(r)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(r.Close()).To(Succeed())
+	g.Expect(string(b)).To(Equal("cp1"))
+
+	// names must be snapshot names, so that files outside the directory cannot be accessed
+	_, err = store.Open(ctx, "../etcd-snapshot-cp1-20261016T130000Z.db")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(store.Delete(ctx, "notes.txt")).ToNot(Succeed())
+
+	t.Run("Prune", func(t *testing.T) {
+		g := NewWithT(t)
+
+		deleted, err := etcdsnapshot.Prune(ctx, store, 2)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(deleted).To(Equal([]string{"etcd-snapshot-cp2-20261016T120000Z.db"}))
+
+		deleted, err = etcdsnapshot.Prune(ctx, store, 2)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(deleted).To(BeEmpty())
+
+		snapshots, err := store.List(ctx)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(snapshots).To(HaveLen(2))
+		g.Expect(filepath.Join(store.Dir, "notes.txt")).To(BeAnExistingFile())
+	})
+}
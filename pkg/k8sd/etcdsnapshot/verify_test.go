@@ -0,0 +1,52 @@
+package etcdsnapshot_test
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/k8sd/etcdsnapshot"
+	. "github.com/onsi/gomega"
+)
+
+func TestVerify(t *testing.T) {
+	db := make([]byte, 2*512)
+	for i := range db {
+		db[i] = byte(i)
+	}
+	hash := sha256.Sum256(db)
+
+	corrupted := append([]byte{}, db...)
+	corrupted[100] ^= 0xff
+
+	for _, tc := range []struct {
+		name      string
+		content   []byte
+		expectErr bool
+	}{
+		{name: "Valid", content: append(append([]byte{}, db...), hash[:]...)},
+		{name: "Corrupted", content: append(corrupted, hash[:]...), expectErr: true},
+		{name: "NoHash", content: db, expectErr: true},
+		{name: "Truncated", content: append(append([]byte{}, db[:512]...), hash[:]...)[:512+10], expectErr: true},
+		{name: "Empty", expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			file := filepath.Join(t.TempDir(), "snapshot.db")
+			g.Expect(os.WriteFile(file, tc.content, 0o600)).To(Succeed())
+
+			if err := etcdsnapshot.Verify(file); tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+
+	t.Run("NotFound", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(etcdsnapshot.Verify(filepath.Join(t.TempDir(), "missing.db"))).ToNot(Succeed())
+	})
+}
@@ -0,0 +1,91 @@
+package addons
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/canonical/k8sd/pkg/client/helm"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/snap"
+)
+
+const (
+	enabledMsg          = "enabled"
+	disabledMsg         = "disabled"
+	deleteFailedMsgTmpl = "Failed to delete add-on %s, the error was: %v"
+	deployFailedMsgTmpl = "Failed to deploy add-on %s, the error was: %v"
+)
+
+// ApplyHelmAddon deploys the Helm chart of an add-on when cfg.Enabled is true.
+// ApplyHelmAddon removes the Helm release of an add-on when cfg.Enabled is false.
+// ApplyHelmAddon will always return a FeatureStatus indicating the current status of the
+// deployment.
+// ApplyHelmAddon returns an error if anything fails. The error is also wrapped in the .Message field of the
+// returned FeatureStatus.
+func ApplyHelmAddon(ctx context.Context, snap snap.Snap, name string, cfg types.HelmAddon) (types.FeatureStatus, error) {
+	chart := helm.InstallableChart{
+		Name:         name,
+		Namespace:    cfg.GetNamespace(),
+		ManifestPath: cfg.GetChartPath(),
+	}
+
+	if _, err := snap.HelmClient().Apply(ctx, chart, helm.StatePresentOrDeleted(cfg.GetEnabled()), cfg.Values); err != nil {
+		if cfg.GetEnabled() {
+			err = fmt.Errorf("failed to install add-on %s chart: %w", name, err)
+			return types.FeatureStatus{
+				Enabled: false,
+				Message: fmt.Sprintf(deployFailedMsgTmpl, name, err),
+			}, err
+		}
+		err = fmt.Errorf("failed to delete add-on %s chart: %w", name, err)
+		return types.FeatureStatus{
+			Enabled: false,
+			Message: fmt.Sprintf(deleteFailedMsgTmpl, name, err),
+		}, err
+	}
+
+	if cfg.GetEnabled() {
+		return types.FeatureStatus{Enabled: true, Message: enabledMsg}, nil
+	}
+	return types.FeatureStatus{Enabled: false, Message: disabledMsg}, nil
+}
+
+// ApplyHelmAddons applies all add-ons of the cluster configuration, in order of their name.
+// ApplyHelmAddons returns the status of each individual add-on, as well as an aggregated status.
+// ApplyHelmAddons keeps going if an add-on fails to apply, and returns the joined errors of all failed add-ons.
+func ApplyHelmAddons(ctx context.Context, snap snap.Snap, addons types.HelmAddons) (map[string]types.FeatureStatus, types.FeatureStatus, error) {
+	statuses := make(map[string]types.FeatureStatus, len(addons))
+
+	var (
+		errs    []error
+		enabled int
+	)
+	for _, name := range slices.Sorted(maps.Keys(addons)) {
+		status, err := ApplyHelmAddon(ctx, snap, name, addons[name])
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if status.Enabled {
+			enabled++
+		}
+		statuses[name] = status
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return statuses, types.FeatureStatus{
+			Enabled: enabled > 0,
+			Message: fmt.Sprintf("Failed to apply %d of %d add-ons, the error was: %v", len(errs), len(addons), err),
+		}, err
+	}
+
+	if enabled == 0 {
+		return statuses, types.FeatureStatus{Enabled: false, Message: disabledMsg}, nil
+	}
+	return statuses, types.FeatureStatus{
+		Enabled: true,
+		Message: fmt.Sprintf("%d of %d add-ons enabled", enabled, len(addons)),
+	}, nil
+}
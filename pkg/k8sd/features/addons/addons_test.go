@@ -0,0 +1,111 @@
+package addons_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/client/helm"
+	helmmock "github.com/canonical/k8sd/pkg/client/helm/mock"
+	"github.com/canonical/k8sd/pkg/k8sd/features/addons"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	"github.com/canonical/k8sd/pkg/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestApplyHelmAddon(t *testing.T) {
+	helmErr := errors.New("failed to apply")
+	for _, tc := range []struct {
+		name        string
+		config      types.HelmAddon
+		expectState helm.State
+		helmError   error
+	}{
+		{
+			name:        "EnableWithoutHelmError",
+			config:      types.HelmAddon{Enabled: utils.Pointer(true)},
+			expectState: helm.StatePresent,
+		},
+		{
+			name:        "DisableWithoutHelmError",
+			config:      types.HelmAddon{Enabled: utils.Pointer(false)},
+			expectState: helm.StateDeleted,
+		},
+		{
+			name:        "EnableWithHelmError",
+			config:      types.HelmAddon{Enabled: utils.Pointer(true)},
+			expectState: helm.StatePresent,
+			helmError:   helmErr,
+		},
+		{
+			name:        "DisableWithHelmError",
+			config:      types.HelmAddon{Enabled: utils.Pointer(false)},
+			expectState: helm.StateDeleted,
+			helmError:   helmErr,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			h := &helmmock.Mock{
+				ApplyErr: tc.helmError,
+			}
+			s := &snapmock.Snap{
+				Mock: snapmock.Mock{
+					HelmClient: h,
+				},
+			}
+
+			tc.config.ChartPath = utils.Pointer("/opt/charts/my-chart")
+			tc.config.Namespace = utils.Pointer("my-namespace")
+			tc.config.Values = map[string]any{"replicas": 2}
+
+			status, err := addons.ApplyHelmAddon(context.Background(), s, "my-chart", tc.config)
+			if tc.helmError == nil {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.helmError))
+			}
+
+			g.Expect(h.ApplyCalledWith).To(ConsistOf(SatisfyAll(
+				HaveField("Chart.Name", Equal("my-chart")),
+				HaveField("Chart.Namespace", Equal("my-namespace")),
+				HaveField("Chart.ManifestPath", Equal("/opt/charts/my-chart")),
+				HaveField("State", Equal(tc.expectState)),
+				HaveField("Values", HaveKeyWithValue("replicas", 2)),
+			)))
+			switch {
+			case tc.helmError != nil:
+				g.Expect(status.Message).To(ContainSubstring(helmErr.Error()))
+			case tc.config.GetEnabled():
+				g.Expect(status.Message).To(Equal("enabled"))
+			default:
+				g.Expect(status.Message).To(Equal("disabled"))
+			}
+		})
+	}
+}
+
+func TestApplyHelmAddons(t *testing.T) {
+	g := NewWithT(t)
+	h := &helmmock.Mock{}
+	s := &snapmock.Snap{
+		Mock: snapmock.Mock{
+			HelmClient: h,
+		},
+	}
+
+	statuses, status, err := addons.ApplyHelmAddons(context.Background(), s, types.HelmAddons{
+		"b": {Enabled: utils.Pointer(false), ChartPath: utils.Pointer("/charts/b")},
+		"a": {Enabled: utils.Pointer(true), ChartPath: utils.Pointer("/charts/a")},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status.Enabled).To(BeTrue())
+	g.Expect(statuses).To(HaveKeyWithValue("a", HaveField("Enabled", BeTrue())))
+	g.Expect(statuses).To(HaveKeyWithValue("b", HaveField("Enabled", BeFalse())))
+
+	// add-ons are applied in order of their name
+	g.Expect(h.ApplyCalledWith).To(HaveLen(2))
+	g.Expect(h.ApplyCalledWith[0].Chart.Name).To(Equal("a"))
+	g.Expect(h.ApplyCalledWith[1].Chart.Name).To(Equal("b"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/canonical/k8sd/pkg/snap"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,6 +14,13 @@ const (
 	EnabledMsg  = "enabled"
 )
 
+const (
+	// ingressServiceName is the name of the shared ingress service that is created by Cilium.
+	ingressServiceName = "cilium-ingress"
+	// gatewayGroupVersion is the Gateway API group version that is served by the Cilium Gateway API controller.
+	gatewayGroupVersion = "gateway.networking.k8s.io/v1"
+)
+
 func CheckNetwork(ctx context.Context, snap snap.Snap) error {
 	client, err := snap.KubernetesClient("kube-system")
 	if err != nil {
@@ -36,3 +44,59 @@ func CheckNetwork(ctx context.Context, snap snap.Snap) error {
 
 	return nil
 }
+
+// CheckIngress checks the Cilium ingress controller and the endpoints of the shared ingress service.
+func CheckIngress(ctx context.Context, snap snap.Snap) error {
+	client, err := snap.KubernetesClient("kube-system")
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	// the ingress controller runs as part of the cilium-operator
+	if err := client.CheckForReadyPods(ctx, "kube-system", metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"io.cilium/app": "operator"}}),
+	}); err != nil {
+		return fmt.Errorf("cilium-operator pods not yet ready: %w", err)
+	}
+
+	endpointSlices, err := client.DiscoveryV1().EndpointSlices("kube-system").List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, ingressServiceName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list endpoints of %s service: %w", ingressServiceName, err)
+	}
+	for _, endpointSlice := range endpointSlices.Items {
+		if len(endpointSlice.Endpoints) > 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no endpoints for %s service", ingressServiceName)
+}
+
+// CheckGateway checks the Cilium Gateway API controller and the Gateway API resources.
+func CheckGateway(ctx context.Context, snap snap.Snap) error {
+	client, err := snap.KubernetesClient("kube-system")
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	// the gateway controller runs as part of the cilium-operator
+	if err := client.CheckForReadyPods(ctx, "kube-system", metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"io.cilium/app": "operator"}}),
+	}); err != nil {
+		return fmt.Errorf("cilium-operator pods not yet ready: %w", err)
+	}
+
+	resources, err := client.ListResourcesForGroupVersion(gatewayGroupVersion)
+	if err != nil {
+		return fmt.Errorf("gateway API resources not yet available: %w", err)
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "gatewayclasses" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("gatewayclasses are not served by %s", gatewayGroupVersion)
+}
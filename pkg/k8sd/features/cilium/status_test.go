@@ -10,7 +10,9 @@ import (
 	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -122,3 +124,56 @@ func TestCheckNetwork(t *testing.T) {
 		g.Expect(err).NotTo(HaveOccurred())
 	})
 }
+
+func TestCheckIngress(t *testing.T) {
+	operator := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "operator",
+			Namespace: "kube-system",
+			Labels:    map[string]string{"io.cilium/app": "operator"},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	endpoints := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cilium-ingress-abcde",
+			Namespace: "kube-system",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "cilium-ingress"},
+		},
+		Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"192.192.192.192"}}},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		objects   []runtime.Object
+		expectErr bool
+	}{
+		{name: "NoOperator", objects: []runtime.Object{endpoints}, expectErr: true},
+		{name: "NoEndpoints", objects: []runtime.Object{operator}, expectErr: true},
+		{name: "Ready", objects: []runtime.Object{operator, endpoints}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			snapM := &snapmock.Snap{
+				Mock: snapmock.Mock{
+					KubernetesClient: &kubernetes.Client{
+						Interface: fake.NewSimpleClientset(tc.objects...),
+					},
+				},
+			}
+
+			err := cilium.CheckIngress(context.Background(), snapM)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
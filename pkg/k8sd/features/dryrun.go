@@ -0,0 +1,75 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/canonical/k8sd/pkg/client/helm"
+	"github.com/canonical/k8sd/pkg/client/kubernetes"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/snap"
+)
+
+// errDryRun is returned by the Kubernetes client of a dry-run snap, so that features cannot change the cluster.
+var errDryRun = errors.New("the kubernetes client is not available in dry-run mode")
+
+// RenderedChart is a Helm chart as a feature would apply it.
+type RenderedChart struct {
+	Chart  helm.InstallableChart
+	State  helm.State
+	Values map[string]any
+}
+
+// Render runs the Apply function of a feature in dry-run mode and returns the Helm charts it would apply, in order.
+// Render does not change the cluster: Helm charts are only recorded, the Kubernetes client is not available, and
+// updates to the cluster configuration and the feature status are discarded. Errors caused by the unavailable
+// Kubernetes client are ignored, since the Helm charts are typically applied before the client is needed.
+// Charts that a feature only applies after waiting for cluster resources (e.g. the MetalLB address pools, which
+// need the MetalLB CRDs) are not rendered.
+func Render(ctx context.Context, env Env, feature Feature, cfg types.ClusterConfig) ([]RenderedChart, error) {
+	recorder := &recordingHelmClient{}
+	env.Snap = dryRunSnap{Snap: env.Snap, helm: recorder}
+	env.UpdateClusterConfig = func(context.Context, types.ClusterConfig) error { return nil }
+	env.SetFeatureStatus = func(context.Context, types.FeatureName, types.FeatureStatus) error { return nil }
+	env.DeleteFeatureStatus = func(context.Context, types.FeatureName) error { return nil }
+
+	if _, err := feature.Apply(ctx, env, cfg); err != nil && !errors.Is(err, errDryRun) {
+		return recorder.rendered(), err
+	}
+	return recorder.rendered(), nil
+}
+
+// dryRunSnap wraps a snap so that features only record the Helm charts they would apply.
+type dryRunSnap struct {
+	snap.Snap
+	helm helm.Client
+}
+
+func (s dryRunSnap) HelmClient() helm.Client { return s.helm }
+
+func (s dryRunSnap) KubernetesClient(string) (*kubernetes.Client, error) { return nil, errDryRun }
+
+// recordingHelmClient is a helm.Client that records the applied charts instead of applying them.
+type recordingHelmClient struct {
+	mu     sync.Mutex
+	charts []RenderedChart
+}
+
+// Apply implements helm.Client. Apply always reports that nothing changed.
+func (c *recordingHelmClient) Apply(_ context.Context, chart helm.InstallableChart, state helm.State, values map[string]any) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.charts = append(c.charts, RenderedChart{Chart: chart, State: state, Values: values})
+	return false, nil
+}
+
+func (c *recordingHelmClient) rendered() []RenderedChart {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.charts
+}
+
+var _ helm.Client = &recordingHelmClient{}
@@ -0,0 +1,54 @@
+package features_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/client/helm"
+	"github.com/canonical/k8sd/pkg/k8sd/features"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+)
+
+func TestRender(t *testing.T) {
+	chart := helm.InstallableChart{Name: "demo", Namespace: "kube-system", ManifestPath: "demo"}
+
+	t.Run("RecordsCharts", func(t *testing.T) {
+		g := NewWithT(t)
+
+		feature := newTestFeature("demo")
+		feature.Apply = func(ctx context.Context, env features.Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+			if _, err := env.Snap.HelmClient().Apply(ctx, chart, helm.StatePresent, map[string]any{"key": "value"}); err != nil {
+				return types.FeatureStatus{}, err
+			}
+			if err := env.UpdateClusterConfig(ctx, types.ClusterConfig{}); err != nil {
+				return types.FeatureStatus{}, err
+			}
+			if _, err := env.Snap.KubernetesClient(""); err != nil {
+				return types.FeatureStatus{}, fmt.Errorf("failed to create kubernetes client: %w", err)
+			}
+			return types.FeatureStatus{Enabled: true}, nil
+		}
+
+		charts, err := features.Render(context.Background(), features.Env{Snap: &snapmock.Snap{}}, feature, types.ClusterConfig{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(charts).To(Equal([]features.RenderedChart{
+			{Chart: chart, State: helm.StatePresent, Values: map[string]any{"key": "value"}},
+		}))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		g := NewWithT(t)
+
+		feature := newTestFeature("demo")
+		feature.Apply = func(context.Context, features.Env, types.ClusterConfig) (types.FeatureStatus, error) {
+			return types.FeatureStatus{}, errors.New("invalid configuration")
+		}
+
+		_, err := features.Render(context.Background(), features.Env{Snap: &snapmock.Snap{}}, feature, types.ClusterConfig{})
+		g.Expect(err).To(MatchError("invalid configuration"))
+	})
+}
@@ -1,6 +1,10 @@
 package features
 
-import "github.com/canonical/k8sd/pkg/k8sd/types"
+import (
+	"strings"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+)
 
 const (
 	DNS           types.FeatureName = "dns"
@@ -10,4 +14,19 @@ const (
 	LoadBalancer  types.FeatureName = "load-balancer"
 	LocalStorage  types.FeatureName = "local-storage"
 	MetricsServer types.FeatureName = "metrics-server"
+	HelmAddons    types.FeatureName = "helm-addons"
 )
+
+// helmAddonStatusPrefix is the prefix of the feature status of individual Helm add-ons.
+const helmAddonStatusPrefix = "helm-addon/"
+
+// HelmAddonStatusName returns the name under which the status of a Helm add-on is stored.
+func HelmAddonStatusName(addon string) types.FeatureName {
+	return types.FeatureName(helmAddonStatusPrefix + addon)
+}
+
+// HelmAddonFromStatusName returns the name of the Helm add-on for a feature status name.
+// HelmAddonFromStatusName returns false if the status does not belong to a Helm add-on.
+func HelmAddonFromStatusName(name types.FeatureName) (string, bool) {
+	return strings.CutPrefix(string(name), helmAddonStatusPrefix)
+}
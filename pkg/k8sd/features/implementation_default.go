@@ -1,33 +1,203 @@
 package features
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/canonical/k8sd/pkg/k8sd/features/addons"
 	"github.com/canonical/k8sd/pkg/k8sd/features/cilium"
 	"github.com/canonical/k8sd/pkg/k8sd/features/coredns"
 	"github.com/canonical/k8sd/pkg/k8sd/features/localpv"
 	"github.com/canonical/k8sd/pkg/k8sd/features/metallb"
 	metrics_server "github.com/canonical/k8sd/pkg/k8sd/features/metrics-server"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/log"
+	"github.com/canonical/k8sd/pkg/utils"
 )
 
-// Default implements the Canonical Kubernetes built-in features.
+// ciliumLock is held by the Cilium-based features (network, gateway, ingress),
+// since they all operate on the `ck-network` chart.
+const ciliumLock = "cilium"
+
+// The Canonical Kubernetes built-in features.
 // Cilium is used for networking (network + ingress + gateway).
 // MetalLB is used for LoadBalancer.
 // CoreDNS is used for DNS.
 // MetricsServer is used for metrics-server.
 // LocalPV Rawfile CSI is used for local-storage.
-var Implementation Interface = &implementation{
-	applyDNS:           coredns.ApplyDNS,
-	applyNetwork:       cilium.ApplyNetwork,
-	applyLoadBalancer:  metallb.ApplyLoadBalancer,
-	applyIngress:       cilium.ApplyIngress,
-	applyGateway:       cilium.ApplyGateway,
-	applyMetricsServer: metrics_server.ApplyMetricsServer,
-	applyLocalStorage:  localpv.ApplyLocalStorage,
+// Operator-defined Helm charts are managed as helm-addons.
+//
+// Gateway and Ingress are provided by Cilium and require the network feature. The rest
+// of the features are applied once the network and DNS are healthy (if they are enabled).
+func init() {
+	Register(Feature{
+		Name:      Network,
+		HasConfig: func(cfg types.ClusterConfig) bool { return !cfg.Network.Empty() },
+		Enabled:   func(cfg types.ClusterConfig) bool { return cfg.Network.GetEnabled() },
+		Lock:      ciliumLock,
+		Apply: func(ctx context.Context, env Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+			return cilium.ApplyNetwork(ctx, env.Snap, env.State, cfg.APIServer, cfg.Network, cfg.Annotations)
+		},
+		CheckStatus: cilium.CheckNetwork,
+	})
+
+	Register(Feature{
+		Name:        DNS,
+		HasConfig:   func(cfg types.ClusterConfig) bool { return !cfg.DNS.Empty() || !cfg.Kubelet.Empty() },
+		Enabled:     func(cfg types.ClusterConfig) bool { return cfg.DNS.GetEnabled() },
+		Apply:       applyDNS,
+		CheckStatus: coredns.CheckDNS,
+	})
+
+	Register(Feature{
+		Name:      Gateway,
+		HasConfig: func(cfg types.ClusterConfig) bool { return !cfg.Gateway.Empty() },
+		Enabled:   func(cfg types.ClusterConfig) bool { return cfg.Gateway.GetEnabled() },
+		Requires:  []types.FeatureName{Network},
+		Lock:      ciliumLock,
+		Apply: func(ctx context.Context, env Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+			return cilium.ApplyGateway(ctx, env.Snap, cfg.Gateway, cfg.Network, cfg.Annotations)
+		},
+		CheckStatus: cilium.CheckGateway,
+	})
+
+	Register(Feature{
+		Name:      Ingress,
+		HasConfig: func(cfg types.ClusterConfig) bool { return !cfg.Ingress.Empty() },
+		Enabled:   func(cfg types.ClusterConfig) bool { return cfg.Ingress.GetEnabled() },
+		Requires:  []types.FeatureName{Network},
+		Lock:      ciliumLock,
+		Apply: func(ctx context.Context, env Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+			return cilium.ApplyIngress(ctx, env.Snap, cfg.Ingress, cfg.Network, cfg.Annotations)
+		},
+		CheckStatus: cilium.CheckIngress,
+	})
+
+	Register(Feature{
+		Name:      LoadBalancer,
+		HasConfig: func(cfg types.ClusterConfig) bool { return !cfg.LoadBalancer.Empty() },
+		Enabled:   func(cfg types.ClusterConfig) bool { return cfg.LoadBalancer.GetEnabled() },
+		DependsOn: []types.FeatureName{Network},
+		Apply: func(ctx context.Context, env Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+			return metallb.ApplyLoadBalancer(ctx, env.Snap, cfg.LoadBalancer, cfg.Network, cfg.Annotations)
+		},
+		CheckStatus: metallb.CheckLoadBalancer,
+	})
+
+	Register(Feature{
+		Name:      LocalStorage,
+		HasConfig: func(cfg types.ClusterConfig) bool { return !cfg.LocalStorage.Empty() },
+		Enabled:   func(cfg types.ClusterConfig) bool { return cfg.LocalStorage.GetEnabled() },
+		DependsOn: []types.FeatureName{Network, DNS},
+		Apply: func(ctx context.Context, env Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+			return localpv.ApplyLocalStorage(ctx, env.Snap, cfg.LocalStorage, cfg.Annotations)
+		},
+		CheckStatus: localpv.CheckLocalStorage,
+	})
+
+	Register(Feature{
+		Name:      MetricsServer,
+		HasConfig: func(cfg types.ClusterConfig) bool { return !cfg.MetricsServer.Empty() },
+		Enabled:   func(cfg types.ClusterConfig) bool { return cfg.MetricsServer.GetEnabled() },
+		DependsOn: []types.FeatureName{Network, DNS},
+		Apply: func(ctx context.Context, env Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+			return metrics_server.ApplyMetricsServer(ctx, env.Snap, cfg.MetricsServer, cfg.Annotations)
+		},
+		CheckStatus: metrics_server.CheckMetricsServer,
+	})
+
+	Register(Feature{
+		Name:      HelmAddons,
+		HasConfig: func(cfg types.ClusterConfig) bool { return len(cfg.Addons) > 0 },
+		Enabled: func(cfg types.ClusterConfig) bool {
+			for _, addon := range cfg.Addons {
+				if addon.GetEnabled() {
+					return true
+				}
+			}
+			return false
+		},
+		DependsOn: []types.FeatureName{Network, DNS},
+		Apply:     applyHelmAddons,
+	})
 }
 
-// StatusChecks implements the Canonical Kubernetes built-in feature status checks.
-var StatusChecks StatusInterface = &statusChecks{
-	checkNetwork: cilium.CheckNetwork,
-	checkDNS:     coredns.CheckDNS,
+// applyDNS applies the DNS feature and stores the ClusterIP of the DNS service as the kubelet cluster DNS.
+func applyDNS(ctx context.Context, env Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+	featureStatus, dnsIP, err := coredns.ApplyDNS(ctx, env.Snap, cfg.DNS, cfg.Kubelet, cfg.Annotations)
+	if err != nil {
+		return featureStatus, fmt.Errorf("failed to apply DNS configuration: %w", err)
+	} else if dnsIP != "" {
+		if err := env.UpdateClusterConfig(ctx, types.ClusterConfig{
+			Kubelet: types.Kubelet{ClusterDNS: utils.Pointer(dnsIP)},
+		}); err != nil {
+			// we already have featureStatus.Message which contains wrapped error of the ApplyDNS
+			// (or empty if no error occurs). we further wrap the error to add the DNS IP change error to the message
+			changeErr := fmt.Errorf("failed to update DNS IP address to %s: %w", dnsIP, err)
+			featureStatus.Message = fmt.Sprintf("%s: %v", featureStatus.Message, changeErr)
+			return featureStatus, changeErr
+		}
+	}
+	return featureStatus, nil
+}
+
+// applyHelmAddons applies the operator-defined Helm add-ons and stores the status of each individual add-on.
+// The Helm releases of removed add-ons are uninstalled, then the add-ons are purged from the cluster configuration
+// and their status is deleted.
+func applyHelmAddons(ctx context.Context, env Env, cfg types.ClusterConfig) (types.FeatureStatus, error) {
+	active := make(types.HelmAddons, len(cfg.Addons))
+	removed := make(types.HelmAddons)
+	for name, addon := range cfg.Addons {
+		if addon.GetRemoved() {
+			removed[name] = addon
+		} else {
+			active[name] = addon
+		}
+	}
+
+	statuses, featureStatus, err := addons.ApplyHelmAddons(ctx, env.Snap, active)
+	for name, status := range statuses {
+		if setErr := env.SetFeatureStatus(ctx, HelmAddonStatusName(name), status); setErr != nil {
+			log.FromContext(ctx).WithValues("addon", name).Error(setErr, "Failed to update add-on status")
+		}
+	}
+
+	if removeErr := removeHelmAddons(ctx, env, removed); removeErr != nil {
+		featureStatus.Message = fmt.Sprintf("%s: %v", featureStatus.Message, removeErr)
+		return featureStatus, errors.Join(err, removeErr)
+	}
+	return featureStatus, err
+}
+
+// removeHelmAddons uninstalls the Helm releases of removed add-ons, purges them from the cluster configuration and
+// deletes their status. Add-ons whose release fails to uninstall are kept, so that they are retried.
+func removeHelmAddons(ctx context.Context, env Env, removed types.HelmAddons) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(removed)) {
+		log := log.FromContext(ctx).WithValues("addon", name)
+
+		if status, err := addons.ApplyHelmAddon(ctx, env.Snap, name, removed[name]); err != nil {
+			if setErr := env.SetFeatureStatus(ctx, HelmAddonStatusName(name), status); setErr != nil {
+				log.Error(setErr, "Failed to update add-on status")
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := env.UpdateClusterConfig(ctx, types.ClusterConfig{
+			Addons: types.HelmAddons{name: {Purge: utils.Pointer(true)}},
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to purge removed add-on %s: %w", name, err))
+			continue
+		}
+		if err := env.DeleteFeatureStatus(ctx, HelmAddonStatusName(name)); err != nil {
+			log.Error(err, "Failed to delete add-on status")
+		}
+	}
+	return errors.Join(errs...)
 }
 
 var Cleanup CleanupInterface = &cleanup{
@@ -14,6 +14,9 @@ var (
 		ManifestPath: filepath.Join("charts", "rawfile-csi-0.9.2.tgz"),
 	}
 
+	// storageClassName is the name of the StorageClass that is created for Rawfile LocalPV CSI.
+	storageClassName = "csi-rawfile-default"
+
 	// imageRepo is the repository to use for Rawfile LocalPV CSI.
 	imageRepo = "ghcr.io/canonical/rawfile-localpv"
 	// ImageTag is the image tag to use for Rawfile LocalPV CSI.
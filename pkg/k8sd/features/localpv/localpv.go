@@ -28,6 +28,7 @@ func ApplyLocalStorage(ctx context.Context, snap snap.Snap, cfg types.LocalStora
 	values := map[string]any{
 		"storageClass": map[string]any{
 			"enabled":              true,
+			"name":                 storageClassName,
 			"isDefault":            cfg.GetDefault(),
 			"reclaimPolicy":        cfg.GetReclaimPolicy(),
 			"allowVolumeExpansion": false,
@@ -0,0 +1,75 @@
+package localpv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/client/kubernetes"
+	"github.com/canonical/k8sd/pkg/k8sd/features/localpv"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckLocalStorage(t *testing.T) {
+	readyPod := func(component string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rawfile-csi-" + component,
+				Namespace: "kube-system",
+				Labels:    map[string]string{"app.kubernetes.io/name": "rawfile-csi", "component": component},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+	}
+	storageClass := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "csi-rawfile-default"}}
+
+	for _, tc := range []struct {
+		name      string
+		objects   []runtime.Object
+		expectErr string
+	}{
+		{
+			name:      "NoStorageClass",
+			objects:   []runtime.Object{readyPod("controller"), readyPod("node")},
+			expectErr: "storage class csi-rawfile-default",
+		},
+		{
+			name:      "NoNodePlugin",
+			objects:   []runtime.Object{storageClass, readyPod("controller")},
+			expectErr: "rawfile-csi-node pods not yet ready",
+		},
+		{
+			name:    "Ready",
+			objects: []runtime.Object{storageClass, readyPod("controller"), readyPod("node")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			snapM := &snapmock.Snap{
+				Mock: snapmock.Mock{
+					KubernetesClient: &kubernetes.Client{
+						Interface: fake.NewSimpleClientset(tc.objects...),
+					},
+				},
+			}
+
+			err := localpv.CheckLocalStorage(context.Background(), snapM)
+			if tc.expectErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectErr)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
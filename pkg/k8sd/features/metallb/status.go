@@ -0,0 +1,65 @@
+package metallb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/canonical/k8sd/pkg/snap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ipAddressPoolListGVK is the kind of the list of MetalLB IP address pools.
+var ipAddressPoolListGVK = schema.GroupVersionKind{Group: "metallb.io", Version: "v1beta1", Kind: "IPAddressPoolList"}
+
+// CheckLoadBalancer checks the MetalLB controller and speaker pods in the cluster.
+// CheckLoadBalancer also reports IP address pools that have no available addresses left, so that new
+// LoadBalancer services would not be allocated an IP address.
+func CheckLoadBalancer(ctx context.Context, snap snap.Snap) error {
+	client, err := snap.KubernetesClient(ChartMetalLB.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	for _, check := range []struct {
+		name      string
+		namespace string
+		labels    map[string]string
+	}{
+		{name: "metallb-controller", namespace: ChartMetalLB.Namespace, labels: map[string]string{"app.kubernetes.io/name": "metallb", "app.kubernetes.io/component": "controller"}},
+		{name: "metallb-speaker", namespace: ChartMetalLB.Namespace, labels: map[string]string{"app.kubernetes.io/name": "metallb", "app.kubernetes.io/component": "speaker"}},
+	} {
+		if err := client.CheckForReadyPods(ctx, check.namespace, metav1.ListOptions{
+			LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: check.labels}),
+		}); err != nil {
+			return fmt.Errorf("%v pods not yet ready: %w", check.name, err)
+		}
+	}
+
+	pools := &unstructured.UnstructuredList{}
+	pools.SetGroupVersionKind(ipAddressPoolListGVK)
+	if err := client.List(ctx, pools, ctrlclient.InNamespace(ChartMetalLBLoadBalancer.Namespace)); err != nil {
+		return fmt.Errorf("failed to list IP address pools: %w", err)
+	}
+
+	var exhausted []string
+	for _, pool := range pools.Items {
+		// the status of the pools is only reported by recent MetalLB versions
+		status, ok, _ := unstructured.NestedMap(pool.Object, "status")
+		if !ok {
+			continue
+		}
+		availableIPv4, _, _ := unstructured.NestedInt64(status, "availableIPv4")
+		availableIPv6, _, _ := unstructured.NestedInt64(status, "availableIPv6")
+		if availableIPv4 == 0 && availableIPv6 == 0 {
+			exhausted = append(exhausted, pool.GetName())
+		}
+	}
+	if len(exhausted) > 0 {
+		return fmt.Errorf("IP address pools %v have no available addresses", exhausted)
+	}
+
+	return nil
+}
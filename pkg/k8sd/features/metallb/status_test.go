@@ -0,0 +1,101 @@
+package metallb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/client/kubernetes"
+	"github.com/canonical/k8sd/pkg/k8sd/features/metallb"
+	snapmock "github.com/canonical/k8sd/pkg/snap/mock"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckLoadBalancer(t *testing.T) {
+	readyPod := func(name string, component string) runtime.Object {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "metallb-system",
+				Labels:    map[string]string{"app.kubernetes.io/name": "metallb", "app.kubernetes.io/component": component},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+	}
+	pool := func(name string, status map[string]any) *unstructured.Unstructured {
+		obj := map[string]any{
+			"apiVersion": "metallb.io/v1beta1",
+			"kind":       "IPAddressPool",
+			"metadata":   map[string]any{"name": name, "namespace": "metallb-system"},
+		}
+		if status != nil {
+			obj["status"] = status
+		}
+		return &unstructured.Unstructured{Object: obj}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		pods      []runtime.Object
+		pools     []*unstructured.Unstructured
+		expectErr string
+	}{
+		{
+			name:      "NoSpeaker",
+			pods:      []runtime.Object{readyPod("controller", "controller")},
+			expectErr: "metallb-speaker pods not yet ready",
+		},
+		{
+			name: "ExhaustedPool",
+			pods: []runtime.Object{readyPod("controller", "controller"), readyPod("speaker", "speaker")},
+			pools: []*unstructured.Unstructured{
+				pool("available", map[string]any{"assignedIPv4": int64(1), "availableIPv4": int64(9)}),
+				pool("exhausted", map[string]any{"assignedIPv4": int64(10), "availableIPv4": int64(0)}),
+			},
+			expectErr: "IP address pools [exhausted] have no available addresses",
+		},
+		{
+			name: "Ready",
+			pods: []runtime.Object{readyPod("controller", "controller"), readyPod("speaker", "speaker")},
+			pools: []*unstructured.Unstructured{
+				pool("available", map[string]any{"assignedIPv4": int64(1), "availableIPv4": int64(9)}),
+				pool("no-status", nil),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ctrlClient := ctrlfake.NewClientBuilder()
+			for _, p := range tc.pools {
+				ctrlClient = ctrlClient.WithObjects(p)
+			}
+
+			snapM := &snapmock.Snap{
+				Mock: snapmock.Mock{
+					KubernetesClient: &kubernetes.Client{
+						Interface: fake.NewSimpleClientset(tc.pods...),
+						Client:    ctrlClient.Build(),
+					},
+				},
+			}
+
+			err := metallb.CheckLoadBalancer(context.Background(), snapM)
+			if tc.expectErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectErr)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
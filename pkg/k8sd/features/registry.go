@@ -0,0 +1,243 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/snap"
+	mctypes "github.com/canonical/microcluster/v3/microcluster/types"
+)
+
+// Env holds the dependencies that are passed to a feature when its configuration is applied.
+type Env struct {
+	// Snap is the snap instance of the local node.
+	Snap snap.Snap
+	// State is the microcluster state of the local node.
+	State mctypes.State
+	// UpdateClusterConfig persists a partial cluster configuration update.
+	// Features use this to store values that are only known after they are applied (e.g. the DNS service IP).
+	UpdateClusterConfig func(context.Context, types.ClusterConfig) error
+	// SetFeatureStatus persists the status of a feature.
+	// Features use this to report the status of sub-components (e.g. individual Helm add-ons).
+	SetFeatureStatus func(context.Context, types.FeatureName, types.FeatureStatus) error
+	// DeleteFeatureStatus deletes the status and the status history of a feature.
+	// Features use this to clean up the status of sub-components that are removed (e.g. individual Helm add-ons).
+	DeleteFeatureStatus func(context.Context, types.FeatureName) error
+}
+
+// Feature describes a feature that is managed by the feature controller.
+type Feature struct {
+	// Name is the unique name of the feature.
+	Name types.FeatureName
+	// HasConfig reports whether a (partial) cluster configuration contains settings for the feature.
+	// It is used to decide which features need to be reconciled after the cluster configuration changes.
+	HasConfig func(types.ClusterConfig) bool
+	// Enabled reports whether the feature is enabled in the cluster configuration.
+	Enabled func(types.ClusterConfig) bool
+	// DependsOn is the list of features this feature depends on.
+	// The feature is not applied before its dependencies that are enabled report healthy.
+	// Dependencies must be registered before the feature itself.
+	DependsOn []types.FeatureName
+	// Requires is the list of features that must be enabled for the feature to be enabled.
+	// Requires implies DependsOn. Required features must be registered before the feature itself.
+	Requires []types.FeatureName
+	// Lock is an optional lock name. Features that share a lock are never applied concurrently,
+	// e.g. because they operate on the same Helm chart.
+	Lock string
+	// Apply applies the feature configuration and returns the resulting status.
+	Apply func(context.Context, Env, types.ClusterConfig) (types.FeatureStatus, error)
+	// CheckStatus checks whether the feature is ready. CheckStatus is optional.
+	CheckStatus func(context.Context, snap.Snap) error
+}
+
+// Dependencies returns the features that must be healthy before the feature is applied.
+func (f Feature) Dependencies() []types.FeatureName {
+	deps := slices.Clone(f.Requires)
+	for _, dep := range f.DependsOn {
+		if !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// Registry keeps track of the features that are managed by k8sd.
+type Registry struct {
+	mu       sync.RWMutex
+	features []Feature
+}
+
+// NewRegistry creates an empty feature registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a feature to the registry.
+// Register returns an error if the feature is invalid, already registered, or depends on a feature that is not registered yet.
+func (r *Registry) Register(feature Feature) error {
+	if feature.Name == "" {
+		return fmt.Errorf("feature name must not be empty")
+	}
+	if feature.HasConfig == nil || feature.Enabled == nil || feature.Apply == nil {
+		return fmt.Errorf("feature %q must implement HasConfig, Enabled and Apply", feature.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(feature.Name) != -1 {
+		return fmt.Errorf("feature %q is already registered", feature.Name)
+	}
+	for _, dep := range feature.Dependencies() {
+		if r.indexOf(dep) == -1 {
+			return fmt.Errorf("feature %q depends on %q which is not registered", feature.Name, dep)
+		}
+	}
+
+	r.features = append(r.features, feature)
+	return nil
+}
+
+// Get returns the feature with the given name.
+func (r *Registry) Get(name types.FeatureName) (Feature, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if idx := r.indexOf(name); idx != -1 {
+		return r.features[idx], true
+	}
+	return Feature{}, false
+}
+
+// Features returns all registered features in registration order.
+// Features are always listed after their dependencies.
+func (r *Registry) Features() []Feature {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.features)
+}
+
+// Names returns the names of all registered features in registration order.
+func (r *Registry) Names() []types.FeatureName {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]types.FeatureName, 0, len(r.features))
+	for _, feature := range r.features {
+		names = append(names, feature.Name)
+	}
+	return names
+}
+
+// Dependents returns the features that depend on or require the given feature, in registration order.
+func (r *Registry) Dependents(name types.FeatureName) []types.FeatureName {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var dependents []types.FeatureName
+	for _, feature := range r.features {
+		if slices.Contains(feature.Dependencies(), name) {
+			dependents = append(dependents, feature.Name)
+		}
+	}
+	return dependents
+}
+
+// RequiredBy returns the features that require the given feature, directly or transitively, in registration order.
+func (r *Registry) RequiredBy(name types.FeatureName) []types.FeatureName {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	required := map[types.FeatureName]struct{}{name: {}}
+	var result []types.FeatureName
+	// features are registered after their requirements, so a single pass finds transitive dependents
+	for _, feature := range r.features {
+		for _, req := range feature.Requires {
+			if _, ok := required[req]; ok {
+				required[feature.Name] = struct{}{}
+				result = append(result, feature.Name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// CheckRequirements checks that all features required by the enabled features of a cluster configuration update are
+// also enabled. Only the features that are enabled or disabled by the update are checked, so that an existing
+// configuration that does not satisfy the requirements does not prevent unrelated updates.
+func (r *Registry) CheckRequirements(old types.ClusterConfig, new types.ClusterConfig) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changed := func(f Feature) bool { return f.Enabled(old) != f.Enabled(new) }
+
+	var (
+		missing    []types.FeatureName
+		requiredBy = make(map[types.FeatureName][]string)
+	)
+	for _, feature := range r.features {
+		if !feature.Enabled(new) {
+			continue
+		}
+		for _, req := range feature.Requires {
+			required := r.features[r.indexOf(req)]
+			if required.Enabled(new) || (!changed(feature) && !changed(required)) {
+				continue
+			}
+			if _, ok := requiredBy[req]; !ok {
+				missing = append(missing, req)
+			}
+			requiredBy[req] = append(requiredBy[req], string(feature.Name))
+		}
+	}
+
+	errs := make([]error, 0, len(missing))
+	for _, req := range missing {
+		errs = append(errs, fmt.Errorf("%s is required by %s", req, strings.Join(requiredBy[req], ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) indexOf(name types.FeatureName) int {
+	return slices.IndexFunc(r.features, func(f Feature) bool { return f.Name == name })
+}
+
+// DefaultRegistry is the registry of features managed by k8sd.
+// The built-in Canonical Kubernetes features are registered in implementation_default.go.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a feature to the default registry.
+// Register is used by the `init()` method in individual packages and panics if the feature cannot be registered.
+func Register(feature Feature) {
+	if err := DefaultRegistry.Register(feature); err != nil {
+		panic(fmt.Errorf("failed to register feature: %w", err))
+	}
+}
+
+// Get returns the feature with the given name from the default registry.
+func Get(name types.FeatureName) (Feature, bool) {
+	return DefaultRegistry.Get(name)
+}
+
+// CheckRequirements checks the requirements of the features of the default registry that are enabled or disabled by a
+// cluster configuration update.
+func CheckRequirements(old types.ClusterConfig, new types.ClusterConfig) error {
+	return DefaultRegistry.CheckRequirements(old, new)
+}
+
+// RequiredBy returns the features of the default registry that require the given feature.
+func RequiredBy(name types.FeatureName) []types.FeatureName {
+	return DefaultRegistry.RequiredBy(name)
+}
+
+// Registered returns all features of the default registry in registration order.
+func Registered() []Feature {
+	return DefaultRegistry.Features()
+}
@@ -0,0 +1,130 @@
+package features_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/k8sd/features"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	. "github.com/onsi/gomega"
+)
+
+func newTestFeature(name types.FeatureName, dependsOn ...types.FeatureName) features.Feature {
+	return features.Feature{
+		Name:      name,
+		HasConfig: func(types.ClusterConfig) bool { return false },
+		Enabled:   func(types.ClusterConfig) bool { return false },
+		DependsOn: dependsOn,
+		Apply: func(context.Context, features.Env, types.ClusterConfig) (types.FeatureStatus, error) {
+			return types.FeatureStatus{}, nil
+		},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("Register", func(t *testing.T) {
+		g := NewWithT(t)
+		r := features.NewRegistry()
+
+		g.Expect(r.Register(newTestFeature("a"))).To(Succeed())
+		g.Expect(r.Register(newTestFeature("b", "a"))).To(Succeed())
+		g.Expect(r.Names()).To(Equal([]types.FeatureName{"a", "b"}))
+
+		f, ok := r.Get("b")
+		g.Expect(ok).To(BeTrue())
+		g.Expect(f.DependsOn).To(ConsistOf(types.FeatureName("a")))
+
+		_, ok = r.Get("c")
+		g.Expect(ok).To(BeFalse())
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		g := NewWithT(t)
+		r := features.NewRegistry()
+
+		g.Expect(r.Register(newTestFeature("a"))).To(Succeed())
+		g.Expect(r.Register(newTestFeature("a"))).ToNot(Succeed())
+	})
+
+	t.Run("MissingDependency", func(t *testing.T) {
+		g := NewWithT(t)
+		r := features.NewRegistry()
+
+		g.Expect(r.Register(newTestFeature("b", "a"))).ToNot(Succeed())
+		g.Expect(r.Names()).To(BeEmpty())
+	})
+
+	t.Run("MissingFunctions", func(t *testing.T) {
+		g := NewWithT(t)
+		r := features.NewRegistry()
+
+		g.Expect(r.Register(features.Feature{Name: "a"})).ToNot(Succeed())
+		g.Expect(r.Register(features.Feature{})).ToNot(Succeed())
+	})
+}
+
+func TestRegistryDependencies(t *testing.T) {
+	enabled := func(name types.FeatureName, requires ...types.FeatureName) features.Feature {
+		f := newTestFeature(name)
+		f.Requires = requires
+		// features are enabled by adding an annotation with their name
+		f.Enabled = func(cfg types.ClusterConfig) bool {
+			_, ok := cfg.Annotations.Get(string(name))
+			return ok
+		}
+		return f
+	}
+
+	g := NewWithT(t)
+	r := features.NewRegistry()
+	g.Expect(r.Register(enabled("network"))).To(Succeed())
+	g.Expect(r.Register(enabled("dns"))).To(Succeed())
+	g.Expect(r.Register(enabled("ingress", "network"))).To(Succeed())
+	g.Expect(r.Register(enabled("ingress-addon", "ingress"))).To(Succeed())
+	g.Expect(r.Register(newTestFeature("metrics", "network", "dns"))).To(Succeed())
+
+	g.Expect(r.Dependents("network")).To(Equal([]types.FeatureName{"ingress", "metrics"}))
+	g.Expect(r.Dependents("metrics")).To(BeEmpty())
+	g.Expect(r.RequiredBy("network")).To(Equal([]types.FeatureName{"ingress", "ingress-addon"}))
+	g.Expect(r.RequiredBy("dns")).To(BeEmpty())
+
+	t.Run("CheckRequirements", func(t *testing.T) {
+		g := NewWithT(t)
+
+		none := types.ClusterConfig{}
+		g.Expect(r.CheckRequirements(none, types.ClusterConfig{Annotations: types.Annotations{"network": "", "ingress": ""}})).To(Succeed())
+		g.Expect(r.CheckRequirements(none, types.ClusterConfig{Annotations: types.Annotations{"network": ""}})).To(Succeed())
+
+		err := r.CheckRequirements(none, types.ClusterConfig{Annotations: types.Annotations{"ingress": "", "ingress-addon": ""}})
+		g.Expect(err).To(MatchError(ContainSubstring("network is required by ingress")))
+
+		// disabling a required feature is refused
+		err = r.CheckRequirements(types.ClusterConfig{Annotations: types.Annotations{"network": "", "ingress": ""}}, types.ClusterConfig{Annotations: types.Annotations{"ingress": ""}})
+		g.Expect(err).To(MatchError(ContainSubstring("network is required by ingress")))
+
+		// features that do not change are not checked
+		unsatisfied := types.ClusterConfig{Annotations: types.Annotations{"ingress": ""}}
+		g.Expect(r.CheckRequirements(unsatisfied, types.ClusterConfig{Annotations: types.Annotations{"ingress": "", "dns": ""}})).To(Succeed())
+	})
+
+	t.Run("Register/MissingRequirement", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(r.Register(enabled("gateway", "unknown"))).ToNot(Succeed())
+	})
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(features.DefaultRegistry.Names()).To(ConsistOf(
+		features.Network,
+		features.DNS,
+		features.Gateway,
+		features.Ingress,
+		features.LoadBalancer,
+		features.LocalStorage,
+		features.MetricsServer,
+		features.HelmAddons,
+	))
+}
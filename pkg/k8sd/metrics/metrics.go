@@ -0,0 +1,47 @@
+// Package metrics defines the Prometheus metrics exported by k8sd.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// Registry is the registry of the k8sd metrics. It is served on the metrics address of k8sd.
+	Registry = prometheus.NewRegistry()
+
+	// CertificateNotBefore is the start of the validity of the certificates and kubeconfigs that k8sd manages.
+	CertificateNotBefore = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "k8sd",
+		Subsystem: "certificate",
+		Name:      "not_before_timestamp_seconds",
+		Help:      "Start of the validity of the certificate, as a Unix timestamp.",
+	}, []string{"name", "type"})
+
+	// CertificateNotAfter is the expiry of the certificates and kubeconfigs that k8sd manages.
+	CertificateNotAfter = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "k8sd",
+		Subsystem: "certificate",
+		Name:      "not_after_timestamp_seconds",
+		Help:      "Expiry of the certificate, as a Unix timestamp.",
+	}, []string{"name", "type"})
+
+	// EtcdSnapshotLastSuccess is the time of the last etcd snapshot taken on the node.
+	EtcdSnapshotLastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "k8sd",
+		Subsystem: "etcd_snapshot",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Time of the last scheduled etcd snapshot taken on the node, as a Unix timestamp.",
+	})
+
+	// EtcdSnapshotFailures is the number of scheduled etcd snapshots that failed on the node.
+	EtcdSnapshotFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "k8sd",
+		Subsystem: "etcd_snapshot",
+		Name:      "failures_total",
+		Help:      "Number of scheduled etcd snapshots that failed on the node.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(CertificateNotBefore, CertificateNotAfter, EtcdSnapshotLastSuccess, EtcdSnapshotFailures)
+}
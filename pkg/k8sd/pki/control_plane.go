@@ -2,6 +2,7 @@ package pki
 
 import (
 	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"net"
 	"time"
@@ -11,13 +12,15 @@ import (
 
 // ControlPlanePKI is a list of all certificates we require for a control plane node.
 type ControlPlanePKI struct {
-	allowSelfSignedCA         bool      // create self-signed CA certificates if missing
-	includeMachineAddressSANs bool      // include any machine IP addresses as SANs for generated certificates
-	hostname                  string    // node name
-	ipSANs                    []net.IP  // IP SANs for generated certificates
-	dnsSANs                   []string  // DNS SANs for the certificates below
-	notBefore                 time.Time // not before date for the certificates
-	notAfter                  time.Time // not after (expiration date) for the certificates
+	allowSelfSignedCA         bool                 // create self-signed CA certificates if missing
+	includeMachineAddressSANs bool                 // include any machine IP addresses as SANs for generated certificates
+	hostname                  string               // node name
+	ipSANs                    []net.IP             // IP SANs for generated certificates
+	dnsSANs                   []string             // DNS SANs for the certificates below
+	notBefore                 time.Time            // not before date for the certificates
+	notAfter                  time.Time            // not after (expiration date) for the certificates
+	keyAlgorithm              pkiutil.KeyAlgorithm // key algorithm for generated certificates
+	profiles                  pkiutil.Profiles     // profiles for generated certificates, by certificate name
 
 	CACert, CAKey                             string // CN=kubernetes-ca (self-signed)
 	ClientCACert, ClientCAKey                 string // CN=kubernetes-ca-client (self-signed)
@@ -61,6 +64,11 @@ type ControlPlanePKIOpts struct {
 	NotAfter                  time.Time
 	AllowSelfSignedCA         bool
 	IncludeMachineAddressSANs bool
+	// KeyAlgorithm is the key algorithm for generated certificates. Defaults to pkiutil.DefaultKeyAlgorithm.
+	KeyAlgorithm pkiutil.KeyAlgorithm
+	// Profiles customize the generated leaf certificates. Profiles are keyed by the certificate names of
+	// "k8s refresh-certs" (e.g. "apiserver", "admin.conf").
+	Profiles pkiutil.Profiles
 }
 
 func NewControlPlanePKI(opts ControlPlanePKIOpts) *ControlPlanePKI {
@@ -77,6 +85,8 @@ func NewControlPlanePKI(opts ControlPlanePKIOpts) *ControlPlanePKI {
 		dnsSANs:                   opts.DNSSANs,
 		allowSelfSignedCA:         opts.AllowSelfSignedCA,
 		includeMachineAddressSANs: opts.IncludeMachineAddressSANs,
+		keyAlgorithm:              opts.KeyAlgorithm,
+		profiles:                  opts.Profiles,
 	}
 }
 
@@ -112,7 +122,7 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 		if !c.allowSelfSignedCA {
 			return fmt.Errorf("kubernetes CA not specified and generating self-signed CA not allowed")
 		}
-		cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, c.notBefore, c.notAfter, 2048)
+		cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, c.notBefore, c.notAfter, c.keyAlgorithm)
 		if err != nil {
 			return fmt.Errorf("failed to generate kubernetes CA: %w", err)
 		}
@@ -125,12 +135,27 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 		}
 	}
 
+	// Use an intermediate kubernetes CA for client certificates too (if the client CA is not set)
+	if c.ClientCACert == "" && c.ClientCAKey == "" && c.CAKey != "" {
+		caCert, _, err := pkiutil.LoadCertificate(c.CACert, "")
+		if err != nil {
+			return fmt.Errorf("failed to parse kubernetes CA: %w", err)
+		}
+		if !pkiutil.IsSelfSigned(caCert) {
+			// NOTE: Only the intermediate CA is trusted for client certificates. The kubernetes CA bundle may also
+			// contain the issuers of the intermediate CA, which would trust client certificates of any other CA
+			// issued by the external PKI.
+			c.ClientCACert = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}))
+			c.ClientCAKey = c.CAKey
+		}
+	}
+
 	// Generate self-signed client CA (if not set already)
 	if c.ClientCACert == "" && c.ClientCAKey == "" {
 		if !c.allowSelfSignedCA {
 			return fmt.Errorf("kubernetes client CA not specified and generating self-signed CA not allowed")
 		}
-		cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca-client"}, c.notBefore, c.notAfter, 2048)
+		cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca-client"}, c.notBefore, c.notAfter, c.keyAlgorithm)
 		if err != nil {
 			return fmt.Errorf("failed to generate kubernetes client CA: %w", err)
 		}
@@ -153,12 +178,22 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 		return fmt.Errorf("failed to parse kubernetes CA: %w", err)
 	}
 
+	// Certificates signed by intermediate CAs include the issuer chain
+	serverChain, err := pkiutil.IssuerChain(c.CACert)
+	if err != nil {
+		return fmt.Errorf("failed to get kubernetes CA chain: %w", err)
+	}
+	clientChain, err := pkiutil.IssuerChain(c.ClientCACert)
+	if err != nil {
+		return fmt.Errorf("failed to get kubernetes client CA chain: %w", err)
+	}
+
 	// Generate self-signed CA for front-proxy (if not set already)
 	if c.FrontProxyCACert == "" && c.FrontProxyCAKey == "" {
 		if !c.allowSelfSignedCA {
 			return fmt.Errorf("front-proxy CA not specified and generating self-signed CA not allowed")
 		}
-		cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "front-proxy-ca"}, c.notBefore, c.notAfter, 2048)
+		cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "front-proxy-ca"}, c.notBefore, c.notAfter, c.keyAlgorithm)
 		if err != nil {
 			return fmt.Errorf("failed to generate front-proxy CA: %w", err)
 		}
@@ -185,12 +220,17 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 		if err != nil {
 			return fmt.Errorf("failed to generate front-proxy-client certificate: %w", err)
 		}
-		cert, key, err := pkiutil.SignCertificate(template, 2048, frontProxyCACert, &frontProxyCAKey.PublicKey, frontProxyCAKey)
+		c.profiles.Get("front-proxy-client").Apply(template)
+		cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, frontProxyCACert, frontProxyCAKey.Public(), frontProxyCAKey)
 		if err != nil {
 			return fmt.Errorf("failed to sign front-proxy-client certificate: %w", err)
 		}
+		frontProxyChain, err := pkiutil.IssuerChain(c.FrontProxyCACert)
+		if err != nil {
+			return fmt.Errorf("failed to get front-proxy CA chain: %w", err)
+		}
 
-		c.FrontProxyClientCert = cert
+		c.FrontProxyClientCert = cert + frontProxyChain
 		c.FrontProxyClientKey = key
 	} else {
 		certCheck := pkiutil.CertCheck{CaPEM: c.FrontProxyCACert}
@@ -226,12 +266,13 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 		if err != nil {
 			return fmt.Errorf("failed to generate kubelet certificate: %w", err)
 		}
-		cert, key, err := pkiutil.SignCertificate(template, 2048, serverCACert, &serverCAKey.PublicKey, serverCAKey)
+		c.profiles.Get("kubelet").Apply(template)
+		cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, serverCACert, serverCAKey.Public(), serverCAKey)
 		if err != nil {
 			return fmt.Errorf("failed to sign kubelet certificate: %w", err)
 		}
 
-		c.KubeletCert = cert
+		c.KubeletCert = cert + serverChain
 		c.KubeletKey = key
 	} else {
 		certCheck := pkiutil.CertCheck{
@@ -253,12 +294,13 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 		if err != nil {
 			return fmt.Errorf("failed to generate apiserver-kubelet-client certificate: %w", err)
 		}
-		cert, key, err := pkiutil.SignCertificate(template, 2048, clientCACert, &clientCAKey.PublicKey, clientCAKey)
+		c.profiles.Get("apiserver-kubelet-client").Apply(template)
+		cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, clientCACert, clientCAKey.Public(), clientCAKey)
 		if err != nil {
 			return fmt.Errorf("failed to sign apiserver-kubelet-client certificate: %w", err)
 		}
 
-		c.APIServerKubeletClientCert = cert
+		c.APIServerKubeletClientCert = cert + clientChain
 		c.APIServerKubeletClientKey = key
 	} else {
 		certCheck := pkiutil.CertCheck{
@@ -284,12 +326,13 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 		if err != nil {
 			return fmt.Errorf("failed to generate apiserver certificate: %w", err)
 		}
-		cert, key, err := pkiutil.SignCertificate(template, 2048, serverCACert, &serverCAKey.PublicKey, serverCAKey)
+		c.profiles.Get("apiserver").Apply(template)
+		cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, serverCACert, serverCAKey.Public(), serverCAKey)
 		if err != nil {
 			return fmt.Errorf("failed to sign apiserver certificate: %w", err)
 		}
 
-		c.APIServerCert = cert
+		c.APIServerCert = cert + serverChain
 		c.APIServerKey = key
 	} else {
 		certCheck := pkiutil.CertCheck{
@@ -302,17 +345,18 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 	}
 
 	for _, i := range []struct {
-		name string
-		cn   string
-		o    []string
-		cert *string
-		key  *string
+		name    string
+		profile string
+		cn      string
+		o       []string
+		cert    *string
+		key     *string
 	}{
-		{name: "admin", cn: "kubernetes-admin", o: []string{"system:masters"}, cert: &c.AdminClientCert, key: &c.AdminClientKey},
-		{name: "controller", cn: "system:kube-controller-manager", cert: &c.KubeControllerManagerClientCert, key: &c.KubeControllerManagerClientKey},
-		{name: "proxy", cn: "system:kube-proxy", cert: &c.KubeProxyClientCert, key: &c.KubeProxyClientKey},
-		{name: "scheduler", cn: "system:kube-scheduler", cert: &c.KubeSchedulerClientCert, key: &c.KubeSchedulerClientKey},
-		{name: "kubelet", cn: fmt.Sprintf("system:node:%s", c.hostname), o: []string{"system:nodes"}, cert: &c.KubeletClientCert, key: &c.KubeletClientKey},
+		{name: "admin", profile: "admin.conf", cn: "kubernetes-admin", o: []string{"system:masters"}, cert: &c.AdminClientCert, key: &c.AdminClientKey},
+		{name: "controller", profile: "controller.conf", cn: "system:kube-controller-manager", cert: &c.KubeControllerManagerClientCert, key: &c.KubeControllerManagerClientKey},
+		{name: "proxy", profile: "proxy.conf", cn: "system:kube-proxy", cert: &c.KubeProxyClientCert, key: &c.KubeProxyClientKey},
+		{name: "scheduler", profile: "scheduler.conf", cn: "system:kube-scheduler", cert: &c.KubeSchedulerClientCert, key: &c.KubeSchedulerClientKey},
+		{name: "kubelet", profile: "kubelet.conf", cn: fmt.Sprintf("system:node:%s", c.hostname), o: []string{"system:nodes"}, cert: &c.KubeletClientCert, key: &c.KubeletClientKey},
 	} {
 		if *i.cert == "" || *i.key == "" {
 			if clientCAKey == nil {
@@ -323,13 +367,14 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 			if err != nil {
 				return fmt.Errorf("failed to generate %s client certificate: %w", i.name, err)
 			}
+			c.profiles.Get(i.profile).Apply(template)
 
-			cert, key, err := pkiutil.SignCertificate(template, 2048, clientCACert, &clientCAKey.PublicKey, clientCAKey)
+			cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, clientCACert, clientCAKey.Public(), clientCAKey)
 			if err != nil {
 				return fmt.Errorf("failed to sign %s client certificate: %w", i.name, err)
 			}
 
-			*i.cert = cert
+			*i.cert = cert + clientChain
 			*i.key = key
 		}
 	}
@@ -340,6 +385,8 @@ func (c *ControlPlanePKI) CompleteCertificates() error {
 			return fmt.Errorf("cluster keypair not specified and generating new key is not allowed")
 		}
 
+		// NOTE: The cluster keypair is always RSA, as worker nodes use the public key to encrypt the
+		// checksum of their certificate signing requests.
 		priv, pub, err := pkiutil.GenerateRSAKey(2048)
 		if err != nil {
 			return fmt.Errorf("failed to generate cluster keypair: %w", err)
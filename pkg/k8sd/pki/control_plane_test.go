@@ -64,7 +64,7 @@ func patchCertPEM(
 		return "", "", fmt.Errorf("failed to load CA cert: %w", err)
 	}
 
-	certPem, keyPem, err := pkiutil.SignCertificate(template, 2048, caCert, &caCert.PublicKey, caKey)
+	certPem, keyPem, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmRSA2048, caCert, caKey.Public(), caKey)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to sign cert: %w", err)
 	}
@@ -157,6 +157,123 @@ func TestControlPlaneCertificates(t *testing.T) {
 		})
 	})
 
+	t.Run("Profiles", func(t *testing.T) {
+		c := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
+			Hostname:          "h1",
+			NotBefore:         notBefore,
+			NotAfter:          notBefore.AddDate(20, 0, 0),
+			AllowSelfSignedCA: true,
+			Profiles: pkiutil.Profiles{
+				"default": {Lifetime: 90 * 24 * time.Hour},
+				"apiserver": {
+					Lifetime:    30 * 24 * time.Hour,
+					DNSSANs:     []string{"api.example.com"},
+					ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+					Country:     []string{"GB"},
+				},
+			},
+		})
+
+		g := NewWithT(t)
+		g.Expect(c.CompleteCertificates()).To(Succeed())
+
+		apiserver, _, err := pkiutil.LoadCertificate(c.APIServerCert, "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(apiserver.NotAfter).To(BeTemporally("~", notBefore.Add(30*24*time.Hour), time.Second))
+		g.Expect(apiserver.DNSNames).To(ContainElements("kubernetes", "api.example.com"))
+		g.Expect(apiserver.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}))
+		g.Expect(apiserver.Subject.Country).To(Equal([]string{"GB"}))
+
+		admin, _, err := pkiutil.LoadCertificate(c.AdminClientCert, "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(admin.NotAfter).To(BeTemporally("~", notBefore.Add(90*24*time.Hour), time.Second))
+		g.Expect(admin.Subject.Organization).To(Equal([]string{"system:masters"}))
+
+		// profiles do not apply to certificate authorities
+		ca, _, err := pkiutil.LoadCertificate(c.CACert, "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ca.NotAfter).To(BeTemporally("~", notBefore.AddDate(20, 0, 0), time.Second))
+	})
+
+	t.Run("IntermediateCA", func(t *testing.T) {
+		g := NewWithT(t)
+
+		root, rootKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "corporate-root"}, notBefore, notBefore.AddDate(20, 0, 0), pkiutil.KeyAlgorithmRSA2048)
+		g.Expect(err).ToNot(HaveOccurred())
+		rootCert, rootSigner, err := pkiutil.LoadCertificate(root, rootKey)
+		g.Expect(err).ToNot(HaveOccurred())
+		template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: "kubernetes-intermediate"}, notBefore, notBefore.AddDate(5, 0, 0), true, nil, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		intermediate, intermediateKey, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmRSA2048, rootCert, rootSigner.Public(), rootSigner)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		c := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
+			Hostname:          "h1",
+			NotBefore:         notBefore,
+			NotAfter:          notBefore.AddDate(20, 0, 0),
+			AllowSelfSignedCA: true,
+		})
+		c.CACert = intermediate + root
+		c.CAKey = intermediateKey
+		g.Expect(c.CompleteCertificates()).To(Succeed())
+
+		// the intermediate CA also signs the client certificates, but only the intermediate CA is trusted for them
+		g.Expect(c.ClientCACert).To(Equal(intermediate))
+		g.Expect(c.ClientCAKey).To(Equal(c.CAKey))
+
+		// leaf certificates include the intermediate CA and verify against the root CA only
+		roots := x509.NewCertPool()
+		roots.AddCert(rootCert)
+		for _, certPEM := range []string{c.APIServerCert, c.KubeletCert, c.AdminClientCert, c.APIServerKubeletClientCert} {
+			certs, err := pkiutil.ParseCertificates(certPEM)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(certs).To(HaveLen(2))
+
+			intermediates := x509.NewCertPool()
+			intermediates.AddCert(certs[1])
+			_, err = certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+			g.Expect(err).ToNot(HaveOccurred())
+
+			// leaf certificates do not outlive the intermediate CA
+			g.Expect(certs[0].NotAfter).To(Equal(certs[1].NotAfter))
+		}
+
+		// existing certificates signed by the intermediate CA are valid
+		g.Expect(c.CompleteCertificates()).To(Succeed())
+
+		// client certificates of a sibling CA issued by the same root CA are not trusted
+		clientCAs, err := pkiutil.ParseCertificates(c.ClientCACert)
+		g.Expect(err).ToNot(HaveOccurred())
+		clientRoots := x509.NewCertPool()
+		for _, ca := range clientCAs {
+			clientRoots.AddCert(ca)
+		}
+		verifyClient := func(certPEM string) error {
+			certs, err := pkiutil.ParseCertificates(certPEM)
+			g.Expect(err).ToNot(HaveOccurred())
+			intermediates := x509.NewCertPool()
+			for _, cert := range certs[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err = certs[0].Verify(x509.VerifyOptions{Roots: clientRoots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+			return err
+		}
+		g.Expect(verifyClient(c.AdminClientCert)).To(Succeed())
+
+		template, err = pkiutil.GenerateCertificate(pkix.Name{CommonName: "sibling-intermediate"}, notBefore, notBefore.AddDate(5, 0, 0), true, nil, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		sibling, siblingKey, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmRSA2048, rootCert, rootSigner.Public(), rootSigner)
+		g.Expect(err).ToNot(HaveOccurred())
+		siblingCert, siblingSigner, err := pkiutil.LoadCertificate(sibling, siblingKey)
+		g.Expect(err).ToNot(HaveOccurred())
+		template, err = pkiutil.GenerateCertificate(pkix.Name{CommonName: "kubernetes-admin", Organization: []string{"system:masters"}}, notBefore, notBefore.AddDate(1, 0, 0), false, nil, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		siblingClient, _, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmRSA2048, siblingCert, siblingSigner.Public(), siblingSigner)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(verifyClient(siblingClient + sibling + root)).ToNot(Succeed())
+	})
+
 	t.Run("KubeletCertSANs", func(t *testing.T) {
 		c := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
 			Hostname:          "h1",
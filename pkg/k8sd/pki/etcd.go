@@ -11,12 +11,14 @@ import (
 
 // EtcdPKI is a list of certificates required by the etcd datastore.
 type EtcdPKI struct {
-	allowSelfSignedCA bool      // create self-signed CA certificates if missing
-	hostname          string    // node name
-	ipSANs            []net.IP  // IP SANs for generated certificates
-	dnsSANs           []string  // DNS SANs for the certificates below
-	notBefore         time.Time // notBefore date for the generated certificates
-	notAfter          time.Time // not after date (expiration date) for the generated certificates
+	allowSelfSignedCA bool                 // create self-signed CA certificates if missing
+	hostname          string               // node name
+	ipSANs            []net.IP             // IP SANs for generated certificates
+	dnsSANs           []string             // DNS SANs for the certificates below
+	notBefore         time.Time            // notBefore date for the generated certificates
+	notAfter          time.Time            // not after date (expiration date) for the generated certificates
+	keyAlgorithm      pkiutil.KeyAlgorithm // key algorithm for generated certificates
+	profiles          pkiutil.Profiles     // profiles for generated certificates, by certificate name
 
 	// CN=etcd, DNS=hostname, IP=127.0.0.1 (self-signed)
 	CACert, CAKey string
@@ -38,6 +40,11 @@ type EtcdPKIOpts struct {
 	NotBefore         time.Time
 	NotAfter          time.Time
 	AllowSelfSignedCA bool
+	// KeyAlgorithm is the key algorithm for generated certificates. Defaults to pkiutil.DefaultKeyAlgorithm.
+	KeyAlgorithm pkiutil.KeyAlgorithm
+	// Profiles customize the generated leaf certificates. Profiles are keyed by the certificate names
+	// "etcd-server", "etcd-peer" and "apiserver-etcd-client". Profiles do not apply to the CA.
+	Profiles pkiutil.Profiles
 }
 
 func NewEtcdPKI(opts EtcdPKIOpts) *EtcdPKI {
@@ -53,6 +60,8 @@ func NewEtcdPKI(opts EtcdPKIOpts) *EtcdPKI {
 		notBefore:         opts.NotBefore,
 		ipSANs:            opts.IPSANs,
 		dnsSANs:           opts.DNSSANs,
+		keyAlgorithm:      opts.KeyAlgorithm,
+		profiles:          opts.Profiles,
 	}
 }
 
@@ -68,7 +77,7 @@ func (c *EtcdPKI) CompleteCertificates() error {
 		if !c.allowSelfSignedCA {
 			return fmt.Errorf("etcd CA not specified and generating self-signed CA not allowed")
 		}
-		cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "etcd-ca"}, c.notBefore, c.notAfter, 2048)
+		cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "etcd-ca"}, c.notBefore, c.notAfter, c.keyAlgorithm)
 		if err != nil {
 			return fmt.Errorf("failed to generate etcd CA: %w", err)
 		}
@@ -95,7 +104,8 @@ func (c *EtcdPKI) CompleteCertificates() error {
 		if err != nil {
 			return fmt.Errorf("failed to generate etcd certificate: %w", err)
 		}
-		cert, key, err := pkiutil.SignCertificate(template, 2048, cert, &key.PublicKey, key)
+		c.profiles.Get("etcd-server").Apply(template)
+		cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, cert, key.Public(), key)
 		if err != nil {
 			return fmt.Errorf("failed to self-sign etcd certificate: %w", err)
 		}
@@ -114,7 +124,8 @@ func (c *EtcdPKI) CompleteCertificates() error {
 		if err != nil {
 			return fmt.Errorf("failed to generate etcd certificate: %w", err)
 		}
-		cert, key, err := pkiutil.SignCertificate(template, 2048, cert, &key.PublicKey, key)
+		c.profiles.Get("etcd-peer").Apply(template)
+		cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, cert, key.Public(), key)
 		if err != nil {
 			return fmt.Errorf("failed to self-sign etcd certificate: %w", err)
 		}
@@ -133,7 +144,8 @@ func (c *EtcdPKI) CompleteCertificates() error {
 		if err != nil {
 			return fmt.Errorf("failed to generate etcd certificate: %w", err)
 		}
-		cert, key, err := pkiutil.SignCertificate(template, 2048, cert, &key.PublicKey, key)
+		c.profiles.Get("apiserver-etcd-client").Apply(template)
+		cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, cert, key.Public(), key)
 		if err != nil {
 			return fmt.Errorf("failed to self-sign etcd certificate: %w", err)
 		}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
 	. "github.com/onsi/gomega"
 )
 
@@ -149,3 +150,42 @@ func TestNewEtcdPKI(t *testing.T) {
 		})
 	}
 }
+
+func TestEtcdPKI_Profiles(t *testing.T) {
+	g := NewWithT(t)
+
+	notBefore := time.Now()
+	c := NewEtcdPKI(EtcdPKIOpts{
+		Hostname:          "h1",
+		NotBefore:         notBefore,
+		NotAfter:          notBefore.AddDate(20, 0, 0),
+		AllowSelfSignedCA: true,
+		Profiles: pkiutil.Profiles{
+			"default": {Lifetime: 90 * 24 * time.Hour},
+			"etcd-server": {
+				Lifetime: 30 * 24 * time.Hour,
+				DNSSANs:  []string{"etcd.example.com"},
+				Country:  []string{"GB"},
+			},
+		},
+	})
+	g.Expect(c.CompleteCertificates()).To(Succeed())
+
+	server, _, err := pkiutil.LoadCertificate(c.ServerCert, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(server.NotAfter).To(BeTemporally("~", notBefore.Add(30*24*time.Hour), time.Second))
+	g.Expect(server.DNSNames).To(ContainElements("h1", "etcd.example.com"))
+	g.Expect(server.Subject.Country).To(Equal([]string{"GB"}))
+
+	for _, cert := range []string{c.ServerPeerCert, c.APIServerClientCert} {
+		cert, _, err := pkiutil.LoadCertificate(cert, "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(cert.NotAfter).To(BeTemporally("~", notBefore.Add(90*24*time.Hour), time.Second))
+		g.Expect(cert.DNSNames).ToNot(ContainElement("etcd.example.com"))
+	}
+
+	// profiles do not apply to the certificate authority
+	ca, _, err := pkiutil.LoadCertificate(c.CACert, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ca.NotAfter).To(BeTemporally("~", notBefore.AddDate(20, 0, 0), time.Second))
+}
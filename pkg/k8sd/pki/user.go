@@ -0,0 +1,40 @@
+package pki
+
+import (
+	"crypto/x509/pkix"
+	"fmt"
+
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+)
+
+// GenerateUserClientCertificate generates a client certificate for a Kubernetes user, signed by the client CA.
+// The certificate is valid between the NotBefore and NotAfter dates of the control plane PKI.
+// Certificate profiles do not apply to user certificates.
+func (c *ControlPlanePKI) GenerateUserClientCertificate(user string, groups []string) (string, string, error) {
+	if user == "" {
+		return "", "", fmt.Errorf("user cannot be empty")
+	}
+
+	clientCACert, clientCAKey, err := pkiutil.LoadCertificate(c.ClientCACert, c.ClientCAKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load kubernetes client CA: %w", err)
+	}
+	if clientCAKey == nil {
+		return "", "", fmt.Errorf("using an external kubernetes CA client without providing the user certificate is not possible")
+	}
+	clientChain, err := pkiutil.IssuerChain(c.ClientCACert)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get kubernetes client CA chain: %w", err)
+	}
+
+	template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: user, Organization: groups}, c.notBefore, c.notAfter, false, nil, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate client certificate for user %s: %w", user, err)
+	}
+	cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, clientCACert, clientCAKey.Public(), clientCAKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign client certificate for user %s: %w", user, err)
+	}
+
+	return cert + clientChain, key, nil
+}
@@ -0,0 +1,55 @@
+package pki_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/k8sd/pki"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+)
+
+func TestControlPlanePKI_GenerateUserClientCertificate(t *testing.T) {
+	g := NewWithT(t)
+	notBefore := time.Now().Truncate(time.Second)
+	clientCACert, clientCAKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca-client"}, notBefore, notBefore.AddDate(1, 0, 0), pkiutil.KeyAlgorithmRSA2048)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{
+		NotBefore: notBefore,
+		NotAfter:  notBefore.Add(time.Hour),
+	})
+	c.ClientCACert = clientCACert
+	c.ClientCAKey = clientCAKey
+
+	certPEM, keyPEM, err := c.GenerateUserClientCertificate("alice", []string{"developers"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(keyPEM).ToNot(BeEmpty())
+
+	cert, _, err := pkiutil.LoadCertificate(certPEM, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cert.Subject.CommonName).To(Equal("alice"))
+	g.Expect(cert.Subject.Organization).To(Equal([]string{"developers"}))
+	g.Expect(cert.NotAfter).To(Equal(notBefore.Add(time.Hour).UTC()))
+	g.Expect(cert.ExtKeyUsage).To(ContainElement(x509.ExtKeyUsageClientAuth))
+
+	caCert, _, err := pkiutil.LoadCertificate(clientCACert, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cert.CheckSignatureFrom(caCert)).To(Succeed())
+
+	t.Run("NoUser", func(t *testing.T) {
+		g := NewWithT(t)
+		_, _, err := c.GenerateUserClientCertificate("", nil)
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("NoClientCAKey", func(t *testing.T) {
+		g := NewWithT(t)
+		c := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{NotBefore: notBefore})
+		c.ClientCACert = clientCACert
+		_, _, err := c.GenerateUserClientCertificate("alice", nil)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -23,8 +23,9 @@ type WorkerNodePKI struct {
 	KubeletClientCert, KubeletClientKey string
 }
 
-// CompleteWorkerNodePKI generates the PKI needed for a worker node.
-func (c *ControlPlanePKI) CompleteWorkerNodePKI(hostname string, nodeIP net.IP, bits int) (*WorkerNodePKI, error) {
+// CompleteWorkerNodePKI generates the PKI needed for a worker node, using the key algorithm and certificate profiles
+// of the control plane PKI.
+func (c *ControlPlanePKI) CompleteWorkerNodePKI(hostname string, nodeIP net.IP) (*WorkerNodePKI, error) {
 	serverCACert, serverCAKey, err := pkiutil.LoadCertificate(c.CACert, c.CAKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubernetes CA: %w", err)
@@ -37,6 +38,16 @@ func (c *ControlPlanePKI) CompleteWorkerNodePKI(hostname string, nodeIP net.IP,
 
 	pki := &WorkerNodePKI{CACert: c.CACert, ClientCACert: c.ClientCACert}
 
+	// Certificates signed by intermediate CAs include the issuer chain
+	serverChain, err := pkiutil.IssuerChain(c.CACert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes CA chain: %w", err)
+	}
+	clientChain, err := pkiutil.IssuerChain(c.ClientCACert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes client CA chain: %w", err)
+	}
+
 	// we have a cluster CA key, sign the kubelet server certificate
 	if serverCAKey != nil {
 		template, err := pkiutil.GenerateCertificate(
@@ -53,38 +64,41 @@ func (c *ControlPlanePKI) CompleteWorkerNodePKI(hostname string, nodeIP net.IP,
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate kubelet certificate for hostname=%s address=%s: %w", hostname, nodeIP.String(), err)
 		}
-		cert, key, err := pkiutil.SignCertificate(template, bits, serverCACert, &serverCAKey.PublicKey, serverCAKey)
+		c.profiles.Get("kubelet").Apply(template)
+		cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, serverCACert, serverCAKey.Public(), serverCAKey)
 		if err != nil {
 			return nil, fmt.Errorf("failed to sign kubelet certificate for hostname=%s address=%s: %w", hostname, nodeIP.String(), err)
 		}
-		pki.KubeletCert = cert
+		pki.KubeletCert = cert + serverChain
 		pki.KubeletKey = key
 	}
 
 	// we have a client CA key, sign the kubelet and kube-proxy client certificates
 	if clientCAKey != nil {
 		for _, i := range []struct {
-			name string
-			cn   string
-			o    []string
-			cert *string
-			key  *string
+			name    string
+			profile string
+			cn      string
+			o       []string
+			cert    *string
+			key     *string
 		}{
-			{name: "proxy", cn: "system:kube-proxy", cert: &pki.KubeProxyClientCert, key: &pki.KubeProxyClientKey},
-			{name: "kubelet", cn: fmt.Sprintf("system:node:%s", hostname), o: []string{"system:nodes"}, cert: &pki.KubeletClientCert, key: &pki.KubeletClientKey},
+			{name: "proxy", profile: "proxy.conf", cn: "system:kube-proxy", cert: &pki.KubeProxyClientCert, key: &pki.KubeProxyClientKey},
+			{name: "kubelet", profile: "kubelet.conf", cn: fmt.Sprintf("system:node:%s", hostname), o: []string{"system:nodes"}, cert: &pki.KubeletClientCert, key: &pki.KubeletClientKey},
 		} {
 			if *i.cert == "" || *i.key == "" {
 				template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: i.cn, Organization: i.o}, c.notBefore, c.notAfter, false, nil, nil)
 				if err != nil {
 					return nil, fmt.Errorf("failed to generate %s client certificate: %w", i.name, err)
 				}
+				c.profiles.Get(i.profile).Apply(template)
 
-				cert, key, err := pkiutil.SignCertificate(template, 2048, clientCACert, &clientCAKey.PublicKey, clientCAKey)
+				cert, key, err := pkiutil.SignCertificate(template, c.keyAlgorithm, clientCACert, clientCAKey.Public(), clientCAKey)
 				if err != nil {
 					return nil, fmt.Errorf("failed to sign %s client certificate: %w", i.name, err)
 				}
 
-				*i.cert = cert
+				*i.cert = cert + clientChain
 				*i.key = key
 			}
 		}
@@ -15,9 +15,9 @@ import (
 func TestControlPlanePKI_CompleteWorkerNodePKI(t *testing.T) {
 	g := NewWithT(t)
 	notBefore := time.Now()
-	serverCACert, serverCAKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, notBefore, notBefore.AddDate(1, 0, 0), 2048)
+	serverCACert, serverCAKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca"}, notBefore, notBefore.AddDate(1, 0, 0), pkiutil.KeyAlgorithmRSA2048)
 	g.Expect(err).ToNot(HaveOccurred())
-	clientCACert, clientCAKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca-client"}, notBefore, notBefore.AddDate(1, 0, 0), 2048)
+	clientCACert, clientCAKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca-client"}, notBefore, notBefore.AddDate(1, 0, 0), pkiutil.KeyAlgorithmRSA2048)
 	g.Expect(err).ToNot(HaveOccurred())
 
 	for _, tc := range []struct {
@@ -106,7 +106,7 @@ func TestControlPlanePKI_CompleteWorkerNodePKI(t *testing.T) {
 			cp := pki.NewControlPlanePKI(pki.ControlPlanePKIOpts{NotBefore: notBefore, NotAfter: notBefore.AddDate(1, 0, 0)})
 			tc.withCerts(cp)
 
-			pki, err := cp.CompleteWorkerNodePKI("worker", net.IP{10, 0, 0, 1}, 2048)
+			pki, err := cp.CompleteWorkerNodePKI("worker", net.IP{10, 0, 0, 1})
 			if tc.expectErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -117,6 +117,30 @@ func EnsureWorkerPKI(snap snap.Snap, certificates *pki.WorkerNodePKI) (bool, err
 	})
 }
 
+// EnsureControlPlaneCAs ensures the certificate authority files of a control plane node are present
+// and have the correct content, permissions and ownership. Other certificates are not changed.
+// It returns true if one or more files were updated and any error that occurred.
+func EnsureControlPlaneCAs(snap snap.Snap, certificates *pki.ControlPlanePKI) (bool, error) {
+	return ensureFiles(snap.UID(), snap.GID(), 0o600, map[string]string{
+		filepath.Join(snap.KubernetesPKIDir(), "ca.crt"):             certificates.CACert,
+		filepath.Join(snap.KubernetesPKIDir(), "ca.key"):             certificates.CAKey,
+		filepath.Join(snap.KubernetesPKIDir(), "client-ca.crt"):      certificates.ClientCACert,
+		filepath.Join(snap.KubernetesPKIDir(), "client-ca.key"):      certificates.ClientCAKey,
+		filepath.Join(snap.KubernetesPKIDir(), "front-proxy-ca.crt"): certificates.FrontProxyCACert,
+		filepath.Join(snap.KubernetesPKIDir(), "front-proxy-ca.key"): certificates.FrontProxyCAKey,
+	})
+}
+
+// EnsureWorkerCAs ensures the certificate authority files of a worker node are present
+// and have the correct content, permissions and ownership. Other certificates are not changed.
+// It returns true if one or more files were updated and any error that occurred.
+func EnsureWorkerCAs(snap snap.Snap, certificates *pki.WorkerNodePKI) (bool, error) {
+	return ensureFiles(snap.UID(), snap.GID(), 0o600, map[string]string{
+		filepath.Join(snap.KubernetesPKIDir(), "ca.crt"):        certificates.CACert,
+		filepath.Join(snap.KubernetesPKIDir(), "client-ca.crt"): certificates.ClientCACert,
+	})
+}
+
 // ReadControlPlanePKI reads the existing control plane PKI files and kubeconfig files,
 // populating a ControlPlanePKI structure with their contents.
 // The readManaged parameter controls which certificates to read:
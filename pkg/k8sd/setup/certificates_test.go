@@ -101,6 +101,46 @@ func TestEnsureWorkerPKI(t *testing.T) {
 	}
 }
 
+// TestEnsureControlPlaneCAs tests the EnsureControlPlaneCAs function.
+func TestEnsureControlPlaneCAs(t *testing.T) {
+	g := NewWithT(t)
+	tempDir := t.TempDir()
+	mock := &mock.Snap{
+		Mock: mock.Mock{
+			KubernetesPKIDir: tempDir,
+			UID:              os.Getuid(),
+			GID:              os.Getgid(),
+		},
+	}
+	g.Expect(os.WriteFile(filepath.Join(tempDir, "apiserver.crt"), []byte("apiserver_cert"), 0o600)).To(Succeed())
+
+	certificates := &pki.ControlPlanePKI{
+		CACert:           "ca_cert",
+		CAKey:            "ca_key",
+		ClientCACert:     "client_ca_cert",
+		ClientCAKey:      "client_ca_key",
+		FrontProxyCACert: "front_proxy_ca_cert",
+		FrontProxyCAKey:  "front_proxy_ca_key",
+	}
+
+	changed, err := setup.EnsureControlPlaneCAs(mock, certificates)
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(changed).To(BeTrue())
+
+	b, err := os.ReadFile(filepath.Join(tempDir, "ca.crt"))
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(string(b)).To(Equal("ca_cert"))
+
+	// other certificates are not touched
+	b, err = os.ReadFile(filepath.Join(tempDir, "apiserver.crt"))
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(string(b)).To(Equal("apiserver_cert"))
+
+	changed, err = setup.EnsureControlPlaneCAs(mock, certificates)
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(changed).To(BeFalse())
+}
+
 func TestExtDatastorePKI(t *testing.T) {
 	g := NewWithT(t)
 	tempDir := t.TempDir()
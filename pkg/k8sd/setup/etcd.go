@@ -82,3 +82,29 @@ func Etcd(snap snap.Snap, name string, nodeIP net.IP, clientPort, peerPort int,
 	}
 	return nil
 }
+
+// EtcdNewClusterFromLocalMember rewrites the etcd arguments so that the local member forms a new single-member
+// cluster, e.g. when recovering from a lost quorum. Only --initial-cluster and --initial-cluster-state are changed,
+// all other arguments of the member (e.g. its data directory) are kept.
+// EtcdNewClusterFromLocalMember returns the name and the client URL of the local member.
+func EtcdNewClusterFromLocalMember(snap snap.Snap) (string, string, error) {
+	args := map[string]string{}
+	for _, arg := range []string{"--name", "--initial-advertise-peer-urls", "--advertise-client-urls"} {
+		value, err := snaputil.GetServiceArgument(snap, "etcd", arg)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get etcd argument %s: %w", arg, err)
+		}
+		if value == "" {
+			return "", "", fmt.Errorf("etcd argument %s is not set", arg)
+		}
+		args[arg] = value
+	}
+
+	if _, err := snaputil.UpdateServiceArguments(snap, "etcd", map[string]string{
+		"--initial-cluster":       fmt.Sprintf("%s=%s", args["--name"], args["--initial-advertise-peer-urls"]),
+		"--initial-cluster-state": "new",
+	}, nil); err != nil {
+		return "", "", fmt.Errorf("failed to write arguments file: %w", err)
+	}
+	return args["--name"], args["--advertise-client-urls"], nil
+}
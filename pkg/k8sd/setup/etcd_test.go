@@ -119,6 +119,40 @@ func TestEtcd(t *testing.T) {
 		g.Expect(args).To(HaveLen(len(tests)))
 	})
 
+	t.Run("NewClusterFromLocalMember", func(t *testing.T) {
+		g := NewWithT(t)
+
+		s := mockEtcdSnap(t)
+		g.Expect(setup.Etcd(s, "t1", net.ParseIP("10.0.0.3"), 12379, 12380, map[string]string{"t2": "https://10.0.0.1:12380"}, map[string]*string{
+			"--quota-backend-bytes": utils.Pointer("8589934592"),
+			"--data-dir":            utils.Pointer("/var/lib/etcd-custom"),
+			"--cert-file":           utils.Pointer("/etc/etcd/custom.crt"),
+		})).To(Succeed())
+
+		name, clientURL, err := setup.EtcdNewClusterFromLocalMember(s)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(name).To(Equal("t1"))
+		g.Expect(clientURL).To(Equal("https://10.0.0.3:12379"))
+
+		args, err := utils.ParseArgumentFile(filepath.Join(s.ServiceArgumentsDir(), "etcd"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(args).To(HaveKeyWithValue("--initial-cluster-state", "new"))
+		g.Expect(args).To(HaveKeyWithValue("--initial-cluster", "t1=https://10.0.0.3:12380"))
+		g.Expect(args).To(HaveKeyWithValue("--listen-peer-urls", "https://10.0.0.3:12380"))
+		g.Expect(args).To(HaveKeyWithValue("--quota-backend-bytes", "8589934592"))
+		g.Expect(args).To(HaveKeyWithValue("--data-dir", "/var/lib/etcd-custom"))
+		g.Expect(args).To(HaveKeyWithValue("--cert-file", "/etc/etcd/custom.crt"))
+		g.Expect(args).To(HaveKeyWithValue("--listen-client-urls", "https://10.0.0.3:12379,https://127.0.0.1:12379"))
+	})
+
+	t.Run("NewClusterFromLocalMemberNotConfigured", func(t *testing.T) {
+		g := NewWithT(t)
+
+		s := mockEtcdSnap(t)
+		_, _, err := setup.EtcdNewClusterFromLocalMember(s)
+		g.Expect(err).To(HaveOccurred())
+	})
+
 	t.Run("MissingArgsDir", func(t *testing.T) {
 		g := NewWithT(t)
 
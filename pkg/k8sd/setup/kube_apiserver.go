@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -22,7 +23,8 @@ type apiserverAuthTokenWebhookTemplateConfig struct {
 var SupportedDatastores = []string{"etcd", "external"}
 
 var (
-	apiserverAuthTokenWebhookTemplate = mustTemplate("apiserver", "auth-token-webhook.conf")
+	apiserverAuthTokenWebhookTemplate     = mustTemplate("apiserver", "auth-token-webhook.conf")
+	apiserverAuthorizationWebhookTemplate = mustTemplate("apiserver", "authorization-webhook.conf")
 
 	apiserverTLSCipherSuites = []string{
 		"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
@@ -35,7 +37,8 @@ var (
 )
 
 // KubeAPIServer configures kube-apiserver on the local node.
-func KubeAPIServer(snap snap.Snap, securePort int, nodeIP net.IP, serviceCIDR string, authWebhookURL string, enableFrontProxy bool, datastore types.Datastore, authorizationMode string, extraArgs map[string]*string) error {
+// The authorization webhook of k8sd is only used if enableRevocationWebhook is set, see KubeAPIServerRevocationWebhookArgs.
+func KubeAPIServer(snap snap.Snap, securePort int, nodeIP net.IP, serviceCIDR string, authWebhookURL string, authzWebhookURL string, enableRevocationWebhook bool, enableFrontProxy bool, datastore types.Datastore, authorizationMode string, extraArgs map[string]*string) error {
 	authTokenWebhookConfigFile := filepath.Join(snap.ServiceExtraConfigDir(), "auth-token-webhook.conf")
 	authTokenWebhookFile, err := os.OpenFile(authTokenWebhookConfigFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
@@ -50,6 +53,20 @@ func KubeAPIServer(snap snap.Snap, securePort int, nodeIP net.IP, serviceCIDR st
 	}
 	defer authTokenWebhookFile.Close()
 
+	authorizationWebhookConfigFile := filepath.Join(snap.ServiceExtraConfigDir(), "authorization-webhook.conf")
+	authorizationWebhookFile, err := os.OpenFile(authorizationWebhookConfigFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open authorization-webhook.conf: %w", err)
+	}
+	defer authorizationWebhookFile.Close()
+
+	if err := apiserverAuthorizationWebhookTemplate.Execute(authorizationWebhookFile, apiserverAuthTokenWebhookTemplateConfig{
+		URL:    authzWebhookURL,
+		CAPath: filepath.Join(snap.K8sdStateDir(), "cluster.crt"),
+	}); err != nil {
+		return fmt.Errorf("failed to write authorization-webhook.conf: %w", err)
+	}
+
 	args := map[string]string{
 		"--anonymous-auth":                           "false",
 		"--allow-privileged":                         "true",
@@ -89,6 +106,13 @@ func KubeAPIServer(snap snap.Snap, securePort int, nodeIP net.IP, serviceCIDR st
 		return fmt.Errorf("failed to get datastore arguments for kube-apiserver: %w", err)
 	}
 
+	if enableRevocationWebhook {
+		webhookArgs, _ := revocationWebhookArgs(snap, true, authorizationMode)
+		for key, val := range webhookArgs {
+			args[key] = val
+		}
+	}
+
 	for key, val := range datastoreUpdateArgs {
 		args[key] = val
 	}
@@ -113,3 +137,53 @@ func KubeAPIServer(snap snap.Snap, securePort int, nodeIP net.IP, serviceCIDR st
 	}
 	return nil
 }
+
+// KubeAPIServerRevocationWebhookArgs returns the kube-apiserver arguments that enable or disable the k8sd authorization
+// webhook, based on the current arguments of kube-apiserver. The webhook denies requests made with certificates that
+// k8sd revoked, and has no opinion otherwise, so that requests are authorized by the next authorizers. Errors of the
+// webhook do not deny requests either, but requests that are not cached are delayed by the retry backoff of
+// kube-apiserver while k8sd is not reachable, which is why the webhook must be enabled explicitly.
+// KubeAPIServerRevocationWebhookArgs returns an error if the webhook is enabled while kube-apiserver uses a different
+// authorization webhook, and leaves the arguments unchanged if it is disabled in that case.
+func KubeAPIServerRevocationWebhookArgs(snap snap.Snap, enabled bool) (map[string]string, []string, error) {
+	configFile, err := snaputil.GetServiceArgument(snap, "kube-apiserver", "--authorization-webhook-config-file")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get kube-apiserver authorization webhook: %w", err)
+	}
+	if configFile != "" && configFile != filepath.Join(snap.ServiceExtraConfigDir(), "authorization-webhook.conf") {
+		if enabled {
+			return nil, nil, fmt.Errorf("kube-apiserver already uses the authorization webhook %s", configFile)
+		}
+		return nil, nil, nil
+	}
+	if configFile == "" && !enabled {
+		return nil, nil, nil
+	}
+
+	authorizationMode, err := snaputil.GetServiceArgument(snap, "kube-apiserver", "--authorization-mode")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get kube-apiserver authorization mode: %w", err)
+	}
+	updateArgs, deleteArgs := revocationWebhookArgs(snap, enabled, authorizationMode)
+	return updateArgs, deleteArgs, nil
+}
+
+// revocationWebhookArgs returns the kube-apiserver arguments that enable or disable the k8sd authorization webhook
+// for the given authorization mode. The webhook is the first authorizer when enabled.
+func revocationWebhookArgs(snap snap.Snap, enabled bool, authorizationMode string) (map[string]string, []string) {
+	modes := slices.DeleteFunc(strings.Split(authorizationMode, ","), func(mode string) bool {
+		return mode == "" || mode == "Webhook"
+	})
+	if !enabled {
+		deleteArgs := []string{"--authorization-webhook-config-file", "--authorization-webhook-version"}
+		if len(modes) == 0 {
+			return nil, append(deleteArgs, "--authorization-mode")
+		}
+		return map[string]string{"--authorization-mode": strings.Join(modes, ",")}, deleteArgs
+	}
+	return map[string]string{
+		"--authorization-mode":                strings.Join(append([]string{"Webhook"}, modes...), ","),
+		"--authorization-webhook-config-file": filepath.Join(snap.ServiceExtraConfigDir(), "authorization-webhook.conf"),
+		"--authorization-webhook-version":     "v1",
+	}, nil
+}
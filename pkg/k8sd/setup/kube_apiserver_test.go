@@ -36,7 +36,7 @@ func TestKubeAPIServer(t *testing.T) {
 		s := mustSetupSnapAndDirectories(t, setKubeAPIServerMock)
 
 		// Call the KubeAPIServer setup function with mock arguments
-		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", true, types.Datastore{Type: utils.Pointer("etcd"), EtcdPort: utils.Pointer(2379)}, "Node,RBAC", nil)).To(Succeed())
+		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", "https://authz-webhook.url", true, true, types.Datastore{Type: utils.Pointer("etcd"), EtcdPort: utils.Pointer(2379)}, "Node,RBAC", nil)).To(Succeed())
 
 		// Ensure the kube-apiserver arguments file has the expected arguments and values
 		tests := []struct {
@@ -47,7 +47,9 @@ func TestKubeAPIServer(t *testing.T) {
 			{key: "--anonymous-auth", expectedVal: "false"},
 			{key: "--allow-privileged", expectedVal: "true"},
 			{key: "--authentication-token-webhook-config-file", expectedVal: filepath.Join(s.Mock.ServiceExtraConfigDir, "auth-token-webhook.conf")},
-			{key: "--authorization-mode", expectedVal: "Node,RBAC"},
+			{key: "--authorization-mode", expectedVal: "Webhook,Node,RBAC"},
+			{key: "--authorization-webhook-config-file", expectedVal: filepath.Join(s.Mock.ServiceExtraConfigDir, "authorization-webhook.conf")},
+			{key: "--authorization-webhook-version", expectedVal: "v1"},
 			{key: "--client-ca-file", expectedVal: filepath.Join(s.Mock.KubernetesPKIDir, "client-ca.crt")},
 			{key: "--enable-admission-plugins", expectedVal: "NodeRestriction"},
 			{key: "--etcd-cafile", expectedVal: filepath.Join(s.Mock.EtcdPKIDir, "ca.crt")},
@@ -99,7 +101,7 @@ func TestKubeAPIServer(t *testing.T) {
 		s := mustSetupSnapAndDirectories(t, setKubeAPIServerMock)
 
 		// Call the KubeAPIServer setup function with mock arguments
-		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", false, types.Datastore{Type: utils.Pointer("etcd"), EtcdPort: utils.Pointer(2379)}, "Node,RBAC", nil)).To(Succeed())
+		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", "https://authz-webhook.url", false, false, types.Datastore{Type: utils.Pointer("etcd"), EtcdPort: utils.Pointer(2379)}, "Node,RBAC", nil)).To(Succeed())
 
 		// Ensure the kube-apiserver arguments file has the expected arguments and values
 		tests := []struct {
@@ -160,7 +162,7 @@ func TestKubeAPIServer(t *testing.T) {
 			"--my-extra-arg":     utils.Pointer("my-extra-val"),
 		}
 		// Call the KubeAPIServer setup function with mock arguments
-		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", true, types.Datastore{Type: utils.Pointer("etcd"), EtcdPort: utils.Pointer(2379)}, "Node,RBAC", extraArgs)).To(Succeed())
+		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", "https://authz-webhook.url", false, true, types.Datastore{Type: utils.Pointer("etcd"), EtcdPort: utils.Pointer(2379)}, "Node,RBAC", extraArgs)).To(Succeed())
 
 		// Ensure the kube-apiserver arguments file has the expected arguments and values
 		tests := []struct {
@@ -225,7 +227,7 @@ func TestKubeAPIServer(t *testing.T) {
 		s := mustSetupSnapAndDirectories(t, setKubeAPIServerMock)
 
 		// Setup without proxy to simplify argument list
-		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24,fd01::/64", "https://auth-webhook.url", false, types.Datastore{Type: utils.Pointer("external"), ExternalServers: utils.Pointer([]string{"datastoreurl1", "datastoreurl2"})}, "Node,RBAC", nil)).To(Succeed())
+		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24,fd01::/64", "https://auth-webhook.url", "https://authz-webhook.url", false, false, types.Datastore{Type: utils.Pointer("external"), ExternalServers: utils.Pointer([]string{"datastoreurl1", "datastoreurl2"})}, "Node,RBAC", nil)).To(Succeed())
 
 		g.Expect(snaputil.GetServiceArgument(s, "kube-apiserver", "--service-cluster-ip-range")).To(Equal("10.0.0.0/24,fd01::/64"))
 		_, err := utils.ParseArgumentFile(filepath.Join(s.Mock.ServiceArgumentsDir, "kube-apiserver"))
@@ -238,7 +240,7 @@ func TestKubeAPIServer(t *testing.T) {
 		s := mustSetupSnapAndDirectories(t, setKubeAPIServerMock)
 
 		// Setup without proxy to simplify argument list
-		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", false, types.Datastore{Type: utils.Pointer("external"), ExternalServers: utils.Pointer([]string{"datastoreurl1", "datastoreurl2"})}, "Node,RBAC", nil)).To(Succeed())
+		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", "https://authz-webhook.url", false, false, types.Datastore{Type: utils.Pointer("external"), ExternalServers: utils.Pointer([]string{"datastoreurl1", "datastoreurl2"})}, "Node,RBAC", nil)).To(Succeed())
 
 		g.Expect(snaputil.GetServiceArgument(s, "kube-apiserver", "--etcd-servers")).To(Equal("datastoreurl1,datastoreurl2"))
 		_, err := utils.ParseArgumentFile(filepath.Join(s.Mock.ServiceArgumentsDir, "kube-apiserver"))
@@ -252,7 +254,7 @@ func TestKubeAPIServer(t *testing.T) {
 		s := mustSetupSnapAndDirectories(t, setKubeAPIServerMock)
 
 		// Attempt to configure kube-apiserver with an unsupported datastore
-		err := setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", false, types.Datastore{Type: utils.Pointer("unsupported")}, "Node,RBAC", nil)
+		err := setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", "https://authz-webhook.url", false, false, types.Datastore{Type: utils.Pointer("unsupported")}, "Node,RBAC", nil)
 		g.Expect(err).To(HaveOccurred())
 		g.Expect(err).To(MatchError(ContainSubstring("unsupported datastore")))
 	})
@@ -264,7 +266,7 @@ func TestKubeAPIServer(t *testing.T) {
 		s := mustSetupSnapAndDirectories(t, setKubeletMock)
 		s.Mock.Hostname = "dev"
 
-		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("2001:db8::"), "fd98::/108", "https://auth-webhook.url", false, types.Datastore{Type: utils.Pointer("etcd")}, "Node,RBAC", nil)).To(Succeed())
+		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("2001:db8::"), "fd98::/108", "https://auth-webhook.url", "https://authz-webhook.url", false, false, types.Datastore{Type: utils.Pointer("etcd")}, "Node,RBAC", nil)).To(Succeed())
 
 		tests := []struct {
 			key         string
@@ -283,3 +285,53 @@ func TestKubeAPIServer(t *testing.T) {
 		}
 	})
 }
+
+func TestKubeAPIServerRevocationWebhookArgs(t *testing.T) {
+	t.Run("Toggle", func(t *testing.T) {
+		g := NewWithT(t)
+
+		s := mustSetupSnapAndDirectories(t, setKubeAPIServerMock)
+		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", "https://authz-webhook.url", false, false, types.Datastore{Type: utils.Pointer("etcd"), EtcdPort: utils.Pointer(2379)}, "Node,RBAC", nil)).To(Succeed())
+
+		updateArgs, deleteArgs, err := setup.KubeAPIServerRevocationWebhookArgs(s, false)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(updateArgs).To(BeEmpty())
+		g.Expect(deleteArgs).To(BeEmpty())
+
+		updateArgs, deleteArgs, err = setup.KubeAPIServerRevocationWebhookArgs(s, true)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(updateArgs).To(Equal(map[string]string{
+			"--authorization-mode":                "Webhook,Node,RBAC",
+			"--authorization-webhook-config-file": filepath.Join(s.Mock.ServiceExtraConfigDir, "authorization-webhook.conf"),
+			"--authorization-webhook-version":     "v1",
+		}))
+		g.Expect(deleteArgs).To(BeEmpty())
+
+		_, err = snaputil.UpdateServiceArguments(s, "kube-apiserver", updateArgs, deleteArgs)
+		g.Expect(err).To(Not(HaveOccurred()))
+
+		updateArgs, deleteArgs, err = setup.KubeAPIServerRevocationWebhookArgs(s, false)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(updateArgs).To(Equal(map[string]string{"--authorization-mode": "Node,RBAC"}))
+		g.Expect(deleteArgs).To(ConsistOf("--authorization-webhook-config-file", "--authorization-webhook-version"))
+	})
+
+	t.Run("OtherWebhook", func(t *testing.T) {
+		g := NewWithT(t)
+
+		s := mustSetupSnapAndDirectories(t, setKubeAPIServerMock)
+		extraArgs := map[string]*string{
+			"--authorization-mode":                utils.Pointer("Webhook,Node,RBAC"),
+			"--authorization-webhook-config-file": utils.Pointer("/my/webhook.conf"),
+		}
+		g.Expect(setup.KubeAPIServer(s, 6443, net.ParseIP("192.168.0.1"), "10.0.0.0/24", "https://auth-webhook.url", "https://authz-webhook.url", false, false, types.Datastore{Type: utils.Pointer("etcd"), EtcdPort: utils.Pointer(2379)}, "Node,RBAC", extraArgs)).To(Succeed())
+
+		updateArgs, deleteArgs, err := setup.KubeAPIServerRevocationWebhookArgs(s, false)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(updateArgs).To(BeEmpty())
+		g.Expect(deleteArgs).To(BeEmpty())
+
+		_, _, err = setup.KubeAPIServerRevocationWebhookArgs(s, true)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
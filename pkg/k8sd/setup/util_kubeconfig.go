@@ -1,7 +1,9 @@
 package setup
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -103,3 +105,36 @@ func SetupWorkerKubeconfigs(kubeConfigDir string, securePort int, pki pki.Worker
 	}
 	return nil
 }
+
+// UpdateKubeconfigsCA updates the certificate authority data of the specified kubeconfig files in kubeConfigDir.
+// Missing kubeconfig files are skipped. The client certificates of the kubeconfig files are not changed.
+// It returns true if one or more files were updated and any error that occurred.
+func UpdateKubeconfigsCA(kubeConfigDir string, files []string, caPEM string) (bool, error) {
+	var changed bool
+	for _, file := range files {
+		path := filepath.Join(kubeConfigDir, file)
+		config, err := clientcmd.LoadFromFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return false, fmt.Errorf("failed to load kubeconfig %s: %w", file, err)
+		}
+
+		var fileChanged bool
+		for _, cluster := range config.Clusters {
+			if !bytes.Equal(cluster.CertificateAuthorityData, []byte(caPEM)) {
+				cluster.CertificateAuthorityData = []byte(caPEM)
+				fileChanged = true
+			}
+		}
+		if !fileChanged {
+			continue
+		}
+		if err := clientcmd.WriteToFile(*config, path); err != nil {
+			return false, fmt.Errorf("failed to write kubeconfig %s: %w", file, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
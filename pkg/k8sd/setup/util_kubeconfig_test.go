@@ -1,10 +1,12 @@
 package setup_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/canonical/k8sd/pkg/k8sd/setup"
 	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 func TestKubeconfigString(t *testing.T) {
@@ -35,3 +37,23 @@ users:
 	g.Expect(actual).To(Equal(expectedConfig))
 	g.Expect(err).To(Not(HaveOccurred()))
 }
+
+func TestUpdateKubeconfigsCA(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+
+	g.Expect(setup.Kubeconfig(filepath.Join(dir, "kubelet.conf"), "server", "old-ca", "crt", "key")).To(Succeed())
+
+	changed, err := setup.UpdateKubeconfigsCA(dir, []string{"kubelet.conf", "missing.conf"}, "new-ca")
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(changed).To(BeTrue())
+
+	config, err := clientcmd.LoadFromFile(filepath.Join(dir, "kubelet.conf"))
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(config.Clusters["k8s"].CertificateAuthorityData).To(Equal([]byte("new-ca")))
+	g.Expect(config.AuthInfos["k8s-user"].ClientCertificateData).To(Equal([]byte("crt")))
+
+	changed, err = setup.UpdateKubeconfigsCA(dir, []string{"kubelet.conf"}, "new-ca")
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(changed).To(BeFalse())
+}
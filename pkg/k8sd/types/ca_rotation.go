@@ -0,0 +1,78 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/utils"
+)
+
+// CARotationPhase is the phase of a cluster certificate authority rotation.
+type CARotationPhase string
+
+const (
+	// CARotationPhaseTrust is the first phase of a CA rotation. The new certificate authorities are trusted
+	// by all nodes and kubeconfigs, but certificates are still signed by the old certificate authorities.
+	CARotationPhaseTrust CARotationPhase = "trust"
+	// CARotationPhaseReissue is the second phase of a CA rotation. Both certificate authorities are still trusted,
+	// but certificates are signed by the new certificate authorities and are re-issued on all nodes.
+	CARotationPhaseReissue CARotationPhase = "reissue"
+)
+
+// CARotation is the state of an in-progress rotation of the kubernetes, kubernetes client and front-proxy
+// certificate authorities.
+type CARotation struct {
+	// Phase is the current phase of the rotation.
+	Phase CARotationPhase `json:"phase"`
+	// StartedAt is the time the rotation was started.
+	StartedAt time.Time `json:"started-at"`
+	// UpdatedAt is the time the rotation entered the current phase.
+	UpdatedAt time.Time `json:"updated-at"`
+	// Old holds the certificate authorities that are being replaced.
+	Old Certificates `json:"old"`
+	// New holds the certificate authorities that replace the old ones.
+	New Certificates `json:"new"`
+}
+
+// InProgress returns true if a CA rotation is in progress.
+func (r CARotation) InProgress() bool { return r.Phase != "" }
+
+// ClusterCertificates returns the certificate authorities of the cluster configuration for the current phase.
+// The certificates are bundles of both the old and the new certificate authorities. The first certificate of each
+// bundle is the signing certificate authority, and matches the respective key.
+func (r CARotation) ClusterCertificates() Certificates {
+	signing, trusted := r.Old, r.New
+	if r.Phase == CARotationPhaseReissue {
+		signing, trusted = r.New, r.Old
+	}
+
+	return Certificates{
+		CACert:           utils.Pointer(CertificateBundle(signing.GetCACert(), trusted.GetCACert())),
+		CAKey:            utils.Pointer(signing.GetCAKey()),
+		ClientCACert:     utils.Pointer(CertificateBundle(signing.GetClientCACert(), trusted.GetClientCACert())),
+		ClientCAKey:      utils.Pointer(signing.GetClientCAKey()),
+		FrontProxyCACert: utils.Pointer(CertificateBundle(signing.GetFrontProxyCACert(), trusted.GetFrontProxyCACert())),
+		FrontProxyCAKey:  utils.Pointer(signing.GetFrontProxyCAKey()),
+	}
+}
+
+// CertificateBundle concatenates PEM encoded certificates, in order. Empty and duplicate certificates are skipped.
+func CertificateBundle(certificates ...string) string {
+	var (
+		seen   = make(map[string]struct{}, len(certificates))
+		bundle strings.Builder
+	)
+	for _, certificate := range certificates {
+		certificate = strings.TrimSpace(certificate)
+		if certificate == "" {
+			continue
+		}
+		if _, ok := seen[certificate]; ok {
+			continue
+		}
+		seen[certificate] = struct{}{}
+		bundle.WriteString(certificate)
+		bundle.WriteString("\n")
+	}
+	return bundle.String()
+}
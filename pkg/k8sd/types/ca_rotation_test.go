@@ -0,0 +1,63 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestCertificateBundle(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(types.CertificateBundle()).To(BeEmpty())
+	g.Expect(types.CertificateBundle("A\n", "", "B")).To(Equal("A\nB\n"))
+	g.Expect(types.CertificateBundle("A\n", "A")).To(Equal("A\n"))
+}
+
+func TestCARotationClusterCertificates(t *testing.T) {
+	rotation := types.CARotation{
+		Old: types.Certificates{
+			CACert:           utils.Pointer("old-ca"),
+			CAKey:            utils.Pointer("old-ca-key"),
+			FrontProxyCACert: utils.Pointer("old-front-proxy-ca"),
+			FrontProxyCAKey:  utils.Pointer("old-front-proxy-ca-key"),
+		},
+		New: types.Certificates{
+			CACert:           utils.Pointer("new-ca"),
+			CAKey:            utils.Pointer("new-ca-key"),
+			ClientCACert:     utils.Pointer("new-client-ca"),
+			ClientCAKey:      utils.Pointer("new-client-ca-key"),
+			FrontProxyCACert: utils.Pointer("new-front-proxy-ca"),
+			FrontProxyCAKey:  utils.Pointer("new-front-proxy-ca-key"),
+		},
+	}
+
+	t.Run("Trust", func(t *testing.T) {
+		g := NewWithT(t)
+		rotation.Phase = types.CARotationPhaseTrust
+
+		certificates := rotation.ClusterCertificates()
+		g.Expect(certificates.GetCACert()).To(Equal("old-ca\nnew-ca\n"))
+		g.Expect(certificates.GetCAKey()).To(Equal("old-ca-key"))
+		// the old client CA is the same as the kubernetes CA
+		g.Expect(certificates.GetClientCACert()).To(Equal("old-ca\nnew-client-ca\n"))
+		g.Expect(certificates.GetClientCAKey()).To(Equal("old-ca-key"))
+		g.Expect(certificates.GetFrontProxyCACert()).To(Equal("old-front-proxy-ca\nnew-front-proxy-ca\n"))
+		g.Expect(certificates.GetFrontProxyCAKey()).To(Equal("old-front-proxy-ca-key"))
+	})
+
+	t.Run("Reissue", func(t *testing.T) {
+		g := NewWithT(t)
+		rotation.Phase = types.CARotationPhaseReissue
+
+		certificates := rotation.ClusterCertificates()
+		g.Expect(certificates.GetCACert()).To(Equal("new-ca\nold-ca\n"))
+		g.Expect(certificates.GetCAKey()).To(Equal("new-ca-key"))
+		g.Expect(certificates.GetClientCACert()).To(Equal("new-client-ca\nold-ca\n"))
+		g.Expect(certificates.GetClientCAKey()).To(Equal("new-client-ca-key"))
+		g.Expect(certificates.GetFrontProxyCACert()).To(Equal("new-front-proxy-ca\nold-front-proxy-ca\n"))
+		g.Expect(certificates.GetFrontProxyCAKey()).To(Equal("new-front-proxy-ca-key"))
+	})
+}
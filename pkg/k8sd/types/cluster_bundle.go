@@ -0,0 +1,220 @@
+package types
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	"github.com/canonical/k8sd/pkg/utils"
+)
+
+// ClusterBundleVersion is the version of the cluster bundle format produced by SealClusterBundle.
+const ClusterBundleVersion = 1
+
+// clusterBundlePBKDF2Iterations is the number of PBKDF2 iterations used to derive the bundle encryption key.
+const clusterBundlePBKDF2Iterations = 600000
+
+// KubernetesAuthToken is a token that is used to authenticate with the Kubernetes API server.
+type KubernetesAuthToken struct {
+	// ID identifies the token in the database of the cluster. ID is not exported with the token.
+	ID       int64    `json:"-"`
+	Username string   `json:"username"`
+	Groups   []string `json:"groups,omitempty"`
+	// Token is the plaintext token. Token is only set for tokens exported by older versions, since tokens are
+	// stored as salted hashes.
+	Token string `json:"token,omitempty"`
+	// TokenLookup is the plaintext prefix of the token, which is used to find the hash of the token.
+	TokenLookup string `json:"token-lookup,omitempty"`
+	// TokenSalt is the salt of the token hash, in hex.
+	TokenSalt string `json:"token-salt,omitempty"`
+	// TokenHash is the SHA256 hash of the salt and the token, in hex.
+	TokenHash string `json:"token-hash,omitempty"`
+	// Description describes the purpose of the token.
+	Description string `json:"description,omitempty"`
+	// ExpiresAt is the expiry of the token. Tokens without an expiry are valid until they are revoked.
+	ExpiresAt time.Time `json:"expires-at,omitzero"`
+	// CreatedAt is the time the token was created. CreatedAt is zero for tokens created by older versions.
+	CreatedAt time.Time `json:"created-at,omitzero"`
+	// LastUsedAt is the last time the token was used to authenticate with the Kubernetes API server, with a
+	// resolution of one minute. LastUsedAt is not exported with the token.
+	LastUsedAt time.Time `json:"-"`
+}
+
+// ClusterBundle is a snapshot of the cluster-wide state that is required to rebuild an equivalent control plane.
+// ClusterBundle contains the CA keys and all tokens of the cluster, and must only be stored sealed.
+// ClusterBundle does not contain the Kubernetes datastore, node certificates and node-local settings, join and worker
+// tokens, feature statuses, revoked certificates or the revisions of the cluster configuration.
+type ClusterBundle struct {
+	// CreatedAt is the time the bundle was exported.
+	CreatedAt time.Time `json:"created-at"`
+	// Config is the complete cluster configuration, including certificates and datastore settings, such as the sealed
+	// S3 secret access key of the etcd snapshots.
+	Config ClusterConfig `json:"config"`
+	// ClusterAPIToken is the token used by ClusterAPI providers to authenticate with k8sd.
+	ClusterAPIToken string `json:"capi-token,omitempty"`
+	// KubernetesAuthTokens are the tokens issued by k8sd to authenticate with the Kubernetes API server.
+	KubernetesAuthTokens []KubernetesAuthToken `json:"kubernetes-auth-tokens,omitempty"`
+}
+
+// sealedClusterBundle is the serialized format of a ClusterBundle.
+// The bundle is encrypted and authenticated with AES-256-GCM, using a key that is derived from a passphrase with
+// PBKDF2-SHA256 and a random salt. The version and the number of iterations are authenticated as additional data.
+type sealedClusterBundle struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+func clusterBundleCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// additionalData binds the bundle version and key derivation parameters to the encrypted data.
+func (s sealedClusterBundle) additionalData() []byte {
+	return fmt.Appendf(nil, "k8sd-cluster-bundle:v%d:%d", s.Version, s.Iterations)
+}
+
+// SealClusterBundle encrypts and signs a cluster bundle with a passphrase.
+func SealClusterBundle(bundle ClusterBundle, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cluster bundle: %w", err)
+	}
+
+	sealed := sealedClusterBundle{
+		Version:    ClusterBundleVersion,
+		Iterations: clusterBundlePBKDF2Iterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return nil, fmt.Errorf("is the system entropy low? failed to get random bytes: %w", err)
+	}
+	aead, err := clusterBundleCipher(passphrase, sealed.Salt, sealed.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return nil, fmt.Errorf("is the system entropy low? failed to get random bytes: %w", err)
+	}
+	sealed.Data = aead.Seal(nil, sealed.Nonce, data, sealed.additionalData())
+
+	b, err := json.Marshal(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sealed cluster bundle: %w", err)
+	}
+	return b, nil
+}
+
+// OpenClusterBundle verifies and decrypts a cluster bundle that was sealed with SealClusterBundle.
+// OpenClusterBundle returns an error if the passphrase is wrong or the bundle was modified.
+func OpenClusterBundle(b []byte, passphrase string) (ClusterBundle, error) {
+	var sealed sealedClusterBundle
+	if err := json.Unmarshal(b, &sealed); err != nil {
+		return ClusterBundle{}, fmt.Errorf("failed to parse cluster bundle: %w", err)
+	}
+	if sealed.Version != ClusterBundleVersion {
+		return ClusterBundle{}, fmt.Errorf("unsupported cluster bundle version %d, must be %d", sealed.Version, ClusterBundleVersion)
+	}
+	if sealed.Iterations <= 0 {
+		return ClusterBundle{}, fmt.Errorf("invalid cluster bundle key derivation iterations %d", sealed.Iterations)
+	}
+
+	aead, err := clusterBundleCipher(passphrase, sealed.Salt, sealed.Iterations)
+	if err != nil {
+		return ClusterBundle{}, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return ClusterBundle{}, fmt.Errorf("invalid cluster bundle nonce size %d", len(sealed.Nonce))
+	}
+	data, err := aead.Open(nil, sealed.Nonce, sealed.Data, sealed.additionalData())
+	if err != nil {
+		return ClusterBundle{}, fmt.Errorf("failed to verify cluster bundle, is the passphrase correct? %w", err)
+	}
+
+	var bundle ClusterBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return ClusterBundle{}, fmt.Errorf("failed to unmarshal cluster bundle: %w", err)
+	}
+	return bundle, nil
+}
+
+// BootstrapConfig returns the bootstrap configuration that rebuilds the cluster of the bundle.
+// Cluster-wide settings (cluster configuration, CIDRs, datastore and certificate authorities) are taken from the bundle.
+// Node-local settings (e.g. extra SANs, extra service arguments and node certificates) are taken from node.
+// The S3 secret access key of the etcd snapshots is put back into the etcd snapshots annotation, since the bootstrap
+// configuration has no field for it. It is sealed again when the cluster configuration is stored.
+func (b ClusterBundle) BootstrapConfig(node apiv2.BootstrapConfig) (apiv2.BootstrapConfig, error) {
+	cfg := b.Config
+	bootstrapConfig := node
+
+	if err := cfg.UnsealEtcdSnapshotSecret(); err != nil {
+		return apiv2.BootstrapConfig{}, fmt.Errorf("failed to restore the etcd snapshot secret: %w", err)
+	}
+	bootstrapConfig.ClusterConfig = cfg.ToUserFacing()
+	bootstrapConfig.PodCIDR = cfg.Network.PodCIDR
+	bootstrapConfig.ServiceCIDR = cfg.Network.ServiceCIDR
+	bootstrapConfig.SecurePort = cfg.APIServer.SecurePort
+	bootstrapConfig.DisableRBAC = utils.Pointer(cfg.APIServer.GetAuthorizationMode() == "AlwaysAllow")
+	bootstrapConfig.ControlPlaneTaints = cfg.Kubelet.GetControlPlaneTaints()
+
+	bootstrapConfig.DatastoreType = cfg.Datastore.Type
+	switch cfg.Datastore.GetType() {
+	case "external":
+		bootstrapConfig.DatastoreServers = cfg.Datastore.GetExternalServers()
+		bootstrapConfig.DatastoreCACert = cfg.Datastore.ExternalCACert
+		bootstrapConfig.DatastoreClientCert = cfg.Datastore.ExternalClientCert
+		bootstrapConfig.DatastoreClientKey = cfg.Datastore.ExternalClientKey
+		bootstrapConfig.EtcdPort = nil
+		bootstrapConfig.EtcdPeerPort = nil
+	default:
+		bootstrapConfig.DatastoreServers = nil
+		bootstrapConfig.DatastoreCACert = nil
+		bootstrapConfig.DatastoreClientCert = nil
+		bootstrapConfig.DatastoreClientKey = nil
+		bootstrapConfig.EtcdPort = cfg.Datastore.EtcdPort
+		bootstrapConfig.EtcdPeerPort = cfg.Datastore.EtcdPeerPort
+		bootstrapConfig.EtcdCACert = cfg.Datastore.EtcdCACert
+		bootstrapConfig.EtcdCAKey = cfg.Datastore.EtcdCAKey
+		bootstrapConfig.EtcdAPIServerClientCert = cfg.Datastore.EtcdAPIServerClientCert
+		bootstrapConfig.EtcdAPIServerClientKey = cfg.Datastore.EtcdAPIServerClientKey
+	}
+
+	bootstrapConfig.CACert = cfg.Certificates.CACert
+	bootstrapConfig.CAKey = cfg.Certificates.CAKey
+	bootstrapConfig.ClientCACert = cfg.Certificates.ClientCACert
+	bootstrapConfig.ClientCAKey = cfg.Certificates.ClientCAKey
+	bootstrapConfig.FrontProxyCACert = cfg.Certificates.FrontProxyCACert
+	bootstrapConfig.FrontProxyCAKey = cfg.Certificates.FrontProxyCAKey
+	bootstrapConfig.ServiceAccountKey = cfg.Certificates.ServiceAccountKey
+	bootstrapConfig.APIServerKubeletClientCert = cfg.Certificates.APIServerKubeletClientCert
+	bootstrapConfig.APIServerKubeletClientKey = cfg.Certificates.APIServerKubeletClientKey
+	bootstrapConfig.AdminClientCert = cfg.Certificates.AdminClientCert
+	bootstrapConfig.AdminClientKey = cfg.Certificates.AdminClientKey
+
+	return bootstrapConfig, nil
+}
@@ -0,0 +1,176 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestClusterBundle(t *testing.T) {
+	bundle := types.ClusterBundle{
+		CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Config: types.ClusterConfig{
+			Network: types.Network{
+				Enabled:     utils.Pointer(true),
+				PodCIDR:     utils.Pointer("10.1.0.0/16"),
+				ServiceCIDR: utils.Pointer("10.152.183.0/24"),
+			},
+			APIServer: types.APIServer{
+				SecurePort:        utils.Pointer(6443),
+				AuthorizationMode: utils.Pointer("Node,RBAC"),
+			},
+			Datastore: types.Datastore{
+				Type:       utils.Pointer("etcd"),
+				EtcdCACert: utils.Pointer("etcd-ca-crt"),
+				EtcdCAKey:  utils.Pointer("etcd-ca-key"),
+				EtcdPort:   utils.Pointer(2379),
+			},
+			Certificates: types.Certificates{
+				CACert:            utils.Pointer("ca-crt"),
+				CAKey:             utils.Pointer("ca-key"),
+				ServiceAccountKey: utils.Pointer("sa-key"),
+			},
+			Annotations: types.Annotations{"k8sd/v1alpha/test": "value"},
+		},
+		ClusterAPIToken: "capi-token",
+		KubernetesAuthTokens: []types.KubernetesAuthToken{
+			{Username: "admin", Groups: []string{"system:masters"}, Token: "token::admin"},
+		},
+	}
+
+	t.Run("SealOpen", func(t *testing.T) {
+		g := NewWithT(t)
+
+		sealed, err := types.SealClusterBundle(bundle, "passphrase")
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(string(sealed)).ToNot(ContainSubstring("ca-key"))
+
+		opened, err := types.OpenClusterBundle(sealed, "passphrase")
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(opened).To(Equal(bundle))
+	})
+
+	t.Run("WrongPassphrase", func(t *testing.T) {
+		g := NewWithT(t)
+
+		sealed, err := types.SealClusterBundle(bundle, "passphrase")
+		g.Expect(err).To(Not(HaveOccurred()))
+
+		_, err = types.OpenClusterBundle(sealed, "wrong")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		g := NewWithT(t)
+
+		sealed, err := types.SealClusterBundle(bundle, "passphrase")
+		g.Expect(err).To(Not(HaveOccurred()))
+
+		var raw map[string]any
+		g.Expect(json.Unmarshal(sealed, &raw)).To(Succeed())
+		raw["iterations"] = 1
+		modified, err := json.Marshal(raw)
+		g.Expect(err).To(Not(HaveOccurred()))
+
+		_, err = types.OpenClusterBundle(modified, "passphrase")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := types.OpenClusterBundle([]byte(`{"version": 99}`), "passphrase")
+		g.Expect(err).To(MatchError(ContainSubstring("unsupported cluster bundle version 99")))
+	})
+
+	t.Run("EmptyPassphrase", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := types.SealClusterBundle(bundle, "")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("BootstrapConfig", func(t *testing.T) {
+		g := NewWithT(t)
+
+		node := apiv2.BootstrapConfig{
+			ExtraSANs:      []string{"node.local"},
+			DatastoreType:  utils.Pointer("external"),
+			PodCIDR:        utils.Pointer("192.168.0.0/16"),
+			CACert:         utils.Pointer("other-ca-crt"),
+			APIServerCert:  utils.Pointer("apiserver-crt"),
+			EtcdServerCert: utils.Pointer("etcd-server-crt"),
+		}
+
+		bootstrapConfig, err := bundle.BootstrapConfig(node)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(bootstrapConfig.ExtraSANs).To(Equal([]string{"node.local"}))
+		g.Expect(bootstrapConfig.GetAPIServerCert()).To(Equal("apiserver-crt"))
+		g.Expect(bootstrapConfig.GetEtcdServerCert()).To(Equal("etcd-server-crt"))
+
+		g.Expect(bootstrapConfig.GetDatastoreType()).To(Equal("etcd"))
+		g.Expect(bootstrapConfig.PodCIDR).To(Equal(utils.Pointer("10.1.0.0/16")))
+		g.Expect(bootstrapConfig.GetCACert()).To(Equal("ca-crt"))
+		g.Expect(bootstrapConfig.GetCAKey()).To(Equal("ca-key"))
+		g.Expect(bootstrapConfig.GetEtcdCAKey()).To(Equal("etcd-ca-key"))
+		g.Expect(bootstrapConfig.GetServiceAccountKey()).To(Equal("sa-key"))
+		g.Expect(bootstrapConfig.DisableRBAC).To(Equal(utils.Pointer(false)))
+		g.Expect(bootstrapConfig.ClusterConfig.Annotations).To(HaveKeyWithValue("k8sd/v1alpha/test", "value"))
+
+		cfg, err := types.ClusterConfigFromBootstrapConfig(bootstrapConfig)
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(cfg.Network.GetServiceCIDR()).To(Equal("10.152.183.0/24"))
+		g.Expect(cfg.APIServer.GetAuthorizationMode()).To(Equal("Node,RBAC"))
+	})
+}
+
+func TestClusterBundleEtcdSnapshotSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	defaults := types.ClusterConfig{}
+	defaults.SetDefaults()
+	exported, err := types.MergeClusterConfig(defaults, types.ClusterConfig{
+		Annotations: types.Annotations{types.AnnotationEtcdSnapshots: `
+interval: 6h
+s3:
+  endpoint: http://10.0.0.10:9000
+  bucket: backups
+  access-key-id: k8s
+  secret-access-key: secret
+`},
+	})
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(exported.Annotations[types.AnnotationEtcdSnapshots]).ToNot(ContainSubstring("secret"))
+
+	sealed, err := types.SealClusterBundle(types.ClusterBundle{Config: exported}, "passphrase")
+	g.Expect(err).To(Not(HaveOccurred()))
+	bundle, err := types.OpenClusterBundle(sealed, "passphrase")
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(bundle.Config.Datastore.GetEtcdSnapshotS3SecretAccessKey()).To(Equal("secret"))
+
+	bootstrapConfig, err := bundle.BootstrapConfig(apiv2.BootstrapConfig{})
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(bundle.Config.Annotations[types.AnnotationEtcdSnapshots]).ToNot(ContainSubstring("secret"))
+
+	// the bootstrap configuration of the bundle is valid, as on bootstrap
+	cfg, err := types.ClusterConfigFromBootstrapConfig(bootstrapConfig)
+	g.Expect(err).To(Not(HaveOccurred()))
+	cfg.SetDefaults()
+	g.Expect(cfg.Validate()).To(Succeed())
+
+	config, ok, err := cfg.EtcdSnapshots()
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(ok).To(BeTrue())
+	g.Expect(config.S3.SecretAccessKey).To(Equal("secret"))
+
+	// the secret is sealed again once the imported configuration is stored
+	imported, err := types.MergeClusterConfig(types.ClusterConfig{}, cfg)
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(imported.Annotations[types.AnnotationEtcdSnapshots]).ToNot(ContainSubstring("secret"))
+	g.Expect(imported.Datastore.GetEtcdSnapshotS3SecretAccessKey()).To(Equal("secret"))
+}
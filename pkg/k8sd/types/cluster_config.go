@@ -14,5 +14,7 @@ type ClusterConfig struct {
 	LocalStorage  LocalStorage  `json:"local-storage,omitempty"`
 	MetricsServer MetricsServer `json:"metrics-server,omitempty"`
 
+	Addons HelmAddons `json:"addons,omitempty"`
+
 	Annotations Annotations `json:"annotations,omitempty"`
 }
@@ -0,0 +1,31 @@
+package types
+
+// HelmAddon is an operator-defined Helm chart that is managed by k8sd like the built-in features.
+type HelmAddon struct {
+	Enabled   *bool          `json:"enabled,omitempty"`
+	ChartPath *string        `json:"chart-path,omitempty"`
+	Namespace *string        `json:"namespace,omitempty"`
+	Values    map[string]any `json:"values,omitempty"`
+
+	// Remove marks the add-on for removal in a cluster configuration update. It is never stored.
+	Remove *bool `json:"-"`
+	// Removed marks an add-on that was removed from the cluster configuration, but whose Helm release may not be
+	// uninstalled yet. The feature controller uninstalls the release and then drops the add-on, see Purge.
+	Removed *bool `json:"removed,omitempty"`
+	// Purge drops a removed add-on in a cluster configuration update. It is never stored.
+	Purge *bool `json:"-"`
+}
+
+func (c HelmAddon) GetEnabled() bool     { return getField(c.Enabled) }
+func (c HelmAddon) GetChartPath() string { return getField(c.ChartPath) }
+func (c HelmAddon) GetNamespace() string { return getField(c.Namespace) }
+func (c HelmAddon) GetRemove() bool      { return getField(c.Remove) }
+func (c HelmAddon) GetRemoved() bool     { return getField(c.Removed) }
+func (c HelmAddon) GetPurge() bool       { return getField(c.Purge) }
+func (c HelmAddon) Empty() bool {
+	return c.Enabled == nil && c.ChartPath == nil && c.Namespace == nil && c.Values == nil && c.Remove == nil && c.Removed == nil && c.Purge == nil
+}
+
+// HelmAddons maps the name of each add-on to its configuration.
+// The add-on name is also used as the name of the Helm release.
+type HelmAddons map[string]HelmAddon
@@ -0,0 +1,44 @@
+package types
+
+import (
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+)
+
+// HelmAddonsFromUserFacing converts the add-on configuration from the API into HelmAddons.
+func HelmAddonsFromUserFacing(u map[string]k8sdapi.HelmAddonConfig) HelmAddons {
+	if u == nil {
+		return nil
+	}
+	addons := make(HelmAddons, len(u))
+	for name, addon := range u {
+		addons[name] = HelmAddon{
+			Enabled:   addon.Enabled,
+			ChartPath: addon.ChartPath,
+			Namespace: addon.Namespace,
+			Values:    addon.Values,
+			Remove:    addon.Remove,
+		}
+	}
+	return addons
+}
+
+// ToUserFacing converts HelmAddons to the add-on configuration of the API.
+// Removed add-ons are not part of the user-facing configuration.
+func (c HelmAddons) ToUserFacing() map[string]k8sdapi.HelmAddonConfig {
+	if c == nil {
+		return nil
+	}
+	addons := make(map[string]k8sdapi.HelmAddonConfig, len(c))
+	for name, addon := range c {
+		if addon.GetRemoved() {
+			continue
+		}
+		addons[name] = k8sdapi.HelmAddonConfig{
+			Enabled:   addon.Enabled,
+			ChartPath: addon.ChartPath,
+			Namespace: addon.Namespace,
+			Values:    addon.Values,
+		}
+	}
+	return addons
+}
@@ -0,0 +1,205 @@
+package types
+
+import (
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	"gopkg.in/yaml.v2"
+)
+
+// AnnotationCertificateProfiles customizes the leaf certificates generated or signed by k8sd.
+// The value is a YAML map of certificate names (as used by "k8s refresh-certs", e.g. "apiserver" or "admin.conf", or
+// "etcd-server", "etcd-peer" and "apiserver-etcd-client" for the certificates of the managed etcd datastore) to
+// profiles. The "default" profile applies to all certificates, and fields set in the profile of a certificate override
+// it. For example:
+//
+//	k8sd/v1alpha1/pki/profiles: |
+//	  default:
+//	    lifetime: 90d
+//	  apiserver:
+//	    extra-sans: [api.example.com, 10.0.0.10]
+//	    key-usages: [server-auth]
+//	    subject:
+//	      country: [GB]
+//	      organizational-unit: [platform]
+//
+// The lifetime is the maximum lifetime of the certificates, and also caps the expiry requested when refreshing
+// certificates. Key usages replace the extended key usages of the certificates, and must be "client-auth" and/or
+// "server-auth". Profiles apply to certificates generated afterwards (e.g. when nodes join the cluster or certificates
+// are refreshed), and do not apply to the certificate authorities.
+const AnnotationCertificateProfiles = "k8sd/v1alpha1/pki/profiles"
+
+// certificateProfile is the YAML format of a certificate profile in the AnnotationCertificateProfiles annotation.
+type certificateProfile struct {
+	Lifetime  string   `yaml:"lifetime"`
+	ExtraSANs []string `yaml:"extra-sans"`
+	KeyUsages []string `yaml:"key-usages"`
+	Subject   struct {
+		Country            []string `yaml:"country"`
+		Province           []string `yaml:"province"`
+		Locality           []string `yaml:"locality"`
+		OrganizationalUnit []string `yaml:"organizational-unit"`
+	} `yaml:"subject"`
+}
+
+var (
+	extKeyUsages = map[string]x509.ExtKeyUsage{
+		"client-auth": x509.ExtKeyUsageClientAuth,
+		"server-auth": x509.ExtKeyUsageServerAuth,
+	}
+
+	// requiredExtKeyUsages are the extended key usages that the certificates need to function.
+	requiredExtKeyUsages = map[apiv2.CertificateName][]x509.ExtKeyUsage{
+		apiv2.CertificateAPIServer:               {x509.ExtKeyUsageServerAuth},
+		apiv2.CertificateKubelet:                 {x509.ExtKeyUsageServerAuth},
+		apiv2.CertificateFrontProxyClient:        {x509.ExtKeyUsageClientAuth},
+		apiv2.CertificateAPIServerKubeletClient:  {x509.ExtKeyUsageClientAuth},
+		apiv2.CertificateAdminClient:             {x509.ExtKeyUsageClientAuth},
+		apiv2.CertificateSchedulerClient:         {x509.ExtKeyUsageClientAuth},
+		apiv2.CertificateControllerManagerClient: {x509.ExtKeyUsageClientAuth},
+		apiv2.CertificateKubeletClient:           {x509.ExtKeyUsageClientAuth},
+		apiv2.CertificateProxyClient:             {x509.ExtKeyUsageClientAuth},
+		// NOTE: etcd also uses its server certificate as a client certificate (e.g. for the gRPC gateway), and the
+		// peer certificate for both ends of the peer connections.
+		"etcd-server":           {x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		"etcd-peer":             {x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		"apiserver-etcd-client": {x509.ExtKeyUsageClientAuth},
+	}
+)
+
+// ParseCertificateProfiles parses the value of the AnnotationCertificateProfiles annotation.
+func ParseCertificateProfiles(value string) (pkiutil.Profiles, error) {
+	var in map[string]certificateProfile
+	if err := yaml.UnmarshalStrict([]byte(value), &in); err != nil {
+		return nil, fmt.Errorf("failed to parse certificate profiles: %w", err)
+	}
+
+	profiles := make(pkiutil.Profiles, len(in))
+	for name, p := range in {
+		if _, ok := requiredExtKeyUsages[apiv2.CertificateName(name)]; !ok && name != pkiutil.DefaultProfileName {
+			return nil, fmt.Errorf("unknown certificate %q", name)
+		}
+
+		var profile pkiutil.Profile
+		if p.Lifetime != "" {
+			lifetime, err := parseLifetime(p.Lifetime)
+			if err != nil {
+				return nil, fmt.Errorf("invalid lifetime %q of certificate %q: %w", p.Lifetime, name, err)
+			}
+			profile.Lifetime = lifetime
+		}
+		profile.IPSANs, profile.DNSSANs = utils.SplitIPAndDNSSANs(p.ExtraSANs)
+		for _, usage := range p.KeyUsages {
+			extKeyUsage, ok := extKeyUsages[usage]
+			if !ok {
+				return nil, fmt.Errorf("invalid key usage %q of certificate %q, must be one of client-auth or server-auth", usage, name)
+			}
+			profile.ExtKeyUsage = append(profile.ExtKeyUsage, extKeyUsage)
+		}
+		profile.Country = p.Subject.Country
+		profile.Province = p.Subject.Province
+		profile.Locality = p.Subject.Locality
+		profile.OrganizationalUnit = p.Subject.OrganizationalUnit
+
+		profiles[name] = profile
+	}
+
+	if defaultProfile, ok := profiles[pkiutil.DefaultProfileName]; ok {
+		for name, profile := range profiles {
+			profiles[name] = inheritProfile(profile, defaultProfile)
+		}
+	}
+
+	// check: the key usages of each certificate (including those using the default profile) must allow it to function
+	for name, required := range requiredExtKeyUsages {
+		usages := profiles.Get(string(name)).ExtKeyUsage
+		if len(usages) == 0 {
+			continue
+		}
+		for _, usage := range required {
+			if !slices.Contains(usages, usage) {
+				return nil, fmt.Errorf("key usages of certificate %q must include %s", name, keyUsageName(usage))
+			}
+		}
+	}
+
+	return profiles, nil
+}
+
+// parseLifetime parses a duration, which may also be specified in days (e.g. "90d").
+func parseLifetime(s string) (time.Duration, error) {
+	var (
+		d   time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
+// inheritProfile returns the profile, with unset fields taken from the default profile.
+func inheritProfile(profile pkiutil.Profile, defaultProfile pkiutil.Profile) pkiutil.Profile {
+	if profile.Lifetime == 0 {
+		profile.Lifetime = defaultProfile.Lifetime
+	}
+	if len(profile.DNSSANs) == 0 && len(profile.IPSANs) == 0 {
+		profile.DNSSANs, profile.IPSANs = defaultProfile.DNSSANs, defaultProfile.IPSANs
+	}
+	if len(profile.ExtKeyUsage) == 0 {
+		profile.ExtKeyUsage = defaultProfile.ExtKeyUsage
+	}
+	if len(profile.Country) == 0 {
+		profile.Country = defaultProfile.Country
+	}
+	if len(profile.Province) == 0 {
+		profile.Province = defaultProfile.Province
+	}
+	if len(profile.Locality) == 0 {
+		profile.Locality = defaultProfile.Locality
+	}
+	if len(profile.OrganizationalUnit) == 0 {
+		profile.OrganizationalUnit = defaultProfile.OrganizationalUnit
+	}
+	return profile
+}
+
+func keyUsageName(usage x509.ExtKeyUsage) string {
+	for name, u := range extKeyUsages {
+		if u == usage {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d", usage)
+}
+
+// CertificateProfiles returns the certificate profiles, as configured by the AnnotationCertificateProfiles annotation.
+// CertificateProfiles returns nil if the annotation is not set or invalid.
+func (c ClusterConfig) CertificateProfiles() pkiutil.Profiles {
+	v, ok := c.Annotations.Get(AnnotationCertificateProfiles)
+	if !ok {
+		return nil
+	}
+	profiles, err := ParseCertificateProfiles(v)
+	if err != nil {
+		return nil
+	}
+	return profiles
+}
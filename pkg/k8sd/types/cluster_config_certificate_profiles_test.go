@@ -0,0 +1,103 @@
+package types_test
+
+import (
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseCertificateProfiles(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		g := NewWithT(t)
+
+		profiles, err := types.ParseCertificateProfiles(`
+default:
+  lifetime: 90d
+  subject:
+    country: [GB]
+kubelet:
+  extra-sans: [node.example.com]
+etcd-server:
+  lifetime: 30d
+apiserver:
+  lifetime: 720h
+  extra-sans: [api.example.com, 10.0.0.10]
+  key-usages: [server-auth]
+  subject:
+    country: [FR]
+    organizational-unit: [platform]
+`)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(profiles).To(Equal(pkiutil.Profiles{
+			"default":     {Lifetime: 90 * 24 * time.Hour, Country: []string{"GB"}},
+			"kubelet":     {Lifetime: 90 * 24 * time.Hour, DNSSANs: []string{"node.example.com"}, Country: []string{"GB"}},
+			"etcd-server": {Lifetime: 30 * 24 * time.Hour, Country: []string{"GB"}},
+			"apiserver": {
+				Lifetime:           720 * time.Hour,
+				DNSSANs:            []string{"api.example.com"},
+				IPSANs:             []net.IP{net.ParseIP("10.0.0.10")},
+				ExtKeyUsage:        []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+				Country:            []string{"FR"},
+				OrganizationalUnit: []string{"platform"},
+			},
+		}))
+	})
+
+	for _, tc := range []struct {
+		name  string
+		value string
+	}{
+		{name: "InvalidYAML", value: "default: ["},
+		{name: "UnknownField", value: "default: {validity: 90d}"},
+		{name: "UnknownCertificate", value: "etcd: {lifetime: 90d}"},
+		{name: "InvalidLifetime", value: "default: {lifetime: 3 months}"},
+		{name: "NegativeLifetime", value: "default: {lifetime: -1h}"},
+		{name: "InvalidKeyUsage", value: "apiserver: {key-usages: [code-signing]}"},
+		{name: "MissingServerAuth", value: "apiserver: {key-usages: [client-auth]}"},
+		{name: "DefaultMissingClientAuth", value: "default: {key-usages: [server-auth]}"},
+		{name: "EtcdPeerMissingClientAuth", value: "etcd-peer: {key-usages: [server-auth]}"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := types.ParseCertificateProfiles(tc.value)
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
+
+func TestValidateCertificateProfiles(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		annotations   types.Annotations
+		expectProfile pkiutil.Profile
+		expectErr     bool
+	}{
+		{name: "Default"},
+		{name: "Lifetime", annotations: types.Annotations{types.AnnotationCertificateProfiles: "default: {lifetime: 90d}"}, expectProfile: pkiutil.Profile{Lifetime: 90 * 24 * time.Hour}},
+		{name: "Invalid", annotations: types.Annotations{types.AnnotationCertificateProfiles: "default: {lifetime: forever}"}, expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			config := types.ClusterConfig{
+				Network: types.Network{
+					PodCIDR:     utils.Pointer("10.1.0.0/16"),
+					ServiceCIDR: utils.Pointer("10.2.0.0/16"),
+				},
+				Annotations: tc.annotations,
+			}
+			if tc.expectErr {
+				g.Expect(config.Validate()).To(HaveOccurred())
+			} else {
+				g.Expect(config.Validate()).ToNot(HaveOccurred())
+				g.Expect(config.CertificateProfiles().Get("apiserver")).To(Equal(tc.expectProfile))
+			}
+		})
+	}
+}
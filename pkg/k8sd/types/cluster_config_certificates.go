@@ -1,5 +1,27 @@
 package types
 
+import (
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+)
+
+// AnnotationKeyAlgorithm selects the key algorithm of the certificates generated by k8sd.
+// Must be one of "rsa-2048", "rsa-4096", "ecdsa-p256", "ecdsa-p384" or "ed25519".
+// The annotation is typically set in the bootstrap configuration, and applies to certificate authorities and
+// certificates generated afterwards (e.g. when nodes join the cluster or certificates are refreshed).
+// New clusters default to "ecdsa-p256", which is recorded in the annotation at bootstrap. Clusters without the
+// annotation (e.g. bootstrapped by earlier versions) use "rsa-2048".
+//
+// NOTE: The annotation does not apply to the k8sd keypair and the service account key, which are always RSA-2048.
+// The k8sd keypair signs the cluster configuration that is shared with the nodes, and also encrypts the signatures
+// of worker node certificate signing requests, which requires an RSA key.
+const AnnotationKeyAlgorithm = "k8sd/v1alpha1/pki/key-algorithm"
+
+// AnnotationRevocationWebhook enables the k8sd authorization webhook of kube-apiserver when set to "true".
+// The webhook denies requests made with certificates that k8sd revoked, e.g. the certificates of removed nodes.
+// The webhook is disabled by default, since kube-apiserver asks k8sd about every request that is not cached.
+// Clients that support certificate revocation lists can use the CRL that k8sd serves instead.
+const AnnotationRevocationWebhook = "k8sd/v1alpha1/pki/revocation-webhook"
+
 type Certificates struct {
 	CACert                     *string `json:"ca-crt,omitempty"`
 	CAKey                      *string `json:"ca-key,omitempty"`
@@ -50,3 +72,32 @@ func (c Certificates) GetK8sdPrivateKey() string  { return getField(c.K8sdPrivat
 
 // Empty returns true if all Certificates fields are unset.
 func (c Certificates) Empty() bool { return c == Certificates{} }
+
+// KeyAlgorithm returns the key algorithm for certificates generated by k8sd, as selected by the
+// AnnotationKeyAlgorithm annotation. KeyAlgorithm returns pkiutil.DefaultKeyAlgorithm if the annotation is not set or invalid.
+func (c ClusterConfig) KeyAlgorithm() pkiutil.KeyAlgorithm {
+	v, _ := c.Annotations.Get(AnnotationKeyAlgorithm)
+	alg, err := pkiutil.ParseKeyAlgorithm(v)
+	if err != nil {
+		return pkiutil.DefaultKeyAlgorithm
+	}
+	return alg
+}
+
+// SetBootstrapKeyAlgorithm selects pkiutil.BootstrapKeyAlgorithm for a new cluster, unless the AnnotationKeyAlgorithm
+// annotation is already set.
+func (c *ClusterConfig) SetBootstrapKeyAlgorithm() {
+	if _, ok := c.Annotations.Get(AnnotationKeyAlgorithm); ok {
+		return
+	}
+	if c.Annotations == nil {
+		c.Annotations = Annotations{}
+	}
+	c.Annotations[AnnotationKeyAlgorithm] = string(pkiutil.BootstrapKeyAlgorithm)
+}
+
+// RevocationWebhookEnabled returns true if the k8sd authorization webhook is enabled by the AnnotationRevocationWebhook annotation.
+func (c ClusterConfig) RevocationWebhookEnabled() bool {
+	v, _ := c.Annotations.Get(AnnotationRevocationWebhook)
+	return v == "true"
+}
@@ -22,6 +22,10 @@ type Datastore struct {
 	EtcdAPIServerClientKey  *string `json:"etcd-apiserver-client-key,omitempty"`
 	EtcdPort                *int    `json:"etcd-port,omitempty"`
 	EtcdPeerPort            *int    `json:"etcd-peer-port,omitempty"`
+
+	// EtcdSnapshotS3SecretAccessKey is the secret access key of the S3 bucket of the etcd snapshots.
+	// It is moved here from the AnnotationEtcdSnapshots annotation, see ClusterConfig.EtcdSnapshots.
+	EtcdSnapshotS3SecretAccessKey *string `json:"etcd-snapshot-s3-secret-access-key,omitempty"`
 }
 
 func (c Datastore) GetType() string               { return getField(c.Type) }
@@ -38,6 +42,9 @@ func (c Datastore) GetEtcdAPIServerClientCert() string {
 func (c Datastore) GetEtcdAPIServerClientKey() string {
 	return getField(c.EtcdAPIServerClientKey)
 }
+func (c Datastore) GetEtcdSnapshotS3SecretAccessKey() string {
+	return getField(c.EtcdSnapshotS3SecretAccessKey)
+}
 func (c Datastore) GetEtcdPort() int     { return getField(c.EtcdPort) }
 func (c Datastore) GetEtcdPeerPort() int { return getField(c.EtcdPeerPort) }
 func (c Datastore) Empty() bool          { return c == Datastore{} }
@@ -106,4 +106,21 @@ func (c *ClusterConfig) SetDefaults() {
 	if c.MetricsServer.Enabled == nil {
 		c.MetricsServer.Enabled = utils.Pointer(true)
 	}
+	// add-ons
+	c.Addons.SetDefaults()
+}
+
+// SetDefaults sets the default values of the add-ons that do not set them.
+// SetDefaults is also applied on every cluster configuration update, so that add-ons added after bootstrap get the
+// same defaults.
+func (c HelmAddons) SetDefaults() {
+	for name, addon := range c {
+		if addon.Enabled == nil {
+			addon.Enabled = utils.Pointer(false)
+		}
+		if addon.GetNamespace() == "" {
+			addon.Namespace = utils.Pointer("default")
+		}
+		c[name] = addon
+	}
 }
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"fmt"
+
+	apiv2 "github.com/canonical/k8s-snap-api/v2/api"
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+	"github.com/canonical/k8sd/pkg/utils"
+)
+
+// redactedFields are user-facing fields whose values are not included in a cluster configuration diff.
+var redactedFields = map[string]struct{}{
+	"datastore.client-key": {},
+}
+
+// userFacingClusterConfig is the user-facing view of a cluster configuration that is compared by DiffClusterConfig.
+type userFacingClusterConfig struct {
+	apiv2.UserFacingClusterConfig
+	Datastore apiv2.UserFacingDatastoreConfig    `json:"datastore,omitempty"`
+	Addons    map[string]k8sdapi.HelmAddonConfig `json:"addons,omitempty"`
+}
+
+// DiffClusterConfig returns the changes between the user-facing fields of two cluster configurations.
+// Keys are the dotted paths of the fields, as used by "k8s set" (e.g. "load-balancer.cidrs").
+// Values of sensitive fields, like the datastore client key, are redacted.
+func DiffClusterConfig(old, new ClusterConfig) ([]utils.FieldChange, error) {
+	changes, err := utils.DiffJSON(
+		userFacingClusterConfig{UserFacingClusterConfig: old.ToUserFacing(), Datastore: old.Datastore.ToUserFacing(), Addons: old.Addons.ToUserFacing()},
+		userFacingClusterConfig{UserFacingClusterConfig: new.ToUserFacing(), Datastore: new.Datastore.ToUserFacing(), Addons: new.Addons.ToUserFacing()},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare cluster configurations: %w", err)
+	}
+
+	for i, change := range changes {
+		if _, ok := redactedFields[change.Key]; !ok {
+			continue
+		}
+		if change.Old != "" {
+			changes[i].Old = "<redacted>"
+		}
+		if change.New != "" {
+			changes[i].New = "<redacted>"
+		}
+	}
+	return changes, nil
+}
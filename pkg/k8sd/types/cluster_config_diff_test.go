@@ -0,0 +1,51 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiffClusterConfig(t *testing.T) {
+	t.Run("Unchanged", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cfg := types.ClusterConfig{LoadBalancer: types.LoadBalancer{Enabled: utils.Pointer(true)}}
+		changes, err := types.DiffClusterConfig(cfg, cfg)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changes).To(BeEmpty())
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		old := types.ClusterConfig{
+			LoadBalancer: types.LoadBalancer{Enabled: utils.Pointer(false)},
+		}
+		new := types.ClusterConfig{
+			LoadBalancer: types.LoadBalancer{Enabled: utils.Pointer(true), CIDRs: utils.Pointer([]string{"10.0.0.0/24"})},
+			Addons:       types.HelmAddons{"demo": {Enabled: utils.Pointer(true)}},
+		}
+		changes, err := types.DiffClusterConfig(old, new)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changes).To(Equal([]utils.FieldChange{
+			{Key: "addons.demo.enabled", New: "true"},
+			{Key: "load-balancer.cidrs", New: `["10.0.0.0/24"]`},
+			{Key: "load-balancer.enabled", Old: "false", New: "true"},
+		}))
+	})
+
+	t.Run("Redacted", func(t *testing.T) {
+		g := NewWithT(t)
+
+		old := types.ClusterConfig{Datastore: types.Datastore{ExternalClientKey: utils.Pointer("key1")}}
+		new := types.ClusterConfig{Datastore: types.Datastore{ExternalClientKey: utils.Pointer("key2")}}
+		changes, err := types.DiffClusterConfig(old, new)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(changes).To(Equal([]utils.FieldChange{
+			{Key: "datastore.client-key", Old: "<redacted>", New: "<redacted>"},
+		}))
+	})
+}
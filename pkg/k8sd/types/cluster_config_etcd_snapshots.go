@@ -0,0 +1,205 @@
+package types
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// AnnotationEtcdSnapshots enables scheduled snapshots of the managed etcd datastore.
+// The value is a YAML map with the interval between snapshots, the number of snapshots to keep and an optional
+// S3-compatible bucket to upload the snapshots to. For example:
+//
+//	k8sd/v1alpha1/etcd/snapshots: |
+//	  interval: 6h
+//	  retention: 28
+//	  s3:
+//	    endpoint: https://minio.example.com:9000
+//	    bucket: k8s-backups
+//	    prefix: cluster-a/
+//	    access-key-id: k8s
+//	    secret-access-key: secret
+//
+// The interval defaults to 24 hours and may also be specified in days (e.g. "1d"). The retention defaults to 7
+// snapshots. Snapshots are taken by the node of the etcd leader. Without an S3 bucket, snapshots are kept in the
+// "etcd-snapshots" directory of the k8sd state directory of that node, so every control plane node that was the leader
+// keeps the snapshots it took. The snapshots of all control plane nodes are then listed together, and the interval and
+// the retention apply to all of them. A snapshot must be restored on the node that stores it.
+// NOTE: The secret access key is moved out of the annotation when the cluster configuration is updated, so that it is
+// not returned with the cluster configuration or recorded in its revisions. It is kept with the datastore credentials
+// and only needs to be set again to change it.
+const AnnotationEtcdSnapshots = "k8sd/v1alpha1/etcd/snapshots"
+
+// EtcdSnapshotConfig is the configuration of scheduled etcd snapshots.
+type EtcdSnapshotConfig struct {
+	// Interval is the time between two snapshots.
+	Interval time.Duration
+	// Retention is the number of snapshots to keep.
+	Retention int
+	// S3 is the bucket the snapshots are uploaded to. Snapshots are stored locally if S3 is nil.
+	S3 *EtcdSnapshotS3Config
+}
+
+// EtcdSnapshotS3Config is an S3-compatible bucket to upload etcd snapshots to.
+type EtcdSnapshotS3Config struct {
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	Prefix          string `yaml:"prefix"`
+	AccessKeyID     string `yaml:"access-key-id"`
+	SecretAccessKey string `yaml:"secret-access-key"`
+	CACert          string `yaml:"ca-crt,omitempty"`
+}
+
+// ParseEtcdSnapshotConfig parses the value of the AnnotationEtcdSnapshots annotation.
+func ParseEtcdSnapshotConfig(value string) (EtcdSnapshotConfig, error) {
+	var in struct {
+		Interval  string                `yaml:"interval"`
+		Retention *int                  `yaml:"retention"`
+		S3        *EtcdSnapshotS3Config `yaml:"s3"`
+	}
+	if err := yaml.UnmarshalStrict([]byte(value), &in); err != nil {
+		return EtcdSnapshotConfig{}, fmt.Errorf("failed to parse etcd snapshot config: %w", err)
+	}
+
+	config := EtcdSnapshotConfig{Interval: 24 * time.Hour, Retention: 7, S3: in.S3}
+	if in.Interval != "" {
+		interval, err := parseLifetime(in.Interval)
+		if err != nil {
+			return EtcdSnapshotConfig{}, fmt.Errorf("invalid interval %q: %w", in.Interval, err)
+		}
+		if interval < 5*time.Minute {
+			return EtcdSnapshotConfig{}, fmt.Errorf("interval %q must be at least 5m", in.Interval)
+		}
+		config.Interval = interval
+	}
+	if in.Retention != nil {
+		if *in.Retention < 1 {
+			return EtcdSnapshotConfig{}, fmt.Errorf("retention must be at least 1")
+		}
+		config.Retention = *in.Retention
+	}
+	if s3 := in.S3; s3 != nil {
+		if s3.Endpoint == "" || s3.Bucket == "" {
+			return EtcdSnapshotConfig{}, fmt.Errorf("s3 endpoint and bucket must be set")
+		}
+		if s3.AccessKeyID == "" {
+			return EtcdSnapshotConfig{}, fmt.Errorf("s3 access-key-id must be set")
+		}
+	}
+	return config, nil
+}
+
+// EtcdSnapshots returns the configuration of scheduled etcd snapshots, as configured by the AnnotationEtcdSnapshots
+// annotation and the stored S3 secret access key. EtcdSnapshots returns false if the annotation is not set.
+func (c ClusterConfig) EtcdSnapshots() (EtcdSnapshotConfig, bool, error) {
+	v, ok := c.Annotations.Get(AnnotationEtcdSnapshots)
+	if !ok {
+		return EtcdSnapshotConfig{}, false, nil
+	}
+	config, err := ParseEtcdSnapshotConfig(v)
+	if err != nil {
+		return EtcdSnapshotConfig{}, false, err
+	}
+	if s3 := config.S3; s3 != nil {
+		if s3.SecretAccessKey == "" {
+			s3.SecretAccessKey = c.Datastore.GetEtcdSnapshotS3SecretAccessKey()
+		}
+		if s3.SecretAccessKey == "" {
+			return EtcdSnapshotConfig{}, false, fmt.Errorf("s3 secret-access-key must be set")
+		}
+	}
+	return config, true, nil
+}
+
+// SealEtcdSnapshotSecret moves the S3 secret access key out of the AnnotationEtcdSnapshots annotation and into the
+// datastore configuration, so that it is neither returned with the cluster configuration nor recorded in its
+// revisions. The stored secret is cleared if the annotation no longer configures an S3 bucket.
+// SealEtcdSnapshotSecret is applied by MergeClusterConfig.
+func (c *ClusterConfig) SealEtcdSnapshotSecret() error {
+	v, ok := c.Annotations.Get(AnnotationEtcdSnapshots)
+	if !ok {
+		c.Datastore.EtcdSnapshotS3SecretAccessKey = nil
+		return nil
+	}
+
+	// NOTE: Decode into a yaml.MapSlice to keep the order and formatting of the other fields.
+	var in yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(v), &in); err != nil {
+		return fmt.Errorf("failed to parse etcd snapshot config: %w", err)
+	}
+	hasS3 := false
+	for i, item := range in {
+		if item.Key != "s3" {
+			continue
+		}
+		s3, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		hasS3 = true
+		for j, field := range s3 {
+			if field.Key != "secret-access-key" {
+				continue
+			}
+			secret, ok := field.Value.(string)
+			if !ok || secret == "" {
+				return fmt.Errorf("s3 secret-access-key must be a string")
+			}
+			c.Datastore.EtcdSnapshotS3SecretAccessKey = &secret
+			in[i].Value = append(s3[:j:j], s3[j+1:]...)
+
+			b, err := yaml.Marshal(in)
+			if err != nil {
+				return fmt.Errorf("failed to encode etcd snapshot config: %w", err)
+			}
+			// NOTE: The annotations may be shared with the configurations that were merged, so copy them.
+			c.Annotations = maps.Clone(c.Annotations)
+			c.Annotations[AnnotationEtcdSnapshots] = string(b)
+			return nil
+		}
+	}
+	if !hasS3 {
+		c.Datastore.EtcdSnapshotS3SecretAccessKey = nil
+	}
+	return nil
+}
+
+// UnsealEtcdSnapshotSecret puts the stored S3 secret access key back into the AnnotationEtcdSnapshots annotation,
+// reverting SealEtcdSnapshotSecret. It carries the secret over to configurations without the datastore settings, e.g.
+// the bootstrap configuration of a cluster bundle, which seals it again once it is stored.
+func (c *ClusterConfig) UnsealEtcdSnapshotSecret() error {
+	secret := c.Datastore.GetEtcdSnapshotS3SecretAccessKey()
+	v, ok := c.Annotations.Get(AnnotationEtcdSnapshots)
+	if !ok || secret == "" {
+		return nil
+	}
+
+	var in yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(v), &in); err != nil {
+		return fmt.Errorf("failed to parse etcd snapshot config: %w", err)
+	}
+	for i, item := range in {
+		if item.Key != "s3" {
+			continue
+		}
+		s3, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		s3 = slices.DeleteFunc(slices.Clone(s3), func(field yaml.MapItem) bool { return field.Key == "secret-access-key" })
+		in[i].Value = append(s3, yaml.MapItem{Key: "secret-access-key", Value: secret})
+
+		b, err := yaml.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode etcd snapshot config: %w", err)
+		}
+		c.Annotations = maps.Clone(c.Annotations)
+		c.Annotations[AnnotationEtcdSnapshots] = string(b)
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	"github.com/canonical/k8sd/pkg/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseEtcdSnapshotConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		g := NewWithT(t)
+
+		config, err := types.ParseEtcdSnapshotConfig("{}")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(config).To(Equal(types.EtcdSnapshotConfig{Interval: 24 * time.Hour, Retention: 7}))
+
+		config, ok, err := types.ClusterConfig{}.EtcdSnapshots()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ok).To(BeFalse())
+		g.Expect(config).To(BeZero())
+	})
+
+	t.Run("S3", func(t *testing.T) {
+		g := NewWithT(t)
+
+		config, err := types.ParseEtcdSnapshotConfig(`
+interval: 2d
+retention: 14
+s3:
+  endpoint: http://10.0.0.10:9000
+  bucket: backups
+  prefix: cluster-a/
+  access-key-id: k8s
+  secret-access-key: secret
+`)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(config).To(Equal(types.EtcdSnapshotConfig{
+			Interval:  48 * time.Hour,
+			Retention: 14,
+			S3: &types.EtcdSnapshotS3Config{
+				Endpoint:        "http://10.0.0.10:9000",
+				Bucket:          "backups",
+				Prefix:          "cluster-a/",
+				AccessKeyID:     "k8s",
+				SecretAccessKey: "secret",
+			},
+		}))
+	})
+
+	for _, tc := range []struct {
+		name  string
+		value string
+	}{
+		{name: "InvalidYAML", value: "interval: ["},
+		{name: "UnknownField", value: "schedule: 6h"},
+		{name: "InvalidInterval", value: "interval: daily"},
+		{name: "ShortInterval", value: "interval: 1m"},
+		{name: "ZeroRetention", value: "retention: 0"},
+		{name: "MissingBucket", value: "s3: {endpoint: http://minio:9000, access-key-id: a, secret-access-key: s}"},
+		{name: "MissingCredentials", value: "s3: {endpoint: http://minio:9000, bucket: b}"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := types.ParseEtcdSnapshotConfig(tc.value)
+			g.Expect(err).To(HaveOccurred())
+
+			// invalid annotations are rejected when validating the cluster config
+			config := types.ClusterConfig{
+				Network: types.Network{
+					PodCIDR:     utils.Pointer("10.1.0.0/16"),
+					ServiceCIDR: utils.Pointer("10.2.0.0/16"),
+				},
+				Annotations: types.Annotations{types.AnnotationEtcdSnapshots: tc.value},
+			}
+			g.Expect(config.Validate()).To(HaveOccurred())
+		})
+	}
+}
+
+func TestEtcdSnapshotSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	old := types.ClusterConfig{}
+	old.SetDefaults()
+
+	merged, err := types.MergeClusterConfig(old, types.ClusterConfig{
+		Annotations: types.Annotations{types.AnnotationEtcdSnapshots: `
+interval: 6h
+s3:
+  endpoint: http://10.0.0.10:9000
+  bucket: backups
+  access-key-id: k8s
+  secret-access-key: secret
+`},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// the secret is moved out of the annotation, so that it is not echoed back or recorded in revisions
+	g.Expect(merged.Annotations[types.AnnotationEtcdSnapshots]).ToNot(ContainSubstring("secret"))
+	g.Expect(merged.Annotations[types.AnnotationEtcdSnapshots]).To(ContainSubstring("access-key-id: k8s"))
+	g.Expect(merged.Datastore.GetEtcdSnapshotS3SecretAccessKey()).To(Equal("secret"))
+	revisionConfig, err := merged.RevisionConfig()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(revisionConfig.Datastore.EtcdSnapshotS3SecretAccessKey).To(BeNil())
+	g.Expect(revisionConfig.Annotations[types.AnnotationEtcdSnapshots]).ToNot(ContainSubstring("secret"))
+
+	// the secret is never recorded if it cannot be sealed
+	_, err = types.ClusterConfig{Annotations: types.Annotations{types.AnnotationEtcdSnapshots: "s3: {secret-access-key: [secret]}"}}.RevisionConfig()
+	g.Expect(err).To(HaveOccurred())
+
+	config, ok, err := merged.EtcdSnapshots()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+	g.Expect(config.Interval).To(Equal(6 * time.Hour))
+	g.Expect(config.S3.SecretAccessKey).To(Equal("secret"))
+
+	t.Run("Update", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// the stored secret is kept when the annotation is updated without it
+		updated, err := types.MergeClusterConfig(merged, types.ClusterConfig{
+			Annotations: types.Annotations{types.AnnotationEtcdSnapshots: "s3: {endpoint: http://10.0.0.10:9000, bucket: other, access-key-id: k8s}"},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		config, _, err := updated.EtcdSnapshots()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(config.S3.Bucket).To(Equal("other"))
+		g.Expect(config.S3.SecretAccessKey).To(Equal("secret"))
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		g := NewWithT(t)
+
+		removed, err := types.MergeClusterConfig(merged, types.ClusterConfig{
+			Annotations: types.Annotations{types.AnnotationEtcdSnapshots: "-"},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(removed.Datastore.EtcdSnapshotS3SecretAccessKey).To(BeNil())
+	})
+
+	t.Run("MissingSecret", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := types.MergeClusterConfig(old, types.ClusterConfig{
+			Annotations: types.Annotations{types.AnnotationEtcdSnapshots: "s3: {endpoint: http://10.0.0.10:9000, bucket: b, access-key-id: k8s}"},
+		})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
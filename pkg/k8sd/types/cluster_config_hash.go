@@ -0,0 +1,19 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Hash returns a SHA256 hash of the cluster configuration.
+// The hash is stable for identical configurations and can be used to identify the configuration that was applied.
+func (c ClusterConfig) Hash() (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cluster configuration: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
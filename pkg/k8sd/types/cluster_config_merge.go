@@ -45,6 +45,7 @@ func MergeClusterConfig(existing ClusterConfig, new ClusterConfig) (ClusterConfi
 		{name: "etcd CA key", val: &config.Datastore.EtcdCAKey, old: existing.Datastore.EtcdCAKey, new: new.Datastore.EtcdCAKey},
 		{name: "etcd apiserver client certificate", val: &config.Datastore.EtcdAPIServerClientCert, old: existing.Datastore.EtcdAPIServerClientCert, new: new.Datastore.EtcdAPIServerClientCert, allowChange: true},
 		{name: "etcd apiserver client key", val: &config.Datastore.EtcdAPIServerClientKey, old: existing.Datastore.EtcdAPIServerClientKey, new: new.Datastore.EtcdAPIServerClientKey, allowChange: true},
+		{name: "etcd snapshot S3 secret access key", val: &config.Datastore.EtcdSnapshotS3SecretAccessKey, old: existing.Datastore.EtcdSnapshotS3SecretAccessKey, new: new.Datastore.EtcdSnapshotS3SecretAccessKey, allowChange: true},
 		// network
 		{name: "pod CIDR", val: &config.Network.PodCIDR, old: existing.Network.PodCIDR, new: new.Network.PodCIDR},
 		{name: "service CIDR", val: &config.Network.ServiceCIDR, old: existing.Network.ServiceCIDR, new: new.Network.ServiceCIDR},
@@ -147,8 +148,17 @@ func MergeClusterConfig(existing ClusterConfig, new ClusterConfig) (ClusterConfi
 		}
 	}
 
+	// merge add-ons
+	if config.Addons, err = mergeHelmAddonsField(existing.Addons, new.Addons); err != nil {
+		return ClusterConfig{}, fmt.Errorf("prevented update of add-ons: %w", err)
+	}
+	config.Addons.SetDefaults()
+
 	// merge annotations
 	config.Annotations = mergeAnnotationsField(existing.Annotations, new.Annotations)
+	if err := config.SealEtcdSnapshotSecret(); err != nil {
+		return ClusterConfig{}, fmt.Errorf("invalid %s annotation: %w", AnnotationEtcdSnapshots, err)
+	}
 
 	if err := config.Validate(); err != nil {
 		return ClusterConfig{}, fmt.Errorf("updated cluster configuration is not valid: %w", err)
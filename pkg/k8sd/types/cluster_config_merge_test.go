@@ -234,6 +234,21 @@ func TestMergeClusterConfig_Scenarios(t *testing.T) {
 		expectMerged types.ClusterConfig
 		expectErr    bool
 	}{
+		{
+			name: "Addons/SetDefaultsOnUpdate",
+			old: types.ClusterConfig{
+				Addons: types.HelmAddons{"a": {Enabled: utils.Pointer(true), ChartPath: utils.Pointer("/charts/a"), Namespace: utils.Pointer("ns1")}},
+			},
+			new: types.ClusterConfig{
+				Addons: types.HelmAddons{"b": {ChartPath: utils.Pointer("/charts/b")}},
+			},
+			expectMerged: types.ClusterConfig{
+				Addons: types.HelmAddons{
+					"a": {Enabled: utils.Pointer(true), ChartPath: utils.Pointer("/charts/a"), Namespace: utils.Pointer("ns1")},
+					"b": {Enabled: utils.Pointer(false), ChartPath: utils.Pointer("/charts/b"), Namespace: utils.Pointer("default")},
+				},
+			},
+		},
 		{
 			name: "Kubelet/AllowSetClusterDNS/EnableDNSAfter",
 			old: types.ClusterConfig{
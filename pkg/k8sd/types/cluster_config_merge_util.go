@@ -3,6 +3,8 @@ package types
 import (
 	"fmt"
 	"slices"
+
+	"github.com/canonical/k8sd/pkg/utils"
 )
 
 func mergeField[T comparable](old *T, new *T, allowChange bool) (*T, error) {
@@ -66,6 +68,73 @@ func mergeAnnotationsField(old Annotations, new Annotations) Annotations {
 	return Annotations(m)
 }
 
+func mergeHelmAddonsField(old HelmAddons, new HelmAddons) (HelmAddons, error) {
+	// new value is not set, use old
+	if new == nil {
+		return old, nil
+	}
+
+	// merge add-ons, start from old and then merge the fields of new
+	// if an add-on is marked for removal, keep it as removed until its Helm release is uninstalled and it is purged
+	m := make(HelmAddons, len(old)+len(new))
+	for name, addon := range old {
+		m[name] = addon
+	}
+	for name, addon := range new {
+		existing, exists := m[name]
+
+		if addon.GetPurge() {
+			if existing.GetRemoved() {
+				delete(m, name)
+			}
+			continue
+		}
+		if addon.GetRemove() {
+			if !exists || existing.GetRemoved() {
+				continue
+			}
+			// the Helm release of an enabled add-on would be left behind, the add-on must be disabled first
+			if existing.GetEnabled() {
+				return nil, fmt.Errorf("add-on %q must be disabled before it is removed", name)
+			}
+			m[name] = HelmAddon{
+				Enabled:   utils.Pointer(false),
+				ChartPath: existing.ChartPath,
+				Namespace: existing.Namespace,
+				Removed:   utils.Pointer(true),
+			}
+			continue
+		}
+		if existing.GetRemoved() {
+			return nil, fmt.Errorf("add-on %q is being removed, wait until its Helm release is uninstalled", name)
+		}
+
+		var (
+			merged HelmAddon
+			err    error
+		)
+		if merged.Enabled, err = mergeField(existing.Enabled, addon.Enabled, true); err != nil {
+			return nil, fmt.Errorf("add-on %q enabled: %w", name, err)
+		}
+		if merged.ChartPath, err = mergeField(existing.ChartPath, addon.ChartPath, true); err != nil {
+			return nil, fmt.Errorf("add-on %q chart path: %w", name, err)
+		}
+		// the namespace of an installed release cannot be changed, the add-on must be disabled first
+		if merged.Namespace, err = mergeField(existing.Namespace, addon.Namespace, !existing.GetEnabled()); err != nil {
+			return nil, fmt.Errorf("add-on %q namespace: %w", name, err)
+		}
+		// values are replaced as a whole
+		merged.Values = existing.Values
+		if addon.Values != nil {
+			merged.Values = addon.Values
+		}
+
+		m[name] = merged
+	}
+
+	return m, nil
+}
+
 func getField[T any](val *T) T {
 	if val != nil {
 		return *val
@@ -169,3 +169,108 @@ func Test_mergeAnnotationsField(t *testing.T) {
 		})
 	}
 }
+
+func Test_mergeHelmAddonsField(t *testing.T) {
+	chart := HelmAddon{Enabled: utils.Pointer(true), ChartPath: utils.Pointer("/charts/a"), Namespace: utils.Pointer("ns1"), Values: map[string]any{"k1": "v1"}}
+	removed := HelmAddon{Enabled: utils.Pointer(false), ChartPath: utils.Pointer("/charts/b"), Namespace: utils.Pointer("ns1"), Removed: utils.Pointer(true)}
+
+	for _, tc := range []struct {
+		name      string
+		old       HelmAddons
+		new       HelmAddons
+		expectErr bool
+		expectVal HelmAddons
+	}{
+		{name: "keep-empty"},
+		{name: "set-empty", new: HelmAddons{"a": chart}, expectVal: HelmAddons{"a": chart}},
+		{name: "keep-old", old: HelmAddons{"a": chart}, expectVal: HelmAddons{"a": chart}},
+		{
+			name:      "add",
+			old:       HelmAddons{"a": chart},
+			new:       HelmAddons{"b": {ChartPath: utils.Pointer("/charts/b")}},
+			expectVal: HelmAddons{"a": chart, "b": {ChartPath: utils.Pointer("/charts/b")}},
+		},
+		{
+			name:      "update-fields",
+			old:       HelmAddons{"a": chart},
+			new:       HelmAddons{"a": {ChartPath: utils.Pointer("/charts/a2"), Values: map[string]any{"k2": "v2"}}},
+			expectVal: HelmAddons{"a": {Enabled: utils.Pointer(true), ChartPath: utils.Pointer("/charts/a2"), Namespace: utils.Pointer("ns1"), Values: map[string]any{"k2": "v2"}}},
+		},
+		{
+			name:      "update-namespace-while-enabled",
+			old:       HelmAddons{"a": chart},
+			new:       HelmAddons{"a": {Namespace: utils.Pointer("ns2")}},
+			expectErr: true,
+		},
+		{
+			name:      "update-namespace-and-disable",
+			old:       HelmAddons{"a": chart},
+			new:       HelmAddons{"a": {Enabled: utils.Pointer(false), Namespace: utils.Pointer("ns2")}},
+			expectErr: true,
+		},
+		{
+			name:      "update-namespace-while-disabled",
+			old:       HelmAddons{"a": {Enabled: utils.Pointer(false), ChartPath: utils.Pointer("/charts/a"), Namespace: utils.Pointer("ns1")}},
+			new:       HelmAddons{"a": {Enabled: utils.Pointer(true), Namespace: utils.Pointer("ns2")}},
+			expectVal: HelmAddons{"a": {Enabled: utils.Pointer(true), ChartPath: utils.Pointer("/charts/a"), Namespace: utils.Pointer("ns2")}},
+		},
+		{
+			name:      "remove",
+			old:       HelmAddons{"a": chart, "b": {Enabled: utils.Pointer(false), ChartPath: utils.Pointer("/charts/b"), Namespace: utils.Pointer("ns1"), Values: map[string]any{"k": "v"}}},
+			new:       HelmAddons{"b": {Remove: utils.Pointer(true)}},
+			expectVal: HelmAddons{"a": chart, "b": removed},
+		},
+		{
+			name:      "remove-removed",
+			old:       HelmAddons{"a": chart, "b": removed},
+			new:       HelmAddons{"b": {Remove: utils.Pointer(true)}},
+			expectVal: HelmAddons{"a": chart, "b": removed},
+		},
+		{
+			name:      "update-removed",
+			old:       HelmAddons{"b": removed},
+			new:       HelmAddons{"b": {Enabled: utils.Pointer(true)}},
+			expectErr: true,
+		},
+		{
+			name:      "purge",
+			old:       HelmAddons{"a": chart, "b": removed},
+			new:       HelmAddons{"b": {Purge: utils.Pointer(true)}},
+			expectVal: HelmAddons{"a": chart},
+		},
+		{
+			name:      "purge-not-removed",
+			old:       HelmAddons{"a": chart},
+			new:       HelmAddons{"a": {Purge: utils.Pointer(true)}},
+			expectVal: HelmAddons{"a": chart},
+		},
+		{
+			name:      "remove-while-enabled",
+			old:       HelmAddons{"a": chart},
+			new:       HelmAddons{"a": {Remove: utils.Pointer(true)}},
+			expectErr: true,
+		},
+		{
+			name:      "remove-missing",
+			old:       HelmAddons{"a": chart},
+			new:       HelmAddons{"b": {Remove: utils.Pointer(true)}},
+			expectVal: HelmAddons{"a": chart},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			result, err := mergeHelmAddonsField(tc.old, tc.new)
+			switch {
+			case tc.expectErr:
+				g.Expect(err).To(HaveOccurred())
+			case tc.expectVal != nil:
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).To(Equal(tc.expectVal))
+			default:
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).To(BeNil())
+			}
+		})
+	}
+}
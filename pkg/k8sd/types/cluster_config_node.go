@@ -40,7 +40,7 @@ func (d configMapData) withoutSignature() configMapData {
 }
 
 // ClusterConfigToConfigMap converts ClusterConfig to a signed configmap.
-// Only Kubelet fields and Network.KubeProxyEnabled are included.
+// Only Kubelet fields, Network.KubeProxyEnabled and the CA certificates are included.
 func ClusterConfigToConfigMap(config ClusterConfig, key *rsa.PrivateKey) (map[string]string, error) {
 	data := make(configMapData)
 
@@ -65,6 +65,14 @@ func ClusterConfigToConfigMap(config ClusterConfig, key *rsa.PrivateKey) (map[st
 	// Network fields
 	data["kube-proxy-enabled"] = fmt.Sprintf("%t", config.Network.GetKubeProxyEnabled())
 
+	// CA certificates, so that worker nodes pick up the trust bundles during a CA rotation
+	if v := config.Certificates.CACert; v != nil {
+		data["ca-crt"] = *v
+	}
+	if v := config.Certificates.ClientCACert; v != nil {
+		data["client-ca-crt"] = *v
+	}
+
 	// Sign configmap data
 	if key != nil {
 		hash, err := data.hash()
@@ -82,7 +90,7 @@ func ClusterConfigToConfigMap(config ClusterConfig, key *rsa.PrivateKey) (map[st
 }
 
 // ConfigMapToClusterConfig parses and verifies a signed configmap.
-// Returns ClusterConfig with Kubelet and Network.KubeProxyEnabled populated.
+// Returns ClusterConfig with Kubelet, Network.KubeProxyEnabled and the CA certificates populated.
 func ConfigMapToClusterConfig(m map[string]string, key *rsa.PublicKey) (ClusterConfig, error) {
 	var config ClusterConfig
 
@@ -136,5 +144,13 @@ func ConfigMapToClusterConfig(m map[string]string, key *rsa.PublicKey) (ClusterC
 		config.Network.KubeProxyEnabled = &kubeProxyEnabled
 	}
 
+	// Parse CA certificates
+	if v, ok := m["ca-crt"]; ok {
+		config.Certificates.CACert = &v
+	}
+	if v, ok := m["client-ca-crt"]; ok {
+		config.Certificates.ClientCACert = &v
+	}
+
 	return config, nil
 }
@@ -93,6 +93,23 @@ func TestClusterConfigConfigMap(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "CertificateAuthorities",
+			configmap: map[string]string{
+				"ca-crt":             "CA DATA",
+				"client-ca-crt":      "CLIENT CA DATA",
+				"kube-proxy-enabled": "true",
+			},
+			config: types.ClusterConfig{
+				Certificates: types.Certificates{
+					CACert:       utils.Pointer("CA DATA"),
+					ClientCACert: utils.Pointer("CLIENT CA DATA"),
+				},
+				Network: types.Network{
+					KubeProxyEnabled: utils.Pointer(true),
+				},
+			},
+		},
 		{
 			name: "EmptyKubeletValues",
 			configmap: map[string]string{
@@ -131,6 +148,7 @@ func TestClusterConfigConfigMap(t *testing.T) {
 				g.Expect(err).To(Not(HaveOccurred()))
 				g.Expect(config.Kubelet).To(Equal(tc.config.Kubelet))
 				g.Expect(config.Network.KubeProxyEnabled).To(Equal(tc.config.Network.KubeProxyEnabled))
+				g.Expect(config.Certificates).To(Equal(tc.config.Certificates))
 			})
 		})
 	}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// ClusterConfigRevision is an accepted revision of the cluster configuration.
+type ClusterConfigRevision struct {
+	// Revision is the revision number. Revision numbers increase monotonically and are never reused.
+	Revision int64
+	// Config is the cluster configuration of the revision, as returned by ClusterConfig.RevisionConfig.
+	Config ClusterConfig
+	// RequestedBy identifies who requested the configuration change.
+	RequestedBy string
+	// Reason is an optional description of the change, e.g. "rollback to revision 3".
+	Reason string
+	// CreatedAt is the time the revision was stored.
+	CreatedAt time.Time
+}
+
+// RevisionConfig returns the part of the cluster configuration that is recorded in its revisions.
+// Revisions only contain the user-facing configuration and the add-ons. Certificates, private keys and the datastore
+// configuration are never recorded, as revisions are kept after the credentials are rotated. This includes the S3
+// secret access key of the etcd snapshots, see SealEtcdSnapshotSecret. RevisionConfig fails if the secret cannot be
+// sealed, so that it is never recorded.
+func (c ClusterConfig) RevisionConfig() (ClusterConfig, error) {
+	sealed := ClusterConfig{Annotations: c.Annotations}
+	if err := sealed.SealEtcdSnapshotSecret(); err != nil {
+		return ClusterConfig{}, fmt.Errorf("failed to seal etcd snapshot secret: %w", err)
+	}
+	return ClusterConfig{
+		Kubelet: Kubelet{
+			CloudProvider: c.Kubelet.CloudProvider,
+			ClusterDNS:    c.Kubelet.ClusterDNS,
+			ClusterDomain: c.Kubelet.ClusterDomain,
+		},
+		Network:       c.Network,
+		DNS:           c.DNS,
+		Ingress:       c.Ingress,
+		LoadBalancer:  c.LoadBalancer,
+		Gateway:       c.Gateway,
+		LocalStorage:  c.LocalStorage,
+		MetricsServer: c.MetricsServer,
+		Addons:        c.Addons,
+		Annotations:   sealed.Annotations,
+	}, nil
+}
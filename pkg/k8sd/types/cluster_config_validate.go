@@ -9,6 +9,8 @@ import (
 
 	metallbAnnotations "github.com/canonical/k8s-snap-api/v2/api/annotations/metallb"
 	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 func validateCIDRs(cidrString string) error {
@@ -160,6 +162,30 @@ func (c *ClusterConfig) Validate() error {
 		}
 	}
 
+	// check: key algorithm annotation must be a supported algorithm
+	if v, ok := c.Annotations.Get(AnnotationKeyAlgorithm); ok {
+		if _, err := pkiutil.ParseKeyAlgorithm(v); err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", AnnotationKeyAlgorithm, err)
+		}
+	}
+
+	// check: revocation webhook annotation must be a boolean
+	if v, ok := c.Annotations.Get(AnnotationRevocationWebhook); ok && v != "true" && v != "false" {
+		return fmt.Errorf("invalid %s annotation %q, must be \"true\" or \"false\"", AnnotationRevocationWebhook, v)
+	}
+
+	// check: certificate profiles annotation must be valid
+	if v, ok := c.Annotations.Get(AnnotationCertificateProfiles); ok {
+		if _, err := ParseCertificateProfiles(v); err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", AnnotationCertificateProfiles, err)
+		}
+	}
+
+	// check: etcd snapshots annotation must be valid
+	if _, _, err := c.EtcdSnapshots(); err != nil {
+		return fmt.Errorf("invalid %s annotation: %w", AnnotationEtcdSnapshots, err)
+	}
+
 	// check: local-storage.reclaim-policy should be one of 3 values
 	switch c.LocalStorage.GetReclaimPolicy() {
 	case "", "Retain", "Delete":
@@ -188,5 +214,20 @@ func (c *ClusterConfig) Validate() error {
 		}
 	}
 
+	// check: add-on names are valid release names, and enabled add-ons have a chart
+	for name, addon := range c.Addons {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return fmt.Errorf("add-on name %q is invalid: %s", name, strings.Join(errs, ", "))
+		}
+		if addon.GetEnabled() && addon.GetChartPath() == "" {
+			return fmt.Errorf("addons.%s.chart-path must be set when the add-on is enabled", name)
+		}
+		if v := addon.GetNamespace(); v != "" {
+			if errs := validation.IsDNS1123Label(v); len(errs) > 0 {
+				return fmt.Errorf("addons.%s.namespace %q is invalid: %s", name, v, strings.Join(errs, ", "))
+			}
+		}
+	}
+
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	metallbAnnotations "github.com/canonical/k8s-snap-api/v2/api/annotations/metallb"
 	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
 	. "github.com/onsi/gomega"
 )
 
@@ -288,3 +289,130 @@ func TestValidateBGPMode(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAddons(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		addons    types.HelmAddons
+		expectErr bool
+	}{
+		{
+			name:   "Empty",
+			addons: nil,
+		},
+		{
+			name:   "Enabled",
+			addons: types.HelmAddons{"my-chart": {Enabled: utils.Pointer(true), ChartPath: utils.Pointer("/opt/charts/my-chart"), Namespace: utils.Pointer("kube-system")}},
+		},
+		{
+			name:   "Disabled/NoChartPath",
+			addons: types.HelmAddons{"my-chart": {Enabled: utils.Pointer(false)}},
+		},
+		{
+			name:      "Enabled/NoChartPath",
+			addons:    types.HelmAddons{"my-chart": {Enabled: utils.Pointer(true)}},
+			expectErr: true,
+		},
+		{
+			name:      "InvalidName",
+			addons:    types.HelmAddons{"My_Chart": {ChartPath: utils.Pointer("/opt/charts/my-chart")}},
+			expectErr: true,
+		},
+		{
+			name:      "InvalidNamespace",
+			addons:    types.HelmAddons{"my-chart": {ChartPath: utils.Pointer("/opt/charts/my-chart"), Namespace: utils.Pointer("Kube.System")}},
+			expectErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			config := types.ClusterConfig{
+				Network: types.Network{
+					PodCIDR:     utils.Pointer("10.1.0.0/16"),
+					ServiceCIDR: utils.Pointer("10.2.0.0/16"),
+				},
+				Addons: tc.addons,
+			}
+			if tc.expectErr {
+				g.Expect(config.Validate()).To(HaveOccurred())
+			} else {
+				g.Expect(config.Validate()).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestValidateKeyAlgorithm(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		annotations types.Annotations
+		expectAlg   pkiutil.KeyAlgorithm
+		expectErr   bool
+	}{
+		{name: "Default", expectAlg: pkiutil.KeyAlgorithmRSA2048},
+		{name: "ECDSA", annotations: types.Annotations{types.AnnotationKeyAlgorithm: "ecdsa-p256"}, expectAlg: pkiutil.KeyAlgorithmECDSAP256},
+		{name: "Ed25519", annotations: types.Annotations{types.AnnotationKeyAlgorithm: "ed25519"}, expectAlg: pkiutil.KeyAlgorithmEd25519},
+		{name: "Invalid", annotations: types.Annotations{types.AnnotationKeyAlgorithm: "dsa"}, expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			config := types.ClusterConfig{
+				Network: types.Network{
+					PodCIDR:     utils.Pointer("10.1.0.0/16"),
+					ServiceCIDR: utils.Pointer("10.2.0.0/16"),
+				},
+				Annotations: tc.annotations,
+			}
+			if tc.expectErr {
+				g.Expect(config.Validate()).To(HaveOccurred())
+			} else {
+				g.Expect(config.Validate()).ToNot(HaveOccurred())
+				g.Expect(config.KeyAlgorithm()).To(Equal(tc.expectAlg))
+			}
+		})
+	}
+
+	t.Run("Bootstrap", func(t *testing.T) {
+		g := NewWithT(t)
+
+		config := types.ClusterConfig{}
+		config.SetBootstrapKeyAlgorithm()
+		g.Expect(config.KeyAlgorithm()).To(Equal(pkiutil.KeyAlgorithmECDSAP256))
+
+		// a configured key algorithm is kept
+		config = types.ClusterConfig{Annotations: types.Annotations{types.AnnotationKeyAlgorithm: "rsa-4096"}}
+		config.SetBootstrapKeyAlgorithm()
+		g.Expect(config.KeyAlgorithm()).To(Equal(pkiutil.KeyAlgorithmRSA4096))
+	})
+}
+
+func TestValidateRevocationWebhook(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		annotations   types.Annotations
+		expectEnabled bool
+		expectErr     bool
+	}{
+		{name: "Default"},
+		{name: "Enabled", annotations: types.Annotations{types.AnnotationRevocationWebhook: "true"}, expectEnabled: true},
+		{name: "Disabled", annotations: types.Annotations{types.AnnotationRevocationWebhook: "false"}},
+		{name: "Invalid", annotations: types.Annotations{types.AnnotationRevocationWebhook: "yes"}, expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			config := types.ClusterConfig{
+				Network: types.Network{
+					PodCIDR:     utils.Pointer("10.1.0.0/16"),
+					ServiceCIDR: utils.Pointer("10.2.0.0/16"),
+				},
+				Annotations: tc.annotations,
+			}
+			if tc.expectErr {
+				g.Expect(config.Validate()).To(HaveOccurred())
+			} else {
+				g.Expect(config.Validate()).ToNot(HaveOccurred())
+				g.Expect(config.RevocationWebhookEnabled()).To(Equal(tc.expectEnabled))
+			}
+		})
+	}
+}
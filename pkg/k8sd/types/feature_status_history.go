@@ -0,0 +1,65 @@
+package types
+
+import (
+	"time"
+
+	k8sdapi "github.com/canonical/k8sd/pkg/api"
+)
+
+// FeatureStatusOutcome is the outcome of a reconcile attempt or health check of a feature.
+type FeatureStatusOutcome string
+
+const (
+	// FeatureStatusOutcomeSucceeded means that the feature configuration was applied successfully.
+	FeatureStatusOutcomeSucceeded FeatureStatusOutcome = "succeeded"
+	// FeatureStatusOutcomeFailed means that the feature configuration could not be applied.
+	FeatureStatusOutcomeFailed FeatureStatusOutcome = "failed"
+	// FeatureStatusOutcomeWaiting means that the feature is waiting for its dependencies.
+	FeatureStatusOutcomeWaiting FeatureStatusOutcome = "waiting"
+	// FeatureStatusOutcomeUnhealthy means that the health check of an applied feature started failing.
+	FeatureStatusOutcomeUnhealthy FeatureStatusOutcome = "unhealthy"
+	// FeatureStatusOutcomeRecovered means that the health check of an unhealthy feature passes again.
+	FeatureStatusOutcomeRecovered FeatureStatusOutcome = "recovered"
+)
+
+// FeatureStatusHistoryEntry records a reconcile attempt or a health change of a feature.
+type FeatureStatusHistoryEntry struct {
+	// Outcome is the outcome of the attempt.
+	Outcome FeatureStatusOutcome
+	// Status is the feature status that was reported.
+	Status FeatureStatus
+	// Error is the error of a failed attempt, if any.
+	Error string
+	// ConfigHash is the hash of the cluster configuration that was applied (see ClusterConfig.Hash).
+	// ConfigHash is empty for health changes.
+	ConfigHash string
+	// StartedAt is the time the attempt started.
+	StartedAt time.Time
+	// Duration is the duration of the attempt.
+	// For repeated entries, Duration is the duration of the latest attempt.
+	Duration time.Duration
+	// Count is the number of consecutive attempts with the same outcome and status.
+	Count int
+	// LastSeenAt is the time the latest repeated attempt started. LastSeenAt is zero if the entry was not repeated.
+	LastSeenAt time.Time
+}
+
+// Repeats reports whether the entry o has the same outcome, status, error and configuration as e, so that it can be
+// recorded as a repetition of e.
+func (e FeatureStatusHistoryEntry) Repeats(o FeatureStatusHistoryEntry) bool {
+	return e.Outcome == o.Outcome && e.Status.Enabled == o.Status.Enabled && e.Status.Message == o.Status.Message &&
+		e.Status.Version == o.Status.Version && e.Error == o.Error && e.ConfigHash == o.ConfigHash
+}
+
+func (e FeatureStatusHistoryEntry) ToAPI() k8sdapi.FeatureStatusHistoryEntry {
+	return k8sdapi.FeatureStatusHistoryEntry{
+		Outcome:    string(e.Outcome),
+		Status:     e.Status.ToAPI(),
+		Error:      e.Error,
+		ConfigHash: e.ConfigHash,
+		StartedAt:  e.StartedAt,
+		Duration:   e.Duration,
+		Count:      e.Count,
+		LastSeenAt: e.LastSeenAt,
+	}
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+)
+
+// IssuedCertificate is a leaf certificate issued by k8sd, which can be revoked.
+// Certificates issued for a node are revoked when the node is removed.
+type IssuedCertificate struct {
+	// Serial is the serial number of the certificate, in hex.
+	Serial string
+	// Fingerprint is the SHA256 fingerprint of the certificate, in hex.
+	Fingerprint string
+	// Node is the name of the node that the certificate was issued for.
+	// Node is empty for certificates that are not issued for a node (e.g. user kubeconfigs).
+	Node string
+	// Name is the name of the certificate, e.g. "kubelet", "kubelet.conf" or "kubeconfig".
+	Name string
+	// Subject is the common name of the certificate, which is the Kubernetes username for client certificates.
+	Subject string
+	// NotAfter is the expiry of the certificate.
+	NotAfter time.Time
+	// RevokedAt is the time the certificate was revoked. RevokedAt is zero for certificates that are not revoked.
+	RevokedAt time.Time
+}
+
+// NewIssuedCertificate returns the IssuedCertificate for the (first) certificate of a PEM bundle.
+func NewIssuedCertificate(node string, name string, certPEM string) (IssuedCertificate, error) {
+	cert, _, err := pkiutil.LoadCertificate(certPEM, "")
+	if err != nil {
+		return IssuedCertificate{}, fmt.Errorf("failed to parse certificate %s: %w", name, err)
+	}
+	fingerprint := sha256.Sum256(cert.Raw)
+	return IssuedCertificate{
+		Serial:      cert.SerialNumber.Text(16),
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+		Node:        node,
+		Name:        name,
+		Subject:     cert.Subject.CommonName,
+		NotAfter:    cert.NotAfter,
+	}, nil
+}
+
+// NormalizeSerial returns a serial number in hex in the format of IssuedCertificate.Serial (lower case, without
+// leading zeros). Colons and a "0x" prefix are ignored, e.g. "0x0A:1B" is normalized to "a1b".
+// NormalizeSerial returns false if the serial number is not valid hex.
+func NormalizeSerial(serial string) (string, bool) {
+	serial = strings.ReplaceAll(strings.ToLower(serial), ":", "")
+	serial = strings.TrimPrefix(serial, "0x")
+	n, ok := new(big.Int).SetString(serial, 16)
+	if !ok || n.Sign() < 0 {
+		return "", false
+	}
+	return n.Text(16), true
+}
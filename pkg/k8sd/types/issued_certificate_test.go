@@ -0,0 +1,35 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/k8sd/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestNormalizeSerial(t *testing.T) {
+	for _, tc := range []struct {
+		serial    string
+		expected  string
+		expectErr bool
+	}{
+		{serial: "a1b", expected: "a1b"},
+		{serial: "A1B", expected: "a1b"},
+		{serial: "0x0A1B", expected: "a1b"},
+		{serial: "0a:1b", expected: "a1b"},
+		{serial: "xyz", expectErr: true},
+		{serial: "-1", expectErr: true},
+	} {
+		t.Run(tc.serial, func(t *testing.T) {
+			g := NewWithT(t)
+
+			serial, ok := types.NormalizeSerial(tc.serial)
+			if tc.expectErr {
+				g.Expect(ok).To(BeFalse())
+				return
+			}
+			g.Expect(ok).To(BeTrue())
+			g.Expect(serial).To(Equal(tc.expected))
+		})
+	}
+}
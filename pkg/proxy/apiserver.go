@@ -2,11 +2,15 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/canonical/k8sd/pkg/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // APIServerProxy is a TCP proxy that forwards requests to the API Servers of the cluster.
@@ -18,45 +22,84 @@ type APIServerProxy struct {
 	EndpointsConfigFile string
 
 	// RefreshCh signals the proxy to update the list of known kube-apiserver endpoints. If the list
-	// of kube-apiserver endpoints have changed, the endpoints config file is updated, and the proxy
-	// will restart automatically.
+	// of kube-apiserver endpoints have changed, the endpoints config file is updated, and the new
+	// endpoints are applied without restarting the proxy.
 	RefreshCh <-chan time.Time
 
-	// Kubeconfig is the kubeconfig file to use to refresh the kube-apiserver endpoints.
+	// Kubeconfig is the kubeconfig file to use to refresh the kube-apiserver endpoints and check their health.
 	KubeconfigFile string
+
+	// NodeName is the name of the local Kubernetes node. If set, kube-apiserver endpoints in the same
+	// topology zone as the local node are preferred.
+	NodeName string
+
+	// HealthCheckInterval is the interval between health checks of the kube-apiserver endpoints.
+	HealthCheckInterval time.Duration
+
+	// HealthCheckTimeout is the timeout of a single health check.
+	HealthCheckTimeout time.Duration
+
+	// DrainTimeout is the time within which connections to removed kube-apiserver endpoints are closed.
+	// The connections are closed at random times within DrainTimeout, so that clients do not all reconnect
+	// at once. DrainTimeout is also the time that the proxy waits for connections to end when stopping.
+	DrainTimeout time.Duration
+
+	// MetricsAddress is the address to listen for the Prometheus metrics endpoint. Empty to disable.
+	MetricsAddress string
 }
 
 // Run starts the proxy.
 func (p *APIServerProxy) Run(ctx context.Context) error {
 	ctx = log.NewContext(ctx, log.FromContext(ctx).WithName("apiserver-proxy"))
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		default:
-		}
+	healthCheck, err := newReadyzHealthCheck(p.KubeconfigFile)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to configure readyz health checks, falling back to TCP health checks")
+		healthCheck = nil
+	}
 
-		cfg, err := loadEndpointsConfig(p.EndpointsConfigFile)
-		if err != nil {
-			return fmt.Errorf("failed to load endpoints configuration: %w", err)
-		}
+	cfg, err := loadEndpointsConfig(p.EndpointsConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load endpoints configuration: %w", err)
+	}
 
-		proxyCtx, cancel := context.WithCancel(ctx)
-		go p.startProxy(proxyCtx, cancel, cfg.Endpoints)
-		go p.watchForNewEndpoints(proxyCtx, cancel, cfg.Endpoints)
-		<-proxyCtx.Done()
+	if p.MetricsAddress != "" {
+		log.FromContext(ctx).WithValues("address", fmt.Sprintf("http://%s/metrics", p.MetricsAddress)).Info("Enable metrics endpoint")
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+		server := &http.Server{Addr: p.MetricsAddress, Handler: mux}
+
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.FromContext(ctx).Error(err, "Failed to serve metrics endpoint")
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				log.FromContext(ctx).Error(err, "Failed to shut down metrics endpoint")
+			}
+		}()
 	}
-}
 
-func (p *APIServerProxy) startProxy(ctx context.Context, cancel func(), endpoints []string) {
-	if err := startProxy(ctx, p.ListenAddress, endpoints); err != nil {
-		log.FromContext(ctx).Error(err, "Failed to start")
+	updateCh := make(chan Configuration)
+	go p.watchForNewEndpoints(ctx, cfg, updateCh)
+
+	if err := startProxy(ctx, p.ListenAddress, cfg, updateCh, &tcpproxy{
+		MonitorInterval:    p.HealthCheckInterval,
+		HealthCheck:        healthCheck,
+		HealthCheckTimeout: p.HealthCheckTimeout,
+		DrainTimeout:       p.DrainTimeout,
+	}); err != nil {
+		return fmt.Errorf("proxy failed: %w", err)
 	}
-	cancel()
+	return nil
 }
 
-func (p *APIServerProxy) watchForNewEndpoints(ctx context.Context, cancel func(), endpoints []string) {
+func (p *APIServerProxy) watchForNewEndpoints(ctx context.Context, cfg Configuration, updateCh chan<- Configuration) {
 	log := log.FromContext(ctx).WithValues("controller", "watchendpoints")
 	if p.RefreshCh == nil {
 		return
@@ -71,13 +114,15 @@ func (p *APIServerProxy) watchForNewEndpoints(ctx context.Context, cancel func()
 		// TODO: use k8s.GetKubernetesEndpoints instead
 		var (
 			newEndpoints []string
+			server       string
 			err          error
 		)
-		for _, ep := range endpoints {
+		for _, ep := range cfg.Endpoints {
 			epCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 			newEndpoints, err = getKubernetesEndpoints(epCtx, p.KubeconfigFile, ep)
 			cancel()
 			if err == nil {
+				server = ep
 				break
 			}
 			log.Error(err, "Failed to get kubernetes endpoints", "server", ep)
@@ -90,19 +135,37 @@ func (p *APIServerProxy) watchForNewEndpoints(ctx context.Context, cancel func()
 		case len(newEndpoints) == 0:
 			log.Info("Warning: empty list of endpoints, skipping update")
 			continue
-		case len(newEndpoints) == len(endpoints) && reflect.DeepEqual(newEndpoints, endpoints):
+		}
+		sort.Strings(newEndpoints)
+
+		// NOTE: Keep the known zones if they cannot be retrieved, to avoid dropping the zone preference.
+		newCfg := Configuration{Endpoints: newEndpoints, Zone: cfg.Zone, EndpointZones: cfg.EndpointZones}
+		if p.NodeName != "" {
+			zoneCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			zone, endpointZones, err := getZones(zoneCtx, p.KubeconfigFile, server, p.NodeName)
+			cancel()
+			if err != nil {
+				log.Error(err, "Failed to get topology zones", "server", server)
+			} else {
+				newCfg.Zone, newCfg.EndpointZones = zone, endpointZones
+			}
+		}
+
+		if reflect.DeepEqual(newCfg, cfg) {
 			continue
 		}
-		log = log.WithValues("endpoints", newEndpoints)
-		log.Info("Updating endpoints")
+		log.Info("Updating endpoints", "endpoints", newEndpoints, "zone", newCfg.Zone)
 
-		if err := WriteEndpointsConfig(newEndpoints, p.EndpointsConfigFile); err != nil {
-			log.Error(err, "Failed to update configuration file with new endpoints")
+		if err := writeConfig(newCfg, p.EndpointsConfigFile); err != nil {
+			log.Error(err, "Failed to update configuration file with new endpoints", "endpoints", newEndpoints)
 			continue
 		}
 
-		// cancel context in order to restart the proxy
-		cancel()
-		return
+		select {
+		case updateCh <- newCfg:
+			cfg = newCfg
+		case <-ctx.Done():
+			return
+		}
 	}
 }
@@ -12,6 +12,11 @@ import (
 // Configuration is the format of the apiserver proxy endpoints config file.
 type Configuration struct {
 	Endpoints []string `json:"endpoints"`
+	// Zone is the topology zone of the local node.
+	Zone string `json:"zone,omitempty"`
+	// EndpointZones are the topology zones of the endpoints, by host. Endpoints in the zone of the local node are
+	// preferred over endpoints in other zones.
+	EndpointZones map[string]string `json:"endpoint-zones,omitempty"`
 }
 
 func loadEndpointsConfig(file string) (Configuration, error) {
@@ -30,7 +35,11 @@ func loadEndpointsConfig(file string) (Configuration, error) {
 }
 
 func WriteEndpointsConfig(endpoints []string, file string) error {
-	b, err := json.Marshal(Configuration{Endpoints: endpoints})
+	return writeConfig(Configuration{Endpoints: endpoints}, file)
+}
+
+func writeConfig(cfg Configuration, file string) error {
+	b, err := json.Marshal(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal configuration: %w", err)
 	}
@@ -6,12 +6,13 @@ import (
 	"log"
 
 	"github.com/canonical/k8sd/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-func getKubernetesEndpoints(ctx context.Context, kubeconfigFile string, server string) ([]string, error) {
+func newClientset(kubeconfigFile string, server string) (*kubernetes.Clientset, error) {
 	config, err := clientcmd.BuildConfigFromFlags(fmt.Sprintf("https://%s", server), kubeconfigFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read load kubeconfig: %w", err)
@@ -20,6 +21,14 @@ func getKubernetesEndpoints(ctx context.Context, kubeconfigFile string, server s
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize kubernetes client: %w", err)
 	}
+	return clientset, nil
+}
+
+func getKubernetesEndpoints(ctx context.Context, kubeconfigFile string, server string) ([]string, error) {
+	clientset, err := newClientset(kubeconfigFile, server)
+	if err != nil {
+		return nil, err
+	}
 
 	endpointSlices, err := clientset.DiscoveryV1().EndpointSlices("default").List(ctx, metav1.ListOptions{
 		LabelSelector: "kubernetes.io/service-name=kubernetes",
@@ -44,3 +53,42 @@ func getKubernetesEndpoints(ctx context.Context, kubeconfigFile string, server s
 
 	return utils.ParseEndpoints(endpoints), nil
 }
+
+// getZones returns the topology zone of the local node, and the topology zones of the control plane nodes by address.
+func getZones(ctx context.Context, kubeconfigFile string, server string, nodeName string) (string, map[string]string, error) {
+	clientset, err := newClientset(kubeconfigFile, server)
+	if err != nil {
+		return "", nil, err
+	}
+
+	node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	controlPlaneNodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: "node-role.kubernetes.io/control-plane",
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list control plane nodes: %w", err)
+	}
+
+	var zones map[string]string
+	for _, controlPlaneNode := range controlPlaneNodes.Items {
+		zone := controlPlaneNode.Labels[corev1.LabelTopologyZone]
+		if zone == "" {
+			continue
+		}
+		for _, address := range controlPlaneNode.Status.Addresses {
+			if address.Type != corev1.NodeInternalIP && address.Type != corev1.NodeExternalIP {
+				continue
+			}
+			if zones == nil {
+				zones = make(map[string]string)
+			}
+			zones[address.Address] = zone
+		}
+	}
+
+	return node.Labels[corev1.LabelTopologyZone], zones, nil
+}
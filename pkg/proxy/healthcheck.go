@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// newReadyzHealthCheck returns a health check that queries the /readyz endpoint of kube-apiserver over TLS, using
+// the credentials and certificate authority of the kubeconfig file. Unlike a TCP health check, this also detects
+// kube-apiserver instances that accept connections but do not respond to requests.
+func newReadyzHealthCheck(kubeconfigFile string) (func(ctx context.Context, addr string) error, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	// NOTE: Unless the kubeconfig sets a server name, the certificate of each endpoint is verified against its address.
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transport: %w", err)
+	}
+	client := &http.Client{Transport: transport}
+
+	return func(ctx context.Context, addr string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/readyz", addr), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to query readyz: %w", err)
+		}
+		defer resp.Body.Close()
+		// NOTE: Read the response, so that the connection can be reused by the next health check.
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("readyz returned status %d", resp.StatusCode)
+		}
+		return nil
+	}, nil
+}
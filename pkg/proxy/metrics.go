@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// metricsRegistry is the registry of the apiserver proxy metrics. It is served on the metrics address of the proxy.
+	metricsRegistry = prometheus.NewRegistry()
+
+	// backendActiveConnections is the number of proxied connections per kube-apiserver endpoint.
+	backendActiveConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "k8s_apiserver_proxy",
+		Subsystem: "backend",
+		Name:      "active_connections",
+		Help:      "Number of active proxied connections to the kube-apiserver endpoint.",
+	}, []string{"endpoint"})
+
+	// backendBytes is the number of bytes proxied per kube-apiserver endpoint and direction.
+	backendBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "k8s_apiserver_proxy",
+		Subsystem: "backend",
+		Name:      "bytes_total",
+		Help:      "Number of bytes proxied to (sent) and from (received) the kube-apiserver endpoint.",
+	}, []string{"endpoint", "direction"})
+
+	// backendDialFailures is the number of failed connection attempts per kube-apiserver endpoint.
+	backendDialFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "k8s_apiserver_proxy",
+		Subsystem: "backend",
+		Name:      "dial_failures_total",
+		Help:      "Number of failed attempts to connect to the kube-apiserver endpoint.",
+	}, []string{"endpoint"})
+
+	// backendEjections is the number of times a kube-apiserver endpoint was deactivated.
+	backendEjections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "k8s_apiserver_proxy",
+		Subsystem: "backend",
+		Name:      "ejections_total",
+		Help:      "Number of times the kube-apiserver endpoint was deactivated after a failed connection attempt or health check.",
+	}, []string{"endpoint"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(backendActiveConnections, backendBytes, backendDialFailures, backendEjections)
+}
+
+// deleteBackendMetrics deletes the metrics of a kube-apiserver endpoint that is no longer proxied to.
+func deleteBackendMetrics(addr string) {
+	backendActiveConnections.DeleteLabelValues(addr)
+	backendBytes.DeleteLabelValues(addr, "sent")
+	backendBytes.DeleteLabelValues(addr, "received")
+	backendDialFailures.DeleteLabelValues(addr)
+	backendEjections.DeleteLabelValues(addr)
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w       io.Writer
+	counter prometheus.Counter
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.counter.Add(float64(n))
+	return n, err
+}
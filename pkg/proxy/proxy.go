@@ -6,56 +6,81 @@ import (
 	"net"
 	"net/url"
 	"strconv"
-	"time"
 
 	"github.com/canonical/k8sd/pkg/log"
 )
 
-func startProxy(ctx context.Context, listenURL string, endpointURLs []string) error {
-	if len(endpointURLs) == 0 {
-		return fmt.Errorf("empty list of endpoints")
+// parseEndpoints parses the endpoints of the configuration. Endpoints in other zones than the local node have a
+// lower priority.
+func parseEndpoints(cfg Configuration) ([]*net.SRV, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("empty list of endpoints")
 	}
-	srvs := make([]*net.SRV, len(endpointURLs))
-	for i, endpoint := range endpointURLs {
+	srvs := make([]*net.SRV, len(cfg.Endpoints))
+	for i, endpoint := range cfg.Endpoints {
 		if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
 			endpoint = u.Host
 		}
 		host, port, err := net.SplitHostPort(endpoint)
 		if err != nil {
-			return fmt.Errorf("failed to parse endpoint %q: %w", endpoint, err)
+			return nil, fmt.Errorf("failed to parse endpoint %q: %w", endpoint, err)
 		}
 		portNumber, err := strconv.ParseUint(port, 10, 16)
 		if err != nil {
-			return fmt.Errorf("failed to parse port %q: %w", port, err)
+			return nil, fmt.Errorf("failed to parse port %q: %w", port, err)
 		}
 		srvs[i] = &net.SRV{Target: host, Port: uint16(portNumber)}
+
+		// NOTE: Prefer endpoints in the same zone as the local node. Endpoints in other zones are only used
+		// if all endpoints in the same zone are inactive.
+		if cfg.Zone != "" && cfg.EndpointZones[host] != cfg.Zone {
+			srvs[i].Priority = 1
+		}
 	}
+	return srvs, nil
+}
 
-	l, err := net.Listen("tcp", listenURL)
+// startProxy runs p on listenURL, and applies the configurations received on updateCh without restarting.
+// startProxy blocks until ctx is cancelled, and then drains the existing connections.
+func startProxy(ctx context.Context, listenURL string, cfg Configuration, updateCh <-chan Configuration, p *tcpproxy) error {
+	srvs, err := parseEndpoints(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to start listener: %w", err)
+		return err
 	}
 
-	p := tcpproxy{
-		Listener:        l,
-		Endpoints:       srvs,
-		MonitorInterval: time.Minute,
+	l, err := net.Listen("tcp", listenURL)
+	if err != nil {
+		return fmt.Errorf("failed to start listener: %w", err)
 	}
+	p.Listener = l
+	p.Endpoints = srvs
 
 	log := log.FromContext(ctx).WithValues(
 		"controller", "proxy",
 		"address", listenURL,
-		"endpoints", endpointURLs,
 	)
-	log.Info("Starting proxy")
+	log.Info("Starting proxy", "endpoints", cfg.Endpoints, "zone", cfg.Zone)
+	errCh := make(chan error, 1)
 	go func() {
-		if err := p.Run(); err != nil {
-			log.Error(err, "Proxy failed")
-		}
+		errCh <- p.Run()
 	}()
 
-	<-ctx.Done()
-	p.Stop()
-
-	return nil
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Stopping proxy")
+			p.Stop()
+			return nil
+		case err := <-errCh:
+			return fmt.Errorf("failed to accept connections: %w", err)
+		case cfg := <-updateCh:
+			srvs, err := parseEndpoints(cfg)
+			if err != nil {
+				log.Error(err, "Failed to parse endpoints, keeping the current endpoints", "endpoints", cfg.Endpoints)
+				continue
+			}
+			log.Info("Applying endpoints", "endpoints", cfg.Endpoints, "zone", cfg.Zone)
+			p.SetEndpoints(srvs)
+		}
+	}
 }
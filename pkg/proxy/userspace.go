@@ -26,6 +26,7 @@ import (
 	"log"
 	"math/rand"
 	"net"
+	"slices"
 	"sync"
 	"time"
 
@@ -37,24 +38,120 @@ type remote struct {
 	srv      *net.SRV
 	addr     string
 	inactive bool
+
+	// failures and successes are the number of consecutive failed and successful health checks.
+	failures  int
+	successes int
+
+	// conns are the proxied connections to the remote.
+	conns map[net.Conn]struct{}
+	// drainTimeout is set while the remote is drained, see drain.
+	drainTimeout time.Duration
+	// drainTimers close the connections of a drained remote.
+	drainTimers []*time.Timer
 }
 
-func (r *remote) inactivate() {
+// inactivate inactivates the remote, and returns true if the remote was active.
+func (r *remote) inactivate() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	wasActive := !r.inactive
 	r.inactive = true
+	r.successes = 0
+	return wasActive
 }
 
-func (r *remote) tryReactivate() error {
-	conn, err := r.connect()
+// recordHealthCheck updates the remote with the result of a health check. The remote is inactivated after
+// unhealthyThreshold consecutive failed health checks, and activated again after healthyThreshold consecutive
+// successful health checks. recordHealthCheck returns true if the remote was activated or inactivated.
+func (r *remote) recordHealthCheck(err error, healthyThreshold int, unhealthyThreshold int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err != nil {
-		return err
+		r.successes = 0
+		r.failures++
+		if !r.inactive && r.failures >= unhealthyThreshold {
+			r.inactive = true
+			return true
+		}
+		return false
 	}
-	conn.Close()
+	r.failures = 0
+	r.successes++
+	if r.inactive && r.successes >= healthyThreshold {
+		r.inactive = false
+		return true
+	}
+	return false
+}
+
+// track adds a proxied connection to the remote.
+func (r *remote) track(conn net.Conn) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.inactive = false
-	return nil
+	if r.conns == nil {
+		r.conns = make(map[net.Conn]struct{})
+	}
+	r.conns[conn] = struct{}{}
+	// NOTE: the remote might have been picked for the connection before it was drained
+	if r.drainTimeout > 0 {
+		r.drainConn(conn)
+	}
+}
+
+// untrack removes a proxied connection from the remote.
+func (r *remote) untrack(conn net.Conn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.conns, conn)
+}
+
+// closeConns closes all proxied connections to the remote.
+// This is used for unhealthy remotes, which might not close connections on their own.
+func (r *remote) closeConns() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for conn := range r.conns {
+		conn.Close()
+	}
+}
+
+// numConns returns the number of proxied connections to the remote.
+func (r *remote) numConns() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.conns)
+}
+
+// drain closes the proxied connections to the remote at random times within timeout, so that clients do not all
+// reconnect at once. Connections that end on their own before that are not affected.
+func (r *remote) drain(timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.drainTimeout > 0 {
+		return
+	}
+	r.drainTimeout = timeout
+	for conn := range r.conns {
+		r.drainConn(conn)
+	}
+}
+
+// drainConn closes a connection at a random time within the drain timeout. r.mu must be held.
+func (r *remote) drainConn(conn net.Conn) {
+	delay := time.Duration(rand.Int63n(int64(r.drainTimeout) + 1))
+	r.drainTimers = append(r.drainTimers, time.AfterFunc(delay, func() { conn.Close() }))
+}
+
+// cancelDrain stops closing the connections of the remote, e.g. because the remote was added again.
+func (r *remote) cancelDrain() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, timer := range r.drainTimers {
+		timer.Stop()
+	}
+	r.drainTimers = nil
+	r.drainTimeout = 0
 }
 
 func (r *remote) isActive() bool {
@@ -66,45 +163,85 @@ func (r *remote) isActive() bool {
 // connect establishes a TCP connection to the remote endpoint.
 // closing the returned connection is the caller's responsibility.
 func (r *remote) connect() (net.Conn, error) {
-	var d net.Dialer
 	dialCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	out, err := d.DialContext(dialCtx, "tcp", r.addr)
-	cancel()
+	defer cancel()
+	return dial(dialCtx, r.addr)
+}
+
+// dial establishes a TCP connection to addr.
+func dial(ctx context.Context, addr string) (net.Conn, error) {
+	var d net.Dialer
+	out, err := d.DialContext(ctx, "tcp", addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to establish tcp connection: %w", err)
 	}
-
 	return out, nil
 }
 
+// tcpHealthCheck is the default health check of tcpproxy, which only checks that a TCP connection can be established.
+func tcpHealthCheck(ctx context.Context, addr string) error {
+	conn, err := dial(ctx, addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
 type tcpproxy struct {
-	Listener        net.Listener
-	Endpoints       []*net.SRV
+	Listener  net.Listener
+	Endpoints []*net.SRV
+
+	// MonitorInterval is the interval between health checks of the endpoints. Defaults to 5 seconds.
 	MonitorInterval time.Duration
+	// HealthCheck checks the health of an endpoint. Defaults to tcpHealthCheck.
+	HealthCheck func(ctx context.Context, addr string) error
+	// HealthCheckTimeout is the timeout of a single health check. Defaults to 2 seconds.
+	HealthCheckTimeout time.Duration
+	// HealthyThreshold is the number of consecutive successful health checks after which an inactive endpoint
+	// is activated again. Defaults to 2.
+	HealthyThreshold int
+	// UnhealthyThreshold is the number of consecutive failed health checks after which an endpoint is
+	// inactivated. Defaults to 2.
+	UnhealthyThreshold int
+	// DrainTimeout is the time within which the connections to removed endpoints are closed, and the time
+	// that Stop waits for connections to end. Defaults to 30 seconds.
+	DrainTimeout time.Duration
 
-	donec chan struct{}
+	// connsWg tracks the connections that are being served.
+	connsWg sync.WaitGroup
 
 	mu        sync.Mutex // guards the following fields
+	donec     chan struct{}
 	remotes   []*remote
-	pickCount int // for round robin
+	draining  map[string]*remote // removed remotes, by address
+	pickCount int                // for round robin
+	panicking bool               // whether all remotes are inactive, see pick
 }
 
 func (tp *tcpproxy) Run() error {
-	tp.donec = make(chan struct{})
 	if tp.MonitorInterval == 0 {
-		tp.MonitorInterval = 5 * time.Minute
+		tp.MonitorInterval = 5 * time.Second
 	}
-	for _, srv := range tp.Endpoints {
-		ip := net.ParseIP(srv.Target)
-		addr := fmt.Sprintf("%s:%d", utils.ToIPString(ip), srv.Port)
-		tp.remotes = append(tp.remotes, &remote{srv: srv, addr: addr})
+	if tp.HealthCheck == nil {
+		tp.HealthCheck = tcpHealthCheck
 	}
-
-	eps := []string{}
-	for _, ep := range tp.Endpoints {
-		eps = append(eps, fmt.Sprintf("%s:%d", ep.Target, ep.Port))
+	if tp.HealthCheckTimeout == 0 {
+		tp.HealthCheckTimeout = 2 * time.Second
+	}
+	if tp.HealthyThreshold == 0 {
+		tp.HealthyThreshold = 2
+	}
+	if tp.UnhealthyThreshold == 0 {
+		tp.UnhealthyThreshold = 2
+	}
+	if tp.DrainTimeout == 0 {
+		tp.DrainTimeout = 30 * time.Second
 	}
-	log.Printf("ready to proxy client requests to %v\n", eps)
+
+	tp.mu.Lock()
+	tp.donec = make(chan struct{})
+	tp.setEndpoints(tp.Endpoints)
+	tp.mu.Unlock()
 
 	go tp.runMonitor()
 	for {
@@ -113,20 +250,110 @@ func (tp *tcpproxy) Run() error {
 			return err
 		}
 
-		go tp.serve(in)
+		tp.connsWg.Add(1)
+		go func() {
+			defer tp.connsWg.Done()
+			tp.serve(in)
+		}()
+	}
+}
+
+// SetEndpoints updates the endpoints of a running proxy, without interrupting connections to endpoints that did
+// not change. Connections to removed endpoints are drained within DrainTimeout.
+func (tp *tcpproxy) SetEndpoints(endpoints []*net.SRV) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	tp.Endpoints = endpoints
+	if tp.donec == nil {
+		// not running yet, Run will use the new endpoints
+		return
 	}
+	tp.setEndpoints(endpoints)
 }
 
-func (tp *tcpproxy) pick() *remote {
+// setEndpoints updates the remotes to match the endpoints. tp.mu must be held.
+func (tp *tcpproxy) setEndpoints(endpoints []*net.SRV) {
+	if tp.draining == nil {
+		tp.draining = make(map[string]*remote)
+	}
+	existing := make(map[string]*remote, len(tp.remotes))
+	for _, r := range tp.remotes {
+		existing[r.addr] = r
+	}
+
+	remotes := make([]*remote, 0, len(endpoints))
+	eps := make([]string, 0, len(endpoints))
+	for _, srv := range endpoints {
+		ip := net.ParseIP(srv.Target)
+		addr := fmt.Sprintf("%s:%d", utils.ToIPString(ip), srv.Port)
+		eps = append(eps, addr)
+
+		r, ok := existing[addr]
+		if ok {
+			delete(existing, addr)
+		} else if r, ok = tp.draining[addr]; ok {
+			delete(tp.draining, addr)
+			r.cancelDrain()
+			log.Printf("stopped draining endpoint %v\n", addr)
+		} else {
+			r = &remote{addr: addr}
+		}
+		// NOTE: the priority of an endpoint changes with the zones of the endpoints
+		r.srv = srv
+		remotes = append(remotes, r)
+	}
+	tp.remotes = remotes
+
+	for addr, r := range tp.draining {
+		if r.numConns() == 0 {
+			delete(tp.draining, addr)
+			deleteBackendMetrics(addr)
+		}
+	}
+	for addr, r := range existing {
+		log.Printf("draining %d connections to removed endpoint %v within %v\n", r.numConns(), addr, tp.DrainTimeout)
+		r.drain(tp.DrainTimeout)
+		tp.draining[addr] = r
+	}
+
+	log.Printf("ready to proxy client requests to %v\n", eps)
+}
+
+// pick picks a remote for a new connection, skipping the remotes that were already tried for it.
+// If no remote is active, pick falls back to all remotes (panic mode), since the health checks of all remotes
+// can fail at once for reasons that do not affect proxied connections, e.g. while the datastore is not ready.
+// tp.mu must be held.
+func (tp *tcpproxy) pick(tried map[*remote]bool) *remote {
+	var candidates []*remote
+	for _, r := range tp.remotes {
+		if r.isActive() && !tried[r] {
+			candidates = append(candidates, r)
+		}
+	}
+	if panicking := len(candidates) == 0 && len(tp.remotes) > 0; len(tried) == 0 && panicking != tp.panicking {
+		tp.panicking = panicking
+		if panicking {
+			log.Printf("all endpoints are inactive, proxying client requests to all endpoints\n")
+		} else {
+			log.Printf("endpoints are active again, proxying client requests to active endpoints only\n")
+		}
+	}
+	if len(candidates) == 0 {
+		for _, r := range tp.remotes {
+			if !tried[r] {
+				candidates = append(candidates, r)
+			}
+		}
+	}
+
 	var weighted []*remote
 	var unweighted []*remote
 
 	bestPr := uint16(65535)
 	w := 0
 	// find best priority class
-	for _, r := range tp.remotes {
+	for _, r := range candidates {
 		switch {
-		case !r.isActive():
 		case r.srv.Priority < bestPr:
 			bestPr = r.srv.Priority
 			w = 0
@@ -163,36 +390,39 @@ func (tp *tcpproxy) pick() *remote {
 		}
 	}
 	if unweighted != nil {
-		for i := 0; i < len(tp.remotes); i++ {
-			picked := tp.remotes[tp.pickCount%len(tp.remotes)]
-			tp.pickCount++
-			if picked.isActive() {
-				return picked
-			}
-		}
+		// NOTE: unweighted only contains candidate remotes of the best priority class.
+		picked := unweighted[tp.pickCount%len(unweighted)]
+		tp.pickCount++
+		return picked
 	}
 	return nil
 }
 
 func (tp *tcpproxy) serve(in net.Conn) {
 	var (
-		err error
-		out net.Conn
+		err    error
+		out    net.Conn
+		picked *remote
 	)
 
+	tried := make(map[*remote]bool)
 	for {
 		tp.mu.Lock()
-		remote := tp.pick()
+		picked = tp.pick(tried)
 		tp.mu.Unlock()
-		if remote == nil {
+		if picked == nil {
 			break
 		}
-		out, err = remote.connect()
+		tried[picked] = true
+		out, err = picked.connect()
 		if err == nil {
 			break
 		}
-		remote.inactivate()
-		log.Printf("deactivated endpoint %v for interval %v, error was %q", remote.addr, tp.MonitorInterval, err)
+		backendDialFailures.WithLabelValues(picked.addr).Inc()
+		if picked.inactivate() {
+			backendEjections.WithLabelValues(picked.addr).Inc()
+		}
+		log.Printf("deactivated endpoint %v until it passes %d health checks, error was %q", picked.addr, tp.HealthyThreshold, err)
 	}
 
 	if out == nil {
@@ -200,44 +430,102 @@ func (tp *tcpproxy) serve(in net.Conn) {
 		return
 	}
 
+	picked.track(out)
+	defer picked.untrack(out)
+	backendActiveConnections.WithLabelValues(picked.addr).Inc()
+	defer backendActiveConnections.WithLabelValues(picked.addr).Dec()
+
+	sent := &countingWriter{w: out, counter: backendBytes.WithLabelValues(picked.addr, "sent")}
+	received := &countingWriter{w: in, counter: backendBytes.WithLabelValues(picked.addr, "received")}
+
+	done := make(chan struct{})
 	go func() {
-		io.Copy(in, out)
+		defer close(done)
+		io.Copy(received, out)
 		in.Close()
 		out.Close()
 	}()
 
-	io.Copy(out, in)
+	io.Copy(sent, in)
 	out.Close()
 	in.Close()
+	<-done
 }
 
 func (tp *tcpproxy) runMonitor() {
+	ticker := time.NewTicker(tp.MonitorInterval)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-time.After(tp.MonitorInterval):
-			tp.mu.Lock()
-			for _, rem := range tp.remotes {
-				if rem.isActive() {
-					continue
-				}
-				go func(r *remote) {
-					if err := r.tryReactivate(); err != nil {
-						log.Printf("failed to activate endpoint %v (stay inactive for another interval %v)\n", r.addr, tp.MonitorInterval)
-					} else {
-						log.Printf("activated endpoint %v\n", r.addr)
-					}
-				}(rem)
-			}
-			tp.mu.Unlock()
+		case <-ticker.C:
+			tp.checkRemotes()
 		case <-tp.donec:
 			return
 		}
 	}
 }
 
+// checkRemotes runs a health check against all remotes in parallel, and blocks until all health checks are done.
+func (tp *tcpproxy) checkRemotes() {
+	tp.mu.Lock()
+	remotes := append([]*remote(nil), tp.remotes...)
+	tp.mu.Unlock()
+
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		deactivated []*remote
+	)
+	for _, r := range remotes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), tp.HealthCheckTimeout)
+			err := tp.HealthCheck(ctx, r.addr)
+			cancel()
+			if !r.recordHealthCheck(err, tp.HealthyThreshold, tp.UnhealthyThreshold) {
+				return
+			}
+			if r.isActive() {
+				log.Printf("activated endpoint %v\n", r.addr)
+				return
+			}
+			backendEjections.WithLabelValues(r.addr).Inc()
+			log.Printf("deactivated endpoint %v after %d failed health checks, error was %q", r.addr, tp.UnhealthyThreshold, err)
+			mu.Lock()
+			deactivated = append(deactivated, r)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(deactivated) == 0 {
+		return
+	}
+	// NOTE: If all endpoints are unhealthy, clients cannot reconnect to a healthy endpoint, so keep the connections.
+	if !slices.ContainsFunc(remotes, (*remote).isActive) {
+		log.Printf("all endpoints are inactive, keeping the connections to %d deactivated endpoints\n", len(deactivated))
+		return
+	}
+	// NOTE: Unhealthy endpoints might hang without closing connections, so close them to let clients reconnect.
+	for _, r := range deactivated {
+		r.closeConns()
+	}
+}
+
+// Stop stops accepting new connections, and drains the existing connections within DrainTimeout.
+// Stop blocks until all connections are closed.
 func (tp *tcpproxy) Stop() {
-	// graceful shutdown?
-	// shutdown current connections?
 	tp.Listener.Close()
-	close(tp.donec)
+
+	tp.mu.Lock()
+	if tp.donec != nil {
+		close(tp.donec)
+	}
+	for _, r := range tp.remotes {
+		r.drain(tp.DrainTimeout)
+	}
+	tp.mu.Unlock()
+
+	tp.connsWg.Wait()
 }
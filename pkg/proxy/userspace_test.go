@@ -0,0 +1,231 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTCPProxyPick(t *testing.T) {
+	g := NewWithT(t)
+
+	local1 := &remote{srv: &net.SRV{Target: "10.0.0.1"}, addr: "10.0.0.1:6443"}
+	local2 := &remote{srv: &net.SRV{Target: "10.0.0.2"}, addr: "10.0.0.2:6443"}
+	other := &remote{srv: &net.SRV{Target: "10.0.1.1", Priority: 1}, addr: "10.0.1.1:6443"}
+	tp := &tcpproxy{remotes: []*remote{other, local1, local2}}
+
+	// endpoints in the same zone are balanced round robin
+	picked := map[*remote]int{}
+	for range 10 {
+		picked[tp.pick(nil)]++
+	}
+	g.Expect(picked).To(Equal(map[*remote]int{local1: 5, local2: 5}))
+
+	local1.inactivate()
+	g.Expect(tp.pick(nil)).To(Equal(local2))
+
+	// endpoints in other zones are used if all endpoints in the same zone are inactive
+	local2.inactivate()
+	g.Expect(tp.pick(nil)).To(Equal(other))
+
+	// endpoints that were already tried for a connection are skipped
+	g.Expect(tp.pick(map[*remote]bool{other: true})).To(BeElementOf(local1, local2))
+
+	// all endpoints are used if all endpoints are inactive (panic mode)
+	other.inactivate()
+	picked = map[*remote]int{}
+	for range 10 {
+		picked[tp.pick(nil)]++
+	}
+	g.Expect(picked).To(Equal(map[*remote]int{local1: 5, local2: 5}))
+	g.Expect(tp.pick(map[*remote]bool{local1: true, local2: true})).To(Equal(other))
+	g.Expect(tp.pick(map[*remote]bool{local1: true, local2: true, other: true})).To(BeNil())
+}
+
+func TestTCPProxyHealthCheck(t *testing.T) {
+	g := NewWithT(t)
+
+	var mu sync.Mutex
+	healthy := map[string]bool{"10.0.0.1:6443": true, "10.0.0.2:6443": true}
+	setHealthy := func(addr string, isHealthy bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		healthy[addr] = isHealthy
+	}
+
+	r1 := &remote{srv: &net.SRV{Target: "10.0.0.1"}, addr: "10.0.0.1:6443"}
+	r2 := &remote{srv: &net.SRV{Target: "10.0.0.2"}, addr: "10.0.0.2:6443"}
+	tp := &tcpproxy{
+		remotes: []*remote{r1, r2},
+		HealthCheck: func(ctx context.Context, addr string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if !healthy[addr] {
+				return fmt.Errorf("not ready")
+			}
+			return nil
+		},
+		HealthCheckTimeout: time.Second,
+		HealthyThreshold:   2,
+		UnhealthyThreshold: 2,
+	}
+
+	// connections to the remote are closed when it is deactivated
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	r1.track(conn)
+
+	setHealthy(r1.addr, false)
+	tp.checkRemotes()
+	g.Expect(r1.isActive()).To(BeTrue())
+
+	tp.checkRemotes()
+	g.Expect(r1.isActive()).To(BeFalse())
+	g.Expect(r2.isActive()).To(BeTrue())
+	_, err := peer.Read(make([]byte, 1))
+	g.Expect(err).To(HaveOccurred())
+
+	setHealthy(r1.addr, true)
+	tp.checkRemotes()
+	g.Expect(r1.isActive()).To(BeFalse())
+
+	tp.checkRemotes()
+	g.Expect(r1.isActive()).To(BeTrue())
+
+	t.Run("AllUnhealthy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// connections are kept if all remotes are deactivated, since clients cannot reconnect to a healthy remote
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		r1.track(conn)
+
+		setHealthy(r1.addr, false)
+		setHealthy(r2.addr, false)
+		tp.checkRemotes()
+		tp.checkRemotes()
+		g.Expect(r1.isActive()).To(BeFalse())
+		g.Expect(r2.isActive()).To(BeFalse())
+
+		go conn.Write([]byte("x"))
+		_, err := peer.Read(make([]byte, 1))
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+}
+
+func TestTCPProxySetEndpoints(t *testing.T) {
+	g := NewWithT(t)
+
+	tp := &tcpproxy{DrainTimeout: 50 * time.Millisecond, donec: make(chan struct{})}
+	tp.SetEndpoints([]*net.SRV{{Target: "10.0.0.1", Port: 6443}, {Target: "10.0.0.2", Port: 6443}})
+	g.Expect(tp.remotes).To(HaveLen(2))
+	r1, r2 := tp.remotes[0], tp.remotes[1]
+
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	r2.track(conn)
+
+	// unchanged endpoints keep their connections, removed endpoints are drained
+	tp.SetEndpoints([]*net.SRV{{Target: "10.0.0.1", Port: 6443}, {Target: "10.0.0.3", Port: 6443, Priority: 1}})
+	g.Expect(tp.remotes).To(HaveLen(2))
+	g.Expect(tp.remotes[0]).To(BeIdenticalTo(r1))
+	g.Expect(tp.remotes[1].addr).To(Equal("10.0.0.3:6443"))
+	g.Expect(tp.remotes[1].srv.Priority).To(Equal(uint16(1)))
+	g.Expect(tp.draining).To(HaveKey("10.0.0.2:6443"))
+
+	_, err := peer.Read(make([]byte, 1))
+	g.Expect(err).To(HaveOccurred())
+
+	t.Run("ReAdded", func(t *testing.T) {
+		g := NewWithT(t)
+
+		tp.DrainTimeout = time.Hour
+		conn, peer := net.Pipe()
+		defer peer.Close()
+		r1.track(conn)
+
+		tp.SetEndpoints([]*net.SRV{{Target: "10.0.0.3", Port: 6443}})
+		g.Expect(tp.draining).To(HaveKey("10.0.0.1:6443"))
+
+		// endpoints that are added again while draining keep their connections
+		tp.SetEndpoints([]*net.SRV{{Target: "10.0.0.1", Port: 6443}, {Target: "10.0.0.3", Port: 6443}})
+		g.Expect(tp.remotes[0]).To(BeIdenticalTo(r1))
+		g.Expect(tp.draining).ToNot(HaveKey("10.0.0.1:6443"))
+		g.Expect(r1.numConns()).To(Equal(1))
+		g.Expect(r1.drainTimers).To(BeEmpty())
+	})
+
+	t.Run("DrainedMetricsDeleted", func(t *testing.T) {
+		g := NewWithT(t)
+
+		backendDialFailures.WithLabelValues("10.0.0.3:6443").Inc()
+		tp.SetEndpoints([]*net.SRV{{Target: "10.0.0.1", Port: 6443}})
+		g.Expect(tp.draining).To(HaveKey("10.0.0.3:6443"))
+
+		// the metrics of an endpoint are deleted once it has no connections left
+		tp.SetEndpoints([]*net.SRV{{Target: "10.0.0.1", Port: 6443}})
+		g.Expect(tp.draining).ToNot(HaveKey("10.0.0.3:6443"))
+		g.Expect(backendDialFailures.DeleteLabelValues("10.0.0.3:6443")).To(BeFalse())
+	})
+}
+
+func TestTCPProxyServe(t *testing.T) {
+	g := NewWithT(t)
+
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).ToNot(HaveOccurred())
+	addr := backend.Addr().(*net.TCPAddr)
+	tp := &tcpproxy{
+		Listener:     l,
+		Endpoints:    []*net.SRV{{Target: addr.IP.String(), Port: uint16(addr.Port)}},
+		DrainTimeout: 50 * time.Millisecond,
+	}
+	go tp.Run()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	g.Expect(err).ToNot(HaveOccurred())
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	g.Expect(err).ToNot(HaveOccurred())
+	b := make([]byte, 4)
+	_, err = io.ReadFull(conn, b)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(b)).To(Equal("ping"))
+
+	endpoint := backend.Addr().String()
+	g.Expect(testutil.ToFloat64(backendActiveConnections.WithLabelValues(endpoint))).To(Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(backendBytes.WithLabelValues(endpoint, "sent"))).To(Equal(float64(4)))
+	g.Eventually(func() float64 {
+		return testutil.ToFloat64(backendBytes.WithLabelValues(endpoint, "received"))
+	}).Should(Equal(float64(4)))
+
+	// Stop drains the existing connections
+	tp.Stop()
+	_, err = conn.Read(b)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(testutil.ToFloat64(backendActiveConnections.WithLabelValues(endpoint))).To(BeZero())
+}
@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"github.com/canonical/k8sd/pkg/k8sd/types"
 	"github.com/canonical/k8sd/pkg/snap"
 	"github.com/canonical/microcluster/v3/microcluster"
 )
@@ -9,7 +10,7 @@ type Provider struct {
 	MicroClusterFn                     func() *microcluster.MicroCluster
 	SnapFn                             func() snap.Snap
 	NotifyUpdateNodeConfigControllerFn func()
-	NotifyFeatureControllerFn          func(network, gateway, ingress, loadBalancer, localStorage, metricsServer, dns bool)
+	NotifyFeatureControllerFn          func(features ...types.FeatureName)
 }
 
 func (p *Provider) MicroCluster() *microcluster.MicroCluster {
@@ -32,8 +33,8 @@ func (p *Provider) NotifyUpdateNodeConfigController() {
 	}
 }
 
-func (p *Provider) NotifyFeatureController(network, gateway, ingress, loadBalancer, localStorage, metricsServer, dns bool) {
+func (p *Provider) NotifyFeatureController(features ...types.FeatureName) {
 	if p.NotifyFeatureControllerFn != nil {
-		p.NotifyFeatureControllerFn(network, gateway, ingress, loadBalancer, localStorage, metricsServer, dns)
+		p.NotifyFeatureControllerFn(features...)
 	}
 }
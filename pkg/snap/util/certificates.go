@@ -0,0 +1,213 @@
+package snaputil
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/canonical/k8sd/pkg/snap"
+	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// NodeCertificateAuthoritiesAnnotation is the annotation of the Kubernetes node with the SHA256 fingerprints of the
+	// certificate authorities that issued the node certificates and kubeconfigs, separated by commas.
+	// It is used to verify that all nodes use certificates of the new certificate authorities before finishing a
+	// CA rotation.
+	NodeCertificateAuthoritiesAnnotation = "k8sd.io/certificate-authorities"
+
+	// UnknownCertificateAuthority is reported instead of a fingerprint for node certificates that are not issued by any
+	// of the certificate authorities trusted by the node.
+	UnknownCertificateAuthority = "unknown"
+)
+
+var (
+	// ControlPlaneCertificateNames are the certificates of control plane nodes, in the Kubernetes PKI directory.
+	ControlPlaneCertificateNames = []string{
+		"apiserver",
+		"apiserver-kubelet-client",
+		"front-proxy-client",
+		"kubelet",
+	}
+
+	// ControlPlaneKubeconfigs are the kubeconfig files of control plane nodes, in the Kubernetes config directory.
+	ControlPlaneKubeconfigs = []string{
+		"admin.conf",
+		"controller.conf",
+		"kubelet.conf",
+		"proxy.conf",
+		"scheduler.conf",
+	}
+
+	// DataStoreCertificateNames are the certificates of the external datastore, in the etcd PKI directory.
+	DataStoreCertificateNames = []string{
+		"client",
+	}
+
+	// WorkerCertificateNames are the certificates of worker nodes, in the Kubernetes PKI directory.
+	WorkerCertificateNames = []string{
+		"kubelet",
+	}
+
+	// WorkerKubeconfigs are the kubeconfig files of worker nodes, in the Kubernetes config directory.
+	WorkerKubeconfigs = []string{
+		"kubelet.conf",
+		"proxy.conf",
+	}
+)
+
+// NodeCertificate is a certificate or kubeconfig managed by k8sd on the local node.
+type NodeCertificate struct {
+	// Name is the name of the certificate (e.g. "apiserver") or kubeconfig file (e.g. "admin.conf").
+	Name string
+	// Type is one of "certificate", "kubeconfig" or "datastore".
+	Type string
+	// Certificate is the parsed certificate.
+	Certificate *x509.Certificate
+}
+
+// ReadKubeconfigCertificate reads the client certificate of the k8s-user in a kubeconfig file.
+func ReadKubeconfigCertificate(kubeconfigDir string, config string) (*x509.Certificate, error) {
+	kubeConfig, err := clientcmd.LoadFromFile(filepath.Join(kubeconfigDir, config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %s: %w", config, err)
+	}
+
+	authInfo, exists := kubeConfig.AuthInfos["k8s-user"]
+	if !exists {
+		return nil, fmt.Errorf("user 'k8s-user' not found in kubeconfig %s", config)
+	}
+
+	if authInfo.ClientCertificateData == nil {
+		return nil, fmt.Errorf("no client certificate data found in kubeconfig %s", config)
+	}
+
+	cert, _, err := pkiutil.LoadCertificate(string(authInfo.ClientCertificateData), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate data from kubeconfig %s: %w", config, err)
+	}
+	return cert, nil
+}
+
+// ReadNodeCertificates reads all certificates and kubeconfigs that k8sd manages on the local node.
+// Datastore certificates are only included if they exist, i.e. when using an external datastore.
+func ReadNodeCertificates(snap snap.Snap) ([]NodeCertificate, error) {
+	isWorker, err := IsWorker(snap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if node is a worker: %w", err)
+	}
+
+	certificateNames, kubeconfigs := ControlPlaneCertificateNames, ControlPlaneKubeconfigs
+	if isWorker {
+		certificateNames, kubeconfigs = WorkerCertificateNames, WorkerKubeconfigs
+	}
+
+	var certificates []NodeCertificate
+	for _, name := range certificateNames {
+		cert, _, err := pkiutil.LoadCertificatePairFromDir(snap.KubernetesPKIDir(), name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate %s: %w", name, err)
+		}
+		certificates = append(certificates, NodeCertificate{Name: name, Type: "certificate", Certificate: cert})
+	}
+	for _, name := range kubeconfigs {
+		cert, err := ReadKubeconfigCertificate(snap.KubernetesConfigDir(), name)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, NodeCertificate{Name: name, Type: "kubeconfig", Certificate: cert})
+	}
+	if !isWorker {
+		for _, name := range DataStoreCertificateNames {
+			if exists, err := utils.FileExists(snap.EtcdPKIDir(), name+".crt"); err != nil {
+				return nil, fmt.Errorf("failed to check datastore certificate %s: %w", name, err)
+			} else if !exists {
+				continue
+			}
+			cert, _, err := pkiutil.LoadCertificatePairFromDir(snap.EtcdPKIDir(), name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read datastore certificate %s: %w", name, err)
+			}
+			certificates = append(certificates, NodeCertificate{Name: name, Type: "datastore", Certificate: cert})
+		}
+	}
+	return certificates, nil
+}
+
+// NodeCertificateAuthorities returns the sorted SHA256 fingerprints of the certificate authorities that issued the
+// certificates and kubeconfigs of the local node. The issuers are looked up in the CA bundles of the Kubernetes PKI
+// directory. Datastore certificates are not included, since they are not issued by the cluster certificate authorities.
+func NodeCertificateAuthorities(snap snap.Snap) ([]string, error) {
+	certificates, err := ReadNodeCertificates(snap)
+	if err != nil {
+		return nil, err
+	}
+
+	var cas []*x509.Certificate
+	for _, name := range []string{"ca.crt", "client-ca.crt", "front-proxy-ca.crt"} {
+		b, err := os.ReadFile(filepath.Join(snap.KubernetesPKIDir(), name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		bundle, err := pkiutil.ParseCertificates(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		cas = append(cas, bundle...)
+	}
+
+	var fingerprints []string
+	for _, certificate := range certificates {
+		if certificate.Type == "datastore" {
+			continue
+		}
+		fingerprint := UnknownCertificateAuthority
+		for _, ca := range cas {
+			if certificate.Certificate.CheckSignatureFrom(ca) == nil {
+				fingerprint = utils.CertFingerprint(ca)
+				break
+			}
+		}
+		if !slices.Contains(fingerprints, fingerprint) {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	slices.Sort(fingerprints)
+	return fingerprints, nil
+}
+
+// ReportNodeCertificateAuthorities sets the NodeCertificateAuthoritiesAnnotation on the Kubernetes node of the local node.
+// Worker nodes use their node credentials, which allow them to update their own node object.
+func ReportNodeCertificateAuthorities(ctx context.Context, snap snap.Snap) error {
+	fingerprints, err := NodeCertificateAuthorities(snap)
+	if err != nil {
+		return fmt.Errorf("failed to check node certificate authorities: %w", err)
+	}
+
+	isWorker, err := IsWorker(snap)
+	if err != nil {
+		return fmt.Errorf("failed to check if node is a worker: %w", err)
+	}
+	newClient := snap.KubernetesClient
+	if isWorker {
+		newClient = snap.KubernetesNodeClient
+	}
+	client, err := newClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	if err := client.AnnotateNode(ctx, snap.Hostname(), NodeCertificateAuthoritiesAnnotation, strings.Join(fingerprints, ",")); err != nil {
+		return fmt.Errorf("failed to annotate node %s: %w", snap.Hostname(), err)
+	}
+	return nil
+}
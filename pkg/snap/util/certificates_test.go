@@ -0,0 +1,92 @@
+package snaputil_test
+
+import (
+	"crypto/x509/pkix"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/canonical/k8sd/pkg/snap/mock"
+	snaputil "github.com/canonical/k8sd/pkg/snap/util"
+	"github.com/canonical/k8sd/pkg/utils"
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestNodeCertificateAuthorities(t *testing.T) {
+	g := NewWithT(t)
+
+	notBefore := time.Now()
+	newCA := func(name string) (string, string) {
+		certPEM, keyPEM, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: name}, notBefore, notBefore.AddDate(1, 0, 0), pkiutil.KeyAlgorithmECDSAP256)
+		g.Expect(err).NotTo(HaveOccurred())
+		return certPEM, keyPEM
+	}
+	sign := func(caPEM, caKeyPEM, name string) (string, string) {
+		ca, caKey, err := pkiutil.LoadCertificate(caPEM, caKeyPEM)
+		g.Expect(err).NotTo(HaveOccurred())
+		template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: name}, notBefore, notBefore.AddDate(0, 1, 0), false, nil, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		certPEM, keyPEM, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmECDSAP256, ca, caKey.Public(), caKey)
+		g.Expect(err).NotTo(HaveOccurred())
+		return certPEM, keyPEM
+	}
+	writeKubeconfig := func(dir, name, certPEM, keyPEM string) {
+		config := clientcmdapi.NewConfig()
+		config.AuthInfos["k8s-user"] = &clientcmdapi.AuthInfo{ClientCertificateData: []byte(certPEM), ClientKeyData: []byte(keyPEM)}
+		g.Expect(clientcmd.WriteToFile(*config, filepath.Join(dir, name))).To(Succeed())
+	}
+
+	oldCACert, oldCAKey := newCA("old-ca")
+	newCACert, newCAKey := newCA("new-ca")
+	otherCACert, otherCAKey := newCA("other-ca")
+
+	snap := &mock.Snap{
+		Mock: mock.Mock{
+			LockFilesDir:        t.TempDir(),
+			KubernetesPKIDir:    t.TempDir(),
+			KubernetesConfigDir: t.TempDir(),
+		},
+	}
+	g.Expect(os.WriteFile(filepath.Join(snap.Mock.LockFilesDir, "worker"), nil, 0o600)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(snap.Mock.KubernetesPKIDir, "ca.crt"), []byte(newCACert+oldCACert), 0o600)).To(Succeed())
+
+	kubeletCert, kubeletKey := sign(newCACert, newCAKey, "kubelet")
+	g.Expect(os.WriteFile(filepath.Join(snap.Mock.KubernetesPKIDir, "kubelet.crt"), []byte(kubeletCert), 0o600)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(snap.Mock.KubernetesPKIDir, "kubelet.key"), []byte(kubeletKey), 0o600)).To(Succeed())
+	writeKubeconfig(snap.Mock.KubernetesConfigDir, "kubelet.conf", kubeletCert, kubeletKey)
+
+	t.Run("Mixed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		proxyCert, proxyKey := sign(oldCACert, oldCAKey, "kube-proxy")
+		writeKubeconfig(snap.Mock.KubernetesConfigDir, "proxy.conf", proxyCert, proxyKey)
+
+		oldCA, _, err := pkiutil.LoadCertificate(oldCACert, "")
+		g.Expect(err).NotTo(HaveOccurred())
+		newCA, _, err := pkiutil.LoadCertificate(newCACert, "")
+		g.Expect(err).NotTo(HaveOccurred())
+		expected := []string{utils.CertFingerprint(oldCA), utils.CertFingerprint(newCA)}
+		slices.Sort(expected)
+
+		fingerprints, err := snaputil.NodeCertificateAuthorities(snap)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(fingerprints).To(Equal(expected))
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		g := NewWithT(t)
+
+		proxyCert, proxyKey := sign(otherCACert, otherCAKey, "kube-proxy")
+		writeKubeconfig(snap.Mock.KubernetesConfigDir, "proxy.conf", proxyCert, proxyKey)
+
+		fingerprints, err := snaputil.NodeCertificateAuthorities(snap)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(fingerprints).To(ContainElement(snaputil.UnknownCertificateAuthority))
+		g.Expect(fingerprints).To(HaveLen(2))
+	})
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// FieldChange is the change of a single field between two JSON documents.
+type FieldChange struct {
+	// Key is the dotted path of the field, e.g. "load-balancer.cidrs".
+	Key string
+	// Old is the JSON encoded old value of the field. Old is empty if the field was added.
+	Old string
+	// New is the JSON encoded new value of the field. New is empty if the field was removed.
+	New string
+}
+
+// DiffJSON returns the field-level changes between the JSON representations of two values, sorted by key.
+// Nested objects are compared field by field, lists and scalars are compared as a whole.
+func DiffJSON(old, new any) ([]FieldChange, error) {
+	oldFields, err := flattenJSON(old)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flatten old value: %w", err)
+	}
+	newFields, err := flattenJSON(new)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flatten new value: %w", err)
+	}
+
+	keys := slices.Collect(maps.Keys(oldFields))
+	for key := range newFields {
+		if _, ok := oldFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var changes []FieldChange
+	for _, key := range keys {
+		if oldFields[key] != newFields[key] {
+			changes = append(changes, FieldChange{Key: key, Old: oldFields[key], New: newFields[key]})
+		}
+	}
+	return changes, nil
+}
+
+// flattenJSON returns the JSON encoded leaf values of a value by their dotted path.
+func flattenJSON(v any) (map[string]string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	result := make(map[string]string)
+	var walk func(prefix string, v any) error
+	walk = func(prefix string, v any) error {
+		if m, ok := v.(map[string]any); ok {
+			for key, value := range m {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				if err := walk(key, value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if v == nil {
+			return nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", prefix, err)
+		}
+		result[prefix] = string(b)
+		return nil
+	}
+	if err := walk("", generic); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiffJSON(t *testing.T) {
+	g := NewWithT(t)
+
+	old := map[string]any{
+		"enabled": true,
+		"ipPool":  map[string]any{"cidrs": []string{"10.0.0.0/24"}},
+		"l2":      map[string]any{"enabled": true, "interfaces": []string{"eth0"}},
+		"removed": "value",
+	}
+	new := map[string]any{
+		"enabled": true,
+		"ipPool":  map[string]any{"cidrs": []string{"10.0.1.0/24"}},
+		"l2":      map[string]any{"enabled": true, "interfaces": []string{"eth0"}},
+		"bgp":     map[string]any{"enabled": false},
+	}
+
+	changes, err := utils.DiffJSON(old, new)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(changes).To(Equal([]utils.FieldChange{
+		{Key: "bgp.enabled", New: "false"},
+		{Key: "ipPool.cidrs", Old: `["10.0.0.0/24"]`, New: `["10.0.1.0/24"]`},
+		{Key: "removed", Old: `"value"`},
+	}))
+
+	changes, err = utils.DiffJSON(old, old)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(changes).To(BeEmpty())
+
+	changes, err = utils.DiffJSON(nil, map[string]any{"a": 1})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(changes).To(Equal([]utils.FieldChange{{Key: "a", New: "1"}}))
+}
@@ -92,3 +92,19 @@ func MicroclusterWorkerJoinConfigFromMap(m map[string]string) (apiv2.WorkerJoinC
 	}
 	return config, nil
 }
+
+// MicroclusterMapWithClusterBundle adds (a JSON formatted) cluster bundle to the config struct.
+func MicroclusterMapWithClusterBundle(m map[string]string, clusterBundleJSON string) map[string]string {
+	if m == nil {
+		m = make(map[string]string)
+	}
+	m["clusterBundle"] = clusterBundleJSON
+	return m
+}
+
+// MicroclusterClusterBundleFromMap returns the (JSON formatted) cluster bundle from the config struct.
+// The second return value is false if the config struct does not contain a cluster bundle.
+func MicroclusterClusterBundleFromMap(m map[string]string) (string, bool) {
+	v, ok := m["clusterBundle"]
+	return v, ok && v != ""
+}
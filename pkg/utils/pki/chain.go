@@ -0,0 +1,71 @@
+package pkiutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// IsSelfSigned returns true if the certificate is issued and signed by itself, e.g. a root CA.
+func IsSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// ParseCertificates parses all certificates of a PEM bundle.
+func ParseCertificates(bundlePEM string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(bundlePEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+	return certs, nil
+}
+
+// IssuerChain returns the chain of the signing certificate of a CA bundle, which is the first certificate of the bundle.
+// The chain starts with the signing certificate and continues with its issuers from the bundle, up to but excluding
+// the self-signed root. IssuerChain returns an empty string if the signing certificate is self-signed.
+//
+// Leaf certificates signed by an intermediate CA are served together with the issuer chain, so that clients only
+// need to trust the root CA.
+func IssuerChain(bundlePEM string) (string, error) {
+	certs, err := ParseCertificates(bundlePEM)
+	if err != nil {
+		return "", err
+	}
+
+	var chain strings.Builder
+	// NOTE: The bundle length bounds the chain length, in case of a (malicious) loop of certificates.
+	current := certs[0]
+	for i := 0; i < len(certs) && current != nil && !IsSelfSigned(current); i++ {
+		if err := pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: current.Raw}); err != nil {
+			return "", fmt.Errorf("failed to encode certificate: %w", err)
+		}
+
+		issuer := current
+		current = nil
+		for _, cert := range certs {
+			if cert != issuer && issuer.CheckSignatureFrom(cert) == nil {
+				current = cert
+				break
+			}
+		}
+	}
+	return chain.String(), nil
+}
@@ -0,0 +1,86 @@
+package pkiutil_test
+
+import (
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+)
+
+// newIntermediateCA returns a CA certificate and key signed by the parent CA.
+func newIntermediateCA(g Gomega, cn string, notAfter time.Time, parentCert string, parentKey string) (string, string) {
+	parent, parentSigner, err := pkiutil.LoadCertificate(parentCert, parentKey)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: cn}, time.Now(), notAfter, true, nil, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	cert, key, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmECDSAP256, parent, parentSigner.Public(), parentSigner)
+	g.Expect(err).ToNot(HaveOccurred())
+	return cert, key
+}
+
+func TestIssuerChain(t *testing.T) {
+	g := NewWithT(t)
+
+	notBefore := time.Now()
+	root, rootKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "root"}, notBefore, notBefore.AddDate(20, 0, 0), pkiutil.KeyAlgorithmECDSAP256)
+	g.Expect(err).ToNot(HaveOccurred())
+	intermediate, intermediateKey := newIntermediateCA(g, "intermediate", notBefore.AddDate(10, 0, 0), root, rootKey)
+	issuing, _ := newIntermediateCA(g, "issuing", notBefore.AddDate(5, 0, 0), intermediate, intermediateKey)
+
+	for _, tc := range []struct {
+		name        string
+		bundle      string
+		expectChain string
+	}{
+		{name: "SelfSigned", bundle: root},
+		{name: "Intermediate", bundle: intermediate + root, expectChain: intermediate},
+		{name: "IntermediateWithoutRoot", bundle: intermediate, expectChain: intermediate},
+		{name: "IssuingUnordered", bundle: issuing + root + intermediate, expectChain: issuing + intermediate},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			chain, err := pkiutil.IssuerChain(tc.bundle)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(chain).To(Equal(tc.expectChain))
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := pkiutil.IssuerChain("not a certificate")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("IsSelfSigned", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rootCert, _, err := pkiutil.LoadCertificate(root, "")
+		g.Expect(err).ToNot(HaveOccurred())
+		intermediateCert, _, err := pkiutil.LoadCertificate(intermediate, "")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(pkiutil.IsSelfSigned(rootCert)).To(BeTrue())
+		g.Expect(pkiutil.IsSelfSigned(intermediateCert)).To(BeFalse())
+	})
+
+	t.Run("LeafDoesNotOutliveIssuer", func(t *testing.T) {
+		g := NewWithT(t)
+
+		intermediateCert, intermediateSigner, err := pkiutil.LoadCertificate(intermediate, intermediateKey)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: "leaf"}, notBefore, notBefore.AddDate(20, 0, 0), false, nil, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		leaf, _, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmECDSAP256, intermediateCert, intermediateSigner.Public(), intermediateSigner)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		leafCert, _, err := pkiutil.LoadCertificate(leaf, "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(leafCert.NotAfter).To(Equal(intermediateCert.NotAfter))
+	})
+}
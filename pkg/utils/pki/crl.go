@@ -0,0 +1,35 @@
+package pkiutil
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// CreateRevocationList returns a PEM encoded certificate revocation list (CRL) signed by the issuer.
+// The CRL number is the time of the update, so that it increases with every update.
+// The issuer must be allowed to sign CRLs. Certificate authorities generated by older versions may not be.
+func CreateRevocationList(issuer *x509.Certificate, signer crypto.Signer, revoked []x509.RevocationListEntry, thisUpdate time.Time, nextUpdate time.Time) (string, error) {
+	if issuer.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		return "", fmt.Errorf("certificate authority %q is not allowed to sign revocation lists, rotate it to enable revocation", issuer.Subject.CommonName)
+	}
+
+	derBytes, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(thisUpdate.Unix()),
+		ThisUpdate:                thisUpdate,
+		NextUpdate:                nextUpdate,
+		RevokedCertificateEntries: revoked,
+	}, issuer, signer)
+	if err != nil {
+		return "", fmt.Errorf("failed to create revocation list: %w", err)
+	}
+	crlPEM := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: derBytes})
+	if crlPEM == nil {
+		return "", fmt.Errorf("failed to encode revocation list PEM")
+	}
+	return string(crlPEM), nil
+}
@@ -0,0 +1,46 @@
+package pkiutil_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+)
+
+func TestCreateRevocationList(t *testing.T) {
+	g := NewWithT(t)
+
+	notBefore := time.Now()
+	caPEM, caKeyPEM, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "kubernetes-ca-client"}, notBefore, notBefore.AddDate(1, 0, 0), pkiutil.KeyAlgorithmECDSAP256)
+	g.Expect(err).ToNot(HaveOccurred())
+	ca, caKey, err := pkiutil.LoadCertificate(caPEM, caKeyPEM)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	revokedAt := notBefore.UTC().Truncate(time.Second)
+	crlPEM, err := pkiutil.CreateRevocationList(ca, caKey, []x509.RevocationListEntry{{SerialNumber: big.NewInt(42), RevocationTime: revokedAt}}, notBefore, notBefore.Add(time.Hour))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	block, _ := pem.Decode([]byte(crlPEM))
+	g.Expect(block).ToNot(BeNil())
+	g.Expect(block.Type).To(Equal("X509 CRL"))
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(crl.CheckSignatureFrom(ca)).To(Succeed())
+	g.Expect(crl.RevokedCertificateEntries).To(HaveLen(1))
+	g.Expect(crl.RevokedCertificateEntries[0].SerialNumber).To(Equal(big.NewInt(42)))
+	g.Expect(crl.RevokedCertificateEntries[0].RevocationTime).To(Equal(revokedAt))
+
+	t.Run("IssuerWithoutCRLSign", func(t *testing.T) {
+		g := NewWithT(t)
+
+		legacy := *ca
+		legacy.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign
+		_, err := pkiutil.CreateRevocationList(&legacy, caKey, nil, notBefore, notBefore.Add(time.Hour))
+		g.Expect(err).To(MatchError(ContainSubstring("rotate it")))
+	})
+}
@@ -40,7 +40,7 @@ func GenerateCertificate(subject pkix.Name, notBefore, notAfter time.Time, ca bo
 	}
 	if ca {
 		cert.IsCA = true
-		cert.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign
+		cert.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign
 	} else {
 		cert.IsCA = false
 		cert.KeyUsage = x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment | x509.KeyUsageDigitalSignature
@@ -49,21 +49,17 @@ func GenerateCertificate(subject pkix.Name, notBefore, notAfter time.Time, ca bo
 	return cert, nil
 }
 
-func GenerateSelfSignedCA(subject pkix.Name, notBefore time.Time, notAfter time.Time, bits int) (string, string, error) {
+func GenerateSelfSignedCA(subject pkix.Name, notBefore time.Time, notAfter time.Time, alg KeyAlgorithm) (string, string, error) {
 	cert, err := GenerateCertificate(subject, notBefore, notAfter, true, nil, nil)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to generate certificate: %w", err)
 	}
 
-	key, err := rsa.GenerateKey(rand.Reader, bits)
+	key, keyPEM, err := GenerateKey(alg)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to generate RSA private key: %w", err)
-	}
-	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
-	if keyPEM == nil {
-		return "", "", fmt.Errorf("failed to encode private key PEM")
+		return "", "", err
 	}
-	derBytes, err := x509.CreateCertificate(rand.Reader, cert, cert, &key.PublicKey, key)
+	derBytes, err := x509.CreateCertificate(rand.Reader, cert, cert, key.Public(), key)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to self-sign certificate: %w", err)
 	}
@@ -72,25 +68,40 @@ func GenerateSelfSignedCA(subject pkix.Name, notBefore time.Time, notAfter time.
 		return "", "", fmt.Errorf("failed to encode certificate PEM")
 	}
 
-	return string(crtPEM), string(keyPEM), nil
+	return string(crtPEM), keyPEM, nil
 }
 
-func SignCertificate(certificate *x509.Certificate, bits int, parent *x509.Certificate, pub any, priv any) (string, string, error) {
-	key, err := rsa.GenerateKey(rand.Reader, bits)
+// SignCertificate generates a new private key with the given algorithm and signs the certificate for it with
+// the parent certificate and private key. If pub and priv are nil, the certificate is self-signed.
+func SignCertificate(certificate *x509.Certificate, alg KeyAlgorithm, parent *x509.Certificate, pub any, priv any) (string, string, error) {
+	key, keyPEM, err := GenerateKey(alg)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to generate RSA private key: %w", err)
-	}
-	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
-	if keyPEM == nil {
-		return "", "", fmt.Errorf("failed to encode private key PEM")
+		return "", "", err
 	}
 
 	if pub == nil && priv == nil {
 		priv = key
-		pub = &key.PublicKey
+		pub = key.Public()
+	}
+
+	// NOTE: Work on a copy, so that the template of the caller is not modified.
+	template := *certificate
+	if parent == certificate {
+		parent = &template
 	}
+	certificate = &template
 
-	derBytes, err := x509.CreateCertificate(rand.Reader, certificate, parent, &key.PublicKey, priv)
+	// NOTE: Certificates must not outlive their issuer, e.g. an intermediate CA.
+	if parent != certificate && certificate.NotAfter.After(parent.NotAfter) {
+		certificate.NotAfter = parent.NotAfter
+	}
+
+	// NOTE: Key encipherment only applies to RSA keys.
+	if _, ok := key.(*rsa.PrivateKey); !ok {
+		certificate.KeyUsage &^= x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, certificate, parent, key.Public(), priv)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to sign certificate: %w", err)
 	}
@@ -99,7 +110,7 @@ func SignCertificate(certificate *x509.Certificate, bits int, parent *x509.Certi
 		return "", "", fmt.Errorf("failed to encode certificate PEM")
 	}
 
-	return string(crtPEM), string(keyPEM), nil
+	return string(crtPEM), keyPEM, nil
 }
 
 func GenerateRSAKey(bits int) (string, string, error) {
@@ -124,14 +135,10 @@ func GenerateRSAKey(bits int) (string, string, error) {
 }
 
 // GenerateCSR generates a certificate signing request (CSR) and private key for the given subject.
-func GenerateCSR(subject pkix.Name, bits int, dnsSANs []string, ipSANs []net.IP) (string, string, error) {
-	key, err := rsa.GenerateKey(rand.Reader, bits)
+func GenerateCSR(subject pkix.Name, alg KeyAlgorithm, dnsSANs []string, ipSANs []net.IP) (string, string, error) {
+	key, keyPEM, err := GenerateKey(alg)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to generate RSA private key: %w", err)
-	}
-	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
-	if keyPEM == nil {
-		return "", "", fmt.Errorf("failed to encode private key PEM")
+		return "", "", err
 	}
 
 	csrTemplate := &x509.CertificateRequest{
@@ -150,5 +157,5 @@ func GenerateCSR(subject pkix.Name, bits int, dnsSANs []string, ipSANs []net.IP)
 		return "", "", fmt.Errorf("failed to encode certificate request PEM")
 	}
 
-	return string(csrPEM), string(keyPEM), nil
+	return string(csrPEM), keyPEM, nil
 }
@@ -1,6 +1,7 @@
 package pkiutil_test
 
 import (
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"testing"
 	"time"
@@ -11,7 +12,7 @@ import (
 
 func TestGenerateSelfSignedCA(t *testing.T) {
 	notBefore := time.Now()
-	cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "test-cert"}, notBefore, notBefore.AddDate(10, 0, 0), 2048)
+	cert, key, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "test-cert"}, notBefore, notBefore.AddDate(10, 0, 0), pkiutil.KeyAlgorithmRSA2048)
 
 	g := NewWithT(t)
 	g.Expect(err).To(Not(HaveOccurred()))
@@ -32,3 +33,29 @@ func TestGenerateSelfSignedCA(t *testing.T) {
 		g.Expect(key).To(BeNil())
 	})
 }
+
+func TestSignCertificate(t *testing.T) {
+	g := NewWithT(t)
+
+	notBefore := time.Now()
+	caCert, caKey, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "test-ca"}, notBefore, notBefore.AddDate(1, 0, 0), pkiutil.KeyAlgorithmRSA2048)
+	g.Expect(err).To(Not(HaveOccurred()))
+	ca, key, err := pkiutil.LoadCertificate(caCert, caKey)
+	g.Expect(err).To(Not(HaveOccurred()))
+
+	template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: "test-cert"}, notBefore, notBefore.AddDate(10, 0, 0), false, nil, nil)
+	g.Expect(err).To(Not(HaveOccurred()))
+	keyUsage, notAfter := template.KeyUsage, template.NotAfter
+
+	cert, _, err := pkiutil.SignCertificate(template, pkiutil.KeyAlgorithmECDSAP256, ca, key.Public(), key)
+	g.Expect(err).To(Not(HaveOccurred()))
+
+	signed, _, err := pkiutil.LoadCertificate(cert, "")
+	g.Expect(err).To(Not(HaveOccurred()))
+	g.Expect(signed.KeyUsage).To(Equal(x509.KeyUsageDigitalSignature))
+	g.Expect(signed.NotAfter).To(BeTemporally("~", ca.NotAfter, time.Second))
+
+	// the template of the caller is not modified
+	g.Expect(template.KeyUsage).To(Equal(keyUsage))
+	g.Expect(template.NotAfter).To(Equal(notAfter))
+}
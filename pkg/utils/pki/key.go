@@ -0,0 +1,168 @@
+package pkiutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// KeyAlgorithm is the algorithm of the private keys generated for certificates.
+type KeyAlgorithm string
+
+const (
+	KeyAlgorithmRSA2048   KeyAlgorithm = "rsa-2048"
+	KeyAlgorithmRSA4096   KeyAlgorithm = "rsa-4096"
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ecdsa-p256"
+	KeyAlgorithmECDSAP384 KeyAlgorithm = "ecdsa-p384"
+	KeyAlgorithmEd25519   KeyAlgorithm = "ed25519"
+
+	// DefaultKeyAlgorithm is the key algorithm used when none is configured, e.g. by clusters that were bootstrapped
+	// before the key algorithm was selectable.
+	DefaultKeyAlgorithm = KeyAlgorithmRSA2048
+	// BootstrapKeyAlgorithm is the key algorithm selected for new clusters, unless another one is configured.
+	BootstrapKeyAlgorithm = KeyAlgorithmECDSAP256
+)
+
+// ParseKeyAlgorithm parses a key algorithm name. An empty name returns DefaultKeyAlgorithm.
+func ParseKeyAlgorithm(name string) (KeyAlgorithm, error) {
+	switch alg := KeyAlgorithm(name); alg {
+	case "":
+		return DefaultKeyAlgorithm, nil
+	case KeyAlgorithmRSA2048, KeyAlgorithmRSA4096, KeyAlgorithmECDSAP256, KeyAlgorithmECDSAP384, KeyAlgorithmEd25519:
+		return alg, nil
+	default:
+		return "", fmt.Errorf("unsupported key algorithm %q, must be one of %s, %s, %s, %s, %s", name,
+			KeyAlgorithmRSA2048, KeyAlgorithmRSA4096, KeyAlgorithmECDSAP256, KeyAlgorithmECDSAP384, KeyAlgorithmEd25519)
+	}
+}
+
+// GenerateKey generates a new private key with the given algorithm.
+// GenerateKey returns the private key and its PEM encoding. RSA keys are encoded as PKCS#1 ("RSA PRIVATE KEY"),
+// ECDSA keys as SEC 1 ("EC PRIVATE KEY") and Ed25519 keys as PKCS#8 ("PRIVATE KEY").
+func GenerateKey(alg KeyAlgorithm) (crypto.Signer, string, error) {
+	if alg == "" {
+		alg = DefaultKeyAlgorithm
+	}
+
+	var (
+		key   crypto.Signer
+		block *pem.Block
+	)
+	switch alg {
+	case KeyAlgorithmRSA2048, KeyAlgorithmRSA4096:
+		bits := 2048
+		if alg == KeyAlgorithmRSA4096 {
+			bits = 4096
+		}
+		rsaKey, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate RSA private key: %w", err)
+		}
+		key = rsaKey
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}
+	case KeyAlgorithmECDSAP256, KeyAlgorithmECDSAP384:
+		curve := elliptic.P256()
+		if alg == KeyAlgorithmECDSAP384 {
+			curve = elliptic.P384()
+		}
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate ECDSA private key: %w", err)
+		}
+		b, err := x509.MarshalECPrivateKey(ecKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal ECDSA private key: %w", err)
+		}
+		key = ecKey
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: b}
+	case KeyAlgorithmEd25519:
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate Ed25519 private key: %w", err)
+		}
+		b, err := x509.MarshalPKCS8PrivateKey(edKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal Ed25519 private key: %w", err)
+		}
+		key = edKey
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: b}
+	default:
+		return nil, "", fmt.Errorf("unsupported key algorithm %q", alg)
+	}
+
+	keyPEM := pem.EncodeToMemory(block)
+	if keyPEM == nil {
+		return nil, "", fmt.Errorf("failed to encode private key PEM")
+	}
+	return key, string(keyPEM), nil
+}
+
+// LoadPrivateKey parses the specified PEM block and returns the private key.
+// RSA, ECDSA and Ed25519 keys are supported.
+func LoadPrivateKey(keyPEM string) (crypto.Signer, error) {
+	pb, _ := pem.Decode([]byte(keyPEM))
+	if pb == nil {
+		return nil, fmt.Errorf("failed to parse PEM block")
+	}
+	switch pb.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(pb.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		return key, nil
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(pb.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse EC private key: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(pb.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		switch key := parsed.(type) {
+		case *rsa.PrivateKey:
+			return key, nil
+		case *ecdsa.PrivateKey:
+			return key, nil
+		case ed25519.PrivateKey:
+			return key, nil
+		default:
+			return nil, fmt.Errorf("unsupported private key type %T", parsed)
+		}
+	}
+	return nil, fmt.Errorf("unknown private key block type %q", pb.Type)
+}
+
+// KeyAlgorithmOf returns the key algorithm of a public key.
+func KeyAlgorithmOf(pub crypto.PublicKey) (KeyAlgorithm, error) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		switch key.N.BitLen() {
+		case 2048:
+			return KeyAlgorithmRSA2048, nil
+		case 4096:
+			return KeyAlgorithmRSA4096, nil
+		}
+		return "", fmt.Errorf("unsupported RSA key size %d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return KeyAlgorithmECDSAP256, nil
+		case elliptic.P384():
+			return KeyAlgorithmECDSAP384, nil
+		}
+		return "", fmt.Errorf("unsupported ECDSA curve %s", key.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return KeyAlgorithmEd25519, nil
+	}
+	return "", fmt.Errorf("unsupported public key type %T", pub)
+}
@@ -0,0 +1,82 @@
+package pkiutil_test
+
+import (
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+)
+
+func TestKeyAlgorithms(t *testing.T) {
+	for _, alg := range []pkiutil.KeyAlgorithm{
+		pkiutil.KeyAlgorithmRSA2048,
+		pkiutil.KeyAlgorithmRSA4096,
+		pkiutil.KeyAlgorithmECDSAP256,
+		pkiutil.KeyAlgorithmECDSAP384,
+		pkiutil.KeyAlgorithmEd25519,
+	} {
+		t.Run(string(alg), func(t *testing.T) {
+			g := NewWithT(t)
+
+			key, keyPEM, err := pkiutil.GenerateKey(alg)
+			g.Expect(err).To(Not(HaveOccurred()))
+
+			loaded, err := pkiutil.LoadPrivateKey(keyPEM)
+			g.Expect(err).To(Not(HaveOccurred()))
+			g.Expect(loaded.Public()).To(Equal(key.Public()))
+
+			detected, err := pkiutil.KeyAlgorithmOf(loaded.Public())
+			g.Expect(err).To(Not(HaveOccurred()))
+			g.Expect(detected).To(Equal(alg))
+
+			notBefore := time.Now()
+			caCertPEM, caKeyPEM, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "test-ca"}, notBefore, notBefore.AddDate(1, 0, 0), alg)
+			g.Expect(err).To(Not(HaveOccurred()))
+			g.Expect(pkiutil.CertCheck{AllowSelfSigned: true}.ValidateKeypair(caCertPEM, caKeyPEM)).To(Succeed())
+
+			caCert, caKey, err := pkiutil.LoadCertificate(caCertPEM, caKeyPEM)
+			g.Expect(err).To(Not(HaveOccurred()))
+
+			template, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: "test"}, notBefore, notBefore.AddDate(1, 0, 0), false, []string{"test"}, nil)
+			g.Expect(err).To(Not(HaveOccurred()))
+			certPEM, certKeyPEM, err := pkiutil.SignCertificate(template, alg, caCert, caKey.Public(), caKey)
+			g.Expect(err).To(Not(HaveOccurred()))
+			g.Expect(pkiutil.CertCheck{CaPEM: caCertPEM, DNSSANs: []string{"test"}}.ValidateKeypair(certPEM, certKeyPEM)).To(Succeed())
+
+			csrPEM, _, err := pkiutil.GenerateCSR(pkix.Name{CommonName: "test"}, alg, []string{"test"}, nil)
+			g.Expect(err).To(Not(HaveOccurred()))
+			csr, err := pkiutil.LoadCertificateRequest(csrPEM)
+			g.Expect(err).To(Not(HaveOccurred()))
+			g.Expect(csr.CheckSignature()).To(Succeed())
+		})
+	}
+
+	t.Run("MismatchedKey", func(t *testing.T) {
+		g := NewWithT(t)
+
+		notBefore := time.Now()
+		certPEM, _, err := pkiutil.GenerateSelfSignedCA(pkix.Name{CommonName: "test-ca"}, notBefore, notBefore.AddDate(1, 0, 0), pkiutil.KeyAlgorithmECDSAP256)
+		g.Expect(err).To(Not(HaveOccurred()))
+		_, otherKeyPEM, err := pkiutil.GenerateKey(pkiutil.KeyAlgorithmECDSAP256)
+		g.Expect(err).To(Not(HaveOccurred()))
+
+		g.Expect(pkiutil.CertCheck{AllowSelfSigned: true}.ValidateKeypair(certPEM, otherKeyPEM)).To(MatchError(ContainSubstring("does not match")))
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		g := NewWithT(t)
+
+		alg, err := pkiutil.ParseKeyAlgorithm("")
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(alg).To(Equal(pkiutil.DefaultKeyAlgorithm))
+
+		alg, err = pkiutil.ParseKeyAlgorithm("ecdsa-p384")
+		g.Expect(err).To(Not(HaveOccurred()))
+		g.Expect(alg).To(Equal(pkiutil.KeyAlgorithmECDSAP384))
+
+		_, err = pkiutil.ParseKeyAlgorithm("dsa-1024")
+		g.Expect(err).To(HaveOccurred())
+	})
+}
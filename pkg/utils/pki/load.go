@@ -1,6 +1,7 @@
 package pkiutil
 
 import (
+	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -12,7 +13,7 @@ import (
 // LoadCertificate parses the PEM blocks and returns the certificate and private key.
 // LoadCertificate will fail if certPEM is not a valid certificate.
 // LoadCertificate will return a nil private key if keyPEM is empty, but will fail if it is not valid.
-func LoadCertificate(certPEM string, keyPEM string) (*x509.Certificate, *rsa.PrivateKey, error) {
+func LoadCertificate(certPEM string, keyPEM string) (*x509.Certificate, crypto.Signer, error) {
 	decodedCert, _ := pem.Decode([]byte(certPEM))
 	if decodedCert == nil {
 		return nil, nil, fmt.Errorf("failed to parse certificate PEM")
@@ -25,9 +26,9 @@ func LoadCertificate(certPEM string, keyPEM string) (*x509.Certificate, *rsa.Pri
 		return cert, nil, nil
 	}
 
-	key, err := LoadRSAPrivateKey(keyPEM)
+	key, err := LoadPrivateKey(keyPEM)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load RSA private key: %w", err)
+		return nil, nil, fmt.Errorf("failed to load private key: %w", err)
 	}
 
 	return cert, key, nil
@@ -100,7 +101,7 @@ func LoadCertificateRequest(csrPEM string) (*x509.CertificateRequest, error) {
 // loadCertificatePairFromDir reads the certificate and corresponding private
 // key files for the given certificate name from the specified directory. It
 // expects the files to be named "<name>.crt" and "<name>.key".
-func LoadCertificatePairFromDir(baseDir string, name string) (*x509.Certificate, *rsa.PrivateKey, error) {
+func LoadCertificatePairFromDir(baseDir string, name string) (*x509.Certificate, crypto.Signer, error) {
 	certBytes, err := os.ReadFile(filepath.Join(baseDir, fmt.Sprintf("%s.crt", name)))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to read %s.crt: %w", name, err)
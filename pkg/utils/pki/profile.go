@@ -0,0 +1,106 @@
+package pkiutil
+
+import (
+	"crypto/x509"
+	"net"
+	"slices"
+	"time"
+)
+
+// DefaultProfileName is the name of the profile that applies to certificates without a profile of their own.
+const DefaultProfileName = "default"
+
+// Profile customizes the certificates that are generated or signed by k8sd.
+type Profile struct {
+	// Lifetime is the maximum lifetime of the certificate. Longer lifetimes are capped. Zero keeps the lifetime as is.
+	Lifetime time.Duration
+	// DNSSANs are additional DNS subject alternative names.
+	DNSSANs []string
+	// IPSANs are additional IP subject alternative names.
+	IPSANs []net.IP
+	// ExtKeyUsage replaces the extended key usages of the certificate, if not empty.
+	ExtKeyUsage []x509.ExtKeyUsage
+	// Country, Province, Locality and OrganizationalUnit replace the respective subject fields, if not empty.
+	// The common name and organization cannot be customized, as Kubernetes uses them as the user and group names.
+	Country            []string
+	Province           []string
+	Locality           []string
+	OrganizationalUnit []string
+}
+
+// Apply customizes the certificate template according to the profile.
+func (p Profile) Apply(cert *x509.Certificate) {
+	if p.Lifetime > 0 {
+		if notAfter := cert.NotBefore.Add(p.Lifetime); notAfter.Before(cert.NotAfter) {
+			cert.NotAfter = notAfter
+		}
+	}
+	if len(p.DNSSANs) > 0 {
+		cert.DNSNames = uniqueStrings(append(slices.Clone(cert.DNSNames), p.DNSSANs...))
+	}
+	if len(p.IPSANs) > 0 {
+		cert.IPAddresses = uniqueIPs(append(slices.Clone(cert.IPAddresses), p.IPSANs...))
+	}
+	if len(p.ExtKeyUsage) > 0 {
+		cert.ExtKeyUsage = slices.Clone(p.ExtKeyUsage)
+	}
+	if len(p.Country) > 0 {
+		cert.Subject.Country = p.Country
+	}
+	if len(p.Province) > 0 {
+		cert.Subject.Province = p.Province
+	}
+	if len(p.Locality) > 0 {
+		cert.Subject.Locality = p.Locality
+	}
+	if len(p.OrganizationalUnit) > 0 {
+		cert.Subject.OrganizationalUnit = p.OrganizationalUnit
+	}
+}
+
+// Profiles are certificate profiles by certificate name.
+type Profiles map[string]Profile
+
+// Get returns the profile of the named certificate. Get falls back to the default profile, and returns an empty
+// profile if neither is set.
+func (p Profiles) Get(name string) Profile {
+	if profile, ok := p[name]; ok {
+		return profile
+	}
+	return p[DefaultProfileName]
+}
+
+// uniqueStrings returns the list of DNS SANs without duplicates, preserving their order.
+func uniqueStrings(sans []string) []string {
+	if sans == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(sans))
+	result := make([]string, 0, len(sans))
+	for _, san := range sans {
+		if _, ok := seen[san]; ok {
+			continue
+		}
+		seen[san] = struct{}{}
+		result = append(result, san)
+	}
+	return result
+}
+
+// uniqueIPs returns the list of IP SANs without duplicates, preserving their order.
+func uniqueIPs(sans []net.IP) []net.IP {
+	if sans == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(sans))
+	result := make([]net.IP, 0, len(sans))
+	for _, san := range sans {
+		key := san.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, san)
+	}
+	return result
+}
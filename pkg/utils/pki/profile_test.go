@@ -0,0 +1,78 @@
+package pkiutil_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+	"time"
+
+	pkiutil "github.com/canonical/k8sd/pkg/utils/pki"
+	. "github.com/onsi/gomega"
+)
+
+func TestProfileApply(t *testing.T) {
+	notBefore := time.Now()
+	newTemplate := func(g Gomega) *x509.Certificate {
+		cert, err := pkiutil.GenerateCertificate(pkix.Name{CommonName: "kube-apiserver", Organization: []string{"org"}}, notBefore, notBefore.AddDate(1, 0, 0), false, []string{"kubernetes"}, []net.IP{net.ParseIP("10.0.0.1")})
+		g.Expect(err).To(Not(HaveOccurred()))
+		return cert
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		g := NewWithT(t)
+		cert := newTemplate(g)
+		pkiutil.Profile{}.Apply(cert)
+
+		g.Expect(cert).To(Equal(newTemplateWithSerial(newTemplate(g), cert)))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		g := NewWithT(t)
+		cert := newTemplate(g)
+		pkiutil.Profile{
+			Lifetime:           90 * 24 * time.Hour,
+			DNSSANs:            []string{"kubernetes", "api.example.com"},
+			IPSANs:             []net.IP{net.ParseIP("10.0.0.2")},
+			ExtKeyUsage:        []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			Country:            []string{"GB"},
+			OrganizationalUnit: []string{"platform"},
+		}.Apply(cert)
+
+		g.Expect(cert.NotAfter).To(Equal(notBefore.Add(90 * 24 * time.Hour)))
+		g.Expect(cert.DNSNames).To(Equal([]string{"kubernetes", "api.example.com"}))
+		g.Expect(cert.IPAddresses).To(Equal([]net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}))
+		g.Expect(cert.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}))
+		g.Expect(cert.Subject.CommonName).To(Equal("kube-apiserver"))
+		g.Expect(cert.Subject.Organization).To(Equal([]string{"org"}))
+		g.Expect(cert.Subject.Country).To(Equal([]string{"GB"}))
+		g.Expect(cert.Subject.OrganizationalUnit).To(Equal([]string{"platform"}))
+	})
+
+	t.Run("LifetimeIsMaximum", func(t *testing.T) {
+		g := NewWithT(t)
+		cert := newTemplate(g)
+		pkiutil.Profile{Lifetime: 10 * 365 * 24 * time.Hour}.Apply(cert)
+
+		g.Expect(cert.NotAfter).To(Equal(notBefore.AddDate(1, 0, 0)))
+	})
+}
+
+func TestProfilesGet(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(pkiutil.Profiles(nil).Get("apiserver")).To(Equal(pkiutil.Profile{}))
+
+	profiles := pkiutil.Profiles{
+		"default":   {Lifetime: time.Hour},
+		"apiserver": {Lifetime: time.Minute},
+	}
+	g.Expect(profiles.Get("apiserver").Lifetime).To(Equal(time.Minute))
+	g.Expect(profiles.Get("kubelet").Lifetime).To(Equal(time.Hour))
+}
+
+// newTemplateWithSerial returns expected with the serial number of actual, since templates have random serial numbers.
+func newTemplateWithSerial(expected *x509.Certificate, actual *x509.Certificate) *x509.Certificate {
+	expected.SerialNumber = actual.SerialNumber
+	return expected
+}
@@ -1,6 +1,7 @@
 package pkiutil
 
 import (
+	"crypto"
 	"crypto/x509"
 	"fmt"
 	"slices"
@@ -22,10 +23,15 @@ type CertCheck struct {
 }
 
 func (check CertCheck) ValidateKeypair(certPEM string, keyPEM string) error {
-	cert, _, err := LoadCertificate(certPEM, keyPEM)
+	cert, key, err := LoadCertificate(certPEM, keyPEM)
 	if err != nil {
 		return fmt.Errorf("failed to parse certificate: %w", err)
 	}
+	if key != nil {
+		if pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(cert.PublicKey) {
+			return fmt.Errorf("private key does not match the certificate")
+		}
+	}
 
 	return check.ValidateCert(cert)
 }
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"regexp"
+
+	"gopkg.in/yaml.v2"
 )
 
 // YamlCommentLines adds "# " at the beginning of each line.
@@ -10,3 +13,71 @@ func YamlCommentLines(content []byte) []byte {
 	out := re.ReplaceAll(content, []byte("# "))
 	return out
 }
+
+// UnmarshalYAMLMap parses a YAML document into a map[string]any.
+// Nested maps are also converted to map[string]any, so that the result can be serialized to JSON.
+func UnmarshalYAMLMap(content []byte) (map[string]any, error) {
+	var raw map[string]any
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	result, err := normalizeYAMLValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return map[string]any{}, nil
+	}
+	return result.(map[string]any), nil
+}
+
+// NormalizeYAMLMap converts the nested maps of a map that was decoded from YAML to map[string]any,
+// so that the result can be serialized to JSON.
+func NormalizeYAMLMap(m map[string]any) (map[string]any, error) {
+	result, err := normalizeYAMLValue(m)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return result.(map[string]any), nil
+}
+
+func normalizeYAMLValue(value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		if v == nil {
+			return nil, nil
+		}
+		result := make(map[string]any, len(v))
+		for key, val := range v {
+			var err error
+			if result[key], err = normalizeYAMLValue(val); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, val := range v {
+			strKey, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("map key %v is not a string", key)
+			}
+			var err error
+			if result[strKey], err = normalizeYAMLValue(val); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(v))
+		for i, val := range v {
+			var err error
+			if result[i], err = normalizeYAMLValue(val); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
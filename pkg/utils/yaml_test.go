@@ -0,0 +1,52 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/canonical/k8sd/pkg/utils"
+	. "github.com/onsi/gomega"
+)
+
+func TestUnmarshalYAMLMap(t *testing.T) {
+	t.Run("Nested", func(t *testing.T) {
+		g := NewWithT(t)
+
+		result, err := utils.UnmarshalYAMLMap([]byte("replicas: 2\nimage:\n  tag: v1\nargs:\n- a: b\n"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result).To(Equal(map[string]any{
+			"replicas": 2,
+			"image":    map[string]any{"tag": "v1"},
+			"args":     []any{map[string]any{"a": "b"}},
+		}))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		g := NewWithT(t)
+
+		result, err := utils.UnmarshalYAMLMap([]byte(""))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result).To(BeEmpty())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := utils.UnmarshalYAMLMap([]byte("- a\n- b\n"))
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestNormalizeYAMLMap(t *testing.T) {
+	g := NewWithT(t)
+
+	result, err := utils.NormalizeYAMLMap(map[string]any{"image": map[any]any{"tag": "v1"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result).To(Equal(map[string]any{"image": map[string]any{"tag": "v1"}}))
+
+	_, err = utils.NormalizeYAMLMap(map[string]any{"ports": map[any]any{80: "http"}})
+	g.Expect(err).To(HaveOccurred())
+
+	result, err = utils.NormalizeYAMLMap(nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(result).To(BeNil())
+}